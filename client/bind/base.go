@@ -0,0 +1,110 @@
+package bind
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/vitelabs/go-vite/v2/client"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/rpcapi/api"
+	"github.com/vitelabs/go-vite/v2/vm/abi"
+)
+
+var (
+	errEventNotMatch = errors.New("vm log does not match the event")
+)
+
+// TransactOpts is the options to build a request block calling a contract method
+type TransactOpts struct {
+	SelfAddr types.Address
+	Amount   *big.Int           // the amount transferred to the contract, nil means 0
+	TokenId  types.TokenTypeId  // the token transferred to the contract, zero means VITE
+	Prev     *ledger.HashHeight // the previous block of SelfAddr, nil means querying the latest one
+}
+
+// BoundContract is the base of the generated bindings, it packs and unpacks data
+// by the contract abi, and talks to the node by client.Client and client.RpcClient.
+type BoundContract struct {
+	Address      types.Address
+	ABI          abi.ABIContract
+	OffChainCode string // hex encoded offchain code, used by the offchain getters
+
+	cli client.Client
+	rpc client.RpcClient
+}
+
+// NewBoundContract creates a BoundContract from the abi json of the contract
+func NewBoundContract(addr types.Address, abiJSON string, offChainCode string, rpc client.RpcClient) (*BoundContract, error) {
+	contractAbi, err := abi.JSONToABIContract(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, err
+	}
+	cli, err := client.NewClient(rpc)
+	if err != nil {
+		return nil, err
+	}
+	return &BoundContract{
+		Address:      addr,
+		ABI:          contractAbi,
+		OffChainCode: offChainCode,
+		cli:          cli,
+		rpc:          rpc,
+	}, nil
+}
+
+// Transact builds a request block calling the method, the block should be signed and sent by the caller
+func (c *BoundContract) Transact(opts *TransactOpts, method string, params ...interface{}) (*api.AccountBlock, error) {
+	data, err := c.ABI.PackMethod(method, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := opts.Amount
+	if amount == nil {
+		amount = big.NewInt(0)
+	}
+	tokenId := opts.TokenId
+	if tokenId == types.ZERO_TOKENID {
+		tokenId = ledger.ViteTokenId
+	}
+
+	return c.cli.BuildNormalRequestBlock(client.RequestTxParams{
+		ToAddr:   c.Address,
+		SelfAddr: opts.SelfAddr,
+		Amount:   amount,
+		TokenId:  tokenId,
+		Data:     data,
+	}, opts.Prev)
+}
+
+// Call calls the offchain method, and returns the unpacked outputs
+func (c *BoundContract) Call(method string, params ...interface{}) ([]interface{}, error) {
+	data, err := c.ABI.PackOffChain(method, params...)
+	if err != nil {
+		return nil, err
+	}
+	output, err := c.rpc.CallOffChainMethod(api.CallOffChainMethodParam{
+		SelfAddr:     c.Address,
+		OffChainCode: c.OffChainCode,
+		Data:         data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.ABI.OffChains[method].Outputs.UnpackValues(output)
+}
+
+// ParseLog decodes the inputs of the event from the vm log, indexed inputs of
+// dynamic types are returned as the topic hashes
+func (c *BoundContract) ParseLog(event string, log *ledger.VmLog) ([]interface{}, error) {
+	e, ok := c.ABI.Events[event]
+	if !ok {
+		return nil, errors.New("event " + event + " not found")
+	}
+	if log == nil || len(log.Topics) != len(e.IndexedInputs)+1 || log.Topics[0] != e.Id() {
+		return nil, errEventNotMatch
+	}
+	return e.DirectUnPack(log.Topics, log.Data)
+}
@@ -0,0 +1,413 @@
+// Package bind generates typed Go bindings of contracts from the abi json,
+// and provides the base the generated bindings work on.
+package bind
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/vitelabs/go-vite/v2/vm/abi"
+)
+
+type tmplArg struct {
+	Name string
+	Type string
+}
+
+type tmplMethod struct {
+	Name     string // go name
+	Original string // name in the abi
+	Params   []tmplArg
+	Outputs  []tmplArg
+}
+
+type tmplEvent struct {
+	Name     string // go name of the event struct
+	Parser   string // go name of the parse method
+	Original string
+	Fields   []tmplArg
+}
+
+type tmplStruct struct {
+	Name   string
+	Fields []tmplArg
+}
+
+type tmplContract struct {
+	Package      string
+	Type         string
+	ABI          string
+	OffChainCode string
+	Methods      []tmplMethod
+	OffChains    []tmplMethod
+	Events       []tmplEvent
+	Structs      []*tmplStruct
+}
+
+// Bind generates the go source of the binding named typeName in package pkg.
+// offChainCode is the hex encoded offchain code of the contract, it can be empty
+// if the contract has no offchain methods.
+func Bind(typeName string, abiJSON string, offChainCode string, pkg string) (string, error) {
+	contractAbi, err := abi.JSONToABIContract(strings.NewReader(abiJSON))
+	if err != nil {
+		return "", err
+	}
+
+	g := &generator{
+		typeName:    typeName,
+		structs:     make(map[string]*tmplStruct),
+		structNames: map[string]bool{typeName: true},
+		methodNames: map[string]bool{"Contract": true},
+	}
+	contract := &tmplContract{
+		Package:      pkg,
+		Type:         typeName,
+		ABI:          strconv.Quote(abiJSON),
+		OffChainCode: strconv.Quote(offChainCode),
+	}
+
+	for _, name := range sortedMethodNames(contractAbi.Methods) {
+		contract.Methods = append(contract.Methods, g.method(contractAbi.Methods[name], false))
+	}
+	for _, name := range sortedMethodNames(contractAbi.OffChains) {
+		contract.OffChains = append(contract.OffChains, g.method(contractAbi.OffChains[name], true))
+	}
+
+	eventNames := make([]string, 0, len(contractAbi.Events))
+	for name := range contractAbi.Events {
+		eventNames = append(eventNames, name)
+	}
+	sort.Strings(eventNames)
+	for _, name := range eventNames {
+		contract.Events = append(contract.Events, g.event(contractAbi.Events[name]))
+	}
+	contract.Structs = g.structList
+
+	buf := new(bytes.Buffer)
+	if err = bindTemplate.Execute(buf, contract); err != nil {
+		return "", err
+	}
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to format generated code: %v\n%s", err, buf.String())
+	}
+	return string(code), nil
+}
+
+type generator struct {
+	typeName    string
+	structs     map[string]*tmplStruct // tuple signature -> struct
+	structNames map[string]bool        // names of the generated types
+	structList  []*tmplStruct
+	methodNames map[string]bool // names of the generated methods
+}
+
+// uniqueName appends a number to name until it is not in used, and marks it used
+func uniqueName(name string, used map[string]bool) string {
+	base := name
+	for i := 1; used[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	used[name] = true
+	return name
+}
+
+func sortedMethodNames(methods map[string]abi.Method) []string {
+	names := make([]string, 0, len(methods))
+	for name := range methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (g *generator) method(m abi.Method, offChain bool) tmplMethod {
+	method := tmplMethod{
+		Name:     toCamelCase(m.Name),
+		Original: m.Name,
+	}
+	if offChain && g.methodNames[method.Name] {
+		// an offchain method may have the same name as a method
+		method.Name += "OffChain"
+	}
+	method.Name = uniqueName(method.Name, g.methodNames)
+
+	used := map[string]bool{"c": true, "opts": true, "values": true, "err": true}
+	for i, input := range m.Inputs {
+		name := toLowerCamelCase(input.Name)
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		for used[name] || token.IsKeyword(name) {
+			name += "_"
+		}
+		used[name] = true
+		method.Params = append(method.Params, tmplArg{name, g.bindType(input.Type, input.Name)})
+	}
+
+	if offChain {
+		for i, output := range m.Outputs {
+			name := fmt.Sprintf("out%d", i)
+			for used[name] {
+				name += "_"
+			}
+			used[name] = true
+			method.Outputs = append(method.Outputs, tmplArg{name, g.bindType(output.Type, output.Name)})
+		}
+	}
+
+	return method
+}
+
+func (g *generator) event(e abi.Event) tmplEvent {
+	event := tmplEvent{
+		Name:     uniqueName(g.typeName+toCamelCase(e.Name), g.structNames),
+		Parser:   uniqueName("Parse"+toCamelCase(e.Name), g.methodNames),
+		Original: e.Name,
+	}
+
+	used := map[string]bool{"Raw": true}
+	for i, input := range e.Inputs {
+		name := toCamelCase(input.Name)
+		if name == "" {
+			name = fmt.Sprintf("Arg%d", i)
+		}
+		for used[name] {
+			name += "_"
+		}
+		used[name] = true
+
+		typ := g.bindType(input.Type, input.Name)
+		if input.Indexed && isHashedTopic(input.Type) {
+			// only the hash of dynamic types is kept in the topics
+			typ = "types.Hash"
+		}
+		event.Fields = append(event.Fields, tmplArg{name, typ})
+	}
+
+	return event
+}
+
+// isHashedTopic returns whether the indexed input is saved as a hash in the topics, see abi.Event.DirectUnPack
+func isHashedTopic(t abi.Type) bool {
+	switch t.T {
+	case abi.ArrayTy, abi.StringTy, abi.SliceTy, abi.BytesTy, abi.TupleTy:
+		return true
+	}
+	return false
+}
+
+// bindType returns the go type of t, hint is used to name the struct of a tuple
+func (g *generator) bindType(t abi.Type, hint string) string {
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		switch t.Size {
+		case 8, 16, 32, 64:
+			if t.T == abi.UintTy {
+				return fmt.Sprintf("uint%d", t.Size)
+			}
+			return fmt.Sprintf("int%d", t.Size)
+		}
+		return "*big.Int"
+	case abi.BoolTy:
+		return "bool"
+	case abi.StringTy:
+		return "string"
+	case abi.AddressTy:
+		return "types.Address"
+	case abi.GidTy:
+		return "types.Gid"
+	case abi.TokenIdTy:
+		return "types.TokenTypeId"
+	case abi.FixedBytesTy:
+		return fmt.Sprintf("[%d]byte", t.Size)
+	case abi.BytesTy:
+		return "[]byte"
+	case abi.SliceTy:
+		return "[]" + g.bindType(*t.Elem, hint)
+	case abi.ArrayTy:
+		return fmt.Sprintf("[%d]%s", t.Size, g.bindType(*t.Elem, hint))
+	case abi.TupleTy:
+		return g.bindStruct(t, hint)
+	}
+	return "interface{}"
+}
+
+// bindStruct returns the name of the struct generated for the tuple, tuples of the same signature share one struct
+func (g *generator) bindStruct(t abi.Type, hint string) string {
+	if s, ok := g.structs[t.String()]; ok {
+		return s.Name
+	}
+
+	name := g.typeName + toCamelCase(hint)
+	if name == g.typeName {
+		name += "Struct"
+	}
+
+	s := &tmplStruct{Name: uniqueName(name, g.structNames)}
+	g.structs[t.String()] = s
+	for i, elem := range t.TupleElems {
+		raw := t.TupleRawNames[i]
+		// same as the field name of the abi tuple type
+		s.Fields = append(s.Fields, tmplArg{capitalise(raw), g.bindType(*elem, raw)})
+	}
+	// nested structs are listed first
+	g.structList = append(g.structList, s)
+	return s.Name
+}
+
+// capitalise makes the first character upper case, also removing any prefixing underscores
+func capitalise(input string) string {
+	input = strings.TrimLeft(input, "_")
+	if len(input) == 0 {
+		return ""
+	}
+	return strings.ToUpper(input[:1]) + input[1:]
+}
+
+// toCamelCase converts an abi name like `get_balance` to `GetBalance`
+func toCamelCase(input string) string {
+	parts := strings.FieldsFunc(input, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, part := range parts {
+		parts[i] = capitalise(part)
+	}
+	name := strings.Join(parts, "")
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "X" + name
+	}
+	return name
+}
+
+// toLowerCamelCase converts an abi name like `_to_addr` to `toAddr`
+func toLowerCamelCase(input string) string {
+	name := toCamelCase(input)
+	if name == "" {
+		return ""
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+var bindTemplate = template.Must(template.New("bind").Parse(tmplSource))
+
+const tmplSource = `// Code generated by abigen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"math/big"
+
+	"github.com/vitelabs/go-vite/v2/client"
+	"github.com/vitelabs/go-vite/v2/client/bind"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/rpcapi/api"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = types.Address{}
+	_ = ledger.VmLog{}
+	_ = api.AccountBlock{}
+)
+
+// {{.Type}}ABI is the abi json of {{.Type}}
+const {{.Type}}ABI = {{.ABI}}
+
+// {{.Type}}OffChainCode is the hex encoded offchain code of {{.Type}}
+const {{.Type}}OffChainCode = {{.OffChainCode}}
+{{range .Structs}}
+// {{.Name}} is a tuple in the abi of {{$.Type}}
+type {{.Name}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}}
+{{- end}}
+}
+{{end}}
+// {{.Type}} is the typed binding of the contract
+type {{.Type}} struct {
+	contract *bind.BoundContract
+}
+
+// New{{.Type}} creates a binding of the contract deployed at addr
+func New{{.Type}}(addr types.Address, rpc client.RpcClient) (*{{.Type}}, error) {
+	contract, err := bind.NewBoundContract(addr, {{.Type}}ABI, {{.Type}}OffChainCode, rpc)
+	if err != nil {
+		return nil, err
+	}
+	return &{{.Type}}{contract: contract}, nil
+}
+
+// Contract returns the underlying BoundContract
+func (c *{{.Type}}) Contract() *bind.BoundContract {
+	return c.contract
+}
+{{range .Methods}}
+// {{.Name}} builds a request block calling the method {{.Original}}, the block should be signed and sent by the caller
+func (c *{{$.Type}}) {{.Name}}(opts *bind.TransactOpts{{range .Params}}, {{.Name}} {{.Type}}{{end}}) (*api.AccountBlock, error) {
+	return c.contract.Transact(opts, "{{.Original}}"{{range .Params}}, {{.Name}}{{end}})
+}
+{{end}}
+{{- range .OffChains}}
+// {{.Name}} calls the offchain method {{.Original}}
+func (c *{{$.Type}}) {{.Name}}({{range $i, $p := .Params}}{{if $i}}, {{end}}{{$p.Name}} {{$p.Type}}{{end}}) ({{range .Outputs}}{{.Name}} {{.Type}}, {{end}}err error) {
+{{- if not .Outputs}}
+	_, err = c.contract.Call("{{.Original}}"{{range .Params}}, {{.Name}}{{end}})
+{{- else}}
+	values, err := c.contract.Call("{{.Original}}"{{range .Params}}, {{.Name}}{{end}})
+	if err != nil {
+		return
+	}
+{{- end}}
+{{- if eq (len .Outputs) 1}}
+	err = c.contract.ABI.OffChains["{{.Original}}"].Outputs.Copy(&{{(index .Outputs 0).Name}}, values)
+{{- else if .Outputs}}
+	err = c.contract.ABI.OffChains["{{.Original}}"].Outputs.Copy(&[]interface{}{ {{- range $i, $o := .Outputs}}{{if $i}}, {{end}}&{{$o.Name}}{{end -}} }, values)
+{{- end}}
+	return
+}
+{{end}}
+{{- range .Events}}
+// {{.Name}} is the event {{.Original}} of {{$.Type}}
+type {{.Name}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}}
+{{- end}}
+	Raw *ledger.VmLog // the vm log carrying the event
+}
+
+// {{.Parser}} decodes the event {{.Original}} from the vm log
+func (c *{{$.Type}}) {{.Parser}}(log *ledger.VmLog) (*{{.Name}}, error) {
+{{- if not .Fields}}
+	if _, err := c.contract.ParseLog("{{.Original}}", log); err != nil {
+		return nil, err
+	}
+	return &{{.Name}}{Raw: log}, nil
+{{- else}}
+	values, err := c.contract.ParseLog("{{.Original}}", log)
+	if err != nil {
+		return nil, err
+	}
+	event := &{{.Name}}{Raw: log}
+{{- if eq (len .Fields) 1}}
+	if err = c.contract.ABI.Events["{{.Original}}"].Inputs.Copy(&event.{{(index .Fields 0).Name}}, values); err != nil {
+		return nil, err
+	}
+{{- else if .Fields}}
+	if err = c.contract.ABI.Events["{{.Original}}"].Inputs.Copy(&[]interface{}{ {{- range $i, $f := .Fields}}{{if $i}}, {{end}}&event.{{$f.Name}}{{end -}} }, values); err != nil {
+		return nil, err
+	}
+{{- end}}
+	return event, nil
+{{- end}}
+}
+{{end}}`
@@ -0,0 +1,183 @@
+package bind
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitelabs/go-vite/v2/client"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/rpcapi/api"
+)
+
+const testABI = `[
+{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}]},
+{"type":"function","name":"submit","inputs":[{"name":"order","type":"tuple","components":[{"name":"id","type":"uint64"},{"name":"memo","type":"string"}]}]},
+{"type":"offchain","name":"balanceOf","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+{"type":"offchain","name":"transfer","inputs":[],"outputs":[]},
+{"type":"offchain","name":"getOrder","inputs":[{"name":"id","type":"uint64"},{"name":"kind","type":"int8"}],"outputs":[{"name":"","type":"tuple","components":[{"name":"id","type":"uint64"},{"name":"memo","type":"string"}]},{"name":"","type":"uint8"},{"name":"","type":"int16[]"}]},
+{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"memo","type":"string","indexed":true},{"name":"amount","type":"uint256"}]},
+{"type":"event","name":"paused","inputs":[]},
+{"type":"event","name":"settled","inputs":[{"name":"id","type":"uint32","indexed":true},{"name":"order","type":"tuple","components":[{"name":"id","type":"uint64"},{"name":"memo","type":"string"}]},{"name":"rate","type":"int64"}]}
+]`
+
+type mockRpc struct {
+	client.RpcClient
+	output []byte
+}
+
+func (m *mockRpc) CallOffChainMethod(param api.CallOffChainMethodParam) ([]byte, error) {
+	return m.output, nil
+}
+
+func TestBind(t *testing.T) {
+	code, err := Bind("Token", testABI, "", "token")
+	require.NoError(t, err)
+
+	_, err = parser.ParseFile(token.NewFileSet(), "token.go", code, 0)
+	require.NoError(t, err)
+
+	for _, s := range []string{
+		"package token",
+		"func NewToken(",
+		"type TokenOrder struct",
+		"func (c *Token) Transfer(",
+		"func (c *Token) Submit(",
+		"func (c *Token) BalanceOf(",
+		"func (c *Token) TransferOffChain(",
+		"type TokenTransfer struct",
+		"func (c *Token) ParseTransfer(",
+		"type TokenPaused struct",
+		"func (c *Token) ParsePaused(",
+		"func (c *Token) GetOrder(",
+		"type TokenSettled struct",
+	} {
+		assert.True(t, strings.Contains(code, s), "missing %q", s)
+	}
+
+	_, err = Bind("Token", "[", "", "token")
+	assert.Error(t, err)
+}
+
+// useToken checks the go types of the generated binding
+const useToken = `package token
+
+import (
+	"math/big"
+
+	"github.com/vitelabs/go-vite/v2/client/bind"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+func use(c *Token, opts *bind.TransactOpts, log *ledger.VmLog) error {
+	if _, err := c.Transfer(opts, types.Address{}, big.NewInt(1)); err != nil {
+		return err
+	}
+	if _, err := c.Submit(opts, TokenOrder{Id: 1, Memo: "memo"}); err != nil {
+		return err
+	}
+	var balance *big.Int
+	var order TokenOrder
+	var flag uint8
+	var rates []int16
+	var err error
+	if balance, err = c.BalanceOf(types.Address{}); err != nil || balance == nil {
+		return err
+	}
+	if order, flag, rates, err = c.GetOrder(uint64(1), int8(-1)); err != nil {
+		return err
+	}
+	_, _, _ = order, flag, rates
+
+	settled, err := c.ParseSettled(log)
+	if err != nil {
+		return err
+	}
+	var id uint32 = settled.Id
+	order = settled.Order
+	var rate int64 = settled.Rate
+	_, _ = id, rate
+
+	transfer, err := c.ParseTransfer(log)
+	if err != nil {
+		return err
+	}
+	var memo types.Hash = transfer.Memo
+	_ = memo
+	return c.TransferOffChain()
+}
+`
+
+// TestBind_Compile builds the generated binding in a package of the module
+func TestBind_Compile(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("the go tool is not found")
+	}
+	code, err := Bind("Token", testABI, "", "token")
+	require.NoError(t, err)
+
+	// the directories starting with _ are ignored by ./...
+	dir, err := ioutil.TempDir(".", "_bindtest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "token.go"), []byte(code), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "use.go"), []byte(useToken), 0644))
+
+	output, err := exec.Command(goTool, "build", "-o", os.DevNull, "./"+filepath.Base(dir)).CombinedOutput()
+	require.NoError(t, err, "%s\n%s", output, code)
+}
+
+func TestBoundContract(t *testing.T) {
+	rpc := &mockRpc{}
+	contract, err := NewBoundContract(types.AddressDexFund, testABI, "", rpc)
+	require.NoError(t, err)
+
+	// transact
+	to := types.AddressDexTrade
+	block, err := contract.Transact(&TransactOpts{SelfAddr: types.AddressQuota, Prev: &ledger.HashHeight{Height: 1}}, "transfer", to, big.NewInt(100))
+	require.NoError(t, err)
+	data, err := contract.ABI.PackMethod("transfer", to, big.NewInt(100))
+	require.NoError(t, err)
+	assert.Equal(t, data, block.Data)
+	assert.Equal(t, ledger.ViteTokenId, block.TokenId)
+	assert.Equal(t, "0", *block.Amount)
+
+	// call
+	rpc.output, err = contract.ABI.OffChains["balanceOf"].Outputs.Pack(big.NewInt(42))
+	require.NoError(t, err)
+	values, err := contract.Call("balanceOf", to)
+	require.NoError(t, err)
+	var balance *big.Int
+	require.NoError(t, contract.ABI.OffChains["balanceOf"].Outputs.Copy(&balance, values))
+	assert.Equal(t, int64(42), balance.Int64())
+
+	// parse log
+	topics, logData, err := contract.ABI.PackEvent("Transfer", to, "hello", big.NewInt(7))
+	require.NoError(t, err)
+	values, err = contract.ParseLog("Transfer", &ledger.VmLog{Topics: topics, Data: logData})
+	require.NoError(t, err)
+	var event struct {
+		From   types.Address
+		Memo   types.Hash
+		Amount *big.Int
+	}
+	require.NoError(t, contract.ABI.Events["Transfer"].Inputs.Copy(&[]interface{}{&event.From, &event.Memo, &event.Amount}, values))
+	assert.Equal(t, to, event.From)
+	assert.Equal(t, topics[2], event.Memo)
+	assert.Equal(t, int64(7), event.Amount.Int64())
+
+	_, err = contract.ParseLog("paused", &ledger.VmLog{Topics: topics, Data: logData})
+	assert.Equal(t, errEventNotMatch, err)
+}
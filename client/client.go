@@ -8,11 +8,11 @@ import (
 	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/errors"
 	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+	"github.com/vitelabs/go-vite/v2/interfaces"
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
 	"github.com/vitelabs/go-vite/v2/rpcapi/api"
 	"github.com/vitelabs/go-vite/v2/vm/abi"
 	"github.com/vitelabs/go-vite/v2/vm/util"
-	"github.com/vitelabs/go-vite/v2/wallet/entropystore"
 	"github.com/vitelabs/go-vite/v2/wallet/hd-bip/derivation"
 )
 
@@ -49,7 +49,7 @@ type Client interface {
 	BuildResponseBlock(params ResponseTxParams, prev *ledger.HashHeight) (block *api.AccountBlock, err error)
 	GetBalance(addr types.Address, tokenId types.TokenTypeId) (*big.Int, *big.Int, error)
 	GetBalanceAll(addr types.Address) (*api.RpcAccountInfo, *api.RpcAccountInfo, error)
-	SignData(wallet *entropystore.Manager, block *api.AccountBlock) error
+	SignData(signer interfaces.Signer, block *api.AccountBlock) error
 	SignDataWithPriKey(key *derivation.Key, block *api.AccountBlock) error
 	SignDataWithEd25519Key(key ed25519.PrivateKey, block *api.AccountBlock) error
 }
@@ -247,8 +247,9 @@ func (c *client) getPrev(addr types.Address) (*ledger.HashHeight, error) {
 	return &ledger.HashHeight{Height: prevHeight, Hash: prevHash}, nil
 }
 
-func (c *client) SignData(wallet *entropystore.Manager, block *api.AccountBlock) error {
-	if wallet == nil {
+// SignData signs the block by the signer, such as an unlocked *entropystore.Manager or a remotesigner.Client
+func (c *client) SignData(signer interfaces.Signer, block *api.AccountBlock) error {
+	if signer == nil {
 		return errorNilWallet
 	}
 	if block == nil {
@@ -259,7 +260,7 @@ func (c *client) SignData(wallet *entropystore.Manager, block *api.AccountBlock)
 	}
 
 	addr := block.AccountAddress
-	signedData, pubkey, err := wallet.SignData(addr, block.Hash.Bytes())
+	signedData, pubkey, err := signer.SignData(addr, block.Hash.Bytes())
 	if err != nil {
 		return err
 	}
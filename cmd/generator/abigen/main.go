@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/vitelabs/go-vite/v2/client/bind"
+)
+
+// go run ./cmd/generator/abigen -abi token.abi -offchain token.offchain -type Token -pkg token -out token.go
+var (
+	abiFile      = flag.String("abi", "", "path of the contract abi json")
+	offChainFile = flag.String("offchain", "", "path of the hex encoded offchain code, optional")
+	typeName     = flag.String("type", "", "go type name of the binding")
+	pkg          = flag.String("pkg", "main", "go package name of the generated file")
+	out          = flag.String("out", "", "output file, stdout if empty")
+)
+
+func main() {
+	flag.Parse()
+	if *abiFile == "" || *typeName == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	abiJSON, err := ioutil.ReadFile(*abiFile)
+	if err != nil {
+		fatal("failed to read abi: %v", err)
+	}
+
+	var offChainCode []byte
+	if *offChainFile != "" {
+		offChainCode, err = ioutil.ReadFile(*offChainFile)
+		if err != nil {
+			fatal("failed to read offchain code: %v", err)
+		}
+	}
+
+	code, err := bind.Bind(*typeName, string(abiJSON), strings.TrimSpace(string(offChainCode)), *pkg)
+	if err != nil {
+		fatal("failed to generate binding: %v", err)
+	}
+
+	if *out == "" {
+		fmt.Print(code)
+		return
+	}
+	if err = ioutil.WriteFile(*out, []byte(code), 0644); err != nil {
+		fatal("failed to write binding: %v", err)
+	}
+}
+
+func fatal(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
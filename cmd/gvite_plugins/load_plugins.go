@@ -13,12 +13,15 @@ import (
 	"gopkg.in/urfave/cli.v1"
 
 	"github.com/vitelabs/go-vite/v2/cmd/nodemanager"
+	"github.com/vitelabs/go-vite/v2/cmd/subcmd_blocks"
 	"github.com/vitelabs/go-vite/v2/cmd/subcmd_export"
 	"github.com/vitelabs/go-vite/v2/cmd/subcmd_ledger"
 	"github.com/vitelabs/go-vite/v2/cmd/subcmd_loadledger"
 	"github.com/vitelabs/go-vite/v2/cmd/subcmd_plugin_data"
 	"github.com/vitelabs/go-vite/v2/cmd/subcmd_recover"
 	"github.com/vitelabs/go-vite/v2/cmd/subcmd_rpc"
+	"github.com/vitelabs/go-vite/v2/cmd/subcmd_signer"
+	"github.com/vitelabs/go-vite/v2/cmd/subcmd_snapshot"
 	"github.com/vitelabs/go-vite/v2/cmd/subcmd_virtualnode"
 	"github.com/vitelabs/go-vite/v2/cmd/utils"
 	"github.com/vitelabs/go-vite/v2/log15"
@@ -59,7 +62,10 @@ func init() {
 		subcmd_rpc.RpcCommand,
 		subcmd_loadledger.LoadLedgerCommand,
 		subcmd_ledger.QueryLedgerCommand,
+		subcmd_snapshot.SnapshotCommand,
 		subcmd_virtualnode.VirtualNodeCommand,
+		subcmd_blocks.BlocksCommand,
+		subcmd_signer.SignerCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
@@ -0,0 +1,98 @@
+package nodemanager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	chain_block "github.com/vitelabs/go-vite/v2/ledger/chain/block"
+	"github.com/vitelabs/go-vite/v2/log15"
+	"github.com/vitelabs/go-vite/v2/node"
+)
+
+// BlocksNodeManager rewrites the block files of the ledger, the node must not be running
+type BlocksNodeManager struct {
+	ctx  *cli.Context
+	node *node.Node
+	log  log15.Logger
+}
+
+func NewBlocksNodeManager(ctx *cli.Context, maker NodeMaker) (*BlocksNodeManager, error) {
+	node, err := maker.MakeNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BlocksNodeManager{
+		ctx:  ctx,
+		node: node,
+		log:  log15.New("module", "blocksCMD"),
+	}, nil
+}
+
+func (nodeManager *BlocksNodeManager) blocksDir() string {
+	return filepath.Join(nodeManager.node.ViteConfig().DataDir, "ledger", chain_block.BlocksDirName)
+}
+
+// Compress rewrites all records of the block files with the compression, and returns the sizes
+// of the block files before and after.
+func (nodeManager *BlocksNodeManager) Compress(compression chain_block.Compression) (int64, int64, error) {
+	blocksDir := nodeManager.blocksDir()
+	before, err := dirSize(blocksDir)
+	if err != nil {
+		return 0, 0, fmt.Errorf("block files %s are not found", blocksDir)
+	}
+
+	viteConfig := nodeManager.node.ViteConfig()
+
+	chainCfg := *viteConfig.Chain
+	chainCfg.OpenPlugins = false
+	chainCfg.LedgerGc = false
+	chainCfg.BlockCompression = compression.String()
+
+	upgrade.CleanupUpgradeBox()
+	upgrade.InitUpgradeBox(viteConfig.Genesis.UpgradeCfg.MakeUpgradeBox())
+
+	// the chain is not started, nothing is written while the block files are rewritten
+	c := chain.NewChain(viteConfig.DataDir, &chainCfg, viteConfig.Genesis)
+	if err := c.Init(); err != nil {
+		return 0, 0, err
+	}
+
+	nodeManager.log.Info("compress block files", "compression", compression, "dir", blocksDir)
+	if err := c.CompressBlocks(compression); err != nil {
+		c.Destroy()
+		return 0, 0, err
+	}
+	if err := c.Destroy(); err != nil {
+		return 0, 0, err
+	}
+
+	after, err := dirSize(blocksDir)
+	if err != nil {
+		return 0, 0, err
+	}
+	return before, after, nil
+}
+
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+func (nodeManager *BlocksNodeManager) Node() *node.Node {
+	return nodeManager.node
+}
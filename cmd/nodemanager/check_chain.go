@@ -35,6 +35,9 @@ func NewCheckChainNodeManager(ctx *cli.Context, maker NodeMaker) (*CheckChainNod
 	node.Config().LedgerGc = &ledgerGc
 	node.ViteConfig().Chain.LedgerGc = ledgerGc
 
+	// blocks are scrubbed by the command
+	node.ViteConfig().Chain.BlockScrub = false
+
 	return &CheckChainNodeManager{
 		ctx:  ctx,
 		node: node,
@@ -76,6 +79,22 @@ func (nodeManager *CheckChainNodeManager) Start() error {
 
 	fmt.Println("check onroad success.")
 
+	// check block files
+	nodeManager.log.Info("start check blocks")
+	damagedChunks, err := c.ScrubBlocks(1, c.GetLatestSnapshotBlock().Height)
+	if err != nil {
+		common.Crit(err.Error(), "check_chain", "blocks")
+	}
+	for _, damaged := range damagedChunks {
+		fmt.Printf("damaged %s\n", damaged)
+	}
+	if len(damagedChunks) > 0 {
+		common.Crit(fmt.Sprintf("%d damaged snapshot chunks in block files", len(damagedChunks)), "check_chain", "blocks")
+	}
+	nodeManager.log.Info("finish checking blocks")
+
+	fmt.Println("check blocks success.")
+
 	fmt.Println("check success.")
 
 	return nil
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"gopkg.in/urfave/cli.v1"
 
@@ -57,7 +58,9 @@ func (maker FullNodeMaker) MakeNodeConfig(ctx *cli.Context) (*nodeconfig.Config,
 	log.Info(fmt.Sprintf("NodeServer.KeyStoreDir:%v", cfg.KeyStoreDir))
 
 	// 4: Config log to file
-	makeRunLogFile(&cfg)
+	if err := makeRunLogFile(&cfg); err != nil {
+		return nil, err
+	}
 
 	return &cfg, nil
 }
@@ -92,6 +95,14 @@ func mappingNodeConfig(ctx *cli.Context, cfg *nodeconfig.Config) {
 		cfg.SetPrivateKey(nodeKeyHex)
 	}
 
+	if nodeMode := ctx.GlobalString(utils.NodeModeFlag.Name); len(nodeMode) > 0 {
+		cfg.NodeMode = nodeMode
+	}
+
+	if syncMirror := ctx.GlobalString(utils.SyncMirrorFlag.Name); len(syncMirror) > 0 {
+		cfg.SyncMirror = syncMirror
+	}
+
 	//Ipc Config
 	if ctx.GlobalIsSet(utils.IPCEnabledFlag.Name) {
 		cfg.IPCEnabled = ctx.GlobalBool(utils.IPCEnabledFlag.Name)
@@ -237,9 +248,46 @@ func IsExist(f string) bool {
 	return err == nil || os.IsExist(err)
 }
 
-func makeRunLogFile(cfg *nodeconfig.Config) {
-	defaultHandler := common.LogHandler(cfg.RunLogDir(), "", "vite.log", cfg.LogLevel)
+func makeRunLogFile(cfg *nodeconfig.Config) error {
+	format, err := log15.FormatFromString(cfg.LogFormat)
+	if err != nil {
+		return err
+	}
+	rotation := common.LogRotation()
+	if cfg.LogMaxSize > 0 {
+		rotation.MaxSize = int64(cfg.LogMaxSize) * 1024 * 1024
+	}
+	if cfg.LogRotateHours > 0 {
+		rotation.Interval = time.Duration(cfg.LogRotateHours) * time.Hour
+	}
+	if cfg.LogMaxBackups > 0 {
+		rotation.MaxBackups = cfg.LogMaxBackups
+	}
+	if cfg.LogMaxAge > 0 {
+		rotation.MaxAge = time.Duration(cfg.LogMaxAge) * 24 * time.Hour
+	}
+	if cfg.LogCompress != nil {
+		rotation.Compress = *cfg.LogCompress
+	}
+	common.SetLogOptions(format, rotation)
+
+	// vite.log is filtered by the levels of the modules, which can be changed by the log api
+	logLevel, err := log15.LvlFromString(cfg.LogLevel)
+	if err != nil {
+		logLevel = log15.LvlInfo
+	}
+	common.RunLogLevels.SetDefault(logLevel)
+	for module, lvl := range cfg.LogModuleLevels {
+		moduleLevel, err := log15.LvlFromString(lvl)
+		if err != nil {
+			return fmt.Errorf("log level of module %s: %v", module, err)
+		}
+		common.RunLogLevels.Set(module, moduleLevel)
+	}
+
+	defaultHandler := log15.ModuleLvlFilterHandler(common.RunLogLevels, common.LogFileHandler(cfg.RunLogDir(), "", "vite.log"))
 	errorHandler := common.LogHandler(cfg.RunLogDir(), "error", "vite.error.log", log15.LvlError.String())
 
 	log15.Root().SetHandler(log15.MultiHandler(defaultHandler, errorHandler))
+	return nil
 }
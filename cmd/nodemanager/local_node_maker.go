@@ -55,7 +55,9 @@ func (maker LocalNodeMaker) MakeNodeConfig(ctx *cli.Context) (*nodeconfig.Config
 	makeLocalNodeCfg(ctx, cfg)
 
 	// 4: Config log to file
-	makeRunLogFile(cfg)
+	if err := makeRunLogFile(cfg); err != nil {
+		return nil, err
+	}
 
 	return cfg, nil
 }
@@ -1,8 +1,11 @@
 package nodemanager
 
 import (
+	"errors"
+
 	"gopkg.in/urfave/cli.v1"
 
+	chain_plugins "github.com/vitelabs/go-vite/v2/ledger/chain/plugins"
 	"github.com/vitelabs/go-vite/v2/node"
 )
 
@@ -56,6 +59,39 @@ func (nodeManager *PluginDataNodeManager) Start() error {
 	return nil
 }
 
+// Status prepares the node without starting it and returns the build progress of the plugins.
+func (nodeManager *PluginDataNodeManager) Status() ([]chain_plugins.PluginStatus, error) {
+	plugins, err := nodeManager.preparePlugins()
+	if err != nil {
+		return nil, err
+	}
+	return plugins.Status(), nil
+}
+
+// RebuildPlugin prepares the node without starting it and rebuilds the data of one plugin up to the latest snapshot block.
+func (nodeManager *PluginDataNodeManager) RebuildPlugin(name string) error {
+	plugins, err := nodeManager.preparePlugins()
+	if err != nil {
+		return err
+	}
+	if err := plugins.RebuildPlugin(name); err != nil {
+		return err
+	}
+	return plugins.BuildData()
+}
+
+func (nodeManager *PluginDataNodeManager) preparePlugins() (*chain_plugins.Plugins, error) {
+	if err := nodeManager.node.Prepare(); err != nil {
+		return nil, err
+	}
+
+	plugins := nodeManager.node.Vite().Chain().Plugins()
+	if plugins == nil {
+		return nil, errors.New("plugins are not opened")
+	}
+	return plugins, nil
+}
+
 func (nodeManager *PluginDataNodeManager) Stop() error {
 
 	StopNode(nodeManager.node)
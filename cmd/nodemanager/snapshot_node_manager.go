@@ -0,0 +1,273 @@
+package nodemanager
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	chain_archive "github.com/vitelabs/go-vite/v2/ledger/chain/archive"
+	"github.com/vitelabs/go-vite/v2/log15"
+	"github.com/vitelabs/go-vite/v2/net"
+	"github.com/vitelabs/go-vite/v2/node"
+)
+
+// SnapshotNodeManager exports the ledger as a signed archive and imports it, the node must not be running
+type SnapshotNodeManager struct {
+	ctx  *cli.Context
+	node *node.Node
+	log  log15.Logger
+}
+
+func NewSnapshotNodeManager(ctx *cli.Context, maker NodeMaker) (*SnapshotNodeManager, error) {
+	node, err := maker.MakeNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SnapshotNodeManager{
+		ctx:  ctx,
+		node: node,
+		log:  log15.New("module", "snapshotCMD"),
+	}, nil
+}
+
+func (nodeManager *SnapshotNodeManager) ledgerDir() string {
+	return filepath.Join(nodeManager.node.ViteConfig().DataDir, "ledger")
+}
+
+// openChain opens the ledger under dataDir without plugins
+func (nodeManager *SnapshotNodeManager) openChain(dataDir string) (chain.Chain, error) {
+	viteConfig := nodeManager.node.ViteConfig()
+
+	chainCfg := *viteConfig.Chain
+	chainCfg.OpenPlugins = false
+	chainCfg.LedgerGc = false
+
+	upgrade.CleanupUpgradeBox()
+	upgrade.InitUpgradeBox(viteConfig.Genesis.UpgradeCfg.MakeUpgradeBox())
+
+	c := chain.NewChain(dataDir, &chainCfg, viteConfig.Genesis)
+	if err := c.Init(); err != nil {
+		return nil, err
+	}
+	if err := c.Start(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func closeChain(c chain.Chain) error {
+	if err := c.Stop(); err != nil {
+		return err
+	}
+	return c.Destroy()
+}
+
+// Export writes the ledger at the snapshot height into outDir, height 0 means the latest snapshot block.
+// The ledger is copied into a staging directory and rolled back there, the ledger of the node is not changed.
+func (nodeManager *SnapshotNodeManager) Export(height uint64, outDir string, chunkSize int64, priv ed25519.PrivateKey) (*chain_archive.Manifest, error) {
+	ledgerDir := nodeManager.ledgerDir()
+	if _, err := os.Stat(ledgerDir); err != nil {
+		return nil, fmt.Errorf("ledger %s is not found", ledgerDir)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, chain_archive.ManifestName)); err == nil {
+		return nil, fmt.Errorf("archive already exists in %s", outDir)
+	}
+
+	stagingDir, err := ioutil.TempDir(nodeManager.node.ViteConfig().DataDir, "snapshot_export")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(stagingDir)
+
+	stagingLedgerDir := filepath.Join(stagingDir, "ledger")
+	nodeManager.log.Info("copy ledger", "from", ledgerDir, "to", stagingLedgerDir)
+	if err := chain_archive.CopyDir(ledgerDir, stagingLedgerDir, chain_archive.LedgerFiles); err != nil {
+		return nil, err
+	}
+
+	c, err := nodeManager.openChain(stagingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := c.GetLatestSnapshotBlock()
+	if height == 0 {
+		height = latest.Height
+	}
+	if height > latest.Height {
+		closeChain(c)
+		return nil, fmt.Errorf("height %d is out of range, the latest snapshot block is %d", height, latest.Height)
+	}
+	if height < latest.Height {
+		nodeManager.log.Info("roll back the staging ledger", "from", latest.Height, "to", height)
+		if _, err := c.DeleteSnapshotBlocksToHeight(height + 1); err != nil {
+			closeChain(c)
+			return nil, err
+		}
+		latest = c.GetLatestSnapshotBlock()
+		if latest.Height != height {
+			closeChain(c)
+			return nil, fmt.Errorf("failed to roll back to height %d, the latest snapshot block is %d", height, latest.Height)
+		}
+	}
+
+	manifest := &chain_archive.Manifest{
+		Version:     chain_archive.ManifestVersion,
+		GenesisHash: c.GetGenesisSnapshotBlock().Hash,
+		Height:      latest.Height,
+		Hash:        latest.Hash,
+		CreatedAt:   time.Now().Unix(),
+	}
+	if err := closeChain(c); err != nil {
+		return nil, err
+	}
+
+	nodeManager.log.Info("pack ledger", "height", manifest.Height, "hash", manifest.Hash, "out", outDir)
+	if err := chain_archive.Pack(stagingLedgerDir, chain_archive.LedgerFiles, outDir, chunkSize, manifest); err != nil {
+		return nil, err
+	}
+	if err := manifest.Sign(priv); err != nil {
+		return nil, err
+	}
+	if err := chain_archive.WriteManifest(outDir, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Import verifies the archive in srcDir, restores it into a staging directory, checks the restored
+// ledger and moves it into place. The node must not have a ledger.
+func (nodeManager *SnapshotNodeManager) Import(srcDir string, signers []types.Address) (*chain_archive.Manifest, error) {
+	ledgerDir := nodeManager.ledgerDir()
+	if _, err := os.Stat(ledgerDir); err == nil {
+		return nil, fmt.Errorf("ledger %s already exists, move it away before importing", ledgerDir)
+	}
+
+	manifest, err := chain_archive.ReadManifest(srcDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := manifest.Verify(); err != nil {
+		return nil, err
+	}
+	if !isTrustedSigner(manifest.Signer(), signers) {
+		return nil, fmt.Errorf("archive is signed by %s, which is not a trusted signer", manifest.Signer())
+	}
+
+	dataDir := nodeManager.node.ViteConfig().DataDir
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		return nil, err
+	}
+	stagingDir, err := ioutil.TempDir(dataDir, "snapshot_import")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(stagingDir)
+
+	stagingLedgerDir := filepath.Join(stagingDir, "ledger")
+	nodeManager.log.Info("unpack ledger", "from", srcDir, "to", stagingLedgerDir)
+	if err := chain_archive.Unpack(srcDir, manifest, stagingLedgerDir); err != nil {
+		return nil, err
+	}
+
+	if err := nodeManager.checkImported(stagingDir, manifest); err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(stagingLedgerDir, ledgerDir); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func (nodeManager *SnapshotNodeManager) checkImported(dataDir string, manifest *chain_archive.Manifest) error {
+	c, err := nodeManager.openChain(dataDir)
+	if err != nil {
+		return err
+	}
+
+	if genesis := c.GetGenesisSnapshotBlock(); genesis.Hash != manifest.GenesisHash {
+		closeChain(c)
+		return fmt.Errorf("archive is of genesis %s, not %s", manifest.GenesisHash, genesis.Hash)
+	}
+	latest := c.GetLatestSnapshotBlock()
+	if latest.Height != manifest.Height || latest.Hash != manifest.Hash || latest.ComputeHash() != latest.Hash {
+		closeChain(c)
+		return fmt.Errorf("latest snapshot block is %d %s, not %d %s in the manifest",
+			latest.Height, latest.Hash, manifest.Height, manifest.Hash)
+	}
+
+	nodeManager.log.Info("check hash")
+	if err := c.CheckHash(); err != nil {
+		closeChain(c)
+		return err
+	}
+	nodeManager.log.Info("check recent blocks")
+	if err := c.CheckRecentBlocks(); err != nil {
+		closeChain(c)
+		return err
+	}
+	return closeChain(c)
+}
+
+// Mirror writes the ledger into outDir as chunk files of step snapshot blocks, nodes can sync from
+// the directory or from an http server of it with the `SyncMirror` config
+func (nodeManager *SnapshotNodeManager) Mirror(outDir string, step uint64) (*net.MirrorManifest, error) {
+	if _, err := os.Stat(nodeManager.ledgerDir()); err != nil {
+		return nil, fmt.Errorf("ledger %s is not found", nodeManager.ledgerDir())
+	}
+
+	c, err := nodeManager.openChain(nodeManager.node.ViteConfig().DataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	height := c.GetLatestSnapshotBlock().Height
+	nodeManager.log.Info("write sync mirror", "height", height, "step", step, "out", outDir)
+	manifest, err := net.WriteSyncMirror(c, outDir, height, step)
+	if err != nil {
+		closeChain(c)
+		return nil, err
+	}
+	return manifest, closeChain(c)
+}
+
+func isTrustedSigner(signer types.Address, signers []types.Address) bool {
+	for _, addr := range signers {
+		if addr == signer {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadSigningKey reads the hex encoded ed25519 private key in the file
+func ReadSigningKey(file string) (ed25519.PrivateKey, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := ed25519.HexToPrivateKey(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, err
+	}
+	if !ed25519.IsValidPrivateKey(priv) {
+		return nil, errors.New("invalid private key")
+	}
+	return priv, nil
+}
+
+func (nodeManager *SnapshotNodeManager) Node() *node.Node {
+	return nodeManager.node
+}
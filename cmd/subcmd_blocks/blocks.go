@@ -0,0 +1,69 @@
+package subcmd_blocks
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/vitelabs/go-vite/v2/cmd/nodemanager"
+	"github.com/vitelabs/go-vite/v2/cmd/utils"
+	chain_block "github.com/vitelabs/go-vite/v2/ledger/chain/block"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+var (
+	compressionFlag = cli.StringFlag{
+		Name:  "compression",
+		Usage: "the compression of the block files, snappy or zstd",
+		Value: "zstd",
+	}
+
+	BlocksCommand = cli.Command{
+		Name:     "blocks",
+		Usage:    "blocks compress",
+		Category: "LOCAL COMMANDS",
+		Description: `
+Rewrite the block files of the ledger.
+The node must be stopped.
+`,
+		Subcommands: []cli.Command{
+			{
+				Name:  "compress",
+				Usage: "blocks compress --compression zstd",
+				Description: `
+Rewrite all records of the block files with the compression and update the block locations in the index db.
+Set BlockCompression in the node config to the same compression, so the new blocks are written with it.
+If the command is interrupted, the rewriting is completed or discarded when the node starts.
+`,
+				Flags:  append([]cli.Flag{compressionFlag}, utils.ConfigFlags...),
+				Action: utils.MigrateFlags(compressAction),
+			},
+		},
+	}
+	log = log15.New("module", "gvite/blocks")
+)
+
+func compressAction(ctx *cli.Context) error {
+	compression, err := chain_block.ParseCompression(ctx.String(compressionFlag.GetName()))
+	if err != nil {
+		return err
+	}
+
+	nodeManager, err := nodemanager.NewBlocksNodeManager(ctx, nodemanager.FullNodeMaker{})
+	if err != nil {
+		log.Error(fmt.Sprintf("new Node error, %+v", err))
+		return err
+	}
+
+	before, after, err := nodeManager.Compress(compression)
+	if err != nil {
+		log.Error(err.Error())
+		fmt.Println(err.Error())
+		return err
+	}
+	fmt.Printf("compress block files with %s, %d bytes to %d bytes\n", compression, before, after)
+
+	os.Exit(0)
+	return nil
+}
@@ -1,6 +1,7 @@
 package subcmd_plugin_data
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -21,6 +22,21 @@ var (
 		Description: `
 recreate plugin data.
 `,
+		Subcommands: []cli.Command{
+			{
+				Name:   "status",
+				Usage:  "print the build progress of every plugin",
+				Flags:  utils.ConfigFlags,
+				Action: utils.MigrateFlags(pluginStatusAction),
+			},
+			{
+				Name:      "rebuild",
+				Usage:     "rebuild the data of one plugin",
+				ArgsUsage: "<plugin name>",
+				Flags:     utils.ConfigFlags,
+				Action:    utils.MigrateFlags(rebuildPluginAction),
+			},
+		},
 	}
 	log = log15.New("module", "gvite/plugin_data")
 )
@@ -41,3 +57,46 @@ func pluginDataAction(ctx *cli.Context) error {
 	os.Exit(0)
 	return nil
 }
+
+func pluginStatusAction(ctx *cli.Context) error {
+	nodeManager, err := nodemanager.NewPluginDataNodeManager(ctx, nodemanager.FullNodeMaker{})
+	if err != nil {
+		log.Error(fmt.Sprintf("new Node error, %+v", err))
+		return err
+	}
+	statusList, err := nodeManager.Status()
+	if err != nil {
+		log.Error(err.Error())
+		fmt.Println(err.Error())
+		return err
+	}
+
+	for _, status := range statusList {
+		fmt.Printf("%-16s %-10s %d\n", status.Name, status.Status, status.Height)
+	}
+
+	os.Exit(0)
+	return nil
+}
+
+func rebuildPluginAction(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("the name of the plugin is required")
+	}
+	name := ctx.Args().First()
+
+	nodeManager, err := nodemanager.NewPluginDataNodeManager(ctx, nodemanager.FullNodeMaker{})
+	if err != nil {
+		log.Error(fmt.Sprintf("new Node error, %+v", err))
+		return err
+	}
+	if err := nodeManager.RebuildPlugin(name); err != nil {
+		log.Error(err.Error())
+		fmt.Println(err.Error())
+		return err
+	}
+	fmt.Printf("rebuild plugin %s succeed\n", name)
+
+	os.Exit(0)
+	return nil
+}
@@ -0,0 +1,110 @@
+package subcmd_signer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/log15"
+	"github.com/vitelabs/go-vite/v2/rpc"
+	"github.com/vitelabs/go-vite/v2/wallet/entropystore"
+	"github.com/vitelabs/go-vite/v2/wallet/remotesigner"
+)
+
+var (
+	endpointFlag = cli.StringFlag{
+		Name:  "signerendpoint",
+		Usage: "the unix socket served by the signer, it is the SignerEndpoint in the node config",
+	}
+	entropyStoreFlag = cli.StringFlag{
+		Name:  "entropystore",
+		Usage: "the entropy store file holding the keys",
+	}
+	passphraseFlag = cli.StringFlag{
+		Name:   "entropystorepassphrase",
+		Usage:  "the passphrase of the entropy store",
+		EnvVar: "GVITE_SIGNER_PASSPHRASE",
+	}
+	addressesFlag = cli.StringFlag{
+		Name:  "signeraddresses",
+		Usage: "comma separated addresses signed by the signer, default the primary address of the entropy store",
+	}
+
+	SignerCommand = cli.Command{
+		Name:     "signer",
+		Usage:    "signer --signerendpoint ~/signer.ipc --entropystore ~/wallet/vite_xxx",
+		Category: "LOCAL COMMANDS",
+		Flags:    []cli.Flag{endpointFlag, entropyStoreFlag, passphraseFlag, addressesFlag},
+		Description: `
+Run a signing daemon backed by an entropy store, as a stand-in of a signer in front of an HSM or an air-gapped box.
+Set SignerEndpoint in the node config, then the producer signs blocks by the daemon, and the keys are not loaded
+by gvite.
+`,
+		Action: signerAction,
+	}
+	log = log15.New("module", "gvite/signer")
+)
+
+func signerAction(ctx *cli.Context) error {
+	endpoint := ctx.String(endpointFlag.GetName())
+	filename := ctx.String(entropyStoreFlag.GetName())
+	if endpoint == "" || filename == "" {
+		return errors.New("signerendpoint and entropystore are required")
+	}
+
+	ok, primaryAddr, err := entropystore.IsMayValidEntropystoreFile(filename)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%s is not an entropy store file", filename)
+	}
+
+	addresses := []types.Address{*primaryAddr}
+	if str := ctx.String(addressesFlag.GetName()); str != "" {
+		addresses = nil
+		for _, s := range strings.Split(str, ",") {
+			addr, err := types.HexToAddress(strings.TrimSpace(s))
+			if err != nil {
+				return err
+			}
+			addresses = append(addresses, addr)
+		}
+	}
+
+	manager := entropystore.NewManager(filename, *primaryAddr, entropystore.DefaultMaxIndex)
+	if err := manager.Unlock(ctx.String(passphraseFlag.GetName())); err != nil {
+		return err
+	}
+	defer manager.Lock()
+	for _, addr := range addresses {
+		if !manager.IsAddrUnlocked(addr) {
+			return fmt.Errorf("address %s is not found in %s", addr, filename)
+		}
+	}
+
+	service := remotesigner.NewService(manager, addresses)
+	listener, _, err := rpc.StartIPCEndpoint(endpoint, []rpc.API{{
+		Namespace: remotesigner.Namespace,
+		Version:   "1.0",
+		Service:   service,
+		Public:    true,
+	}})
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	log.Info("signer started", "endpoint", endpoint, "addresses", addresses)
+	fmt.Printf("signer serves %d addresses at %s\n", len(addresses), endpoint)
+
+	exit := make(chan os.Signal, 1)
+	signal.Notify(exit, syscall.SIGINT, syscall.SIGTERM)
+	<-exit
+	return nil
+}
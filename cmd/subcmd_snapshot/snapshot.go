@@ -0,0 +1,176 @@
+package subcmd_snapshot
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/vitelabs/go-vite/v2/cmd/nodemanager"
+	"github.com/vitelabs/go-vite/v2/cmd/utils"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+var (
+	heightFlag = cli.Uint64Flag{
+		Name:  "height",
+		Usage: "the snapshot block height to export, 0 means the latest one",
+	}
+	outFlag = cli.StringFlag{
+		Name:  "out",
+		Usage: "the directory to write the archive into",
+	}
+	keyFileFlag = cli.StringFlag{
+		Name:  "keyFile",
+		Usage: "the file of the hex encoded ed25519 private key signing the manifest",
+	}
+	chunkSizeFlag = cli.Int64Flag{
+		Name:  "chunkSize",
+		Usage: "the size of the archive chunks in MB",
+		Value: 256,
+	}
+	stepFlag = cli.Uint64Flag{
+		Name:  "step",
+		Usage: "the snapshot blocks in every chunk file of the sync mirror",
+		Value: 10000,
+	}
+	fromFlag = cli.StringFlag{
+		Name:  "from",
+		Usage: "the directory of the archive",
+	}
+	signerFlag = cli.StringFlag{
+		Name:  "signer",
+		Usage: "the trusted addresses signing the archive, separated by comma",
+	}
+
+	SnapshotCommand = cli.Command{
+		Name:     "snapshot",
+		Usage:    "snapshot export|import|mirror",
+		Category: "LOCAL COMMANDS",
+		Description: `
+Export the ledger as a signed archive, or import it into an empty data directory.
+Or write the ledger as chunk files of a sync mirror.
+The node must be stopped.
+`,
+		Subcommands: []cli.Command{
+			{
+				Name:   "export",
+				Usage:  "snapshot export --height 5000000 --out /xxx/xxx --keyFile /xxx/key",
+				Flags:  append([]cli.Flag{heightFlag, outFlag, keyFileFlag, chunkSizeFlag}, utils.ConfigFlags...),
+				Action: utils.MigrateFlags(exportAction),
+			},
+			{
+				Name:   "import",
+				Usage:  "snapshot import --from /xxx/xxx --signer vite_xxx",
+				Flags:  append([]cli.Flag{fromFlag, signerFlag}, utils.ConfigFlags...),
+				Action: utils.MigrateFlags(importAction),
+			},
+			{
+				Name:   "mirror",
+				Usage:  "snapshot mirror --out /xxx/xxx --step 10000",
+				Flags:  append([]cli.Flag{outFlag, stepFlag}, utils.ConfigFlags...),
+				Action: utils.MigrateFlags(mirrorAction),
+			},
+		},
+	}
+	log = log15.New("module", "gvite/snapshot")
+)
+
+func exportAction(ctx *cli.Context) error {
+	outDir := ctx.String(outFlag.GetName())
+	if outDir == "" {
+		return errors.New("out not set")
+	}
+	keyFile := ctx.String(keyFileFlag.GetName())
+	if keyFile == "" {
+		return errors.New("keyFile not set")
+	}
+	priv, err := nodemanager.ReadSigningKey(keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read signing key, %v", err)
+	}
+
+	nodeManager, err := nodemanager.NewSnapshotNodeManager(ctx, nodemanager.FullNodeMaker{})
+	if err != nil {
+		log.Error(fmt.Sprintf("new Node error, %+v", err))
+		return err
+	}
+
+	manifest, err := nodeManager.Export(ctx.Uint64(heightFlag.GetName()), outDir, ctx.Int64(chunkSizeFlag.GetName())*1024*1024, priv)
+	if err != nil {
+		log.Error(err.Error())
+		fmt.Println(err.Error())
+		return err
+	}
+	fmt.Printf("export snapshot %d %s into %s, %d chunks, signed by %s\n",
+		manifest.Height, manifest.Hash, outDir, len(manifest.Chunks), manifest.Signer())
+
+	os.Exit(0)
+	return nil
+}
+
+func importAction(ctx *cli.Context) error {
+	fromDir := ctx.String(fromFlag.GetName())
+	if fromDir == "" {
+		return errors.New("from not set")
+	}
+
+	var signers []types.Address
+	for _, s := range strings.Split(ctx.String(signerFlag.GetName()), ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		addr, err := types.HexToAddress(s)
+		if err != nil {
+			return fmt.Errorf("invalid signer %s", s)
+		}
+		signers = append(signers, addr)
+	}
+	if len(signers) == 0 {
+		return errors.New("signer not set")
+	}
+
+	nodeManager, err := nodemanager.NewSnapshotNodeManager(ctx, nodemanager.FullNodeMaker{})
+	if err != nil {
+		log.Error(fmt.Sprintf("new Node error, %+v", err))
+		return err
+	}
+
+	manifest, err := nodeManager.Import(fromDir, signers)
+	if err != nil {
+		log.Error(err.Error())
+		fmt.Println(err.Error())
+		return err
+	}
+	fmt.Printf("import snapshot %d %s signed by %s\n", manifest.Height, manifest.Hash, manifest.Signer())
+
+	os.Exit(0)
+	return nil
+}
+
+func mirrorAction(ctx *cli.Context) error {
+	outDir := ctx.String(outFlag.GetName())
+	if outDir == "" {
+		return errors.New("out not set")
+	}
+
+	nodeManager, err := nodemanager.NewSnapshotNodeManager(ctx, nodemanager.FullNodeMaker{})
+	if err != nil {
+		log.Error(fmt.Sprintf("new Node error, %+v", err))
+		return err
+	}
+
+	manifest, err := nodeManager.Mirror(outDir, ctx.Uint64(stepFlag.GetName()))
+	if err != nil {
+		log.Error(err.Error())
+		fmt.Println(err.Error())
+		return err
+	}
+	fmt.Printf("write %d chunk files into %s\n", len(manifest.Segments), outDir)
+
+	os.Exit(0)
+	return nil
+}
@@ -4,9 +4,8 @@ import (
 	"flag"
 	"fmt"
 
-	"github.com/syndtr/goleveldb/leveldb"
-
 	"github.com/vitelabs/go-vite/v2/common/types"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
 	"github.com/vitelabs/go-vite/v2/ledger/consensus/cdb"
 )
 
@@ -16,7 +15,7 @@ var delete = flag.Bool("delete", false, "delete ?")
 
 func main() {
 	flag.Parse()
-	db, err := leveldb.OpenFile(*dir, nil)
+	db, err := chain_db.OpenLevelDBBackend(*dir)
 
 	if err != nil {
 		panic(err)
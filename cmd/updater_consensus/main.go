@@ -5,8 +5,7 @@ import (
 	"flag"
 	"fmt"
 
-	"github.com/syndtr/goleveldb/leveldb"
-
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
 	"github.com/vitelabs/go-vite/v2/ledger/consensus/cdb"
 )
 
@@ -21,7 +20,7 @@ func main() {
 	if *dir == "" {
 		panic("err dir")
 	}
-	d, err := leveldb.OpenFile(*dir, nil)
+	d, err := chain_db.OpenLevelDBBackend(*dir)
 	if err != nil {
 		panic(err)
 	}
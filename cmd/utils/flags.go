@@ -69,6 +69,14 @@ var (
 		Name:  "discovery", //mapping:p2p.Discovery
 		Usage: "enable p2p discovery or not",
 	}
+	NodeModeFlag = cli.StringFlag{
+		Name:  "nodemode", //mapping:p2p.NodeMode
+		Usage: "Node mode, `full` holds the whole ledger, `edge` syncs only snapshot blocks",
+	}
+	SyncMirrorFlag = cli.StringFlag{
+		Name:  "syncmirror", //mapping:p2p.SyncMirror
+		Usage: "Local directory or http(s) url of ledger chunk files to sync from",
+	}
 
 	//IPC Settings
 	IPCEnabledFlag = cli.BoolFlag{
@@ -262,6 +270,8 @@ var (
 		ListenPortFlag,
 		NodeKeyHexFlag,
 		DiscoveryFlag,
+		NodeModeFlag,
+		SyncMirrorFlag,
 	}
 
 	//IPC
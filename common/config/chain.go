@@ -4,11 +4,20 @@ import "github.com/vitelabs/go-vite/v2/common/types"
 
 // chain config
 type Chain struct {
-	LedgerGcRetain uint64 // no use
+	LedgerGcRetain uint64 // count of latest snapshot blocks whose history state is retained by ledger gc, 0 means retain all
 	GenesisFile    string // genesis file path
-	LedgerGc       bool   // open or close ledger garbage collector
+	LedgerGc       bool   // open or close ledger garbage collector, it prunes the history state older than LedgerGcRetain
 	OpenPlugins    bool   // open or close chain plugins. eg, filter account blocks by token.
+	BlockScrub     bool   // scrub the records of the block files in background every day, the damaged records are logged
+	BlockRepair    bool   // fetch the damaged snapshot chunks found by the block scrubber from peers and rewrite them
 
 	VmLogWhiteList []types.Address // contract address white list which save VM logs
 	VmLogAll       bool            // save all VM logs, it will cost more disk space
+
+	BlockCompression string // compression of the new records of the block files, snappy or zstd. Empty means snappy
+
+	// key-value backend of the index, state, plugins and consensus dbs, leveldb or memory. Empty means leveldb.
+	// The memory backend loses the dbs on restart, it is for the tests and the ephemeral nodes. The block files
+	// and the other dbs of the chain go to DataDir/ledger_memory with it, which is cleared on every start.
+	Backend string
 }
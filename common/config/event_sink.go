@@ -0,0 +1,56 @@
+package config
+
+import "github.com/vitelabs/go-vite/v2/common/types"
+
+const (
+	EventSinkWebhook = "webhook"
+	EventSinkFile    = "file"
+)
+
+// EventSink pushes the chain events matching the filter to a webhook or a jsonl file
+type EventSink struct {
+	Name   string       `json:"Name"`
+	Type   string       `json:"Type"`
+	Filter *EventFilter `json:"Filter"`
+
+	// the events are posted as a json array, the body is signed by HMAC-SHA256 with the secret
+	// in the X-Vite-Signature header if the secret is set
+	URL    string `json:"URL"`
+	Secret string `json:"Secret"`
+	// a failed delivery is retried until it succeeds. The interval starts from RetryInterval milliseconds
+	// and doubles after each retry up to MaxRetryInterval milliseconds, 1 second and 1 minute by default.
+	// After MaxRetries retries, 10 if it is 0, the event log no longer keeps the events for the sink, the
+	// events pruned before the delivery succeeds are skipped.
+	MaxRetries       int    `json:"MaxRetries"`
+	RetryInterval    uint64 `json:"RetryInterval"`
+	MaxRetryInterval uint64 `json:"MaxRetryInterval"`
+	// the timeout of a post in seconds
+	Timeout uint64 `json:"Timeout"`
+
+	// the events are appended to the file as json lines, the file is rotated once it is larger than
+	// MaxSize bytes and the last MaxFiles rotated files are kept
+	Path     string `json:"Path"`
+	MaxSize  int64  `json:"MaxSize"`
+	MaxFiles int    `json:"MaxFiles"`
+}
+
+// EventFilter matches the account blocks, an empty list matches all. The snapshot blocks are matched
+// only if SnapshotBlocks is set.
+type EventFilter struct {
+	// the blocks of the addresses or sent to them, AddressFile has an address per line for long lists
+	Addresses   []types.Address `json:"Addresses"`
+	AddressFile string          `json:"AddressFile"`
+
+	TokenIds []types.TokenTypeId `json:"TokenIds"`
+
+	// the blocks with a vm log whose first topic is one of the topics
+	LogTopics []types.Hash `json:"LogTopics"`
+
+	// only the receive blocks, the onroad transactions received
+	OnroadReceived bool `json:"OnroadReceived"`
+
+	// the account blocks are pushed once they are confirmed by a snapshot block instead of inserted
+	Confirmed bool `json:"Confirmed"`
+
+	SnapshotBlocks bool `json:"SnapshotBlocks"`
+}
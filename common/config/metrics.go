@@ -0,0 +1,13 @@
+package config
+
+type Metrics struct {
+	Enabled bool
+
+	// push the metrics to influxdb periodically
+	InfluxDBEnable   bool
+	InfluxDBEndpoint string
+	InfluxDBDatabase string
+	InfluxDBUsername string
+	InfluxDBPassword string
+	InfluxDBHostTag  string
+}
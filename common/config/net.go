@@ -1,6 +1,7 @@
 package config
 
 import (
+	"crypto"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -31,6 +32,14 @@ const (
 
 	DefaultForwardStrategy = "cross"
 	DefaultAccessControl   = "any"
+
+	EncryptionPrefer  = "prefer"
+	EncryptionRequire = "require"
+	EncryptionDisable = "disable"
+	DefaultEncryption = EncryptionPrefer
+
+	NodeModeFull = "full"
+	NodeModeEdge = "edge"
 )
 
 type Net struct {
@@ -93,7 +102,22 @@ type Net struct {
 	BlackBlockHashList []string
 	WhiteBlockList     []string
 
+	// Encryption is how to encrypt messages with peers, `prefer` encrypts messages if the peer supports,
+	// `require` refuses peers don't support encryption, `disable` always sends clear text, default `prefer`
+	Encryption string
+
+	// NodeMode is `full` to hold the whole ledger, or `edge` to sync only snapshot blocks and fetch
+	// account data from full peers on demand, default `full`
+	NodeMode string
+
+	// SyncMirror is a local directory or an http(s) url of ledger chunk files, chunks covered by the mirror
+	// are downloaded from it instead of peers, default empty
+	SyncMirror string
+
 	MineKey ed25519.PrivateKey
+
+	// MineSigner signs with the key of the coinbase kept outside the process, MineKey is ignored if it is set
+	MineSigner crypto.Signer
 }
 
 func getPeerKey(filename string) (privateKey ed25519.PrivateKey, err error) {
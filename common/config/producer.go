@@ -13,6 +13,10 @@ type Producer struct {
 	Coinbase         string `json:"Coinbase"`
 	EntropyStorePath string `json:"EntropyStorePath"`
 
+	// SignerEndpoint is the unix socket of a remote signer holding the key of the coinbase,
+	// the entropy store is not used by the producer if it is set
+	SignerEndpoint string `json:"SignerEndpoint"`
+
 	coinbase types.Address
 	index    uint32
 
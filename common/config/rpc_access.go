@@ -0,0 +1,41 @@
+package config
+
+// RPCAccess authenticates the requests of the public rpc endpoints by api keys and limits their rates
+type RPCAccess struct {
+	Keys []*APIKey `json:"Keys"`
+
+	// the requests without a key are rejected if RequireKey is set, otherwise they can call all the
+	// exposed methods and are limited by AnonymousLimit per remote ip
+	RequireKey     bool       `json:"RequireKey"`
+	AnonymousLimit *RateLimit `json:"AnonymousLimit"`
+
+	// the limits of methods like "ledger_getVmLogsByFilter", applied to each key or remote ip
+	MethodLimits map[string]*RateLimit `json:"MethodLimits"`
+
+	// the ips or cidrs of the reverse proxies. The anonymous requests from them are limited by the client ip
+	// in X-Forwarded-For or X-Real-IP, the headers of the requests from the other remotes are ignored.
+	TrustedProxies []string `json:"TrustedProxies"`
+
+	// the max bytes of a request and the max number of requests in a batch, 0 means the default
+	MaxRequestSize int64 `json:"MaxRequestSize"`
+	MaxBatchSize   int   `json:"MaxBatchSize"`
+}
+
+// APIKey is sent as "Authorization: Bearer <key>" or "X-API-Key: <key>" header,
+// or the apikey query parameter of a websocket url
+type APIKey struct {
+	Key  string `json:"Key"`
+	Name string `json:"Name"`
+
+	// namespaces like "ledger" or methods like "ledger_getAccountInfoByAddress",
+	// all the exposed methods are allowed if it is empty
+	Allowed []string `json:"Allowed"`
+
+	Limit *RateLimit `json:"Limit"`
+}
+
+// RateLimit is a token bucket, Rate tokens are added per second up to Burst
+type RateLimit struct {
+	Rate  float64 `json:"Rate"`
+	Burst int     `json:"Burst"`
+}
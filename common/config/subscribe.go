@@ -2,4 +2,13 @@ package config
 
 type Subscribe struct {
 	IsSubscribe bool `json:"IsSubscribe"`
+
+	// EventLog keeps the chain events in DataDir/events for the clients resuming from a cursor,
+	// only the last EventLogRetention events are kept if it is not 0
+	EventLog          bool   `json:"EventLog"`
+	EventLogRetention uint64 `json:"EventLogRetention"`
+
+	// EventSinks push the chain events to webhooks and files, they follow the event log, which is
+	// kept if there are sinks even if EventLog is false. Their cursors are in DataDir/eventsinks.
+	EventSinks []*EventSink `json:"EventSinks"`
 }
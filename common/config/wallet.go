@@ -3,4 +3,8 @@ package config
 type Wallet struct {
 	DataDir        string
 	MaxSearchIndex uint32
+
+	PolicyFile    string // json file of the transfer policies of the addresses
+	AuditLogFile  string // the unlocks, locks and policy decisions are appended to it
+	UnlockTimeout uint64 // seconds, an unlocked entropy store is locked again after it if not 0
 }
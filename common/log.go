@@ -1,23 +1,48 @@
 package common
 
 import (
-	"io"
 	"path/filepath"
-
-	"gopkg.in/natefinch/lumberjack.v2"
+	"sync"
+	"time"
 
 	"github.com/vitelabs/go-vite/v2/log15"
 )
 
-func makeDefaultLogger(absFilePath string) io.Writer {
-	return &lumberjack.Logger{
-		Filename:   absFilePath,
-		MaxSize:    100,
+// RunLogLevels are the levels of the modules in the run log, they can be changed at runtime
+var RunLogLevels = log15.NewModuleLvls(log15.LvlInfo)
+
+var (
+	logOptionsMu sync.RWMutex
+	logFormat    = log15.LogfmtFormat()
+	logRotation  = log15.RotateOptions{
+		MaxSize:    100 * 1024 * 1024,
 		MaxBackups: 14,
-		MaxAge:     14,
+		MaxAge:     14 * 24 * time.Hour,
 		Compress:   true,
-		LocalTime:  true,
 	}
+)
+
+// SetLogOptions changes the format and the rotation of the log files opened afterwards
+func SetLogOptions(format log15.Format, rotation log15.RotateOptions) {
+	logOptionsMu.Lock()
+	defer logOptionsMu.Unlock()
+	logFormat = format
+	logRotation = rotation
+}
+
+// LogRotation returns the rotation of the log files
+func LogRotation() log15.RotateOptions {
+	logOptionsMu.RLock()
+	defer logOptionsMu.RUnlock()
+	return logRotation
+}
+
+// LogFileHandler returns a handler writing all the records to a rotated file
+func LogFileHandler(path, subDir, filename string) log15.Handler {
+	logOptionsMu.RLock()
+	defer logOptionsMu.RUnlock()
+	absFilename := filepath.Join(path, subDir, filename)
+	return log15.RotatingFileHandler(absFilename, logRotation, logFormat)
 }
 
 func LogHandler(path, subDir, filename, lvl string) log15.Handler {
@@ -25,7 +50,5 @@ func LogHandler(path, subDir, filename, lvl string) log15.Handler {
 	if err != nil {
 		logLevel = log15.LvlInfo
 	}
-	absFilename := filepath.Join(path, subDir, filename)
-	out := makeDefaultLogger(absFilename)
-	return log15.LvlFilterHandler(logLevel, log15.StreamHandler(out, log15.LogfmtFormat()))
+	return log15.LvlFilterHandler(logLevel, LogFileHandler(path, subDir, filename))
 }
@@ -55,7 +55,24 @@ func InitUpgradeBox(box UpgradeBox) error {
 	return nil
 }
 
-func AddUpgradePoint(version uint32, height uint64) error {
+// AddUpgradePoint sets the height of version while the chain is at the snapshot height head.
+// The height must be above head, and the points activated at or below head can't be changed.
+func AddUpgradePoint(version uint32, height uint64, head uint64) error {
+	assertUpgradeNotNil()
+	current := upgrade.UpgradePoints()
+	var points []*UpgradePoint
+	for _, point := range current {
+		if point.Version != version {
+			points = append(points, point)
+		}
+	}
+	points = append(points, &UpgradePoint{Version: version, Height: height})
+	if err := CheckUpgradePoints(points); err != nil {
+		return err
+	}
+	if err := CheckUpgradeSchedule(current, points, head); err != nil {
+		return err
+	}
 	upgrade = upgrade.AddPoint(version, height)
 	return nil
 }
@@ -0,0 +1,70 @@
+package upgrade
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CheckUpgradePoints checks that the versions of the points are continuous from 1,
+// and the activation heights never decrease with the version.
+func CheckUpgradePoints(points []*UpgradePoint) error {
+	sorted := make([]*UpgradePoint, len(points))
+	copy(sorted, points)
+	sort.Sort(byVersion(sorted))
+
+	lastHeight := uint64(0)
+	for index, point := range sorted {
+		if point.Version != uint32(index)+1 {
+			return fmt.Errorf("upgrade version %d is missing", index+1)
+		}
+		if point.Height == 0 {
+			return fmt.Errorf("height of upgrade version %d must be greater than 0", point.Version)
+		}
+		if point.Height < lastHeight {
+			return fmt.Errorf("height of upgrade version %d is %d, lower than %d of the previous version",
+				point.Version, point.Height, lastHeight)
+		}
+		lastHeight = point.Height
+	}
+	return nil
+}
+
+// CheckUpgradeSchedule checks whether the ledger built under the stored points at
+// snapshot height head can be continued under the active points. The points
+// activated at or below head must be the same in both schedules, while the
+// upcoming points are free to change.
+func CheckUpgradeSchedule(stored, active []*UpgradePoint, head uint64) error {
+	storedMap := make(map[uint32]*UpgradePoint, len(stored))
+	for _, point := range stored {
+		storedMap[point.Version] = point
+	}
+	activeMap := make(map[uint32]*UpgradePoint, len(active))
+	for _, point := range active {
+		activeMap[point.Version] = point
+	}
+
+	for _, point := range stored {
+		if point.Height > head {
+			continue
+		}
+		current, ok := activeMap[point.Version]
+		if !ok {
+			return fmt.Errorf("upgrade version %d activated at height %d is removed", point.Version, point.Height)
+		}
+		if current.Height != point.Height {
+			return fmt.Errorf("upgrade version %d activated at height %d is moved to height %d",
+				point.Version, point.Height, current.Height)
+		}
+	}
+
+	for _, point := range active {
+		if point.Height > head {
+			continue
+		}
+		if previous, ok := storedMap[point.Version]; !ok || previous.Height != point.Height {
+			return fmt.Errorf("upgrade version %d is set to height %d, which is behind the current height %d",
+				point.Version, point.Height, head)
+		}
+	}
+	return nil
+}
@@ -321,3 +321,53 @@ func TestUpgrade(t *testing.T) {
 	assert.True(t, activePoints[1].Version == 2)
 
 }
+
+func TestCheckUpgradePoints(t *testing.T) {
+	assert.NoError(t, CheckUpgradePoints(NewMainnetUpgradeBox().UpgradePoints()))
+	assert.NoError(t, CheckUpgradePoints(NewLatestUpgradeBox().UpgradePoints()))
+
+	// missing version
+	assert.Error(t, CheckUpgradePoints([]*UpgradePoint{{Version: 1, Height: 10}, {Version: 3, Height: 20}}))
+	// zero height
+	assert.Error(t, CheckUpgradePoints([]*UpgradePoint{{Version: 1, Height: 0}}))
+	// decreasing height
+	assert.Error(t, CheckUpgradePoints([]*UpgradePoint{{Version: 2, Height: 10}, {Version: 1, Height: 20}}))
+}
+
+func TestCheckUpgradeSchedule(t *testing.T) {
+	stored := []*UpgradePoint{{Version: 1, Height: 10}, {Version: 2, Height: 20}, {Version: 3, Height: 30}}
+
+	// same schedule
+	assert.NoError(t, CheckUpgradeSchedule(stored, stored, 25))
+	// upcoming point moved
+	assert.NoError(t, CheckUpgradeSchedule(stored, []*UpgradePoint{{Version: 1, Height: 10}, {Version: 2, Height: 20}, {Version: 3, Height: 40}}, 25))
+	// upcoming point added
+	assert.NoError(t, CheckUpgradeSchedule(stored, append(stored, &UpgradePoint{Version: 4, Height: 50}), 25))
+
+	// passed point moved
+	assert.Error(t, CheckUpgradeSchedule(stored, []*UpgradePoint{{Version: 1, Height: 10}, {Version: 2, Height: 22}, {Version: 3, Height: 30}}, 25))
+	// passed point removed
+	assert.Error(t, CheckUpgradeSchedule(stored, stored[:1], 25))
+	// upcoming point moved behind the head
+	assert.Error(t, CheckUpgradeSchedule(stored, []*UpgradePoint{{Version: 1, Height: 10}, {Version: 2, Height: 20}, {Version: 3, Height: 24}}, 25))
+	// point added behind the head
+	assert.Error(t, CheckUpgradeSchedule(stored[:1], stored, 25))
+}
+
+func TestAddUpgradePoint(t *testing.T) {
+	cleanupUpgradeBox()
+	InitUpgradeBox(newUpgradeBox([]*UpgradePoint{{Version: 1, Height: 10}}))
+
+	assert.Error(t, AddUpgradePoint(3, 20, 1))
+	assert.Error(t, AddUpgradePoint(2, 5, 1))
+	assert.NoError(t, AddUpgradePoint(2, 20, 1))
+	assert.True(t, IsDexUpgrade(20))
+
+	// the heights at or below the head, and the points activated already can't be set
+	assert.Error(t, AddUpgradePoint(3, 15, 15))
+	assert.Error(t, AddUpgradePoint(3, 12, 15))
+	assert.Error(t, AddUpgradePoint(1, 16, 15))
+	assert.NoError(t, AddUpgradePoint(3, 30, 15))
+	assert.NoError(t, AddUpgradePoint(2, 25, 15))
+	assert.False(t, IsDexUpgrade(20))
+}
@@ -58,6 +58,9 @@ type Handshake struct {
 	Key                  []byte   `protobuf:"bytes,10,opt,name=Key,proto3" json:"Key,omitempty"`
 	Token                []byte   `protobuf:"bytes,11,opt,name=Token,proto3" json:"Token,omitempty"`
 	PublicAddress        []byte   `protobuf:"bytes,12,opt,name=PublicAddress,proto3" json:"PublicAddress,omitempty"`
+	Encryption           uint32   `protobuf:"varint,13,opt,name=Encryption,proto3" json:"Encryption,omitempty"`
+	EphemeralKey         []byte   `protobuf:"bytes,14,opt,name=EphemeralKey,proto3" json:"EphemeralKey,omitempty"`
+	Signature            []byte   `protobuf:"bytes,15,opt,name=Signature,proto3" json:"Signature,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -172,6 +175,27 @@ func (m *Handshake) GetPublicAddress() []byte {
 	return nil
 }
 
+func (m *Handshake) GetEncryption() uint32 {
+	if m != nil {
+		return m.Encryption
+	}
+	return 0
+}
+
+func (m *Handshake) GetEphemeralKey() []byte {
+	if m != nil {
+		return m.EphemeralKey
+	}
+	return nil
+}
+
+func (m *Handshake) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
 type SyncConnHandshake struct {
 	ID                   []byte   `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
 	Timestamp            int64    `protobuf:"varint,2,opt,name=Timestamp,proto3" json:"Timestamp,omitempty"`
@@ -1067,6 +1091,366 @@ func (m *Trace) GetTTL() uint32 {
 	return 0
 }
 
+type GetAccountBlockProof struct {
+	Hash                 []byte   `protobuf:"bytes,1,opt,name=Hash,proto3" json:"Hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetAccountBlockProof) Reset()         { *m = GetAccountBlockProof{} }
+func (m *GetAccountBlockProof) String() string { return proto.CompactTextString(m) }
+func (*GetAccountBlockProof) ProtoMessage()    {}
+func (*GetAccountBlockProof) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a6a8486deb9ab39, []int{17}
+}
+
+func (m *GetAccountBlockProof) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetAccountBlockProof.Unmarshal(m, b)
+}
+func (m *GetAccountBlockProof) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetAccountBlockProof.Marshal(b, m, deterministic)
+}
+func (m *GetAccountBlockProof) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetAccountBlockProof.Merge(m, src)
+}
+func (m *GetAccountBlockProof) XXX_Size() int {
+	return xxx_messageInfo_GetAccountBlockProof.Size(m)
+}
+func (m *GetAccountBlockProof) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetAccountBlockProof.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetAccountBlockProof proto.InternalMessageInfo
+
+func (m *GetAccountBlockProof) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+type AccountBlockProof struct {
+	SnapshotHeight       uint64          `protobuf:"varint,1,opt,name=SnapshotHeight,proto3" json:"SnapshotHeight,omitempty"`
+	Blocks               []*AccountBlock `protobuf:"bytes,2,rep,name=Blocks,proto3" json:"Blocks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *AccountBlockProof) Reset()         { *m = AccountBlockProof{} }
+func (m *AccountBlockProof) String() string { return proto.CompactTextString(m) }
+func (*AccountBlockProof) ProtoMessage()    {}
+func (*AccountBlockProof) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a6a8486deb9ab39, []int{18}
+}
+
+func (m *AccountBlockProof) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AccountBlockProof.Unmarshal(m, b)
+}
+func (m *AccountBlockProof) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AccountBlockProof.Marshal(b, m, deterministic)
+}
+func (m *AccountBlockProof) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AccountBlockProof.Merge(m, src)
+}
+func (m *AccountBlockProof) XXX_Size() int {
+	return xxx_messageInfo_AccountBlockProof.Size(m)
+}
+func (m *AccountBlockProof) XXX_DiscardUnknown() {
+	xxx_messageInfo_AccountBlockProof.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AccountBlockProof proto.InternalMessageInfo
+
+func (m *AccountBlockProof) GetSnapshotHeight() uint64 {
+	if m != nil {
+		return m.SnapshotHeight
+	}
+	return 0
+}
+
+func (m *AccountBlockProof) GetBlocks() []*AccountBlock {
+	if m != nil {
+		return m.Blocks
+	}
+	return nil
+}
+
+type GetAccountState struct {
+	Address              []byte      `protobuf:"bytes,1,opt,name=Address,proto3" json:"Address,omitempty"`
+	Snapshot             *HashHeight `protobuf:"bytes,2,opt,name=Snapshot,proto3" json:"Snapshot,omitempty"`
+	TokenIds             [][]byte    `protobuf:"bytes,3,rep,name=TokenIds,proto3" json:"TokenIds,omitempty"`
+	StorageKeys          [][]byte    `protobuf:"bytes,4,rep,name=StorageKeys,proto3" json:"StorageKeys,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *GetAccountState) Reset()         { *m = GetAccountState{} }
+func (m *GetAccountState) String() string { return proto.CompactTextString(m) }
+func (*GetAccountState) ProtoMessage()    {}
+func (*GetAccountState) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a6a8486deb9ab39, []int{19}
+}
+
+func (m *GetAccountState) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetAccountState.Unmarshal(m, b)
+}
+func (m *GetAccountState) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetAccountState.Marshal(b, m, deterministic)
+}
+func (m *GetAccountState) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetAccountState.Merge(m, src)
+}
+func (m *GetAccountState) XXX_Size() int {
+	return xxx_messageInfo_GetAccountState.Size(m)
+}
+func (m *GetAccountState) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetAccountState.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetAccountState proto.InternalMessageInfo
+
+func (m *GetAccountState) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *GetAccountState) GetSnapshot() *HashHeight {
+	if m != nil {
+		return m.Snapshot
+	}
+	return nil
+}
+
+func (m *GetAccountState) GetTokenIds() [][]byte {
+	if m != nil {
+		return m.TokenIds
+	}
+	return nil
+}
+
+func (m *GetAccountState) GetStorageKeys() [][]byte {
+	if m != nil {
+		return m.StorageKeys
+	}
+	return nil
+}
+
+type AccountState struct {
+	Snapshot             *HashHeight `protobuf:"bytes,1,opt,name=Snapshot,proto3" json:"Snapshot,omitempty"`
+	Balances             [][]byte    `protobuf:"bytes,2,rep,name=Balances,proto3" json:"Balances,omitempty"`
+	StorageValues        [][]byte    `protobuf:"bytes,3,rep,name=StorageValues,proto3" json:"StorageValues,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *AccountState) Reset()         { *m = AccountState{} }
+func (m *AccountState) String() string { return proto.CompactTextString(m) }
+func (*AccountState) ProtoMessage()    {}
+func (*AccountState) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a6a8486deb9ab39, []int{20}
+}
+
+func (m *AccountState) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AccountState.Unmarshal(m, b)
+}
+func (m *AccountState) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AccountState.Marshal(b, m, deterministic)
+}
+func (m *AccountState) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AccountState.Merge(m, src)
+}
+func (m *AccountState) XXX_Size() int {
+	return xxx_messageInfo_AccountState.Size(m)
+}
+func (m *AccountState) XXX_DiscardUnknown() {
+	xxx_messageInfo_AccountState.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AccountState proto.InternalMessageInfo
+
+func (m *AccountState) GetSnapshot() *HashHeight {
+	if m != nil {
+		return m.Snapshot
+	}
+	return nil
+}
+
+func (m *AccountState) GetBalances() [][]byte {
+	if m != nil {
+		return m.Balances
+	}
+	return nil
+}
+
+func (m *AccountState) GetStorageValues() [][]byte {
+	if m != nil {
+		return m.StorageValues
+	}
+	return nil
+}
+
+type GetElection struct {
+	Timestamp            int64    `protobuf:"varint,1,opt,name=Timestamp,proto3" json:"Timestamp,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetElection) Reset()         { *m = GetElection{} }
+func (m *GetElection) String() string { return proto.CompactTextString(m) }
+func (*GetElection) ProtoMessage()    {}
+func (*GetElection) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a6a8486deb9ab39, []int{21}
+}
+
+func (m *GetElection) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetElection.Unmarshal(m, b)
+}
+func (m *GetElection) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetElection.Marshal(b, m, deterministic)
+}
+func (m *GetElection) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetElection.Merge(m, src)
+}
+func (m *GetElection) XXX_Size() int {
+	return xxx_messageInfo_GetElection.Size(m)
+}
+func (m *GetElection) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetElection.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetElection proto.InternalMessageInfo
+
+func (m *GetElection) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+type Election struct {
+	Index                uint64          `protobuf:"varint,1,opt,name=Index,proto3" json:"Index,omitempty"`
+	PeriodStime          int64           `protobuf:"varint,2,opt,name=PeriodStime,proto3" json:"PeriodStime,omitempty"`
+	PeriodEtime          int64           `protobuf:"varint,3,opt,name=PeriodEtime,proto3" json:"PeriodEtime,omitempty"`
+	Slots                []*ElectionSlot `protobuf:"bytes,4,rep,name=Slots,proto3" json:"Slots,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *Election) Reset()         { *m = Election{} }
+func (m *Election) String() string { return proto.CompactTextString(m) }
+func (*Election) ProtoMessage()    {}
+func (*Election) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a6a8486deb9ab39, []int{22}
+}
+
+func (m *Election) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Election.Unmarshal(m, b)
+}
+func (m *Election) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Election.Marshal(b, m, deterministic)
+}
+func (m *Election) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Election.Merge(m, src)
+}
+func (m *Election) XXX_Size() int {
+	return xxx_messageInfo_Election.Size(m)
+}
+func (m *Election) XXX_DiscardUnknown() {
+	xxx_messageInfo_Election.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Election proto.InternalMessageInfo
+
+func (m *Election) GetIndex() uint64 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *Election) GetPeriodStime() int64 {
+	if m != nil {
+		return m.PeriodStime
+	}
+	return 0
+}
+
+func (m *Election) GetPeriodEtime() int64 {
+	if m != nil {
+		return m.PeriodEtime
+	}
+	return 0
+}
+
+func (m *Election) GetSlots() []*ElectionSlot {
+	if m != nil {
+		return m.Slots
+	}
+	return nil
+}
+
+type ElectionSlot struct {
+	Address              []byte   `protobuf:"bytes,1,opt,name=Address,proto3" json:"Address,omitempty"`
+	Stime                int64    `protobuf:"varint,2,opt,name=Stime,proto3" json:"Stime,omitempty"`
+	Etime                int64    `protobuf:"varint,3,opt,name=Etime,proto3" json:"Etime,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ElectionSlot) Reset()         { *m = ElectionSlot{} }
+func (m *ElectionSlot) String() string { return proto.CompactTextString(m) }
+func (*ElectionSlot) ProtoMessage()    {}
+func (*ElectionSlot) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a6a8486deb9ab39, []int{23}
+}
+
+func (m *ElectionSlot) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ElectionSlot.Unmarshal(m, b)
+}
+func (m *ElectionSlot) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ElectionSlot.Marshal(b, m, deterministic)
+}
+func (m *ElectionSlot) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ElectionSlot.Merge(m, src)
+}
+func (m *ElectionSlot) XXX_Size() int {
+	return xxx_messageInfo_ElectionSlot.Size(m)
+}
+func (m *ElectionSlot) XXX_DiscardUnknown() {
+	xxx_messageInfo_ElectionSlot.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ElectionSlot proto.InternalMessageInfo
+
+func (m *ElectionSlot) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *ElectionSlot) GetStime() int64 {
+	if m != nil {
+		return m.Stime
+	}
+	return 0
+}
+
+func (m *ElectionSlot) GetEtime() int64 {
+	if m != nil {
+		return m.Etime
+	}
+	return 0
+}
 func init() {
 	proto.RegisterEnum("vitepb.State_PeerStatus", State_PeerStatus_name, State_PeerStatus_value)
 	proto.RegisterType((*Handshake)(nil), "vitepb.Handshake")
@@ -1087,60 +1471,83 @@ func init() {
 	proto.RegisterType((*NewAccountBlock)(nil), "vitepb.NewAccountBlock")
 	proto.RegisterType((*NewAccountBlockBytes)(nil), "vitepb.NewAccountBlockBytes")
 	proto.RegisterType((*Trace)(nil), "vitepb.Trace")
+	proto.RegisterType((*GetAccountBlockProof)(nil), "vitepb.GetAccountBlockProof")
+	proto.RegisterType((*AccountBlockProof)(nil), "vitepb.AccountBlockProof")
+	proto.RegisterType((*GetAccountState)(nil), "vitepb.GetAccountState")
+	proto.RegisterType((*AccountState)(nil), "vitepb.AccountState")
+	proto.RegisterType((*GetElection)(nil), "vitepb.GetElection")
+	proto.RegisterType((*Election)(nil), "vitepb.Election")
+	proto.RegisterType((*ElectionSlot)(nil), "vitepb.ElectionSlot")
 }
 
 func init() { proto.RegisterFile("vitepb/message.proto", fileDescriptor_2a6a8486deb9ab39) }
 
 var fileDescriptor_2a6a8486deb9ab39 = []byte{
-	// 788 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x55, 0x4b, 0x6f, 0x1a, 0x57,
-	0x14, 0xee, 0xbc, 0x30, 0x1c, 0x03, 0xc5, 0x57, 0xb4, 0x1d, 0xd1, 0x2e, 0xd0, 0xa8, 0xaa, 0x50,
-	0x5b, 0xe3, 0xca, 0xdd, 0x74, 0xd3, 0x56, 0xd8, 0x8e, 0x8d, 0x15, 0x8b, 0x90, 0x0b, 0xca, 0xd6,
-	0x1a, 0x86, 0x23, 0x33, 0xc2, 0xcc, 0x90, 0xb9, 0x17, 0x5b, 0x8e, 0x94, 0x5d, 0x56, 0xf9, 0x3b,
-	0xf9, 0x83, 0xd1, 0x7d, 0xcc, 0x0b, 0x43, 0x94, 0x4d, 0x76, 0xe7, 0x35, 0xe7, 0x3b, 0xdf, 0xb9,
-	0x1f, 0x07, 0x68, 0x3f, 0x84, 0x1c, 0xd7, 0xb3, 0x93, 0x15, 0x32, 0xe6, 0xdf, 0x61, 0x7f, 0x9d,
-	0xc4, 0x3c, 0x26, 0x15, 0x15, 0xed, 0x74, 0x74, 0xd6, 0x0f, 0x82, 0x78, 0x13, 0xf1, 0xdb, 0xd9,
-	0x7d, 0x1c, 0x2c, 0x55, 0x4d, 0xe7, 0x67, 0x9d, 0x63, 0x91, 0xbf, 0x66, 0x8b, 0xb8, 0x94, 0xf4,
-	0x3e, 0x99, 0x50, 0x1b, 0xfa, 0xd1, 0x9c, 0x2d, 0xfc, 0x25, 0x12, 0x17, 0x0e, 0xde, 0x60, 0xc2,
-	0xc2, 0x38, 0x72, 0x8d, 0xae, 0xd1, 0xb3, 0x68, 0xea, 0x92, 0x36, 0x38, 0x23, 0xe4, 0xd7, 0x73,
-	0xd7, 0x94, 0x71, 0xe5, 0x10, 0x02, 0xf6, 0xc8, 0x5f, 0xa1, 0x6b, 0x75, 0x8d, 0x5e, 0x8d, 0x4a,
-	0x9b, 0x34, 0xc1, 0xbc, 0xbe, 0x70, 0xed, 0xae, 0xd1, 0xab, 0x53, 0xf3, 0xfa, 0x82, 0xfc, 0x02,
-	0xb5, 0x69, 0xb8, 0x42, 0xc6, 0xfd, 0xd5, 0xda, 0x75, 0xe4, 0xd7, 0x79, 0x40, 0x20, 0x5e, 0x61,
-	0x84, 0x2c, 0x64, 0x6e, 0x45, 0x7e, 0x92, 0xba, 0xe4, 0x47, 0xa8, 0x0c, 0x31, 0xbc, 0x5b, 0x70,
-	0xf7, 0xa0, 0x6b, 0xf4, 0x6c, 0xaa, 0x3d, 0x81, 0x39, 0x44, 0x7f, 0xee, 0x56, 0x65, 0xb9, 0xb4,
-	0x49, 0x17, 0x0e, 0x2f, 0xc3, 0x7b, 0x1c, 0xcc, 0xe7, 0x09, 0x32, 0xe6, 0xd6, 0x64, 0xaa, 0x18,
-	0x22, 0x2d, 0xb0, 0x5e, 0xe2, 0x93, 0x0b, 0x32, 0x23, 0x4c, 0xc1, 0x68, 0x1a, 0x2f, 0x31, 0x72,
-	0x0f, 0x65, 0x4c, 0x39, 0xe4, 0x57, 0x68, 0x8c, 0x37, 0xb3, 0xfb, 0x30, 0x48, 0x7b, 0xd5, 0x65,
-	0xb6, 0x1c, 0xf4, 0x42, 0x38, 0x9a, 0x3c, 0x45, 0xc1, 0x79, 0x1c, 0x45, 0xf9, 0xf2, 0x14, 0x71,
-	0x63, 0x37, 0x71, 0x73, 0x9b, 0xb8, 0x1e, 0xc8, 0xda, 0x31, 0x90, 0x5d, 0x18, 0xc8, 0x5b, 0x40,
-	0xfd, 0x7c, 0xb1, 0x89, 0x96, 0x14, 0xdf, 0x6e, 0x90, 0x49, 0xfa, 0x97, 0x49, 0xbc, 0x92, 0x38,
-	0x36, 0x95, 0xb6, 0x40, 0x9e, 0xc6, 0x12, 0xc2, 0xa6, 0xe6, 0x34, 0x26, 0x1d, 0xa8, 0x8e, 0x13,
-	0x7c, 0x18, 0xfa, 0x6c, 0xa1, 0x01, 0x32, 0x5f, 0x2c, 0xfc, 0x45, 0x34, 0x97, 0x29, 0x85, 0x93,
-	0xba, 0xde, 0x7b, 0x68, 0x68, 0x24, 0xb6, 0x8e, 0x23, 0x86, 0xdf, 0x0e, 0x4a, 0x74, 0x9e, 0x84,
-	0xef, 0x50, 0xca, 0xc1, 0xa6, 0xd2, 0xf6, 0x3e, 0x9a, 0xe0, 0x4c, 0xb8, 0xcf, 0x91, 0xf4, 0xc0,
-	0x19, 0x23, 0x26, 0xcc, 0x35, 0xba, 0x56, 0xef, 0xf0, 0x94, 0xf4, 0x95, 0x80, 0xfb, 0x32, 0xdb,
-	0x17, 0x29, 0xaa, 0x0a, 0xc4, 0xca, 0xc6, 0x3e, 0x0f, 0x16, 0x72, 0xa0, 0x2a, 0x55, 0x4e, 0xa6,
-	0x10, 0xab, 0xa0, 0x90, 0x5c, 0x4d, 0x76, 0x49, 0x4d, 0xa5, 0x47, 0x82, 0xad, 0x47, 0xea, 0x0c,
-	0xc1, 0x16, 0x40, 0xcf, 0x9e, 0xf6, 0x2f, 0xa8, 0x88, 0x61, 0x36, 0x4c, 0x62, 0x34, 0x4f, 0xdd,
-	0xe7, 0x23, 0xaa, 0x3c, 0xd5, 0x75, 0xde, 0x31, 0x40, 0x1e, 0x25, 0x0d, 0xa8, 0x09, 0xed, 0x60,
-	0xc0, 0x71, 0xde, 0xfa, 0x8e, 0xb4, 0xa0, 0x7e, 0x11, 0xb2, 0x20, 0x8b, 0x18, 0xde, 0x3f, 0x00,
-	0x62, 0x51, 0x05, 0xc9, 0x8b, 0x2d, 0x1a, 0x9a, 0x90, 0x58, 0x61, 0x4e, 0xc8, 0x2c, 0x12, 0xf2,
-	0x5e, 0xc1, 0xf7, 0xf9, 0x97, 0xe3, 0x38, 0x8c, 0xb8, 0xdc, 0xa7, 0x30, 0xe4, 0xf7, 0x85, 0x7d,
-	0xe6, 0x75, 0x54, 0x15, 0x64, 0xef, 0x62, 0x16, 0xde, 0x65, 0x00, 0xcd, 0xbc, 0xf0, 0x26, 0x64,
-	0x9c, 0x9c, 0x40, 0x45, 0x96, 0xa7, 0x0f, 0xf4, 0xd3, 0xf3, 0x86, 0x32, 0x4f, 0x75, 0x99, 0x77,
-	0x0b, 0x47, 0x57, 0xc8, 0xb7, 0xba, 0xfc, 0x96, 0xa9, 0xcb, 0xda, 0x33, 0x94, 0x52, 0x9c, 0x98,
-	0x89, 0xe3, 0x3a, 0x9b, 0x89, 0xe3, 0x5a, 0xab, 0xd0, 0x4a, 0x55, 0xe8, 0x2d, 0x25, 0xc0, 0x44,
-	0x1f, 0xb8, 0x33, 0x71, 0xdf, 0x58, 0x01, 0xc0, 0xf8, 0x22, 0x40, 0x1b, 0x9c, 0x73, 0x71, 0x34,
-	0x35, 0x82, 0x72, 0x84, 0x78, 0x2f, 0xe3, 0xe4, 0xd1, 0x4f, 0x94, 0x8e, 0xaa, 0x34, 0x75, 0xbd,
-	0xff, 0xa1, 0xb9, 0x85, 0x74, 0x0c, 0x15, 0x65, 0x69, 0x32, 0x3f, 0x64, 0x72, 0x28, 0xd6, 0x51,
-	0x5d, 0xe4, 0x7d, 0x30, 0xa0, 0x75, 0x85, 0x7c, 0xa0, 0x6e, 0xb5, 0xee, 0xe1, 0xc2, 0x41, 0x7a,
-	0x72, 0xd4, 0x33, 0xa7, 0x6e, 0xc6, 0xc3, 0xfc, 0x5a, 0x1e, 0xd6, 0x1e, 0x1e, 0x76, 0x99, 0xc7,
-	0xbf, 0xd0, 0x28, 0x8f, 0xf0, 0xe7, 0x16, 0x8d, 0x76, 0x0a, 0x55, 0x2c, 0xcb, 0x58, 0xbc, 0x86,
-	0xd6, 0x08, 0x1f, 0x4b, 0x0c, 0xc9, 0x1f, 0xe0, 0x48, 0x43, 0xef, 0x7c, 0xcf, 0x1e, 0x54, 0x8d,
-	0xb8, 0x80, 0xd3, 0xe9, 0x8d, 0xa4, 0xe5, 0x50, 0x61, 0x0a, 0xed, 0x8e, 0xf0, 0xb1, 0x88, 0x46,
-	0x7e, 0x2f, 0x77, 0xdc, 0x3d, 0xd2, 0xde, 0x86, 0xff, 0x41, 0x7b, 0xab, 0xe1, 0xd9, 0x13, 0x47,
-	0x79, 0x37, 0xf2, 0xae, 0xf5, 0xfd, 0xdf, 0x0f, 0xc0, 0x99, 0x26, 0x7e, 0x80, 0x3b, 0x7f, 0x81,
-	0x04, 0xec, 0xb1, 0xcf, 0xc5, 0xed, 0xb1, 0x44, 0x4c, 0xd8, 0x69, 0x0b, 0xf1, 0x02, 0x0d, 0xd9,
-	0x62, 0x56, 0x91, 0xff, 0xb3, 0x7f, 0x7f, 0x0e, 0x00, 0x00, 0xff, 0xff, 0x61, 0x22, 0x50, 0xa0,
-	0xc0, 0x07, 0x00, 0x00,
+	// 1041 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x56, 0xcd, 0x6f, 0xe3, 0x44,
+	0x14, 0xc7, 0x1f, 0xc9, 0xa6, 0xaf, 0x49, 0xdb, 0x1d, 0x05, 0xb0, 0x0a, 0x42, 0x91, 0x85, 0x56,
+	0xd1, 0x2e, 0xdb, 0x45, 0xcb, 0x85, 0x0b, 0xa0, 0x7e, 0xb7, 0xda, 0x55, 0x09, 0x4e, 0xb4, 0xd7,
+	0xd5, 0xd4, 0x7e, 0x34, 0x56, 0x13, 0x3b, 0x78, 0x26, 0x5b, 0x8a, 0x84, 0xc4, 0x81, 0x13, 0x27,
+	0x2e, 0x1c, 0xf8, 0x6f, 0xd1, 0x9b, 0x19, 0xdb, 0xe3, 0xb4, 0xa9, 0x7a, 0xe1, 0x36, 0xef, 0xbd,
+	0x9f, 0xdf, 0xef, 0x7d, 0xf9, 0xcd, 0x40, 0xff, 0x43, 0x2a, 0x71, 0x71, 0xf9, 0x6a, 0x8e, 0x42,
+	0xf0, 0x2b, 0xdc, 0x5b, 0x14, 0xb9, 0xcc, 0x59, 0x5b, 0x6b, 0x77, 0x77, 0x8d, 0x95, 0xc7, 0x71,
+	0xbe, 0xcc, 0xe4, 0xfb, 0xcb, 0x59, 0x1e, 0x5f, 0x6b, 0xcc, 0xee, 0x67, 0xc6, 0x26, 0x32, 0xbe,
+	0x10, 0xd3, 0xbc, 0x61, 0x0c, 0xff, 0xf1, 0x60, 0xe3, 0x8c, 0x67, 0x89, 0x98, 0xf2, 0x6b, 0x64,
+	0x01, 0x3c, 0x79, 0x87, 0x85, 0x48, 0xf3, 0x2c, 0x70, 0x06, 0xce, 0xd0, 0x8b, 0x4a, 0x91, 0xf5,
+	0xa1, 0x75, 0x81, 0xf2, 0x3c, 0x09, 0x5c, 0xa5, 0xd7, 0x02, 0x63, 0xe0, 0x5f, 0xf0, 0x39, 0x06,
+	0xde, 0xc0, 0x19, 0x6e, 0x44, 0xea, 0xcc, 0xb6, 0xc0, 0x3d, 0x3f, 0x0a, 0xfc, 0x81, 0x33, 0xec,
+	0x46, 0xee, 0xf9, 0x11, 0xfb, 0x1c, 0x36, 0x26, 0xe9, 0x1c, 0x85, 0xe4, 0xf3, 0x45, 0xd0, 0x52,
+	0x5f, 0xd7, 0x0a, 0x62, 0x3c, 0xc5, 0x0c, 0x45, 0x2a, 0x82, 0xb6, 0xfa, 0xa4, 0x14, 0xd9, 0x27,
+	0xd0, 0x3e, 0xc3, 0xf4, 0x6a, 0x2a, 0x83, 0x27, 0x03, 0x67, 0xe8, 0x47, 0x46, 0x22, 0xce, 0x33,
+	0xe4, 0x49, 0xd0, 0x51, 0x70, 0x75, 0x66, 0x03, 0xd8, 0x3c, 0x49, 0x67, 0xb8, 0x9f, 0x24, 0x05,
+	0x0a, 0x11, 0x6c, 0x28, 0x93, 0xad, 0x62, 0x3b, 0xe0, 0xbd, 0xc1, 0xdb, 0x00, 0x94, 0x85, 0x8e,
+	0x94, 0xd1, 0x24, 0xbf, 0xc6, 0x2c, 0xd8, 0x54, 0x3a, 0x2d, 0xb0, 0x2f, 0xa1, 0x37, 0x5a, 0x5e,
+	0xce, 0xd2, 0xb8, 0xf4, 0xd5, 0x55, 0xd6, 0xa6, 0x92, 0x7d, 0x01, 0x70, 0x9c, 0xc5, 0xc5, 0xed,
+	0x42, 0x52, 0xa9, 0x7a, 0x03, 0x67, 0xd8, 0x8b, 0x2c, 0x0d, 0x0b, 0xa1, 0x7b, 0xbc, 0x98, 0xe2,
+	0x1c, 0x0b, 0x3e, 0x23, 0xda, 0x2d, 0xe5, 0xa4, 0xa1, 0xa3, 0xba, 0x8c, 0xd3, 0xab, 0x8c, 0xcb,
+	0x65, 0x81, 0xc1, 0xb6, 0x02, 0xd4, 0x8a, 0x30, 0x85, 0xa7, 0xe3, 0xdb, 0x2c, 0x3e, 0xcc, 0xb3,
+	0xac, 0x6e, 0x8f, 0x2e, 0xad, 0x73, 0x7f, 0x69, 0xdd, 0xd5, 0xd2, 0x9a, 0x94, 0xbd, 0x7b, 0x52,
+	0xf6, 0xad, 0x94, 0xc3, 0x29, 0x74, 0x0f, 0xa7, 0xcb, 0xec, 0x3a, 0xc2, 0x5f, 0x96, 0x28, 0x54,
+	0x81, 0x4f, 0x8a, 0x7c, 0xae, 0x78, 0xfc, 0x48, 0x9d, 0x89, 0x79, 0x92, 0x2b, 0x0a, 0x3f, 0x72,
+	0x27, 0x39, 0xdb, 0x85, 0xce, 0xa8, 0xc0, 0x0f, 0x67, 0x5c, 0x4c, 0x0d, 0x41, 0x25, 0x53, 0x4b,
+	0x8f, 0xb3, 0x44, 0x99, 0x34, 0x4f, 0x29, 0x86, 0xbf, 0x43, 0xcf, 0x30, 0x89, 0x45, 0x9e, 0x09,
+	0xfc, 0xff, 0xa8, 0xc8, 0xf3, 0x38, 0xfd, 0x0d, 0xd5, 0xc0, 0xf9, 0x91, 0x3a, 0x87, 0x7f, 0xb9,
+	0xd0, 0x1a, 0x4b, 0x2e, 0x91, 0x0d, 0xa1, 0x35, 0x42, 0x2c, 0x44, 0xe0, 0x0c, 0xbc, 0xe1, 0xe6,
+	0x6b, 0xb6, 0xa7, 0x7f, 0x91, 0x3d, 0x65, 0xdd, 0x23, 0x53, 0xa4, 0x01, 0x54, 0xb2, 0x11, 0x97,
+	0xf1, 0x54, 0x05, 0xd4, 0x89, 0xb4, 0x50, 0xcd, 0xa0, 0x67, 0xcd, 0x60, 0x3d, 0xaf, 0x7e, 0x63,
+	0x5e, 0x1b, 0x4d, 0x82, 0x95, 0x26, 0xed, 0x9e, 0x81, 0x4f, 0x44, 0x77, 0x5a, 0xfb, 0x35, 0xb4,
+	0x29, 0x98, 0xa5, 0x50, 0x1c, 0x5b, 0xaf, 0x83, 0xbb, 0x21, 0x6a, 0x7b, 0x64, 0x70, 0xe1, 0x4b,
+	0x80, 0x5a, 0xcb, 0x7a, 0xb0, 0x41, 0xb3, 0x83, 0xb1, 0xc4, 0x64, 0xe7, 0x23, 0xb6, 0x03, 0xdd,
+	0xa3, 0x54, 0xc4, 0x95, 0xc6, 0x09, 0xbf, 0x05, 0xa0, 0x42, 0x59, 0x3f, 0x15, 0x55, 0xd1, 0x31,
+	0x09, 0x51, 0x09, 0xeb, 0x84, 0x5c, 0x3b, 0xa1, 0xf0, 0x47, 0xd8, 0xae, 0xbf, 0x1c, 0xe5, 0x69,
+	0x26, 0x55, 0x3d, 0xe9, 0xa0, 0xbe, 0xb7, 0xea, 0x59, 0xe3, 0x22, 0x0d, 0xa8, 0xfa, 0xe2, 0x5a,
+	0x7d, 0xd9, 0x87, 0xad, 0x1a, 0xf8, 0x36, 0x15, 0x92, 0xbd, 0x82, 0xb6, 0x82, 0x97, 0x0d, 0xfa,
+	0xf4, 0xae, 0x43, 0x65, 0x8f, 0x0c, 0x2c, 0x7c, 0x0f, 0x4f, 0x4f, 0x51, 0xae, 0x78, 0x79, 0x56,
+	0x4d, 0x97, 0xb7, 0x26, 0x28, 0x3d, 0x71, 0x14, 0x93, 0xc4, 0x45, 0x15, 0x93, 0xc4, 0x85, 0x99,
+	0x42, 0xaf, 0x9c, 0xc2, 0xf0, 0x5a, 0x11, 0x8c, 0xcd, 0x0a, 0x3d, 0xa0, 0x0d, 0x2a, 0x2c, 0x02,
+	0xe7, 0x41, 0x82, 0x3e, 0xb4, 0x0e, 0x69, 0x2d, 0x1b, 0x06, 0x2d, 0xd0, 0xf0, 0x9e, 0xe4, 0xc5,
+	0x0d, 0x2f, 0xf4, 0x1c, 0x75, 0xa2, 0x52, 0x0c, 0x7f, 0x80, 0xad, 0x15, 0xa6, 0x97, 0xd0, 0xd6,
+	0x27, 0x93, 0xcc, 0xc7, 0xd5, 0x38, 0xd8, 0xb8, 0xc8, 0x80, 0xc2, 0x3f, 0x1d, 0xd8, 0x39, 0x45,
+	0xb9, 0xaf, 0x6f, 0x03, 0xe3, 0x23, 0x80, 0x27, 0xe5, 0x52, 0xd3, 0x6d, 0x2e, 0xc5, 0x2a, 0x0f,
+	0xf7, 0xb1, 0x79, 0x78, 0x6b, 0xf2, 0xf0, 0x9b, 0x79, 0x7c, 0x07, 0xbd, 0x66, 0x08, 0x5f, 0xad,
+	0xa4, 0xd1, 0x2f, 0xa9, 0x6c, 0x58, 0x95, 0xc5, 0x4f, 0xb0, 0x73, 0x81, 0x37, 0x8d, 0x0c, 0xd9,
+	0x0b, 0x68, 0xa9, 0x83, 0xa9, 0xf9, 0x9a, 0x3a, 0x68, 0x0c, 0x6d, 0xc0, 0xc9, 0xe4, 0xad, 0x4a,
+	0xab, 0x15, 0xd1, 0x91, 0x66, 0xf7, 0x02, 0x6f, 0x6c, 0x36, 0xf6, 0xbc, 0xe9, 0xf1, 0xfe, 0x90,
+	0xd6, 0x3a, 0xfc, 0x1e, 0xfa, 0x2b, 0x0e, 0x0f, 0x6e, 0x25, 0xaa, 0xbd, 0x51, 0x7b, 0xed, 0xae,
+	0xff, 0x7e, 0x1f, 0x5a, 0x93, 0x82, 0xc7, 0x78, 0xef, 0x1f, 0xc8, 0xc0, 0x1f, 0x71, 0x49, 0xbb,
+	0xc7, 0x23, 0x1d, 0x9d, 0x4b, 0x17, 0x9e, 0xba, 0x73, 0x94, 0x8b, 0xe7, 0xd0, 0x5f, 0xe9, 0xf5,
+	0xa8, 0xc8, 0xf3, 0x9f, 0xef, 0xf3, 0x48, 0xd7, 0xca, 0x5d, 0xe0, 0xb3, 0x7a, 0xdc, 0xcc, 0x0f,
+	0xaf, 0xf7, 0xf1, 0x8a, 0xd6, 0xea, 0x9e, 0xfb, 0x88, 0xee, 0xfd, 0xeb, 0xc0, 0x76, 0x1d, 0x97,
+	0xde, 0xbb, 0xeb, 0x47, 0x70, 0x0f, 0x3a, 0x25, 0xdb, 0x03, 0x63, 0x58, 0x61, 0xe8, 0x56, 0x50,
+	0xb7, 0xd7, 0x79, 0x42, 0x1b, 0x92, 0xca, 0x53, 0xc9, 0xf4, 0x1a, 0x18, 0xcb, 0xbc, 0xe0, 0x57,
+	0xf8, 0x06, 0x6f, 0x45, 0xe0, 0x2b, 0xb3, 0xad, 0x0a, 0xff, 0x70, 0xa0, 0xdb, 0x08, 0xcc, 0xa6,
+	0x77, 0x1e, 0x47, 0x7f, 0xc0, 0x67, 0x3c, 0x8b, 0x51, 0x98, 0xee, 0x54, 0x32, 0x3d, 0x21, 0x0c,
+	0xd7, 0x3b, 0x3e, 0x5b, 0x62, 0x19, 0x5f, 0x53, 0x19, 0xbe, 0x80, 0xcd, 0x53, 0x94, 0xc7, 0x33,
+	0x8c, 0xd5, 0x8b, 0xa1, 0x71, 0x4b, 0x38, 0x2b, 0xb7, 0x44, 0xf8, 0xb7, 0x03, 0x9d, 0x0a, 0xda,
+	0x87, 0xd6, 0x79, 0x96, 0xe0, 0xaf, 0xa6, 0x4b, 0x5a, 0xa0, 0xa4, 0x47, 0x58, 0xa4, 0x79, 0x32,
+	0x96, 0xe9, 0x1c, 0xcd, 0x6b, 0xc0, 0x56, 0xd5, 0x88, 0x63, 0x85, 0xf0, 0x6c, 0x84, 0x52, 0xd1,
+	0xaf, 0x30, 0x9e, 0xe5, 0x52, 0x97, 0xcc, 0xea, 0x6f, 0x49, 0x4d, 0xc6, 0x48, 0x43, 0xc2, 0x09,
+	0x74, 0x6d, 0xf5, 0x03, 0xad, 0xed, 0xd3, 0xad, 0x5b, 0xc7, 0xa4, 0x05, 0xd2, 0xda, 0x71, 0x68,
+	0xe1, 0xb2, 0xad, 0x5e, 0xa5, 0xdf, 0xfc, 0x37, 0x00, 0x40, 0x79, 0x42, 0x47, 0xee, 0x0a, 0x00,
+	0x00,
 }
@@ -93,6 +93,18 @@ func X25519ComputeSecret(private []byte, peersPublic []byte) ([]byte, error) {
 	return sec[:], nil
 }
 
+// X25519GenerateKey generates a key pair for an ephemeral key exchange
+func X25519GenerateKey() (private []byte, public []byte, err error) {
+	private = GetEntropyCSPRNG(curve25519.ScalarSize)
+	public, err = curve25519.X25519(private, curve25519.Basepoint)
+	return
+}
+
+// X25519SharedSecret is X25519ComputeSecret which refuses the low order public keys
+func X25519SharedSecret(private []byte, peersPublic []byte) ([]byte, error) {
+	return curve25519.X25519(private, peersPublic)
+}
+
 // AesCTRXOR(plainText) = cipherText AesCTRXOR(cipherText) = plainText
 func AesCTRXOR(key, inText, iv []byte) ([]byte, error) {
 
@@ -17,3 +17,9 @@ type Account interface {
 	Sign(msg []byte) (signData []byte, pub ed25519.PublicKey, err error)
 	Verify(pub ed25519.PublicKey, message, signdata []byte) error
 }
+
+// Signer signs data with the key of an address, the key can be kept outside the process,
+// such as by a remote signing daemon in front of an HSM.
+type Signer interface {
+	SignData(addr types.Address, data []byte) (signedData, pubkey []byte, err error)
+}
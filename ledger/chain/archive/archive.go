@@ -0,0 +1,369 @@
+package chain_archive
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const DefaultChunkSize = int64(256 * 1024 * 1024)
+
+// LedgerFiles are the files under the ledger directory that make up a ledger,
+// the onroad, sync cache and plugin data are rebuilt by the node.
+var LedgerFiles = []string{"blocks", "index", "state", "state_redo", "chain_meta", "flusher", "flush.redo.log"}
+
+var errChunkMismatch = errors.New("chunk does not match the manifest")
+
+// Pack writes the files of the names under srcDir into outDir as a tar stream split
+// into chunks of chunkSize bytes, and records the files and the chunks in the manifest.
+// Names missing in srcDir are skipped.
+func Pack(srcDir string, names []string, outDir string, chunkSize int64, m *Manifest) error {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return err
+	}
+
+	cw := &chunkWriter{dir: outDir, size: chunkSize}
+	tw := tar.NewWriter(cw)
+
+	var files []*FileEntry
+	walkFn := func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if info.IsDir() {
+			return tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: rel + "/", Mode: 0755})
+		}
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("unsupported file %s", path)
+		}
+
+		if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: rel, Mode: 0644, Size: info.Size()}); err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		h := sha256.New()
+		n, err := io.Copy(io.MultiWriter(tw, h), f)
+		if err != nil {
+			return err
+		}
+		if n != info.Size() {
+			return fmt.Errorf("file %s is modified while packing", path)
+		}
+		files = append(files, &FileEntry{Path: rel, Size: n, Sha256: hex.EncodeToString(h.Sum(nil))})
+		return nil
+	}
+	for _, name := range names {
+		root := filepath.Join(srcDir, name)
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+		if err := filepath.Walk(root, walkFn); err != nil {
+			cw.Close()
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		cw.Close()
+		return err
+	}
+	if err := cw.Close(); err != nil {
+		return err
+	}
+
+	m.ChunkSize = chunkSize
+	m.Files = files
+	m.Chunks = cw.chunks
+	return nil
+}
+
+// Unpack extracts the chunks of the archive in srcDir into destDir, every chunk
+// and every file is checked against the manifest. The signature of the manifest
+// should be verified by the caller before. destDir is left in an undefined state
+// if an error is returned.
+func Unpack(srcDir string, m *Manifest, destDir string) error {
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return err
+	}
+
+	expected := make(map[string]*FileEntry, len(m.Files))
+	for _, file := range m.Files {
+		expected[file.Path] = file
+	}
+
+	cr := &chunkReader{dir: srcDir, chunks: m.Chunks}
+	tr := tar.NewReader(cr)
+	extracted := make(map[string]bool, len(m.Files))
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("invalid path %s in archive", header.Name)
+		}
+		target := filepath.Join(destDir, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			entry, ok := expected[filepath.ToSlash(name)]
+			if !ok || extracted[entry.Path] {
+				return fmt.Errorf("file %s is not in the manifest", header.Name)
+			}
+			if err := extractFile(tr, target, entry); err != nil {
+				return err
+			}
+			extracted[entry.Path] = true
+		default:
+			return fmt.Errorf("unsupported entry %s in archive", header.Name)
+		}
+	}
+
+	// drain the padding, so the checksum of the last chunk is checked
+	if _, err := io.Copy(ioutil.Discard, cr); err != nil {
+		return err
+	}
+
+	if len(extracted) != len(expected) {
+		return fmt.Errorf("%d files are missing in archive", len(expected)-len(extracted))
+	}
+	return nil
+}
+
+func extractFile(r io.Reader, target string, entry *FileEntry) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), r)
+	if err != nil {
+		return err
+	}
+	if n != entry.Size || hex.EncodeToString(h.Sum(nil)) != entry.Sha256 {
+		return fmt.Errorf("file %s does not match the manifest", entry.Path)
+	}
+	return f.Sync()
+}
+
+// CopyDir copies the regular files of the names under srcDir into destDir, names
+// missing in srcDir are skipped.
+func CopyDir(srcDir string, destDir string, names []string) error {
+	for _, name := range names {
+		root := filepath.Join(srcDir, name)
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(srcDir, path)
+			if err != nil {
+				return err
+			}
+			target := filepath.Join(destDir, rel)
+			if info.IsDir() {
+				return os.MkdirAll(target, 0755)
+			}
+			if !info.Mode().IsRegular() {
+				return fmt.Errorf("unsupported file %s", path)
+			}
+			return copyFile(path, target)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	return out.Sync()
+}
+
+func chunkName(index int) string {
+	return fmt.Sprintf("chunk-%06d", index)
+}
+
+// chunkWriter splits the written data into files of at most size bytes
+type chunkWriter struct {
+	dir  string
+	size int64
+
+	file    *os.File
+	written int64
+	hash    hash.Hash
+	chunks  []*ChunkEntry
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	total := 0
+	for len(p) > 0 {
+		if w.file == nil {
+			f, err := os.Create(filepath.Join(w.dir, chunkName(len(w.chunks))))
+			if err != nil {
+				return total, err
+			}
+			w.file = f
+			w.written = 0
+			w.hash = sha256.New()
+		}
+
+		n := int64(len(p))
+		if left := w.size - w.written; n > left {
+			n = left
+		}
+		if _, err := w.file.Write(p[:n]); err != nil {
+			return total, err
+		}
+		w.hash.Write(p[:n])
+		w.written += n
+		total += int(n)
+		p = p[n:]
+
+		if w.written == w.size {
+			if err := w.finish(); err != nil {
+				return total, err
+			}
+		}
+	}
+	return total, nil
+}
+
+func (w *chunkWriter) finish() error {
+	if err := w.file.Sync(); err != nil {
+		w.file.Close()
+		return err
+	}
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.chunks = append(w.chunks, &ChunkEntry{
+		Name:   filepath.Base(w.file.Name()),
+		Size:   w.written,
+		Sha256: hex.EncodeToString(w.hash.Sum(nil)),
+	})
+	w.file = nil
+	return nil
+}
+
+func (w *chunkWriter) Close() error {
+	if w.file == nil {
+		return nil
+	}
+	return w.finish()
+}
+
+// chunkReader reads the chunks in order, a chunk is checked against the manifest
+// when it is read to the end
+type chunkReader struct {
+	dir    string
+	chunks []*ChunkEntry
+
+	index int
+	file  *os.File
+	read  int64
+	hash  hash.Hash
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if r.file == nil {
+			if r.index >= len(r.chunks) {
+				return 0, io.EOF
+			}
+			chunk := r.chunks[r.index]
+			if filepath.Base(chunk.Name) != chunk.Name {
+				return 0, fmt.Errorf("invalid chunk name %s", chunk.Name)
+			}
+			f, err := os.Open(filepath.Join(r.dir, chunk.Name))
+			if err != nil {
+				return 0, err
+			}
+			r.file = f
+			r.read = 0
+			r.hash = sha256.New()
+		}
+
+		n, err := r.file.Read(p)
+		r.hash.Write(p[:n])
+		r.read += int64(n)
+		if err == io.EOF {
+			if cErr := r.closeChunk(); cErr != nil {
+				return n, cErr
+			}
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *chunkReader) closeChunk() error {
+	chunk := r.chunks[r.index]
+	r.file.Close()
+	r.file = nil
+	r.index++
+	if r.read != chunk.Size || hex.EncodeToString(r.hash.Sum(nil)) != chunk.Sha256 {
+		return fmt.Errorf("%s: %s", chunk.Name, errChunkMismatch)
+	}
+	return nil
+}
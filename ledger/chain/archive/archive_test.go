@@ -0,0 +1,98 @@
+package chain_archive
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+)
+
+func mockLedgerDir(t *testing.T, dir string) map[string][]byte {
+	files := map[string][]byte{
+		"blocks/f1":          bytes.Repeat([]byte{1}, 3000),
+		"blocks/f2":          bytes.Repeat([]byte{2}, 10),
+		"index/000001.ldb":   bytes.Repeat([]byte{3}, 700),
+		"state/CURRENT":      []byte("MANIFEST-000001"),
+		"chain_meta/LOG":     {},
+		"flush.redo.log":     []byte("redo"),
+		"onroad/000001.ldb":  []byte("not exported"),
+		"sync_cache/f1":      []byte("not exported"),
+		"plugins/000001.ldb": []byte("not exported"),
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, data, 0644))
+	}
+	return files
+}
+
+func TestPackUnpack(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chain_archive")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	srcDir := filepath.Join(dir, "src")
+	files := mockLedgerDir(t, srcDir)
+
+	_, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	archiveDir := filepath.Join(dir, "archive")
+	m := &Manifest{Version: ManifestVersion, Height: 100, Hash: types.Hash{1}}
+	require.NoError(t, Pack(srcDir, LedgerFiles, archiveDir, 1024, m))
+	require.NoError(t, m.Sign(priv))
+	require.NoError(t, WriteManifest(archiveDir, m))
+
+	assert.Equal(t, 6, len(m.Files))
+	assert.True(t, len(m.Chunks) > 1)
+	for _, chunk := range m.Chunks {
+		assert.True(t, chunk.Size <= 1024)
+	}
+
+	m, err = ReadManifest(archiveDir)
+	require.NoError(t, err)
+	require.NoError(t, m.Verify())
+	assert.Equal(t, types.PrikeyToAddress(priv), m.Signer())
+
+	destDir := filepath.Join(dir, "dest")
+	require.NoError(t, Unpack(archiveDir, m, destDir))
+	for name, data := range files {
+		restored, err := ioutil.ReadFile(filepath.Join(destDir, filepath.FromSlash(name)))
+		if filepath.Dir(name) == "onroad" || filepath.Dir(name) == "sync_cache" || filepath.Dir(name) == "plugins" {
+			assert.True(t, os.IsNotExist(err), name)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, data, restored, name)
+	}
+
+	// tampered manifest
+	m.Height = 101
+	assert.Equal(t, errInvalidSignature, m.Verify())
+	m.Height = 100
+
+	// corrupted chunk
+	chunk := filepath.Join(archiveDir, m.Chunks[len(m.Chunks)-1].Name)
+	data, err := ioutil.ReadFile(chunk)
+	require.NoError(t, err)
+	data[len(data)-1] ^= 0xff
+	require.NoError(t, ioutil.WriteFile(chunk, data, 0644))
+	assert.Error(t, Unpack(archiveDir, m, filepath.Join(dir, "dest2")))
+
+	// missing chunk
+	require.NoError(t, os.Remove(chunk))
+	assert.Error(t, Unpack(archiveDir, m, filepath.Join(dir, "dest3")))
+}
+
+func TestManifestNotSigned(t *testing.T) {
+	m := &Manifest{Version: ManifestVersion, Height: 100}
+	assert.Equal(t, errNotSigned, m.Verify())
+}
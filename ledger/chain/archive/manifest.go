@@ -0,0 +1,117 @@
+package chain_archive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/crypto"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+)
+
+const (
+	ManifestName    = "manifest.json"
+	ManifestVersion = uint32(1)
+)
+
+var (
+	errNotSigned        = errors.New("manifest is not signed")
+	errInvalidSignature = errors.New("invalid manifest signature")
+)
+
+// Manifest describes an archive of the ledger at a snapshot block
+type Manifest struct {
+	Version     uint32     `json:"version"`
+	GenesisHash types.Hash `json:"genesisHash"`
+	Height      uint64     `json:"height"`
+	Hash        types.Hash `json:"hash"`
+	CreatedAt   int64      `json:"createdAt"`
+
+	ChunkSize int64         `json:"chunkSize"`
+	Files     []*FileEntry  `json:"files"`
+	Chunks    []*ChunkEntry `json:"chunks"`
+
+	PublicKey ed25519.PublicKey `json:"publicKey"`
+	Signature []byte            `json:"signature"`
+}
+
+// FileEntry is a file of the ledger directory, Path is relative to the directory
+type FileEntry struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Sha256 string `json:"sha256"`
+}
+
+// ChunkEntry is a piece of the tar stream of the ledger directory
+type ChunkEntry struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	Sha256 string `json:"sha256"`
+}
+
+func (m *Manifest) signingHash() ([]byte, error) {
+	unsigned := *m
+	unsigned.PublicKey = nil
+	unsigned.Signature = nil
+
+	data, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Hash256(data), nil
+}
+
+func (m *Manifest) Sign(priv ed25519.PrivateKey) error {
+	hash, err := m.signingHash()
+	if err != nil {
+		return err
+	}
+	m.PublicKey = priv.PubByte()
+	m.Signature = ed25519.Sign(priv, hash)
+	return nil
+}
+
+// Verify checks the signature of the manifest, the caller should check whether the Signer is trusted
+func (m *Manifest) Verify() error {
+	if len(m.PublicKey) != ed25519.PublicKeySize || len(m.Signature) == 0 {
+		return errNotSigned
+	}
+	hash, err := m.signingHash()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(m.PublicKey, hash, m.Signature) {
+		return errInvalidSignature
+	}
+	return nil
+}
+
+func (m *Manifest) Signer() types.Address {
+	return types.PubkeyToAddress(m.PublicKey)
+}
+
+func WriteManifest(dir string, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, ManifestName), data, 0644)
+}
+
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, ManifestName))
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("invalid manifest, %s", err)
+	}
+	if m.Version != ManifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d", m.Version)
+	}
+	return m, nil
+}
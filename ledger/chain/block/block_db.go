@@ -22,11 +22,26 @@ var (
 	FixFileSize = int64(10 * 1024 * 1024)
 )
 
+// BlocksDirName is the directory of the block files in the chain directory
+const BlocksDirName = "blocks"
+
+// CorruptionError is returned when a record in the block files is damaged
+type CorruptionError struct {
+	File     string
+	Location chain_file_manager.Location
+	Err      error
+}
+
+func (e *CorruptionError) Error() string {
+	return fmt.Sprintf("damaged record in block file %s at offset %d: %s", e.File, e.Location.Offset, e.Err)
+}
+
 // BlockDB append all blocks to file
 type BlockDB struct {
 	fm *chain_file_manager.FileManager
 
 	snappyWriteBuffer []byte
+	compression       Compression
 	wg                sync.WaitGroup
 
 	fileSize int64
@@ -46,9 +61,14 @@ func NewBlockDB(chainDir string) (*BlockDB, error) {
 
 // NewBlockDB instance for BlocksDB
 func NewBlockDBFixedSize(chainDir string, fileSize int64) (*BlockDB, error) {
+	return NewBlockDBInDir(path.Join(chainDir, BlocksDirName), fileSize)
+}
+
+// NewBlockDBInDir opens the block files in dir
+func NewBlockDBInDir(dir string, fileSize int64) (*BlockDB, error) {
 	id, _ := types.BytesToHash(crypto.Hash256([]byte("blockDb")))
 
-	fm, err := chain_file_manager.NewFileManager(path.Join(chainDir, "blocks"), fileSize, 10)
+	fm, err := chain_file_manager.NewFileManager(dir, fileSize, 10)
 	if err != nil {
 		return nil, err
 	}
@@ -67,6 +87,16 @@ func (bDB *BlockDB) FileSize() int64 {
 	return bDB.fileSize
 }
 
+// SetCompression sets the compression of the records written later, the records written before are not changed
+func (bDB *BlockDB) SetCompression(compression Compression) {
+	bDB.compression = compression
+}
+
+// LatestLocation returns the location after the last record
+func (bDB *BlockDB) LatestLocation() *chain_file_manager.Location {
+	return bDB.fm.LatestLocation()
+}
+
 // Close close db
 func (bDB *BlockDB) Close() error {
 	if err := bDB.fm.Close(); err != nil {
@@ -87,7 +117,7 @@ func (bDB *BlockDB) Write(ss *ledger.SnapshotChunk) (map[types.Hash]*chain_file_
 			return nil, nil, fmt.Errorf("ss.AccountBlocks.Serialize failed, error is %s, accountBlock is %+v", err.Error(), accountBlock)
 		}
 
-		if location, err := bDB.fm.Write(makeWriteBytes(bDB.snappyWriteBuffer, BlockTypeAccountBlock, buf)); err != nil {
+		if location, err := bDB.fm.Write(makeWriteBytes(bDB.snappyWriteBuffer, bDB.compression, BlockTypeAccountBlock, buf)); err != nil {
 			return nil, nil, fmt.Errorf("bDB.fm.Write failed, error is %s, accountBlock is %+v", err.Error(), accountBlock)
 		} else {
 			accountBlocksLocation[accountBlock.Hash] = location
@@ -99,7 +129,7 @@ func (bDB *BlockDB) Write(ss *ledger.SnapshotChunk) (map[types.Hash]*chain_file_
 		return nil, nil, fmt.Errorf("ss.SnapshotBlock.Serialize failed, error is %s, snapshotBlock is %+v", err.Error(), ss.SnapshotBlock)
 	}
 
-	snapshotBlockLocation, err := bDB.fm.Write(makeWriteBytes(bDB.snappyWriteBuffer, BlockTypeSnapshotBlock, buf))
+	snapshotBlockLocation, err := bDB.fm.Write(makeWriteBytes(bDB.snappyWriteBuffer, bDB.compression, BlockTypeSnapshotBlock, buf))
 
 	//bDB.log.Info(fmt.Sprintf("sb %s %d %d", ss.SnapshotBlock.Hash, ss.SnapshotBlock.Height, data), "method", "Write")
 
@@ -109,6 +139,52 @@ func (bDB *BlockDB) Write(ss *ledger.SnapshotChunk) (map[types.Hash]*chain_file_
 	return accountBlocksLocation, snapshotBlockLocation, nil
 }
 
+// EncodeChunk encodes the account blocks and the snapshot block of the chunk in order, and returns the records
+// and the offset of every record. The records are of version 1 if checksum is false, which are always compressed by snappy.
+func EncodeChunk(chunk *ledger.SnapshotChunk, checksum bool, compression Compression) ([]byte, []int64, error) {
+	var records []byte
+	var offsets []int64
+	encode := func(blockType byte, buf []byte) {
+		offsets = append(offsets, int64(len(records)))
+		record := make([]byte, 9+snappy.MaxEncodedLen(len(buf)))
+		if checksum {
+			record = makeWriteBytes(record, compression, blockType, buf)
+		} else {
+			record = makeLegacyWriteBytes(record, blockType, buf)
+		}
+		records = append(records, record...)
+	}
+
+	for _, accountBlock := range chunk.AccountBlocks {
+		buf, err := accountBlock.Serialize()
+		if err != nil {
+			return nil, nil, fmt.Errorf("accountBlock.Serialize failed, error is %s, accountBlock is %+v", err.Error(), accountBlock)
+		}
+		encode(BlockTypeAccountBlock, buf)
+	}
+
+	buf, err := chunk.SnapshotBlock.Serialize()
+	if err != nil {
+		return nil, nil, fmt.Errorf("chunk.SnapshotBlock.Serialize failed, error is %s, snapshotBlock is %+v", err.Error(), chunk.SnapshotBlock)
+	}
+	encode(BlockTypeSnapshotBlock, buf)
+
+	return records, offsets, nil
+}
+
+// Overwrite replaces the records from the location with buf, the records must have been flushed
+func (bDB *BlockDB) Overwrite(location *chain_file_manager.Location, buf []byte) error {
+	return bDB.fm.Overwrite(location, buf)
+}
+
+// Corruption wraps err as a CorruptionError of the record at the location
+func (bDB *BlockDB) Corruption(location *chain_file_manager.Location, err error) error {
+	if _, ok := err.(*CorruptionError); ok {
+		return err
+	}
+	return bDB.corruption(location, err)
+}
+
 func (bDB *BlockDB) Read(location *chain_file_manager.Location) ([]byte, error) {
 	buf, _, err := bDB.fm.Read(location)
 	if err != nil {
@@ -118,7 +194,7 @@ func (bDB *BlockDB) Read(location *chain_file_manager.Location) ([]byte, error)
 		return nil, nil
 	}
 
-	sBuf, err := snappy.Decode(nil, buf[1:])
+	_, sBuf, err := bDB.decodeRecord(location, buf)
 	if err != nil {
 		return nil, err
 	}
@@ -137,7 +213,7 @@ func (bDB *BlockDB) ReadUnitBytes(location *chain_file_manager.Location) ([]byte
 	if len(buf) <= 0 {
 		return nil, nextLocation, nil
 	}
-	sBuf, err := snappy.Decode(nil, buf[1:])
+	_, sBuf, err := bDB.decodeRecord(location, buf)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -152,21 +228,21 @@ func (bDB *BlockDB) ReadUnit(location *chain_file_manager.Location) (*ledger.Sna
 	if len(buf) <= 0 {
 		return nil, nil, nextLocation, nil
 	}
-	sBuf, err := snappy.Decode(nil, buf[1:])
+	blockType, sBuf, err := bDB.decodeRecord(location, buf)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
-	if buf[0] == BlockTypeSnapshotBlock {
+	if blockType == BlockTypeSnapshotBlock {
 		sb := &ledger.SnapshotBlock{}
 		if err := sb.Deserialize(sBuf); err != nil {
-			return nil, nil, nil, err
+			return nil, nil, nil, bDB.corruption(location, err)
 		}
 		return sb, nil, nextLocation, nil
-	} else if buf[0] == BlockTypeAccountBlock {
+	} else if blockType == BlockTypeAccountBlock {
 		ab := &ledger.AccountBlock{}
 		if err := ab.Deserialize(sBuf); err != nil {
-			return nil, nil, nil, err
+			return nil, nil, nil, bDB.corruption(location, err)
 		}
 		return nil, ab, nextLocation, nil
 	}
@@ -230,7 +306,6 @@ func (bDB *BlockDB) ReadRange(startLocation *chain_file_manager.Location, endLoc
 	var segList []*ledger.SnapshotChunk
 	var seg *ledger.SnapshotChunk
 
-	var snappyReadBuffer = make([]byte, 0, 8*1024) // 8kb
 	iterator := bfp.Iterator()
 
 	for buf := range iterator {
@@ -238,12 +313,12 @@ func (bDB *BlockDB) ReadRange(startLocation *chain_file_manager.Location, endLoc
 			seg = &ledger.SnapshotChunk{}
 		}
 
-		sBuf, err := snappy.Decode(snappyReadBuffer, buf.Buffer)
+		blockType, sBuf, err := decodeRecordData(buf.BlockType, buf.Buffer)
 		if err != nil {
 			return nil, err
 		}
 
-		if buf.BlockType == BlockTypeSnapshotBlock {
+		if blockType == BlockTypeSnapshotBlock {
 
 			sb := &ledger.SnapshotBlock{}
 			if err := sb.Deserialize(sBuf); err != nil {
@@ -252,7 +327,7 @@ func (bDB *BlockDB) ReadRange(startLocation *chain_file_manager.Location, endLoc
 			seg.SnapshotBlock = sb
 			segList = append(segList, seg)
 			seg = nil
-		} else if buf.BlockType == BlockTypeAccountBlock {
+		} else if blockType == BlockTypeAccountBlock {
 			ab := &ledger.AccountBlock{}
 			if err := ab.Deserialize(sBuf); err != nil {
 				return nil, err
@@ -295,7 +370,6 @@ func (bDB *BlockDB) PrepareRollback(location *chain_file_manager.Location) ([]*l
 
 	var segList []*ledger.SnapshotChunk
 	var seg *ledger.SnapshotChunk
-	var snappyReadBuffer = make([]byte, 0, 4*1024) // 4KB
 
 	iterator := bfp.Iterator()
 
@@ -304,12 +378,12 @@ func (bDB *BlockDB) PrepareRollback(location *chain_file_manager.Location) ([]*l
 			seg = &ledger.SnapshotChunk{}
 		}
 
-		sBuf, err := snappy.Decode(snappyReadBuffer, buf.Buffer)
+		blockType, sBuf, err := decodeRecordData(buf.BlockType, buf.Buffer)
 		if err != nil {
 			return nil, err
 		}
 
-		if buf.BlockType == BlockTypeSnapshotBlock {
+		if blockType == BlockTypeSnapshotBlock {
 
 			sb := &ledger.SnapshotBlock{}
 			if err := sb.Deserialize(sBuf); err != nil {
@@ -318,7 +392,7 @@ func (bDB *BlockDB) PrepareRollback(location *chain_file_manager.Location) ([]*l
 			seg.SnapshotBlock = sb
 			segList = append(segList, seg)
 			seg = nil
-		} else if buf.BlockType == BlockTypeAccountBlock {
+		} else if blockType == BlockTypeAccountBlock {
 
 			ab := &ledger.AccountBlock{}
 			if err := ab.Deserialize(sBuf); err != nil {
@@ -353,6 +427,23 @@ func (bDB *BlockDB) GetStatus() []interfaces.DBStatus {
 	return bDB.fm.GetCacheStatusList()
 }
 
+// decodeRecord returns the block type and the decompressed data of the record at the location
+func (bDB *BlockDB) decodeRecord(location *chain_file_manager.Location, buf []byte) (byte, []byte, error) {
+	blockType, sBuf, err := decodeRecordData(buf[0], buf[1:])
+	if err != nil {
+		return BlockTypeUnknown, nil, bDB.corruption(location, err)
+	}
+	return blockType, sBuf, nil
+}
+
+func (bDB *BlockDB) corruption(location *chain_file_manager.Location, err error) *CorruptionError {
+	return &CorruptionError{
+		File:     bDB.fm.FileName(location.FileId),
+		Location: *location,
+		Err:      err,
+	}
+}
+
 func (bDB *BlockDB) maxLocation(location *chain_file_manager.Location) *chain_file_manager.Location {
 	latestLocation := bDB.fm.LatestLocation()
 
@@ -2,6 +2,8 @@ package chain_block
 
 import (
 	"encoding/binary"
+	"fmt"
+	"hash/crc32"
 
 	"github.com/pkg/errors"
 )
@@ -10,10 +12,56 @@ const (
 	BlockTypeUnknown       = byte(0)
 	BlockTypeAccountBlock  = byte(1)
 	BlockTypeSnapshotBlock = byte(2)
+
+	// BlockTypeChecksum is set in the type of the records of version 2, which have
+	// a 4 bytes crc32 of the compressed data after the type. Records of version 1
+	// have no checksum, they are still readable.
+	BlockTypeChecksum = byte(0x80)
+
+	// BlockTypeZstd is set in the type of the records whose data is compressed by zstd
+	// instead of snappy, it is only set in the records of version 2.
+	BlockTypeZstd = byte(0x40)
 )
 
 var ClosedErr = errors.New("blockFileParser is closed")
 
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// parseRecord returns the block type, the compression and the compressed data of a record,
+// the checksum is verified if the record has one
+func parseRecord(code byte, payload []byte) (byte, Compression, []byte, error) {
+	if code&BlockTypeChecksum == 0 {
+		return code, CompressionSnappy, payload, nil
+	}
+
+	if len(payload) < 4 {
+		return BlockTypeUnknown, CompressionSnappy, nil, fmt.Errorf("record of %d bytes is too short", len(payload))
+	}
+	if crc32.Checksum(payload[4:], crcTable) != binary.BigEndian.Uint32(payload) {
+		return BlockTypeUnknown, CompressionSnappy, nil, ErrChecksumMismatch
+	}
+
+	compression := CompressionSnappy
+	if code&BlockTypeZstd != 0 {
+		compression = CompressionZstd
+	}
+	return code &^ (BlockTypeChecksum | BlockTypeZstd), compression, payload[4:], nil
+}
+
+// decodeRecordData verifies and decompresses the payload of a record
+func decodeRecordData(code byte, payload []byte) (byte, []byte, error) {
+	blockType, compression, data, err := parseRecord(code, payload)
+	if err != nil {
+		return BlockTypeUnknown, nil, err
+	}
+
+	buf, err := decompress(compression, data)
+	if err != nil {
+		return BlockTypeUnknown, nil, err
+	}
+	return blockType, buf, nil
+}
+
 type byteBuffer struct {
 	BlockType byte
 	Buffer    []byte
@@ -2,11 +2,34 @@ package chain_block
 
 import (
 	"encoding/binary"
+	"hash/crc32"
 
 	"github.com/golang/snappy"
 )
 
-func makeWriteBytes(buf []byte, dataType byte, data []byte) []byte {
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// makeWriteBytes encodes the data as a record of version 2, the payload is the type with BlockTypeChecksum,
+// the crc of the compressed data and the compressed data.
+func makeWriteBytes(buf []byte, compression Compression, dataType byte, data []byte) []byte {
+
+	buf[4] = dataType | BlockTypeChecksum
+	if compression == CompressionZstd {
+		buf[4] |= BlockTypeZstd
+	}
+	buf = compress(compression, buf[:9], data)
+	sBuf := buf[9:]
+	sBufLen := len(sBuf)
+
+	binary.BigEndian.PutUint32(buf[5:], crc32.Checksum(sBuf, crcTable))
+	binary.BigEndian.PutUint32(buf, uint32(sBufLen+5))
+
+	return buf
+}
+
+// makeLegacyWriteBytes encodes the data as a record of version 1, the payload is the type and the compressed data.
+// Records of version 1 are the format of the chunks sent to peers.
+func makeLegacyWriteBytes(buf []byte, dataType byte, data []byte) []byte {
 
 	buf[4] = dataType
 	sBuf := snappy.Encode(buf[5:], data)
@@ -0,0 +1,76 @@
+package chain_block
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression is the codec of the block data in the records, it is recorded in the type of every record,
+// so records of different codecs can be read from the same file.
+type Compression byte
+
+const (
+	CompressionSnappy Compression = iota
+	CompressionZstd
+)
+
+func (c Compression) String() string {
+	switch c {
+	case CompressionSnappy:
+		return "snappy"
+	case CompressionZstd:
+		return "zstd"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(c))
+	}
+}
+
+// ParseCompression parses the name of the compression, empty name means snappy
+func ParseCompression(name string) (Compression, error) {
+	switch strings.ToLower(name) {
+	case "", "snappy":
+		return CompressionSnappy, nil
+	case "zstd":
+		return CompressionZstd, nil
+	default:
+		return CompressionSnappy, fmt.Errorf("unknown block compression %s, should be snappy or zstd", name)
+	}
+}
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+)
+
+// the encoder and the decoder are safe for concurrent EncodeAll and DecodeAll
+func initZstd() {
+	zstdOnce.Do(func() {
+		zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBetterCompression), zstd.WithEncoderConcurrency(1))
+		zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+	})
+}
+
+// compress appends the compressed data to dst
+func compress(compression Compression, dst, data []byte) []byte {
+	if compression == CompressionZstd {
+		initZstd()
+		return zstdEncoder.EncodeAll(data, dst)
+	}
+
+	sBuf := snappy.Encode(dst[len(dst):cap(dst)], data)
+	return append(dst, sBuf...)
+}
+
+// decompress decodes the compressed data of a record into a new buffer
+func decompress(compression Compression, data []byte) ([]byte, error) {
+	if compression == CompressionZstd {
+		initZstd()
+		return zstdDecoder.DecodeAll(data, nil)
+	}
+	return snappy.Decode(nil, data)
+}
@@ -134,3 +134,12 @@ func (bDB *BlockDB) PatchRedoLog(redoLog []byte) error {
 
 	return bDB.fm.Flush(flushStartLocation, flushTargetLocation, redoLog[24:])
 }
+
+// FlushDirectly writes the records in the cache into the files, it is only used when the BlockDB is not
+// flushed by a flusher, such as rewriting the block files.
+func (bDB *BlockDB) FlushDirectly() error {
+	bDB.Prepare()
+	defer bDB.AfterCommit()
+
+	return bDB.Commit()
+}
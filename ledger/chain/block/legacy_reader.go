@@ -0,0 +1,134 @@
+package chain_block
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/golang/snappy"
+
+	chain_file_manager "github.com/vitelabs/go-vite/v2/ledger/chain/file_manager"
+)
+
+const legacyReadSize = 64 * 1024
+
+// LegacyReader reads the records between two locations in the format of version 1, which is the format
+// of the chunks sent to peers. The checksums of the records of version 2 are verified and removed,
+// and the data compressed by zstd is compressed by snappy again.
+type LegacyReader struct {
+	bDB *BlockDB
+
+	current *chain_file_manager.Location
+	to      *chain_file_manager.Location
+
+	// location of raw[0]
+	rawLocation *chain_file_manager.Location
+	raw         []byte
+
+	out bytes.Buffer
+}
+
+func (bDB *BlockDB) NewLegacyReader(from, to *chain_file_manager.Location) *LegacyReader {
+	return &LegacyReader{
+		bDB:         bDB,
+		current:     from,
+		to:          to,
+		rawLocation: from,
+	}
+}
+
+// LegacySize returns the size of the records between two locations in the format of version 1
+func (bDB *BlockDB) LegacySize(from, to *chain_file_manager.Location) (int64, error) {
+	buf := make([]byte, legacyReadSize)
+
+	r := bDB.NewLegacyReader(from, to)
+	var size int64
+	for {
+		n, err := r.Read(buf)
+		size += int64(n)
+		if err == io.EOF {
+			return size, nil
+		}
+		if err != nil {
+			return size, err
+		}
+	}
+}
+
+func (r *LegacyReader) Read(p []byte) (int, error) {
+	for r.out.Len() == 0 {
+		if err := r.fill(); err != nil {
+			return 0, err
+		}
+	}
+	return r.out.Read(p)
+}
+
+func (r *LegacyReader) fill() error {
+	consumed := 0
+	for len(r.raw)-consumed >= 4 {
+		size := int(binary.BigEndian.Uint32(r.raw[consumed:]))
+		if size == 0 {
+			return r.bDB.corruption(r.rawLocation, fmt.Errorf("record of 0 bytes"))
+		}
+		if len(r.raw)-consumed < 4+size {
+			break
+		}
+
+		record := r.raw[consumed+4 : consumed+4+size]
+		blockType, compression, data, err := parseRecord(record[0], record[1:])
+		if err != nil {
+			return r.bDB.corruption(r.rawLocation, err)
+		}
+		if compression != CompressionSnappy {
+			buf, err := decompress(compression, data)
+			if err != nil {
+				return r.bDB.corruption(r.rawLocation, err)
+			}
+			data = snappy.Encode(nil, buf)
+		}
+
+		var head [5]byte
+		binary.BigEndian.PutUint32(head[:], uint32(len(data)+1))
+		head[4] = blockType
+		r.out.Write(head[:])
+		r.out.Write(data)
+
+		consumed += 4 + size
+		r.rawLocation = r.bDB.advance(r.rawLocation, int64(4+size))
+	}
+	r.raw = append(r.raw[:0], r.raw[consumed:]...)
+
+	if r.out.Len() > 0 {
+		return nil
+	}
+
+	readN := r.current.Distance(r.bDB.fileSize, r.to)
+	if readN <= 0 {
+		if len(r.raw) > 0 {
+			return r.bDB.corruption(r.rawLocation, fmt.Errorf("incomplete record of %d bytes", len(r.raw)))
+		}
+		return io.EOF
+	}
+	if readN > legacyReadSize {
+		readN = legacyReadSize
+	}
+
+	buf := make([]byte, readN)
+	next, n, err := r.bDB.ReadRaw(r.current, buf)
+	r.raw = append(r.raw, buf[:n]...)
+	r.current = next
+	if err != nil && (err != io.EOF || n == 0) {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return nil
+}
+
+func (bDB *BlockDB) advance(location *chain_file_manager.Location, n int64) *chain_file_manager.Location {
+	offset := location.Offset + n
+	return chain_file_manager.NewLocation(location.FileId+uint64(offset/bDB.fileSize), offset%bDB.fileSize)
+}
@@ -0,0 +1,156 @@
+package chain_block
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_file_manager "github.com/vitelabs/go-vite/v2/ledger/chain/file_manager"
+)
+
+func mockRecordChunk(height uint64) *ledger.SnapshotChunk {
+	now := time.Unix(1600000000, 0)
+	chunk := &ledger.SnapshotChunk{
+		SnapshotBlock: &ledger.SnapshotBlock{
+			Height:    height,
+			Hash:      types.Hash{byte(height)},
+			Timestamp: &now,
+		},
+	}
+	for i := uint64(1); i <= 3; i++ {
+		chunk.AccountBlocks = append(chunk.AccountBlocks, &ledger.AccountBlock{
+			BlockType:      ledger.BlockTypeSendCall,
+			Height:         i,
+			Hash:           types.Hash{byte(height), byte(i)},
+			AccountAddress: types.AddressGovernance,
+			ToAddress:      types.AddressGovernance,
+			Amount:         big.NewInt(int64(i)),
+			Fee:            big.NewInt(0),
+		})
+	}
+	return chunk
+}
+
+func TestParseRecord(t *testing.T) {
+	chunk := mockRecordChunk(2)
+
+	records, offsets, err := EncodeChunk(chunk, true, CompressionSnappy)
+	assert.NoError(t, err)
+	assert.Equal(t, len(chunk.AccountBlocks)+1, len(offsets))
+
+	last := records[offsets[len(offsets)-1]:]
+	blockType, _, data, err := parseRecord(last[4], last[5:])
+	assert.NoError(t, err)
+	assert.Equal(t, BlockTypeSnapshotBlock, blockType)
+	assert.NotEmpty(t, data)
+
+	// flip a bit of the compressed data
+	damaged := append([]byte{}, last...)
+	damaged[len(damaged)-1] ^= 0x01
+	_, _, _, err = parseRecord(damaged[4], damaged[5:])
+	assert.Equal(t, ErrChecksumMismatch, err)
+
+	// records of version 1 have no checksum, the compressed data is the same
+	legacy, legacyOffsets, err := EncodeChunk(chunk, false, CompressionSnappy)
+	assert.NoError(t, err)
+	legacyFirst := legacy[:legacyOffsets[1]]
+	blockType, _, legacyData, err := parseRecord(legacyFirst[4], legacyFirst[5:])
+	assert.NoError(t, err)
+	assert.Equal(t, BlockTypeAccountBlock, blockType)
+
+	first := records[:offsets[1]]
+	blockType, _, data, err = parseRecord(first[4], first[5:])
+	assert.NoError(t, err)
+	assert.Equal(t, BlockTypeAccountBlock, blockType)
+	assert.Equal(t, legacyData, data)
+}
+
+func TestZstdRecord(t *testing.T) {
+	chunk := mockRecordChunk(2)
+
+	records, offsets, err := EncodeChunk(chunk, true, CompressionZstd)
+	assert.NoError(t, err)
+
+	last := records[offsets[len(offsets)-1]:]
+	assert.NotZero(t, last[4]&BlockTypeZstd)
+
+	blockType, compression, _, err := parseRecord(last[4], last[5:])
+	assert.NoError(t, err)
+	assert.Equal(t, BlockTypeSnapshotBlock, blockType)
+	assert.Equal(t, CompressionZstd, compression)
+
+	blockType, data, err := decodeRecordData(last[4], last[5:])
+	assert.NoError(t, err)
+	assert.Equal(t, BlockTypeSnapshotBlock, blockType)
+	expected, err := chunk.SnapshotBlock.Serialize()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, data)
+
+	_, err = ParseCompression("lz4")
+	assert.Error(t, err)
+	compression, err = ParseCompression("ZSTD")
+	assert.NoError(t, err)
+	assert.Equal(t, CompressionZstd, compression)
+}
+
+func TestLegacyReader(t *testing.T) {
+	for _, compression := range []Compression{CompressionSnappy, CompressionZstd} {
+		t.Run(compression.String(), func(t *testing.T) {
+			testLegacyReader(t, compression)
+		})
+	}
+}
+
+func testLegacyReader(t *testing.T, compression Compression) {
+	dir, err := ioutil.TempDir("", "block_db")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	db, err := NewBlockDBFixedSize(dir, 1024)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	var expected []byte
+	var sbLocations []*chain_file_manager.Location
+	for height := uint64(2); height <= 10; height++ {
+		// the earlier records are compressed by snappy
+		if height == 6 {
+			db.SetCompression(compression)
+		}
+		chunk := mockRecordChunk(height)
+
+		_, sbLocation, err := db.Write(chunk)
+		assert.NoError(t, err)
+		sbLocations = append(sbLocations, sbLocation)
+
+		legacy, _, err := EncodeChunk(chunk, false, CompressionSnappy)
+		assert.NoError(t, err)
+		expected = append(expected, legacy...)
+
+		sb, _, _, err := db.ReadUnit(sbLocation)
+		assert.NoError(t, err)
+		assert.Equal(t, chunk.SnapshotBlock.Hash, sb.Hash)
+	}
+
+	from := chain_file_manager.NewLocation(1, 0)
+	to, err := db.GetNextLocation(sbLocations[len(sbLocations)-1])
+	assert.NoError(t, err)
+
+	size, err := db.LegacySize(from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(expected)), size)
+
+	data, err := ioutil.ReadAll(db.NewLegacyReader(from, to))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, data)
+
+	chunks, err := db.ReadRange(from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, 9, len(chunks))
+}
@@ -17,6 +17,7 @@ import (
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
 	chain_block "github.com/vitelabs/go-vite/v2/ledger/chain/block"
 	chain_cache "github.com/vitelabs/go-vite/v2/ledger/chain/cache"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
 	chain_flusher "github.com/vitelabs/go-vite/v2/ledger/chain/flusher"
 	chain_genesis "github.com/vitelabs/go-vite/v2/ledger/chain/genesis"
 	chain_index "github.com/vitelabs/go-vite/v2/ledger/chain/index"
@@ -31,6 +32,9 @@ const (
 	start = 1
 )
 
+// the chain dir of the memory backend, cleared on every start. The ledger dir of the disk backends is left untouched.
+const memoryChainDirName = "ledger_memory"
+
 type chain struct {
 	genesisCfg *config.Genesis
 	chainCfg   *config.Chain
@@ -66,6 +70,9 @@ type chain struct {
 
 	plugins *chain_plugins.Plugins
 
+	scrubber     *blockScrubber
+	chunkFetcher ChunkFetcher
+
 	status uint32
 }
 
@@ -110,6 +117,16 @@ func NewChain(dir string, chainCfg *config.Chain, genesisCfg *config.Genesis) *c
 func (c *chain) Init() error {
 	c.log.Info("Begin initializing", "method", "Init")
 
+	// the dbs of the memory backend are lost on restart, the block files and the other dbs are thrown away
+	// with them, or the next start finds the blocks without their indexes
+	if c.chainCfg.Backend == chain_db.BackendMemory {
+		c.chainDir = path.Join(c.dataDir, memoryChainDirName)
+		c.log.Info("clear the chain dir of the memory backend", "dir", c.chainDir, "method", "Init")
+		if err := os.RemoveAll(c.chainDir); err != nil {
+			return err
+		}
+	}
+
 	// init db
 	if err := c.newDbAndRecover(); err != nil {
 		return err
@@ -137,6 +154,11 @@ func (c *chain) Init() error {
 		return err
 	}
 
+	// check the upgrade schedule the ledger was built with
+	if err := c.checkUpgradeSchedule(); err != nil {
+		return err
+	}
+
 	// reconstruct the plugins
 	/*	if c.chainCfg.OpenPlugins {
 			c.plugins.BuildPluginsDb(c.flusher)
@@ -155,6 +177,22 @@ func (c *chain) Start() error {
 	c.flusher.Start()
 	c.log.Info("Start flusher", "method", "Start")
 
+	if pruner := c.stateDB.Pruner(); pruner != nil {
+		pruner.Start()
+		c.log.Info("Start state pruner", "method", "Start")
+	}
+
+	if c.plugins != nil {
+		c.plugins.Start()
+		c.log.Info("Start plugins", "method", "Start")
+	}
+
+	if c.chainCfg.BlockScrub {
+		c.scrubber = newBlockScrubber(c, c.chainCfg.BlockRepair)
+		c.scrubber.Start()
+		c.log.Info("Start block scrubber", "method", "Start")
+	}
+
 	return nil
 }
 
@@ -163,6 +201,22 @@ func (c *chain) Stop() error {
 		return nil
 	}
 
+	if c.scrubber != nil {
+		c.scrubber.Stop()
+		c.scrubber = nil
+		c.log.Info("Stop block scrubber", "method", "Stop")
+	}
+
+	if c.plugins != nil {
+		c.plugins.Stop()
+		c.log.Info("Stop plugins", "method", "Stop")
+	}
+
+	if pruner := c.stateDB.Pruner(); pruner != nil {
+		pruner.Stop()
+		c.log.Info("Stop state pruner", "method", "Stop")
+	}
+
 	c.flusher.Stop()
 
 	c.log.Info("Stop flusher", "method", "Stop")
@@ -204,12 +258,20 @@ func (c *chain) Destroy() error {
 	}
 	c.log.Info("Close syncCache", "method", "Close")
 
+	if err := c.metaDB.Close(); err != nil {
+		cErr := fmt.Errorf("c.metaDB.Close failed, error is %s", err)
+		c.log.Error(cErr.Error(), "method", "Close")
+		return cErr
+	}
+	c.log.Info("Close metaDB", "method", "Close")
+
 	c.flusher = nil
 	c.cache = nil
 	c.stateDB = nil
 	c.indexDB = nil
 	c.blockDB = nil
 	c.syncCache = nil
+	c.metaDB = nil
 
 	c.log.Info("Complete destruction", "method", "Close")
 
@@ -230,6 +292,15 @@ func (c *chain) NewDb(dirName string) (*leveldb.DB, error) {
 	return db, nil
 }
 
+// NewBackend opens the backend of the dir in the chain dir, the kind of the backend is the one of the chain config
+func (c *chain) NewBackend(dirName string) (chain_db.Backend, error) {
+	return chain_db.OpenBackend(c.chainCfg.Backend, path.Join(c.chainDir, dirName))
+}
+
+func (c *chain) openStore(dirName string, name string) (*chain_db.Store, error) {
+	return chain_db.OpenStore(c.chainCfg.Backend, path.Join(c.chainDir, dirName), name)
+}
+
 func (c *chain) PrepareOnroadDb() (*leveldb.DB, error) {
 	dirName := "onroad"
 	absoluteDirName := path.Join(c.chainDir, dirName)
@@ -262,19 +333,34 @@ func (c *chain) newDbAndRecover() error {
 	}
 
 	// new ledger db
-	if c.indexDB, err = chain_index.NewIndexDB(c.chainDir); err != nil {
+	indexStore, err := c.openStore("index", "indexDb")
+	if err != nil {
+		c.log.Error(fmt.Sprintf("open index store failed, error is %s, chainDir is %s", err, c.chainDir), "method", "newDbAndRecover")
+		return err
+	}
+	if c.indexDB, err = chain_index.NewIndexDBWithStore(indexStore); err != nil {
 		c.log.Error(fmt.Sprintf("chain_index.NewIndexDB failed, error is %s, chainDir is %s", err, c.chainDir), "method", "newDbAndRecover")
 		return err
 	}
 
 	// new block db
-	if c.blockDB, err = chain_block.NewBlockDB(c.chainDir); err != nil {
+	if err = c.openBlockDB(); err != nil {
 		c.log.Error(fmt.Sprintf("chain_block.NewBlockDB failed, error is %s, chainDir is %s", err, c.chainDir), "method", "newDbAndRecover")
 		return err
 	}
 
 	// new state db
-	if c.stateDB, err = chain_state.NewStateDB(c, c.chainCfg, c.chainDir); err != nil {
+	stateStore, err := c.openStore("state", "stateDb")
+	if err != nil {
+		c.log.Error(fmt.Sprintf("open state store failed, error is %s, chainDir is %s", err, c.chainDir), "method", "newDbAndRecover")
+		return err
+	}
+	stateRedoStore, err := c.openStore("state_redo", "stateDbRedo")
+	if err != nil {
+		c.log.Error(fmt.Sprintf("open state redo store failed, error is %s, chainDir is %s", err, c.chainDir), "method", "newDbAndRecover")
+		return err
+	}
+	if c.stateDB, err = chain_state.NewStateDBWithStore(c, c.chainCfg, stateStore, stateRedoStore); err != nil {
 		cErr := fmt.Errorf("chain_cache.NewStateDB failed, error is %s", err)
 
 		c.log.Error(cErr.Error(), "method", "newDbAndRecover")
@@ -283,13 +369,22 @@ func (c *chain) newDbAndRecover() error {
 
 	// init plugins
 	if c.chainCfg.OpenPlugins {
-		var err error
-		if c.plugins, err = chain_plugins.NewPlugins(c.chainDir, c); err != nil {
+		pluginsStore, err := c.openStore("plugins", "plugins")
+		if err != nil {
+			c.log.Error(fmt.Sprintf("open plugins store failed, error is %s, chainDir is %s", err, c.chainDir), "method", "newDbAndRecover")
+			return err
+		}
+		if c.plugins, err = chain_plugins.NewPluginsWithStore(path.Join(c.chainDir, "plugins"), pluginsStore, c); err != nil {
 			cErr := fmt.Errorf("chain_plugins.NewPlugins failed. Error: %s", err)
 			c.log.Error(cErr.Error(), "method", "newDbAndRecover")
 			return cErr
 		}
 		c.Register(c.plugins)
+
+		if !c.canWriteVmLog(types.AddressDexTrade) {
+			c.log.Warn(fmt.Sprintf("the vm logs of %s are not saved, the dexTrade plugin indexes no trades, "+
+				"add it to VmLogWhiteList or enable VmLogAll", types.AddressDexTrade), "method", "newDbAndRecover")
+		}
 	}
 
 	// new flusher
@@ -310,6 +405,13 @@ func (c *chain) newDbAndRecover() error {
 		return cErr
 	}
 
+	// complete the rewriting of the block files if the node was stopped while rewriting
+	if err := c.finishBlockMigration(); err != nil {
+		cErr := fmt.Errorf("c.finishBlockMigration failed. Error: %s", err)
+		c.log.Error(cErr.Error(), "method", "newDbAndRecover")
+		return cErr
+	}
+
 	// new cache
 	if c.cache, err = chain_cache.NewCache(c); err != nil {
 		cErr := fmt.Errorf("chain_cache.NewCache failed, error is %s", err)
@@ -379,6 +481,35 @@ func (c *chain) checkForkPoints() error {
 	return nil
 }
 
+func (c *chain) checkUpgradeSchedule() error {
+	active := upgrade.GetAllPoints()
+	if err := upgrade.CheckUpgradePoints(active); err != nil {
+		return fmt.Errorf("invalid upgrade schedule, %s", err)
+	}
+
+	stored, err := c.QueryUpgradePoints()
+	if err != nil {
+		cErr := fmt.Errorf("c.QueryUpgradePoints failed. Error: %s", err)
+		c.log.Error(cErr.Error(), "method", "checkUpgradeSchedule")
+		return cErr
+	}
+
+	if stored != nil {
+		latest := c.GetLatestSnapshotBlock()
+		if err := upgrade.CheckUpgradeSchedule(stored, active, latest.Height); err != nil {
+			return fmt.Errorf("The upgrade schedule is different from the one the ledger was built with, %s. "+
+				"The directory of database is %s.", err, c.chainDir)
+		}
+	}
+
+	if err := c.WriteUpgradePoints(active); err != nil {
+		cErr := fmt.Errorf("c.WriteUpgradePoints failed. Error: %s", err)
+		c.log.Error(cErr.Error(), "method", "checkUpgradeSchedule")
+		return cErr
+	}
+	return nil
+}
+
 func (c *chain) initCache() error {
 
 	// init cache
@@ -472,6 +603,19 @@ func (c *chain) cleanAllData() error {
 	return os.RemoveAll(c.chainDir)
 }
 
+// canWriteVmLog checks whether the vm logs of addr are saved by VmLogAll or VmLogWhiteList
+func (c *chain) canWriteVmLog(addr types.Address) bool {
+	if c.chainCfg.VmLogAll {
+		return true
+	}
+	for _, whiteAddr := range c.chainCfg.VmLogWhiteList {
+		if whiteAddr == addr {
+			return true
+		}
+	}
+	return false
+}
+
 func defaultConfig() *config.Chain {
 	return &config.Chain{
 		LedgerGc:       true,
@@ -13,10 +13,12 @@ import (
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
 	"github.com/vitelabs/go-vite/v2/common/config"
 	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/common/upgrade"
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
 	"github.com/vitelabs/go-vite/v2/ledger/chain/test_tools"
 	"github.com/vitelabs/go-vite/v2/vm/quota"
 )
@@ -160,7 +162,15 @@ var GenesisJson = `{
 }
 `
 
+// NewChainInstance opens a new chain on the memory backend if clear, or the chain in the dir on leveldb
 func NewChainInstance(t gomock.TestReporter, dirName string, clear bool) (*chain, error) {
+	if clear {
+		return newChainInstance(t, dirName, true, chain_db.BackendMemory)
+	}
+	return newChainInstance(t, dirName, false, chain_db.BackendLevelDB)
+}
+
+func newChainInstance(t gomock.TestReporter, dirName string, clear bool, backend string) (*chain, error) {
 	var dataDir string
 
 	if path.IsAbs(dirName) {
@@ -178,6 +188,7 @@ func NewChainInstance(t gomock.TestReporter, dirName string, clear bool) (*chain
 
 	chainCfg := &config.Chain{
 		VmLogAll: true,
+		Backend:  backend,
 	}
 	chainInstance := NewChain(dataDir, chainCfg, genesisConfig)
 
@@ -201,6 +212,10 @@ func Clear(c *chain) error {
 }
 
 func SetUp(t *testing.T, accountNum, txCount, snapshotPerBlockNum int) (*chain, map[types.Address]*Account, []*ledger.SnapshotBlock) {
+	return setUpWithBackend(t, chain_db.BackendMemory, accountNum, txCount, snapshotPerBlockNum)
+}
+
+func setUpWithBackend(t *testing.T, backend string, accountNum, txCount, snapshotPerBlockNum int) (*chain, map[types.Address]*Account, []*ledger.SnapshotBlock) {
 	// set fork point
 	upgrade.CleanupUpgradeBox()
 	upgrade.InitUpgradeBox(upgrade.NewEmptyUpgradeBox().AddPoint(1, 10000000))
@@ -208,7 +223,7 @@ func SetUp(t *testing.T, accountNum, txCount, snapshotPerBlockNum int) (*chain,
 	// test quota
 	quota.InitQuotaConfig(true, true)
 
-	chainInstance, err := NewChainInstance(t, t.Name(), true)
+	chainInstance, err := newChainInstance(t, t.Name(), true, backend)
 	if err != nil {
 		panic(err)
 	}
@@ -320,6 +335,22 @@ func TestCheckHash2(t *testing.T) {
 	fmt.Printf("%+v\n", block)
 }
 
+func TestChain_MemoryBackend(t *testing.T) {
+	chainInstance, _, _ := SetUp(t, 2, 10, 2)
+	dataDir := chainInstance.dataDir
+	assert.Equal(t, path.Join(dataDir, memoryChainDirName), chainInstance.chainDir)
+	assert.True(t, chainInstance.GetLatestSnapshotBlock().Height > 1)
+	TearDown(chainInstance)
+
+	assert.False(t, pathExists(path.Join(dataDir, "ledger")))
+
+	// the blocks are thrown away with the dbs on restart, the ledger starts from the genesis again
+	chainInstance = NewChain(dataDir, &config.Chain{Backend: chain_db.BackendMemory}, chainInstance.genesisCfg)
+	assert.NoError(t, chainInstance.Init())
+	defer chainInstance.Destroy()
+	assert.Equal(t, uint64(1), chainInstance.GetLatestSnapshotBlock().Height)
+}
+
 /**
   fork  rollback only for one forkpoint
 */
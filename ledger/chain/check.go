@@ -218,6 +218,8 @@ func (c *chain) CheckHash() error {
 	store := c.indexDB.Store()
 	iter := store.NewIterator(util.BytesPrefix([]byte{chain_utils.AccountBlockHashKeyPrefix}))
 	defer iter.Release()
+
+	mismatched := 0
 	for iter.Next() {
 		key := iter.Key()
 		hash, err := types.BytesToHash(key[1:])
@@ -236,6 +238,7 @@ func (c *chain) CheckHash() error {
 		if !(block.IsSendBlock() && block.Height == 0 && block.PrevHash.IsZero()) {
 			if block.Hash != block.ComputeHash() {
 				c.log.Error(fmt.Sprintf("error. block.Hash != block.ComputeHash(), block is %+v, computedHash is %s", block, block.ComputeHash()), "method", "CheckHash")
+				mismatched++
 				continue
 			}
 		}
@@ -245,6 +248,9 @@ func (c *chain) CheckHash() error {
 	if err := iter.Error(); err != nil {
 		return err
 	}
+	if mismatched > 0 {
+		return fmt.Errorf("%d account blocks have mismatched hashes", mismatched)
+	}
 
 	return nil
 }
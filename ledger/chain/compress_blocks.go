@@ -0,0 +1,218 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync/atomic"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_block "github.com/vitelabs/go-vite/v2/ledger/chain/block"
+	chain_file_manager "github.com/vitelabs/go-vite/v2/ledger/chain/file_manager"
+)
+
+const (
+	// the rewritten block files are written into blocksMigrateDir, blocksMigratedMark is created after
+	// all of them are written. Then the locations in the index db are updated, and blocksMigrateDir
+	// replaces the blocks directory.
+	blocksMigrateDir   = "blocks.migrate"
+	blocksOldDir       = "blocks.old"
+	blocksMigratedMark = "blocks.migrated"
+
+	// count of snapshot chunks whose locations are flushed together
+	reindexFlushChunks = 10000
+)
+
+// CompressBlocks rewrites all records of the block files with the compression, and updates the locations of
+// the blocks in the index db. The chain must be initialized but not started, it is used by the command
+// converting the block files of a stopped node.
+func (c *chain) CompressBlocks(compression chain_block.Compression) error {
+	if atomic.LoadUint32(&c.status) == start {
+		return errors.New("the block files can not be rewritten when the chain is running")
+	}
+
+	// all records are in the files
+	c.flusher.Flush()
+
+	migrateDir := path.Join(c.chainDir, blocksMigrateDir)
+	if err := os.RemoveAll(migrateDir); err != nil {
+		return err
+	}
+
+	newDB, err := chain_block.NewBlockDBInDir(migrateDir, c.blockDB.FileSize())
+	if err != nil {
+		return err
+	}
+	newDB.SetCompression(compression)
+
+	latestHeight := c.GetLatestSnapshotBlock().Height
+	if err := c.rewriteBlocks(newDB, latestHeight); err != nil {
+		newDB.Close()
+		return err
+	}
+	if err := newDB.Close(); err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(path.Join(c.chainDir, blocksMigratedMark), []byte(compression.String()), 0644); err != nil {
+		return err
+	}
+	c.log.Info(fmt.Sprintf("rewrite %d snapshot chunks into %s", latestHeight, migrateDir), "method", "CompressBlocks")
+
+	if err := c.finishBlockMigration(); err != nil {
+		return err
+	}
+	c.blockDB.SetCompression(compression)
+	return nil
+}
+
+// rewriteBlocks writes the snapshot chunks from 1 to latestHeight into newDB
+func (c *chain) rewriteBlocks(newDB *chain_block.BlockDB, latestHeight uint64) error {
+	location := chain_file_manager.NewLocation(1, 0)
+	flushedFileId := uint64(1)
+
+	for height := uint64(1); height <= latestHeight; height++ {
+		chunk, next, err := c.blockDB.ReadChunk(*location)
+		if err != nil {
+			return fmt.Errorf("failed to read snapshot chunk %d at %s. Error: %s", height, location, err)
+		}
+		if chunk.SnapshotBlock.Height != height {
+			return fmt.Errorf("snapshot block at %s is %d, not %d", location, chunk.SnapshotBlock.Height, height)
+		}
+
+		if _, _, err := newDB.Write(chunk); err != nil {
+			return err
+		}
+		location = next
+
+		// the unflushed records are kept in the file cache, flush them once a file is filled
+		if latest := newDB.LatestLocation(); latest.FileId > flushedFileId {
+			if err := newDB.FlushDirectly(); err != nil {
+				return err
+			}
+			flushedFileId = latest.FileId
+		}
+	}
+
+	return newDB.FlushDirectly()
+}
+
+// finishBlockMigration completes the rewriting of the block files after they are written into blocksMigrateDir.
+// It is called again when the chain is initialized if the node was stopped before it completed.
+func (c *chain) finishBlockMigration() error {
+	migrateDir := path.Join(c.chainDir, blocksMigrateDir)
+	oldDir := path.Join(c.chainDir, blocksOldDir)
+	blocksDir := path.Join(c.chainDir, chain_block.BlocksDirName)
+	markFile := path.Join(c.chainDir, blocksMigratedMark)
+
+	if !pathExists(markFile) {
+		// the block files were not completely rewritten, the blocks directory is not changed
+		return os.RemoveAll(migrateDir)
+	}
+
+	if pathExists(migrateDir) {
+		c.log.Info(fmt.Sprintf("update the block locations from %s", migrateDir), "method", "finishBlockMigration")
+
+		newDB, err := chain_block.NewBlockDBInDir(migrateDir, c.blockDB.FileSize())
+		if err != nil {
+			return err
+		}
+		err = c.reindexBlockLocations(newDB)
+		newDB.Close()
+		if err != nil {
+			return err
+		}
+
+		if err := c.blockDB.Close(); err != nil {
+			return err
+		}
+		if pathExists(blocksDir) {
+			if pathExists(oldDir) {
+				return fmt.Errorf("both %s and %s exist, remove one of them manually", blocksDir, oldDir)
+			}
+			if err := os.Rename(blocksDir, oldDir); err != nil {
+				return err
+			}
+		}
+		if err := os.Rename(migrateDir, blocksDir); err != nil {
+			return err
+		}
+
+		if err := c.openBlockDB(); err != nil {
+			return err
+		}
+		c.flusher.ReplaceStore(c.blockDB.Id(), c.blockDB)
+	}
+
+	if err := os.RemoveAll(oldDir); err != nil {
+		return err
+	}
+	if err := os.Remove(markFile); err != nil {
+		return err
+	}
+	c.log.Info("finish rewriting the block files", "method", "finishBlockMigration")
+	return nil
+}
+
+// reindexBlockLocations writes the locations of the blocks in bDB into the index db
+func (c *chain) reindexBlockLocations(bDB *chain_block.BlockDB) error {
+	latest := bDB.LatestLocation()
+	location := chain_file_manager.NewLocation(1, 0)
+
+	var accountBlocks []*ledger.AccountBlock
+	abLocations := make(map[types.Hash]*chain_file_manager.Location)
+
+	count := 0
+	for location.Compare(latest) < 0 {
+		sb, ab, next, err := bDB.ReadUnit(location)
+		if err != nil {
+			return err
+		}
+
+		if ab != nil {
+			accountBlocks = append(accountBlocks, ab)
+			abLocations[ab.Hash] = location
+		} else if sb != nil {
+			c.indexDB.UpdateBlockLocations(sb, accountBlocks, location, abLocations)
+
+			accountBlocks = nil
+			abLocations = make(map[types.Hash]*chain_file_manager.Location)
+
+			if count++; count%reindexFlushChunks == 0 {
+				c.flusher.Flush()
+			}
+		} else {
+			return bDB.Corruption(location, errors.New("record is not a block"))
+		}
+		location = next
+	}
+
+	if len(accountBlocks) > 0 {
+		return fmt.Errorf("%d account blocks are not confirmed by a snapshot block at the end of the block files", len(accountBlocks))
+	}
+
+	c.flusher.Flush()
+	return nil
+}
+
+// openBlockDB opens the block files in the chain directory with the compression of the config
+func (c *chain) openBlockDB() error {
+	compression, err := chain_block.ParseCompression(c.chainCfg.BlockCompression)
+	if err != nil {
+		return err
+	}
+
+	if c.blockDB, err = chain_block.NewBlockDB(c.chainDir); err != nil {
+		return err
+	}
+	c.blockDB.SetCompression(compression)
+	return nil
+}
+
+func pathExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
@@ -0,0 +1,69 @@
+package chain
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_block "github.com/vitelabs/go-vite/v2/ledger/chain/block"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
+)
+
+func TestCompressBlocks(t *testing.T) {
+	// the chain is reopened from the disk
+	chainInstance, _, _ := setUpWithBackend(t, chain_db.BackendLevelDB, 10, 100, 5)
+	chainInstance.flusher.Flush()
+
+	latestHeight := chainInstance.GetLatestSnapshotBlock().Height
+	expected, err := chainInstance.GetSubLedger(1, latestHeight)
+	assert.NoError(t, err)
+
+	// the block files are rewritten when the chain is stopped
+	assert.Error(t, chainInstance.CompressBlocks(chain_block.CompressionZstd))
+	chainInstance.Stop()
+
+	assert.NoError(t, chainInstance.CompressBlocks(chain_block.CompressionZstd))
+	checkCompressedBlocks(t, chainInstance, expected)
+
+	// the rewriting is interrupted after the files are written, it is completed when the chain is initialized
+	newDB, err := chain_block.NewBlockDBInDir(path.Join(chainInstance.chainDir, blocksMigrateDir), chainInstance.blockDB.FileSize())
+	assert.NoError(t, err)
+	assert.NoError(t, chainInstance.rewriteBlocks(newDB, latestHeight))
+	assert.NoError(t, newDB.Close())
+	assert.NoError(t, ioutil.WriteFile(path.Join(chainInstance.chainDir, blocksMigratedMark), nil, 0644))
+	assert.NoError(t, chainInstance.Destroy())
+
+	chainInstance, err = NewChainInstance(t, t.Name(), false)
+	assert.NoError(t, err)
+	defer TearDown(chainInstance)
+
+	checkCompressedBlocks(t, chainInstance, expected)
+}
+
+func checkCompressedBlocks(t *testing.T, chainInstance *chain, expected []*ledger.SnapshotChunk) {
+	for _, name := range []string{blocksMigrateDir, blocksOldDir, blocksMigratedMark} {
+		assert.False(t, pathExists(path.Join(chainInstance.chainDir, name)), name)
+	}
+
+	latestHeight := chainInstance.GetLatestSnapshotBlock().Height
+	damagedChunks, err := chainInstance.ScrubBlocks(1, latestHeight)
+	assert.NoError(t, err)
+	assert.Empty(t, damagedChunks)
+
+	chunks, err := chainInstance.GetSubLedger(1, latestHeight)
+	assert.NoError(t, err)
+	if !assert.Equal(t, len(expected), len(chunks)) {
+		return
+	}
+	for i, chunk := range chunks {
+		assert.Equal(t, expected[i].SnapshotBlock.Hash, chunk.SnapshotBlock.Hash)
+		if assert.Equal(t, len(expected[i].AccountBlocks), len(chunk.AccountBlocks)) {
+			for j, ab := range chunk.AccountBlocks {
+				assert.Equal(t, expected[i].AccountBlocks[j].Hash, ab.Hash)
+			}
+		}
+	}
+}
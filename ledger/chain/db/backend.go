@@ -0,0 +1,92 @@
+package chain_db
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/memdb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+)
+
+const (
+	BackendLevelDB = "leveldb"
+	BackendMemory  = "memory"
+)
+
+// ErrNotFound is returned by the backends if the key doesn't exist
+var ErrNotFound = leveldb.ErrNotFound
+
+// Backend is the key-value engine under a Store. The batches are the batches of xleveldb, so the
+// redo logs are the same whatever the backend is.
+type Backend interface {
+	// Get returns ErrNotFound if the key doesn't exist
+	Get(key []byte) ([]byte, error)
+	Has(key []byte) (bool, error)
+	Put(key, value []byte) error
+	Delete(key []byte) error
+	NewIterator(slice *util.Range) interfaces.StorageIterator
+
+	// Write applies the batch atomically
+	Write(batch *leveldb.Batch) error
+
+	// GetSnapshot returns a frozen view of the backend, it must be released after use
+	GetSnapshot() (BackendSnapshot, error)
+
+	CompactRange(r util.Range) error
+
+	// Stats returns the statistics of the backend, they are marshalled to json in the status of the store
+	Stats() (interface{}, error)
+
+	Close() error
+}
+
+type BackendSnapshot interface {
+	Get(key []byte) ([]byte, error)
+	Has(key []byte) (bool, error)
+	NewIterator(slice *util.Range) interfaces.StorageIterator
+	Release()
+}
+
+// overlayBackend is implemented by the backends reading through the mem db of a store natively,
+// the store merges the mem db with the other backends by itself
+type overlayBackend interface {
+	Get2(key []byte, mdb *memdb.DB, seq uint64) ([]byte, error)
+	NewIterator2(slice *util.Range, mdb *memdb.DB, seq uint64) interfaces.StorageIterator
+}
+
+// BackendOpener opens the backend of a dir, the in-memory backends ignore the dir
+type BackendOpener func(dir string) (Backend, error)
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]BackendOpener{
+		BackendLevelDB: OpenLevelDBBackend,
+		BackendMemory: func(string) (Backend, error) {
+			return NewMemoryBackend(), nil
+		},
+	}
+)
+
+// RegisterBackend makes a backend selectable by its kind in the chain config
+func RegisterBackend(kind string, opener BackendOpener) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	backends[kind] = opener
+}
+
+// OpenBackend opens the backend of the kind, the empty kind is leveldb
+func OpenBackend(kind, dir string) (Backend, error) {
+	if kind == "" {
+		kind = BackendLevelDB
+	}
+
+	backendsMu.RLock()
+	opener, ok := backends[kind]
+	backendsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown backend %q", kind)
+	}
+	return opener(dir)
+}
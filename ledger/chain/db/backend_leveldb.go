@@ -0,0 +1,101 @@
+package chain_db
+
+import (
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/memdb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+)
+
+// levelDBBackend is the default backend, it reads through the mem db of a store natively
+type levelDBBackend struct {
+	db *leveldb.DB
+}
+
+func OpenLevelDBBackend(dir string) (Backend, error) {
+	db, err := leveldb.OpenFile(dir, nil)
+	if err != nil {
+		return nil, err
+	}
+	return NewLevelDBBackend(db), nil
+}
+
+func NewLevelDBBackend(db *leveldb.DB) Backend {
+	return &levelDBBackend{db: db}
+}
+
+func (b *levelDBBackend) Get(key []byte) ([]byte, error) {
+	return b.db.Get(key, nil)
+}
+
+func (b *levelDBBackend) Has(key []byte) (bool, error) {
+	return b.db.Has(key, nil)
+}
+
+func (b *levelDBBackend) Put(key, value []byte) error {
+	return b.db.Put(key, value, nil)
+}
+
+func (b *levelDBBackend) Delete(key []byte) error {
+	return b.db.Delete(key, nil)
+}
+
+func (b *levelDBBackend) NewIterator(slice *util.Range) interfaces.StorageIterator {
+	return b.db.NewIterator(slice, nil)
+}
+
+func (b *levelDBBackend) Get2(key []byte, mdb *memdb.DB, seq uint64) ([]byte, error) {
+	return b.db.Get2(key, nil, mdb, seq)
+}
+
+func (b *levelDBBackend) NewIterator2(slice *util.Range, mdb *memdb.DB, seq uint64) interfaces.StorageIterator {
+	return b.db.NewIterator2(slice, nil, mdb, seq)
+}
+
+func (b *levelDBBackend) Write(batch *leveldb.Batch) error {
+	return b.db.Write(batch, nil)
+}
+
+func (b *levelDBBackend) GetSnapshot() (BackendSnapshot, error) {
+	snapshot, err := b.db.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &levelDBSnapshot{snapshot: snapshot}, nil
+}
+
+func (b *levelDBBackend) CompactRange(r util.Range) error {
+	return b.db.CompactRange(r)
+}
+
+func (b *levelDBBackend) Stats() (interface{}, error) {
+	s := &leveldb.DBStats{}
+	if err := b.db.Stats(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (b *levelDBBackend) Close() error {
+	return b.db.Close()
+}
+
+type levelDBSnapshot struct {
+	snapshot *leveldb.Snapshot
+}
+
+func (s *levelDBSnapshot) Get(key []byte) ([]byte, error) {
+	return s.snapshot.Get(key, nil)
+}
+
+func (s *levelDBSnapshot) Has(key []byte) (bool, error) {
+	return s.snapshot.Has(key, nil)
+}
+
+func (s *levelDBSnapshot) NewIterator(slice *util.Range) interfaces.StorageIterator {
+	return s.snapshot.NewIterator(slice, nil)
+}
+
+func (s *levelDBSnapshot) Release() {
+	s.snapshot.Release()
+}
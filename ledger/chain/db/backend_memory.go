@@ -0,0 +1,147 @@
+package chain_db
+
+import (
+	"sync"
+
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/comparer"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/memdb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+)
+
+// memoryBackend keeps the data in a skip list, for the tests and the ephemeral nodes. The
+// overwritten values stay in the buffer of the skip list until CompactRange rebuilds it.
+// The iterators see the writes after they are created, the snapshots are copies.
+type memoryBackend struct {
+	mu sync.RWMutex
+	db *memdb.DB
+}
+
+func NewMemoryBackend() Backend {
+	return &memoryBackend{
+		db: memdb.New(comparer.DefaultComparer, 0),
+	}
+}
+
+func (b *memoryBackend) Get(key []byte) ([]byte, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return memDbGet(b.db, key)
+}
+
+func (b *memoryBackend) Has(key []byte) (bool, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.db.Contains(key), nil
+}
+
+func (b *memoryBackend) Put(key, value []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.db.Put(key, value)
+}
+
+func (b *memoryBackend) Delete(key []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.db.Delete(key)
+	return nil
+}
+
+func (b *memoryBackend) NewIterator(slice *util.Range) interfaces.StorageIterator {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.db.NewIterator(slice)
+}
+
+func (b *memoryBackend) Write(batch *leveldb.Batch) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return batch.Replay(memoryReplay{b.db})
+}
+
+func (b *memoryBackend) GetSnapshot() (BackendSnapshot, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return &memorySnapshot{db: b.db.Copy()}, nil
+}
+
+// CompactRange rebuilds the skip list to drop the overwritten values, the range is ignored
+func (b *memoryBackend) CompactRange(r util.Range) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	db := memdb.New(comparer.DefaultComparer, b.db.Size())
+	iter := b.db.NewIterator(nil)
+	defer iter.Release()
+	for iter.Next() {
+		if err := db.Put(iter.Key(), iter.Value()); err != nil {
+			return err
+		}
+	}
+	b.db = db
+	return nil
+}
+
+type MemoryStats struct {
+	Count    int `json:"count"`
+	Size     int `json:"size"`
+	Capacity int `json:"capacity"`
+}
+
+func (b *memoryBackend) Stats() (interface{}, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return &MemoryStats{
+		Count:    b.db.Len(),
+		Size:     b.db.Size(),
+		Capacity: b.db.Capacity(),
+	}, nil
+}
+
+func (b *memoryBackend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.db.Reset()
+	return nil
+}
+
+type memorySnapshot struct {
+	db *memdb.DB
+}
+
+func (s *memorySnapshot) Get(key []byte) ([]byte, error) {
+	return memDbGet(s.db, key)
+}
+
+func (s *memorySnapshot) Has(key []byte) (bool, error) {
+	return s.db.Contains(key), nil
+}
+
+func (s *memorySnapshot) NewIterator(slice *util.Range) interfaces.StorageIterator {
+	return s.db.NewIterator(slice)
+}
+
+func (s *memorySnapshot) Release() {}
+
+// memDbGet returns a copy of the value, the value in the skip list is overwritten by Reset
+func memDbGet(db *memdb.DB, key []byte) ([]byte, error) {
+	value, err := db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{}, value...), nil
+}
+
+type memoryReplay struct {
+	db *memdb.DB
+}
+
+func (r memoryReplay) Put(key, value []byte) {
+	r.db.Put(key, value)
+}
+
+func (r memoryReplay) Delete(key []byte) {
+	r.db.Delete(key)
+}
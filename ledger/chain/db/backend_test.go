@@ -0,0 +1,135 @@
+package chain_db
+
+import (
+	"math/rand"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/v2/common/fileutils"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	chain_utils "github.com/vitelabs/go-vite/v2/ledger/chain/utils"
+)
+
+type kv struct {
+	key   uint64
+	value uint64
+}
+
+func collect(iter interfaces.StorageIterator, backward bool) []kv {
+	var kvs []kv
+	ok := iter.Next
+	if backward {
+		ok = iter.Prev
+	}
+	for ok() {
+		kvs = append(kvs, kv{chain_utils.BytesToUint64(iter.Key()), chain_utils.BytesToUint64(iter.Value())})
+	}
+	return kvs
+}
+
+// TestMemoryBackend runs the same writes and flushes on a leveldb store and a memory store, the
+// memory store reads through its mem db by the overlay iterator
+func TestMemoryBackend(t *testing.T) {
+	dir := fileutils.CreateTempDir()
+	defer os.RemoveAll(dir)
+
+	diskStore, err := OpenStore(BackendLevelDB, path.Join(dir, "leveldb"), "test")
+	assert.NoError(t, err)
+	defer diskStore.Close()
+	memStore, err := OpenStore(BackendMemory, "", "test")
+	assert.NoError(t, err)
+	defer memStore.Close()
+	stores := []*Store{diskStore, memStore}
+
+	r := rand.New(rand.NewSource(1))
+	for round := 0; round < 50; round++ {
+		batch := new(leveldb.Batch)
+		for i := 0; i < 20; i++ {
+			key := chain_utils.Uint64ToBytes(uint64(r.Intn(100)))
+			if r.Intn(3) == 0 {
+				batch.Delete(key)
+			} else {
+				batch.Put(key, chain_utils.Uint64ToBytes(uint64(r.Intn(1000))))
+			}
+		}
+		flush := r.Intn(3) == 0
+		for _, store := range stores {
+			store.WriteDirectly(batch)
+			if flush {
+				flushToDisk(store)
+			}
+		}
+
+		for key := uint64(0); key < 100; key++ {
+			expected, err := diskStore.Get(chain_utils.Uint64ToBytes(key))
+			assert.NoError(t, err)
+			value, err := memStore.Get(chain_utils.Uint64ToBytes(key))
+			assert.NoError(t, err)
+			assert.Equal(t, expected, value)
+		}
+
+		slice := &util.Range{Start: chain_utils.Uint64ToBytes(10), Limit: chain_utils.Uint64ToBytes(90)}
+		// walk forward, then backward from the end
+		var walks [2][]kv
+		for i, store := range stores {
+			iter := store.NewIterator(slice)
+			walks[i] = append(collect(iter, false), collect(iter, true)...)
+			iter.Release()
+		}
+		assert.Equal(t, walks[0], walks[1])
+
+		// seek and switch the direction
+		seek := chain_utils.Uint64ToBytes(uint64(r.Intn(100)))
+		var results [2][]kv
+		for i, store := range stores {
+			iter := store.NewIterator(nil)
+			if iter.Seek(seek) {
+				results[i] = append(results[i], kv{chain_utils.BytesToUint64(iter.Key()), 0})
+			}
+			for j := 0; j < 3 && iter.Next(); j++ {
+				results[i] = append(results[i], kv{chain_utils.BytesToUint64(iter.Key()), 0})
+			}
+			results[i] = append(results[i], collect(iter, true)...)
+			iter.Release()
+		}
+		assert.Equal(t, results[0], results[1])
+	}
+
+	stats := memStore.GetStatus()
+	assert.Equal(t, 2, len(stats))
+	assert.NoError(t, memStore.Backend().CompactRange(util.Range{}))
+	all := collect(diskStore.NewIterator(nil), false)
+	assert.Equal(t, all, collect(memStore.NewIterator(nil), false))
+}
+
+func TestMemoryBackend_Snapshot(t *testing.T) {
+	backend := NewMemoryBackend()
+	assert.NoError(t, backend.Put([]byte("a"), []byte("1")))
+
+	snapshot, err := backend.GetSnapshot()
+	assert.NoError(t, err)
+	defer snapshot.Release()
+
+	batch := new(leveldb.Batch)
+	batch.Put([]byte("a"), []byte("2"))
+	batch.Put([]byte("b"), []byte("3"))
+	assert.NoError(t, backend.Write(batch))
+
+	value, err := snapshot.Get([]byte("a"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("1"), value)
+	_, err = snapshot.Get([]byte("b"))
+	assert.Equal(t, ErrNotFound, err)
+
+	value, err = backend.Get([]byte("a"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("2"), value)
+
+	_, err = OpenBackend("unknown", "")
+	assert.Error(t, err)
+}
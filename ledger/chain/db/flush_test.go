@@ -92,15 +92,15 @@ func TestFlush(t *testing.T) {
 	assert.True(t, store.snapshotBatch == nil || store.snapshotBatch.Len() <= 0)
 
 	// check value
-	v1, err := store.db.Get([]byte("key1"), nil)
+	v1, err := store.db.Get([]byte("key1"))
 	assert.NoError(t, err)
 	assert.Equal(t, v1, []byte("value1"))
 
-	v2, err := store.db.Get([]byte("key2"), nil)
+	v2, err := store.db.Get([]byte("key2"))
 	assert.NoError(t, err)
 	assert.Equal(t, v2, []byte("value2"))
 
-	v3, err := store.db.Get([]byte("key3"), nil)
+	v3, err := store.db.Get([]byte("key3"))
 	assert.NoError(t, err)
 	assert.Equal(t, v3, []byte("value3"))
 
@@ -175,15 +175,15 @@ func TestRecover(t *testing.T) {
 	assert.Equal(t, callAfterRecover, true)
 
 	// check value
-	v1, err := store.db.Get([]byte("key1"), nil)
+	v1, err := store.db.Get([]byte("key1"))
 	assert.NoError(t, err)
 	assert.Equal(t, v1, []byte("value1"))
 
-	v2, err := store.db.Get([]byte("key2"), nil)
+	v2, err := store.db.Get([]byte("key2"))
 	assert.NoError(t, err)
 	assert.Equal(t, v2, []byte("value2"))
 
-	v3, err := store.db.Get([]byte("key3"), nil)
+	v3, err := store.db.Get([]byte("key3"))
 	assert.NoError(t, err)
 	assert.Equal(t, v3, []byte("value3"))
 }
@@ -0,0 +1,272 @@
+package chain_db
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/memdb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+)
+
+// overlayGet reads the key from the mem db of a store at the seq, then from the backend.
+// The mem db is keyed by the internal keys of xleveldb, the deletions hide the keys of the backend.
+func overlayGet(backend Backend, key []byte, mdb *memdb.DB, seq uint64) ([]byte, error) {
+	rkey, value, err := mdb.Find(leveldb.MakeInternalKey(nil, key, seq, leveldb.KeyTypeSeek))
+	if err == nil {
+		ukey, _, kt, err := leveldb.ParseInternalKey(rkey)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(ukey, key) {
+			if kt == leveldb.KeyTypeDel {
+				return nil, ErrNotFound
+			}
+			return append([]byte{}, value...), nil
+		}
+	}
+	return backend.Get(key)
+}
+
+type overlayEntry struct {
+	key     []byte
+	value   []byte
+	deleted bool
+}
+
+// overlayEntries returns the latest entries of the mem db in the slice at the seq
+func overlayEntries(mdb *memdb.DB, slice *util.Range, seq uint64) ([]overlayEntry, error) {
+	var islice *util.Range
+	if slice != nil {
+		islice = &util.Range{}
+		if slice.Start != nil {
+			islice.Start = leveldb.MakeInternalKey(nil, slice.Start, leveldb.KeyMaxSeq, leveldb.KeyTypeSeek)
+		}
+		if slice.Limit != nil {
+			islice.Limit = leveldb.MakeInternalKey(nil, slice.Limit, leveldb.KeyMaxSeq, leveldb.KeyTypeSeek)
+		}
+	}
+
+	iter := mdb.NewIterator(islice)
+	defer iter.Release()
+
+	var entries []overlayEntry
+	for iter.Next() {
+		ukey, kseq, kt, err := leveldb.ParseInternalKey(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		// the versions of a key are sorted from the latest one
+		if kseq > seq || (len(entries) > 0 && bytes.Equal(entries[len(entries)-1].key, ukey)) {
+			continue
+		}
+		entries = append(entries, overlayEntry{
+			key:     append([]byte{}, ukey...),
+			value:   append([]byte{}, iter.Value()...),
+			deleted: kt == leveldb.KeyTypeDel,
+		})
+	}
+	return entries, iter.Error()
+}
+
+const (
+	dirStart = iota
+	dirForward
+	dirBackward
+)
+
+// overlayIterator merges the entries of the mem db of a store with an iterator of the backend,
+// the entries win over the backend. The cursors point to the candidates of the next step.
+type overlayIterator struct {
+	entries []overlayEntry
+	pos     int
+
+	iter   interfaces.StorageIterator
+	iterOk bool
+
+	dir   int
+	key   []byte
+	value []byte
+	err   error
+}
+
+func newOverlayIterator(backend Backend, slice *util.Range, mdb *memdb.DB, seq uint64) interfaces.StorageIterator {
+	entries, err := overlayEntries(mdb, slice, seq)
+	return &overlayIterator{
+		entries: entries,
+		iter:    backend.NewIterator(slice),
+		err:     err,
+	}
+}
+
+func (it *overlayIterator) Seek(key []byte) bool {
+	if it.err != nil {
+		return false
+	}
+	it.pos = sort.Search(len(it.entries), func(i int) bool {
+		return bytes.Compare(it.entries[i].key, key) >= 0
+	})
+	it.iterOk = it.iter.Seek(key)
+	it.dir = dirForward
+	return it.forward()
+}
+
+func (it *overlayIterator) Last() bool {
+	if it.err != nil {
+		return false
+	}
+	it.pos = len(it.entries) - 1
+	it.iterOk = it.iter.Last()
+	it.dir = dirBackward
+	return it.backward()
+}
+
+func (it *overlayIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.dir != dirForward {
+		if it.key == nil {
+			// at the start or before the first key
+			it.pos = 0
+			it.iterOk = first(it.iter)
+		} else {
+			key := it.key
+			it.pos = sort.Search(len(it.entries), func(i int) bool {
+				return bytes.Compare(it.entries[i].key, key) > 0
+			})
+			it.iterOk = it.iter.Seek(key)
+			if it.iterOk && bytes.Equal(it.iter.Key(), key) {
+				it.iterOk = it.iter.Next()
+			}
+		}
+		it.dir = dirForward
+	}
+	return it.forward()
+}
+
+func (it *overlayIterator) Prev() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.dir != dirBackward {
+		if it.dir == dirStart {
+			return false
+		}
+		if it.key == nil {
+			// after the last key
+			return it.Last()
+		}
+		key := it.key
+		it.pos = sort.Search(len(it.entries), func(i int) bool {
+			return bytes.Compare(it.entries[i].key, key) >= 0
+		}) - 1
+		if it.iter.Seek(key) {
+			it.iterOk = it.iter.Prev()
+		} else {
+			it.iterOk = it.iter.Last()
+		}
+		it.dir = dirBackward
+	}
+	return it.backward()
+}
+
+// first moves the iterator to its first key
+func first(iter interfaces.StorageIterator) bool {
+	if f, ok := iter.(interface{ First() bool }); ok {
+		return f.First()
+	}
+	return iter.Seek(nil)
+}
+
+func (it *overlayIterator) forward() bool {
+	for {
+		entryOk := it.pos >= 0 && it.pos < len(it.entries)
+		if !entryOk && !it.iterOk {
+			return it.end()
+		}
+
+		if entryOk {
+			entry := it.entries[it.pos]
+			cmp := -1
+			if it.iterOk {
+				cmp = bytes.Compare(entry.key, it.iter.Key())
+			}
+			if cmp <= 0 {
+				if cmp == 0 {
+					it.iterOk = it.iter.Next()
+				}
+				it.pos++
+				if entry.deleted {
+					continue
+				}
+				it.key, it.value = entry.key, entry.value
+				return true
+			}
+		}
+
+		it.key = append([]byte{}, it.iter.Key()...)
+		it.value = append([]byte{}, it.iter.Value()...)
+		it.iterOk = it.iter.Next()
+		return true
+	}
+}
+
+func (it *overlayIterator) backward() bool {
+	for {
+		entryOk := it.pos >= 0 && it.pos < len(it.entries)
+		if !entryOk && !it.iterOk {
+			return it.end()
+		}
+
+		if entryOk {
+			entry := it.entries[it.pos]
+			cmp := 1
+			if it.iterOk {
+				cmp = bytes.Compare(entry.key, it.iter.Key())
+			}
+			if cmp >= 0 {
+				if cmp == 0 {
+					it.iterOk = it.iter.Prev()
+				}
+				it.pos--
+				if entry.deleted {
+					continue
+				}
+				it.key, it.value = entry.key, entry.value
+				return true
+			}
+		}
+
+		it.key = append([]byte{}, it.iter.Key()...)
+		it.value = append([]byte{}, it.iter.Value()...)
+		it.iterOk = it.iter.Prev()
+		return true
+	}
+}
+
+func (it *overlayIterator) end() bool {
+	it.key, it.value = nil, nil
+	if err := it.iter.Error(); err != nil {
+		it.err = err
+	}
+	return false
+}
+
+func (it *overlayIterator) Key() []byte {
+	return it.key
+}
+
+func (it *overlayIterator) Value() []byte {
+	return it.value
+}
+
+func (it *overlayIterator) Error() error {
+	return it.err
+}
+
+func (it *overlayIterator) Release() {
+	it.iter.Release()
+	it.entries = nil
+}
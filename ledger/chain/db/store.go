@@ -27,24 +27,32 @@ type Store struct {
 
 	unconfirmedBatchs *UnconfirmedBatchs
 
-	dbDir string
-	db    *leveldb.DB
+	dbDir   string
+	db      Backend
+	overlay overlayBackend
 
 	afterRecoverFuncs []func()
 }
 
 func NewStore(dataDir string, name string) (*Store, error) {
-	diskStore, err := leveldb.OpenFile(dataDir, nil)
+	return OpenStore(BackendLevelDB, dataDir, name)
+}
 
+// OpenStore opens the store of the dir on the backend of the kind
+func OpenStore(kind string, dataDir string, name string) (*Store, error) {
+	backend, err := OpenBackend(kind, dataDir)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewStoreWithDb(dataDir, name, diskStore)
-
+	return NewStoreWithBackend(dataDir, name, backend)
 }
 
 func NewStoreWithDb(dataDir string, name string, diskStore *leveldb.DB) (*Store, error) {
+	return NewStoreWithBackend(dataDir, name, NewLevelDBBackend(diskStore))
+}
+
+func NewStoreWithBackend(dataDir string, name string, backend Backend) (*Store, error) {
 	id, _ := types.BytesToHash(crypto.Hash256([]byte(name)))
 
 	store := &Store{
@@ -55,8 +63,9 @@ func NewStoreWithDb(dataDir string, name string, diskStore *leveldb.DB) (*Store,
 		unconfirmedBatchs: NewUnconfirmedBatchs(),
 
 		dbDir: dataDir,
-		db:    diskStore,
+		db:    backend,
 	}
+	store.overlay, _ = backend.(overlayBackend)
 
 	store.snapshotBatch = store.getNewBatch()
 
@@ -72,11 +81,9 @@ func (store *Store) NewBatch() *leveldb.Batch {
 }
 
 func (store *Store) Get(key []byte) ([]byte, error) {
-	mdb, seq := store.getSnapshotMemDb()
-
-	value, err := store.db.Get2(key, nil, mdb, seq)
+	value, err := store.GetOriginal(key)
 	if err != nil {
-		if err == leveldb.ErrNotFound {
+		if err == ErrNotFound {
 			return nil, nil
 		}
 		return nil, err
@@ -87,16 +94,17 @@ func (store *Store) Get(key []byte) ([]byte, error) {
 
 func (store *Store) GetOriginal(key []byte) ([]byte, error) {
 	mdb, seq := store.getSnapshotMemDb()
-	return store.db.Get2(key, nil, mdb, seq)
+	if store.overlay != nil {
+		return store.overlay.Get2(key, mdb, seq)
+	}
+	return overlayGet(store.db, key, mdb, seq)
 }
 
 func (store *Store) Has(key []byte) (bool, error) {
-	mdb, seq := store.getSnapshotMemDb()
-
-	_, err := store.db.Get2(key, nil, mdb, seq)
+	_, err := store.GetOriginal(key)
 
 	if err != nil {
-		if err == leveldb.ErrNotFound {
+		if err == ErrNotFound {
 			return false, nil
 		}
 
@@ -109,7 +117,15 @@ func (store *Store) Has(key []byte) (bool, error) {
 func (store *Store) NewIterator(slice *util.Range) interfaces.StorageIterator {
 	mdb, seq := store.getSnapshotMemDb()
 
-	return store.db.NewIterator2(slice, nil, mdb, seq)
+	if store.overlay != nil {
+		return store.overlay.NewIterator2(slice, mdb, seq)
+	}
+	return newOverlayIterator(store.db, slice, mdb, seq)
+}
+
+// Backend returns the key-value engine under the store
+func (store *Store) Backend() Backend {
+	return store.db
 }
 
 func (store *Store) Close() error {
@@ -155,10 +171,11 @@ func (store *Store) GetStatus() []interfaces.DBStatus {
 		size += store.snapshotBatch.Size()
 	}
 
-	s := &leveldb.DBStats{}
-	store.db.Stats(s)
-
-	status, err := json.Marshal(s)
+	var status []byte
+	s, err := store.db.Stats()
+	if err == nil {
+		status, err = json.Marshal(s)
+	}
 	if err != nil {
 		status = []byte("Error:" + err.Error())
 	}
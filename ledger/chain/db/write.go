@@ -61,3 +61,16 @@ func (store *Store) WriteSnapshotByHash(snapshotBatch *leveldb.Batch, blockHashL
 	store.snapshotBatch.Append(snapshotBatch)
 
 }
+
+// AppendAccountBlock appends the batch to the unconfirmed batch of the block, used to write the data of a plugin
+// switching to live for the blocks that have been inserted.
+func (store *Store) AppendAccountBlock(batch *leveldb.Batch, block *ledger.AccountBlock) {
+	// write store.memDb
+	store.putMemDb(batch)
+
+	if unconfirmedBatch, ok := store.unconfirmedBatchs.Get(block.Hash); ok {
+		unconfirmedBatch.Append(batch)
+		return
+	}
+	store.unconfirmedBatchs.Put(block.Hash, batch)
+}
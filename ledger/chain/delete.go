@@ -7,6 +7,7 @@ import (
 	"github.com/vitelabs/go-vite/v2/common"
 	"github.com/vitelabs/go-vite/v2/common/types"
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_state "github.com/vitelabs/go-vite/v2/ledger/chain/state"
 )
 
 func (c *chain) DeleteSnapshotBlocks(toHash types.Hash) ([]*ledger.SnapshotChunk, error) {
@@ -36,6 +37,11 @@ func (c *chain) DeleteSnapshotBlocksToHeight(toHeight uint64) ([]*ledger.Snapsho
 		return nil, cErr
 	}
 
+	if err := c.checkRollbackPruned(toHeight); err != nil {
+		c.log.Error(err.Error(), "method", "DeleteSnapshotBlocksToHeight")
+		return nil, err
+	}
+
 	deleteAtOnce := uint64(120)
 	// init target height
 	targetHeight := latestHeight + 1
@@ -73,6 +79,11 @@ func (c *chain) DeleteSnapshotBlocksToHeight(toHeight uint64) ([]*ledger.Snapsho
 	return allChunksDeleted, nil
 }
 func (c *chain) deleteSnapshotBlocksToHeight(toHeight uint64) (chunks []*ledger.SnapshotChunk, returnErr error) {
+	// check before any store is rolled back, the state can't be recovered below the pruned height
+	if err := c.checkRollbackPruned(toHeight); err != nil {
+		return nil, err
+	}
+
 	// lock flush
 	c.flushMu.RLock()
 	defer func() {
@@ -307,3 +318,13 @@ func (c *chain) deleteAccountBlocks(blocks []*ledger.AccountBlock) error {
 	}
 	return nil
 }
+
+// checkRollbackPruned returns an error if the history state of toHeight - 1, the latest height after
+// the rollback, has been pruned
+func (c *chain) checkRollbackPruned(toHeight uint64) error {
+	if prunedHeight := c.stateDB.PrunedHeight(); toHeight-1 < prunedHeight {
+		return fmt.Errorf("can't roll back to height %d, the history state below %d has been pruned: %w",
+			toHeight-1, prunedHeight, chain_state.ErrStatePruned)
+	}
+	return nil
+}
@@ -122,7 +122,9 @@ func (em *eventManager) TriggerInsertSbs(eventType byte, chunks []*ledger.Snapsh
 		splitChunks(chunks)
 	case InsertSbsEvent:
 		for _, listener := range em.listenerList {
-			listener.InsertSnapshotBlocks(chunks)
+			if err := listener.InsertSnapshotBlocks(chunks); err != nil {
+				em.chain.log.Error("Insert Snapshot Block trigger fail", "err", err)
+			}
 		}
 
 		splitChunks(chunks)
@@ -148,7 +150,9 @@ func (em *eventManager) TriggerDeleteSbs(eventType byte, chunks []*ledger.Snapsh
 		splitChunks(chunks)
 	case deleteSbsEvent:
 		for _, listener := range em.listenerList {
-			listener.DeleteSnapshotBlocks(chunks)
+			if err := listener.DeleteSnapshotBlocks(chunks); err != nil {
+				em.chain.log.Error("Delete Snapshot Block trigger fail", "err", err)
+			}
 		}
 
 		splitChunks(chunks)
@@ -204,6 +204,37 @@ func (fdSet *fdManager) DiskDelete(highLocation *Location, lowLocation *Location
 	return nil
 }
 
+// Overwrite replaces the bytes from the location with buf in the file and in the cache
+func (fdSet *fdManager) Overwrite(location *Location, buf []byte) error {
+	fdSet.changeFdMu.RLock()
+	defer fdSet.changeFdMu.RUnlock()
+
+	file, err := fdSet.getFileFd(location.FileId)
+	if err != nil {
+		return err
+	}
+	if file == nil {
+		return fmt.Errorf("file %d is not existed", location.FileId)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteAt(buf, location.Offset); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+
+	if cacheItem := fdSet.getCacheItem(location.FileId); cacheItem != nil {
+		cacheItem.Mu.Lock()
+		if cacheItem.FileId == location.FileId && location.Offset+int64(len(buf)) <= cacheItem.BufferLen {
+			copy(cacheItem.Buffer[location.Offset:], buf)
+		}
+		cacheItem.Mu.Unlock()
+	}
+	return nil
+}
+
 func (fdSet *fdManager) CreateNextFd() error {
 	fdSet.changeFdMu.Lock()
 	defer fdSet.changeFdMu.Unlock()
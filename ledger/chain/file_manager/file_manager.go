@@ -173,6 +173,11 @@ func (fm *FileManager) Read(location *Location) ([]byte, *Location, error) {
 
 	bufSize := binary.BigEndian.Uint32(bufSizeBytes)
 
+	// a damaged size should not allocate more than the rest of the files
+	if int64(bufSize) > nextLocation.Distance(fm.fileSize, fm.LatestLocation()) {
+		return nil, nextLocation, fmt.Errorf("record of %d bytes at %s exceeds the latest location", bufSize, location)
+	}
+
 	buf := make([]byte, bufSize)
 
 	nextLocation, _, err = fm.ReadRaw(nextLocation, buf)
@@ -261,6 +266,35 @@ func (fm *FileManager) ReadRange(startLocation *Location, endLocation *Location,
 	}
 }
 
+// Overwrite replaces the bytes from the location with buf, the bytes must have been flushed.
+// The bytes in the file cache are replaced as well.
+func (fm *FileManager) Overwrite(location *Location, buf []byte) error {
+	if end := fm.nextFlushStartLocation; end == nil || location.Distance(fm.fileSize, end) < int64(len(buf)) {
+		return fmt.Errorf("bytes from %s to %s are not flushed", location, end)
+	}
+
+	current := NewLocation(location.FileId, location.Offset)
+	for len(buf) > 0 {
+		n := int64(len(buf))
+		if rest := fm.fileSize - current.Offset; n > rest {
+			n = rest
+		}
+
+		if err := fm.fdSet.Overwrite(current, buf[:n]); err != nil {
+			return err
+		}
+
+		buf = buf[n:]
+		current = NewLocation(current.FileId+1, 0)
+	}
+	return nil
+}
+
+// FileName returns the absolute file name of the file id
+func (fm *FileManager) FileName(fileId uint64) string {
+	return fm.fdSet.fileIdToAbsoluteFilename(fileId)
+}
+
 func (fm *FileManager) SetLog(h log15.Handler) {
 	fm.log.SetHandler(h)
 }
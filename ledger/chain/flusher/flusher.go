@@ -154,6 +154,14 @@ func (flusher *Flusher) Flush() {
 	flusher.flush()
 }
 
+// Exclusive runs fn between two flushes
+func (flusher *Flusher) Exclusive(fn func() error) error {
+	flusher.flushingMu.Lock()
+	defer flusher.flushingMu.Unlock()
+
+	return fn()
+}
+
 func (flusher *Flusher) Recover() error {
 	flusher.mu.Lock()
 	defer flusher.mu.Unlock()
@@ -35,6 +35,10 @@ func NewIndexDB(chainDir string) (*IndexDB, error) {
 		return nil, err
 	}
 
+	return NewIndexDBWithStore(store)
+}
+
+func NewIndexDBWithStore(store *chain_db.Store) (*IndexDB, error) {
 	iDB := &IndexDB{
 		store: store,
 		log:   log15.New("module", "indexDB"),
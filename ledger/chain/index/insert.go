@@ -57,6 +57,19 @@ func (iDB *IndexDB) InsertSnapshotBlock(snapshotBlock *ledger.SnapshotBlock, con
 	iDB.store.WriteSnapshot(batch, confirmedBlocks)
 }
 
+// UpdateBlockLocations rewrites the locations of the snapshot block and the account blocks confirmed by it,
+// it is used when the block files are rewritten
+func (iDB *IndexDB) UpdateBlockLocations(snapshotBlock *ledger.SnapshotBlock, confirmedBlocks []*ledger.AccountBlock, snapshotBlockLocation *chain_file_manager.Location, abLocationsList map[types.Hash]*chain_file_manager.Location) {
+	batch := iDB.store.NewBatch()
+
+	iDB.insertSbHeightLocation(batch, snapshotBlock, snapshotBlockLocation)
+	for _, block := range confirmedBlocks {
+		iDB.insertAbHeightLocation(batch, block, abLocationsList[block.Hash])
+	}
+
+	iDB.store.WriteDirectly(batch)
+}
+
 // hash、 height、 onroad key set(key:toAddress+sendBlockHash,value:nil)、 receive (key: sendBlockHash, receiveBlockHash)
 // sendCreateBlock confirmed cache
 func (iDB *IndexDB) insertAccountBlock(batch *leveldb.Batch, accountBlock *ledger.AccountBlock) error {
@@ -4,13 +4,22 @@ import (
 	"fmt"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/vitelabs/go-vite/v2/common"
 	"github.com/vitelabs/go-vite/v2/interfaces"
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/metrics"
+)
+
+var (
+	insertAccountBlockTimer  = metrics.NewRegisteredHistogram("vite_chain_insert_account_block_seconds", "Latency of inserting an account block into the chain.", nil)
+	insertSnapshotBlockTimer = metrics.NewRegisteredHistogram("vite_chain_insert_snapshot_block_seconds", "Latency of inserting a snapshot block into the chain.", nil)
 )
 
 func (c *chain) InsertAccountBlock(vmAccountBlock *interfaces.VmAccountBlock) error {
+	defer insertAccountBlockTimer.UpdateSince(time.Now())
+
 	c.flushMu.RLock()
 	defer c.flushMu.RUnlock()
 
@@ -46,6 +55,8 @@ func (c *chain) InsertAccountBlock(vmAccountBlock *interfaces.VmAccountBlock) er
 }
 
 func (c *chain) InsertSnapshotBlock(snapshotBlock *ledger.SnapshotBlock) ([]*ledger.AccountBlock, error) {
+	defer insertSnapshotBlockTimer.UpdateSince(time.Now())
+
 	// FOR DEBUG
 	c.log.Info(fmt.Sprintf("insert snapshot block %s %d\n", snapshotBlock.Hash, snapshotBlock.Height))
 	if err := c.insertSnapshotBlock(snapshotBlock); err != nil {
@@ -7,9 +7,11 @@ import (
 	"github.com/syndtr/goleveldb/leveldb"
 
 	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
 	"github.com/vitelabs/go-vite/v2/interfaces"
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
 	chain_block "github.com/vitelabs/go-vite/v2/ledger/chain/block"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
 	chain_flusher "github.com/vitelabs/go-vite/v2/ledger/chain/flusher"
 	chain_index "github.com/vitelabs/go-vite/v2/ledger/chain/index"
 	chain_plugins "github.com/vitelabs/go-vite/v2/ledger/chain/plugins"
@@ -219,6 +221,12 @@ type Chain interface {
 
 	GetValue(address types.Address, key []byte) ([]byte, error)
 
+	// get the state of the account before the account block was inserted, used to re-execute the account block
+	GetStateBeforeAccountBlock(block *ledger.AccountBlock) (interfaces.StateSnapshot, *ledger.SnapshotBlock, error)
+
+	// get the state of the account at the snapshot block
+	GetSnapshotState(address types.Address, snapshotHeight uint64) (interfaces.StateSnapshot, error)
+
 	GetVmLogList(logListHash *types.Hash) (ledger.VmLogList, error)
 
 	GetVMLogListByAddress(address types.Address, start uint64, end uint64, id *types.Hash) (ledger.VmLogList, error)
@@ -282,6 +290,8 @@ type Chain interface {
 
 	NewDb(dirName string) (*leveldb.DB, error)
 
+	NewBackend(dirName string) (chain_db.Backend, error)
+
 	PrepareOnroadDb() (*leveldb.DB, error)
 
 	Plugins() *chain_plugins.Plugins
@@ -300,12 +310,26 @@ type Chain interface {
 
 	QueryGenesisCheckSum() (*types.Hash, error)
 
+	WriteUpgradePoints(points []*upgrade.UpgradePoint) error
+
+	QueryUpgradePoints() ([]*upgrade.UpgradePoint, error)
+
 	// ====== Check ======
 	CheckRedo() error
 
 	CheckRecentBlocks() error
 
+	CheckHash() error
+
 	CheckOnRoad() error
 
+	ScrubBlocks(fromHeight, toHeight uint64) ([]*DamagedChunk, error)
+
+	RepairChunk(chunk *ledger.SnapshotChunk) error
+
+	SetChunkFetcher(fetcher ChunkFetcher)
+
+	CompressBlocks(compression chain_block.Compression) error
+
 	GetStatus() []interfaces.DBStatus
 }
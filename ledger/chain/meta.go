@@ -1,13 +1,17 @@
 package chain
 
 import (
+	"encoding/json"
+
 	"github.com/syndtr/goleveldb/leveldb"
 
 	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
 )
 
 const (
 	GenesisKey = byte(0)
+	UpgradeKey = byte(1)
 )
 
 func (c *chain) WriteGenesisCheckSum(hash types.Hash) error {
@@ -35,3 +39,28 @@ func (c *chain) QueryGenesisCheckSum() (*types.Hash, error) {
 	}
 	return &checkSum, nil
 }
+
+func (c *chain) WriteUpgradePoints(points []*upgrade.UpgradePoint) error {
+	value, err := json.Marshal(points)
+	if err != nil {
+		return err
+	}
+	return c.metaDB.Put([]byte{UpgradeKey}, value, nil)
+}
+
+// QueryUpgradePoints returns the upgrade points the ledger was built with, nil if they were never written
+func (c *chain) QueryUpgradePoints() ([]*upgrade.UpgradePoint, error) {
+	value, err := c.metaDB.Get([]byte{UpgradeKey}, nil)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var points []*upgrade.UpgradePoint
+	if err := json.Unmarshal(value, &points); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
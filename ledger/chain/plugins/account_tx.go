@@ -0,0 +1,252 @@
+package chain_plugins
+
+import (
+	"fmt"
+
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/v2/common/helper"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain/db"
+	chain_utils "github.com/vitelabs/go-vite/v2/ledger/chain/utils"
+)
+
+const (
+	DirectionAll = byte(0)
+	DirectionIn  = byte(1)
+	DirectionOut = byte(2)
+)
+
+const accountTxValueSize = 1 + types.AddressSize + types.TokenTypeIdSize + types.HashSize
+
+// AccountTxFilter selects the indexed blocks of an address, zero fields match all.
+type AccountTxFilter struct {
+	Counterparty *types.Address
+	Direction    byte
+	TokenId      *types.TokenTypeId
+	// [StartTime, EndTime) is the range of the snapshot time in seconds, EndTime 0 means no limit
+	StartTime int64
+	EndTime   int64
+}
+
+// AccountTx indexes the confirmed blocks of every address by snapshot time, and by counterparty and snapshot time.
+// A send block is indexed as out of its address to the to address, a receive block is indexed as in of its address
+// from the address of the send block, and the send blocks created by a contract are indexed as out of the contract.
+type AccountTx struct {
+	store *chain_db.Store
+	chain Chain
+}
+
+func newAccountTx(store *chain_db.Store, chain Chain) Plugin {
+	return &AccountTx{
+		store: store,
+		chain: chain,
+	}
+}
+
+type accountTxRecord struct {
+	address      types.Address
+	counterparty types.Address
+	direction    byte
+	tokenId      types.TokenTypeId
+	hash         types.Hash
+	height       uint64
+	index        uint16
+}
+
+func (at *AccountTx) SetStore(store *chain_db.Store) {
+	at.store = store
+}
+
+func (at *AccountTx) InsertAccountBlock(batch *leveldb.Batch, accountBlock *ledger.AccountBlock) error {
+	return nil
+}
+
+func (at *AccountTx) InsertSnapshotBlock(batch *leveldb.Batch, snapshotBlock *ledger.SnapshotBlock, confirmedBlocks []*ledger.AccountBlock) error {
+	if len(confirmedBlocks) == 0 {
+		return nil
+	}
+	timestamp := snapshotBlock.Timestamp.Unix()
+	sendBlocksMap := make(map[types.Hash]*ledger.AccountBlock)
+	for _, accountBlock := range confirmedBlocks {
+		records, err := at.getRecords(accountBlock, sendBlocksMap)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			value := record.value()
+			batch.Put(createAccountTxKey(record, timestamp), value)
+			batch.Put(createAccountCounterpartyTxKey(record, timestamp), value)
+		}
+	}
+	return nil
+}
+
+// DeleteAccountBlocks does nothing, the unconfirmed blocks are not indexed.
+func (at *AccountTx) DeleteAccountBlocks(batch *leveldb.Batch, accountBlocks []*ledger.AccountBlock) error {
+	return nil
+}
+
+func (at *AccountTx) DeleteSnapshotBlocks(batch *leveldb.Batch, chunks []*ledger.SnapshotChunk) error {
+	sendBlocksMap := make(map[types.Hash]*ledger.AccountBlock)
+	for _, chunk := range chunks {
+		if chunk.SnapshotBlock == nil {
+			continue
+		}
+		timestamp := chunk.SnapshotBlock.Timestamp.Unix()
+		for _, accountBlock := range chunk.AccountBlocks {
+			records, err := at.getRecords(accountBlock, sendBlocksMap)
+			if err != nil {
+				return err
+			}
+			for _, record := range records {
+				batch.Delete(createAccountTxKey(record, timestamp))
+				batch.Delete(createAccountCounterpartyTxKey(record, timestamp))
+			}
+		}
+	}
+	return nil
+}
+
+func (at *AccountTx) RemoveNewUnconfirmed(*leveldb.Batch, []*ledger.AccountBlock) error {
+	return nil
+}
+
+// GetBlockHashes returns the hashes of the indexed blocks of addr matching the filter, the latest one first.
+// The first skip matched blocks are skipped.
+func (at *AccountTx) GetBlockHashes(addr types.Address, filter AccountTxFilter, skip, count int) ([]types.Hash, error) {
+	endTime := uint64(helper.MaxUint64)
+	if filter.EndTime > 0 {
+		endTime = uint64(filter.EndTime)
+	}
+	var prefix []byte
+	if filter.Counterparty != nil {
+		prefix = createAccountCounterpartyTxPrefixKey(addr, *filter.Counterparty)
+	} else {
+		prefix = createAccountTxPrefixKey(addr)
+	}
+	start := append(append([]byte{}, prefix...), chain_utils.Uint64ToBytes(uint64(filter.StartTime))...)
+	limit := append(append([]byte{}, prefix...), chain_utils.Uint64ToBytes(endTime)...)
+
+	iter := at.store.NewIterator(&util.Range{Start: start, Limit: limit})
+	defer iter.Release()
+
+	hashes := make([]types.Hash, 0, count)
+	for ok := iter.Last(); ok && len(hashes) < count; ok = iter.Prev() {
+		value := iter.Value()
+		if len(value) != accountTxValueSize {
+			return nil, fmt.Errorf("invalid account tx value %x", value)
+		}
+		if filter.Direction != DirectionAll && value[0] != filter.Direction {
+			continue
+		}
+		if filter.TokenId != nil && string(value[1+types.AddressSize:1+types.AddressSize+types.TokenTypeIdSize]) != string(filter.TokenId.Bytes()) {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		hash, err := types.BytesToHash(value[accountTxValueSize-types.HashSize:])
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, iter.Error()
+}
+
+func (at *AccountTx) getRecords(accountBlock *ledger.AccountBlock, sendBlocksMap map[types.Hash]*ledger.AccountBlock) ([]*accountTxRecord, error) {
+	if accountBlock.BlockType == ledger.BlockTypeGenesisReceive {
+		return nil, nil
+	}
+
+	var records []*accountTxRecord
+	if accountBlock.IsSendBlock() {
+		sendBlocksMap[accountBlock.Hash] = accountBlock
+		records = append(records, &accountTxRecord{
+			address:      accountBlock.AccountAddress,
+			counterparty: accountBlock.ToAddress,
+			direction:    DirectionOut,
+			tokenId:      accountBlock.TokenId,
+			hash:         accountBlock.Hash,
+			height:       accountBlock.Height,
+		})
+	} else {
+		sendBlock, ok := sendBlocksMap[accountBlock.FromBlockHash]
+		if !ok {
+			var err error
+			if sendBlock, err = at.chain.GetAccountBlockByHash(accountBlock.FromBlockHash); err != nil {
+				return nil, fmt.Errorf("at.chain.GetAccountBlockByHash failed. Error: %s", err)
+			}
+			if sendBlock == nil {
+				return nil, fmt.Errorf("send block %s is nil", accountBlock.FromBlockHash)
+			}
+		}
+		records = append(records, &accountTxRecord{
+			address:      accountBlock.AccountAddress,
+			counterparty: sendBlock.AccountAddress,
+			direction:    DirectionIn,
+			tokenId:      sendBlock.TokenId,
+			hash:         accountBlock.Hash,
+			height:       accountBlock.Height,
+		})
+	}
+
+	for i, sendBlock := range accountBlock.SendBlockList {
+		sendBlocksMap[sendBlock.Hash] = sendBlock
+		records = append(records, &accountTxRecord{
+			address:      accountBlock.AccountAddress,
+			counterparty: sendBlock.ToAddress,
+			direction:    DirectionOut,
+			tokenId:      sendBlock.TokenId,
+			hash:         sendBlock.Hash,
+			height:       accountBlock.Height,
+			index:        uint16(i + 1),
+		})
+	}
+	return records, nil
+}
+
+func (record *accountTxRecord) value() []byte {
+	value := make([]byte, 0, accountTxValueSize)
+	value = append(value, record.direction)
+	value = append(value, record.counterparty.Bytes()...)
+	value = append(value, record.tokenId.Bytes()...)
+	value = append(value, record.hash.Bytes()...)
+	return value
+}
+
+func createAccountTxPrefixKey(addr types.Address) []byte {
+	key := make([]byte, 0, 1+types.AddressSize)
+	key = append(key, AccountTxKeyPrefix)
+	key = append(key, addr.Bytes()...)
+	return key
+}
+
+func createAccountTxKey(record *accountTxRecord, timestamp int64) []byte {
+	key := make([]byte, 0, 1+types.AddressSize+8+8+2)
+	key = append(key, createAccountTxPrefixKey(record.address)...)
+	return appendAccountTxSuffix(key, record, timestamp)
+}
+
+func createAccountCounterpartyTxPrefixKey(addr, counterparty types.Address) []byte {
+	key := make([]byte, 0, 1+2*types.AddressSize)
+	key = append(key, AccountCounterpartyTxKeyPrefix)
+	key = append(key, addr.Bytes()...)
+	key = append(key, counterparty.Bytes()...)
+	return key
+}
+
+func createAccountCounterpartyTxKey(record *accountTxRecord, timestamp int64) []byte {
+	key := make([]byte, 0, 1+2*types.AddressSize+8+8+2)
+	key = append(key, createAccountCounterpartyTxPrefixKey(record.address, record.counterparty)...)
+	return appendAccountTxSuffix(key, record, timestamp)
+}
+
+func appendAccountTxSuffix(key []byte, record *accountTxRecord, timestamp int64) []byte {
+	key = append(key, chain_utils.Uint64ToBytes(uint64(timestamp))...)
+	key = append(key, chain_utils.Uint64ToBytes(record.height)...)
+	return append(key, byte(record.index>>8), byte(record.index))
+}
@@ -0,0 +1,117 @@
+package chain_plugins
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
+)
+
+type blockHashChain struct {
+	Chain
+	blocks map[types.Hash]*ledger.AccountBlock
+}
+
+func (c *blockHashChain) GetAccountBlockByHash(blockHash types.Hash) (*ledger.AccountBlock, error) {
+	return c.blocks[blockHash], nil
+}
+
+func TestAccountTx(t *testing.T) {
+	dir, err := ioutil.TempDir("", "account_tx")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := chain_db.NewStore(dir, "plugins")
+	assert.NoError(t, err)
+	defer store.Close()
+
+	caller1, _ := types.BytesToAddress(append(make([]byte, types.AddressSize-2), 1, 0))
+	caller2, _ := types.BytesToAddress(append(make([]byte, types.AddressSize-2), 2, 0))
+	contract1, _ := types.BytesToAddress(append(make([]byte, types.AddressSize-2), 1, 1))
+
+	c := &blockHashChain{blocks: make(map[types.Hash]*ledger.AccountBlock)}
+	at := newAccountTx(store, c).(*AccountTx)
+
+	newBlock := func(blockType byte, addr, to types.Address, height uint64, tokenId types.TokenTypeId, fromHash types.Hash) *ledger.AccountBlock {
+		block := &ledger.AccountBlock{BlockType: blockType, AccountAddress: addr, ToAddress: to, Height: height, TokenId: tokenId, FromBlockHash: fromHash}
+		block.Hash = types.DataHash(append(addr.Bytes(), byte(height)))
+		c.blocks[block.Hash] = block
+		return block
+	}
+	snapshot := func(height uint64, seconds int64) *ledger.SnapshotBlock {
+		timestamp := time.Unix(seconds, 0)
+		return &ledger.SnapshotBlock{Height: height, Timestamp: &timestamp}
+	}
+
+	// caller1 sends vite and vcp to contract1, contract1 receives the vite and sends it to caller2
+	send1 := newBlock(ledger.BlockTypeSendCall, caller1, contract1, 1, ledger.ViteTokenId, types.Hash{})
+	send2 := newBlock(ledger.BlockTypeSendCall, caller1, contract1, 2, ledger.VCPTokenId, types.Hash{})
+	receive1 := newBlock(ledger.BlockTypeReceive, contract1, types.Address{}, 1, types.TokenTypeId{}, send1.Hash)
+	contractSend := &ledger.AccountBlock{BlockType: ledger.BlockTypeSendCall, AccountAddress: contract1, ToAddress: caller2, TokenId: ledger.ViteTokenId}
+	contractSend.Hash = types.DataHash([]byte("contractSend"))
+	receive1.SendBlockList = []*ledger.AccountBlock{contractSend}
+	receive2 := newBlock(ledger.BlockTypeReceive, caller2, types.Address{}, 1, types.TokenTypeId{}, contractSend.Hash)
+
+	batch := store.NewBatch()
+	assert.NoError(t, at.InsertSnapshotBlock(batch, snapshot(1, 100), []*ledger.AccountBlock{send1, send2}))
+	store.WriteDirectly(batch)
+	chunk2 := &ledger.SnapshotChunk{SnapshotBlock: snapshot(2, 200), AccountBlocks: []*ledger.AccountBlock{receive1, receive2}}
+	batch = store.NewBatch()
+	assert.NoError(t, at.InsertSnapshotBlock(batch, chunk2.SnapshotBlock, chunk2.AccountBlocks))
+	store.WriteDirectly(batch)
+
+	hashes, err := at.GetBlockHashes(caller1, AccountTxFilter{}, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Hash{send2.Hash, send1.Hash}, hashes)
+
+	hashes, err = at.GetBlockHashes(caller1, AccountTxFilter{TokenId: &ledger.ViteTokenId}, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Hash{send1.Hash}, hashes)
+
+	hashes, err = at.GetBlockHashes(caller1, AccountTxFilter{}, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Hash{send1.Hash}, hashes)
+
+	hashes, err = at.GetBlockHashes(contract1, AccountTxFilter{}, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Hash{contractSend.Hash, receive1.Hash}, hashes)
+
+	hashes, err = at.GetBlockHashes(contract1, AccountTxFilter{Direction: DirectionIn, Counterparty: &caller1}, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Hash{receive1.Hash}, hashes)
+
+	hashes, err = at.GetBlockHashes(contract1, AccountTxFilter{Direction: DirectionOut, Counterparty: &caller1}, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(hashes))
+
+	hashes, err = at.GetBlockHashes(caller2, AccountTxFilter{Counterparty: &contract1, StartTime: 200, EndTime: 201}, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Hash{receive2.Hash}, hashes)
+
+	hashes, err = at.GetBlockHashes(caller2, AccountTxFilter{StartTime: 100, EndTime: 200}, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(hashes))
+
+	// rollback the second snapshot block
+	batch = store.NewBatch()
+	assert.NoError(t, at.DeleteSnapshotBlocks(batch, []*ledger.SnapshotChunk{chunk2}))
+	store.RollbackSnapshot(batch)
+
+	hashes, err = at.GetBlockHashes(contract1, AccountTxFilter{}, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(hashes))
+
+	hashes, err = at.GetBlockHashes(caller2, AccountTxFilter{Counterparty: &contract1}, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(hashes))
+
+	hashes, err = at.GetBlockHashes(caller1, AccountTxFilter{Counterparty: &contract1}, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(hashes))
+}
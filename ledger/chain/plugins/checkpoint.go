@@ -0,0 +1,111 @@
+package chain_plugins
+
+import (
+	"fmt"
+
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/v2/ledger/chain/db"
+	chain_utils "github.com/vitelabs/go-vite/v2/ledger/chain/utils"
+)
+
+const (
+	// PluginStatusClearing means the old data of the plugin is being deleted
+	PluginStatusClearing = byte(1)
+	// PluginStatusBuilding means the plugin is catching up to the latest snapshot block in the background
+	PluginStatusBuilding = byte(2)
+	// PluginStatusLive means the plugin is updated by the inserted and deleted blocks
+	PluginStatusLive = byte(3)
+)
+
+var pluginStatusText = map[byte]string{
+	PluginStatusClearing: "clearing",
+	PluginStatusBuilding: "building",
+	PluginStatusLive:     "live",
+}
+
+// the plugins built before checkpoints were added
+var legacyPlugins = []string{"filterToken", "onRoadInfo"}
+
+type PluginStatus struct {
+	Name   string
+	Status string
+	// Height is the snapshot height the data of the plugin has been built to
+	Height uint64
+}
+
+// pluginCheckpoint is the build progress of a plugin persisted in the plugins store
+type pluginCheckpoint struct {
+	status byte
+	height uint64
+}
+
+func (cp *pluginCheckpoint) serialize() []byte {
+	return append([]byte{cp.status}, chain_utils.Uint64ToBytes(cp.height)...)
+}
+
+func deserializePluginCheckpoint(buf []byte) (*pluginCheckpoint, error) {
+	if len(buf) != 9 {
+		return nil, fmt.Errorf("invalid plugin checkpoint %x", buf)
+	}
+	if _, ok := pluginStatusText[buf[0]]; !ok {
+		return nil, fmt.Errorf("unknown plugin status %d", buf[0])
+	}
+	return &pluginCheckpoint{
+		status: buf[0],
+		height: chain_utils.BytesToUint64(buf[1:]),
+	}, nil
+}
+
+// loadCheckpoints reads the checkpoints of the plugins, the plugins without a checkpoint are rebuilt from scratch.
+// A store with data but no checkpoints was built by an old version, the legacy plugins of it are live.
+func loadCheckpoints(store *chain_db.Store, names []string) (map[string]*pluginCheckpoint, error) {
+	checkpoints := make(map[string]*pluginCheckpoint, len(names))
+
+	iter := store.NewIterator(util.BytesPrefix([]byte{PluginStatusKeyPrefix}))
+	for iter.Next() {
+		cp, err := deserializePluginCheckpoint(iter.Value())
+		if err != nil {
+			iter.Release()
+			return nil, err
+		}
+		checkpoints[string(iter.Key()[1:])] = cp
+	}
+	err := iter.Error()
+	iter.Release()
+	if err != nil {
+		return nil, err
+	}
+
+	legacy := false
+	if len(checkpoints) == 0 {
+		iter := store.NewIterator(nil)
+		legacy = iter.Next()
+		err := iter.Error()
+		iter.Release()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	batch := store.NewBatch()
+	for _, name := range names {
+		if _, ok := checkpoints[name]; ok {
+			continue
+		}
+		cp := &pluginCheckpoint{status: PluginStatusClearing}
+		if legacy {
+			for _, legacyName := range legacyPlugins {
+				if name == legacyName {
+					cp.status = PluginStatusLive
+				}
+			}
+		}
+		checkpoints[name] = cp
+		batch.Put(createPluginStatusKey(name), cp.serialize())
+	}
+	if batch.Len() > 0 {
+		store.WriteDirectly(batch)
+	}
+
+	return checkpoints, nil
+}
@@ -0,0 +1,145 @@
+package chain_plugins
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+type subLedgerChain struct {
+	Chain
+	chunks []*ledger.SnapshotChunk
+}
+
+func (c *subLedgerChain) StopWrite()    {}
+func (c *subLedgerChain) RecoverWrite() {}
+
+func (c *subLedgerChain) GetLatestSnapshotBlock() *ledger.SnapshotBlock {
+	return c.chunks[len(c.chunks)-1].SnapshotBlock
+}
+
+func (c *subLedgerChain) GetSubLedger(startHeight, endHeight uint64) ([]*ledger.SnapshotChunk, error) {
+	var chunks []*ledger.SnapshotChunk
+	for _, chunk := range c.chunks {
+		if chunk.SnapshotBlock.Height >= startHeight && chunk.SnapshotBlock.Height <= endHeight {
+			chunks = append(chunks, chunk)
+		}
+	}
+	return chunks, nil
+}
+
+func (c *subLedgerChain) GetAllUnconfirmedBlocks() []*ledger.AccountBlock {
+	return nil
+}
+
+func TestLoadCheckpoints(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plugin_checkpoint")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := chain_db.NewStore(dir, "plugins")
+	assert.NoError(t, err)
+	defer store.Close()
+
+	// legacy data without checkpoints
+	batch := store.NewBatch()
+	batch.Put([]byte{OnRoadInfoKeyPrefix, 1}, []byte{1})
+	store.WriteDirectly(batch)
+
+	checkpoints, err := loadCheckpoints(store, []string{"accountTx", "filterToken", "onRoadInfo"})
+	assert.NoError(t, err)
+	assert.Equal(t, PluginStatusClearing, checkpoints["accountTx"].status)
+	assert.Equal(t, PluginStatusLive, checkpoints["filterToken"].status)
+	assert.Equal(t, PluginStatusLive, checkpoints["onRoadInfo"].status)
+
+	// a new plugin is added
+	checkpoints, err = loadCheckpoints(store, []string{"accountTx", "dexTrade", "filterToken", "onRoadInfo"})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(checkpoints))
+	assert.Equal(t, PluginStatusClearing, checkpoints["dexTrade"].status)
+	assert.Equal(t, PluginStatusLive, checkpoints["onRoadInfo"].status)
+}
+
+func TestPluginsBuild(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plugin_build")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := chain_db.NewStore(dir, "plugins")
+	assert.NoError(t, err)
+	defer store.Close()
+
+	addr, _ := types.BytesToAddress(append(make([]byte, types.AddressSize-2), 1, 0))
+	to, _ := types.BytesToAddress(append(make([]byte, types.AddressSize-2), 2, 0))
+
+	c := &subLedgerChain{}
+	for i := uint64(1); i <= 25; i++ {
+		timestamp := time.Unix(int64(i*10), 0)
+		block := &ledger.AccountBlock{BlockType: ledger.BlockTypeSendCall, AccountAddress: addr, ToAddress: to, Height: i, TokenId: ledger.ViteTokenId}
+		block.Hash = types.DataHash([]byte{byte(i)})
+		c.chunks = append(c.chunks, &ledger.SnapshotChunk{
+			SnapshotBlock: &ledger.SnapshotBlock{Height: i, Timestamp: &timestamp},
+			AccountBlocks: []*ledger.AccountBlock{block},
+		})
+	}
+
+	// stale data of the plugin
+	batch := store.NewBatch()
+	batch.Put([]byte{AccountTxKeyPrefix, 1}, []byte{1})
+	store.WriteDirectly(batch)
+
+	p := &Plugins{
+		chain:   c,
+		store:   store,
+		plugins: map[string]Plugin{"accountTx": newAccountTx(store, c)},
+		wakeCh:  make(chan struct{}, 1),
+		log:     log15.New("module", "chain_plugins"),
+	}
+	p.checkpoints = map[string]*pluginCheckpoint{"accountTx": {status: PluginStatusClearing}}
+
+	busy, err := p.buildRound()
+	assert.NoError(t, err)
+	assert.True(t, busy)
+	assert.Equal(t, []PluginStatus{{Name: "accountTx", Status: "building", Height: 0}}, p.Status())
+
+	busy, err = p.buildRound()
+	assert.NoError(t, err)
+	assert.True(t, busy)
+	assert.Equal(t, []PluginStatus{{Name: "accountTx", Status: "building", Height: 10}}, p.Status())
+
+	// the checkpoint is persisted
+	checkpoints, err := loadCheckpoints(store, []string{"accountTx"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), checkpoints["accountTx"].height)
+
+	// rollback the built snapshot blocks
+	assert.NoError(t, p.PrepareDeleteSnapshotBlocks(c.chunks[7:]))
+	assert.Equal(t, uint64(7), p.checkpoints["accountTx"].height)
+	c.chunks = c.chunks[:7]
+
+	for busy {
+		busy, err = p.buildRound()
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []PluginStatus{{Name: "accountTx", Status: "live", Height: 7}}, p.Status())
+
+	hashes, err := p.GetPlugin("accountTx").(*AccountTx).GetBlockHashes(addr, AccountTxFilter{}, 0, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, len(hashes))
+
+	has, err := store.Has([]byte{AccountTxKeyPrefix, 1})
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	assert.Error(t, p.RebuildPlugin("unknown"))
+	assert.NoError(t, p.RebuildPlugin("accountTx"))
+	assert.Equal(t, PluginStatusClearing, p.checkpoints["accountTx"].status)
+}
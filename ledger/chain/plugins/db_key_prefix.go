@@ -6,8 +6,33 @@ const (
 	OnRoadInfoKeyPrefix = byte(1)
 
 	DiffTokenHash = byte(2)
+
+	DexTradeKeyPrefix = byte(3)
+
+	DexKlineKeyPrefix = byte(4)
+
+	AccountTxKeyPrefix = byte(5)
+
+	AccountCounterpartyTxKeyPrefix = byte(6)
+
+	PluginStatusKeyPrefix = byte(7)
 )
 
+// pluginKeyPrefixes lists the key prefixes of the data of each plugin, used to clear the data of one plugin
+var pluginKeyPrefixes = map[string][]byte{
+	"filterToken": {DiffTokenHash},
+	"onRoadInfo":  {OnRoadInfoKeyPrefix},
+	"dexTrade":    {DexTradeKeyPrefix, DexKlineKeyPrefix},
+	"accountTx":   {AccountTxKeyPrefix, AccountCounterpartyTxKeyPrefix},
+}
+
+func createPluginStatusKey(name string) []byte {
+	key := make([]byte, 0, 1+len(name))
+	key = append(key, PluginStatusKeyPrefix)
+	key = append(key, name...)
+	return key
+}
+
 func CreateOnRoadInfoKey(addr *types.Address, tId *types.TokenTypeId) []byte {
 	key := make([]byte, 0, 1+types.AddressSize+types.TokenTypeIdSize)
 	key = append(key, OnRoadInfoKeyPrefix)
@@ -0,0 +1,348 @@
+package chain_plugins
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain/db"
+	chain_utils "github.com/vitelabs/go-vite/v2/ledger/chain/utils"
+	"github.com/vitelabs/go-vite/v2/log15"
+	"github.com/vitelabs/go-vite/v2/vm/contracts/dex"
+)
+
+var (
+	dtLog = log15.New("plugin", "dex_trade")
+
+	txEventTopic = new(dex.TransactionEvent).GetTopicId()
+
+	ErrUnknownKlineInterval = errors.New("unknown kline interval")
+)
+
+// KlineIntervals are the supported kline intervals in seconds, the index is stored in the kline key.
+var KlineIntervals = []struct {
+	Name    string
+	Seconds int64
+}{
+	{"1m", 60},
+	{"5m", 5 * 60},
+	{"1h", 60 * 60},
+	{"1d", 24 * 60 * 60},
+}
+
+// DexKline is the OHLCV of the trades of a market in [Time, Time + interval).
+type DexKline struct {
+	Time       int64
+	Open       []byte
+	High       []byte
+	Low        []byte
+	Close      []byte
+	Quantity   *big.Int
+	Amount     *big.Int
+	TradeCount uint64
+}
+
+// DexTrade indexes the trades of the dex and builds klines from them.
+// The trades are read from the vm logs of the dex trade contract, so the node must save the vm logs of
+// types.AddressDexTrade by VmLogWhiteList or VmLogAll. Only the confirmed blocks are indexed, because the
+// vm logs of an account block are written after the plugins prepared it.
+type DexTrade struct {
+	store *chain_db.Store
+	chain Chain
+}
+
+func newDexTrade(store *chain_db.Store, chain Chain) Plugin {
+	return &DexTrade{
+		store: store,
+		chain: chain,
+	}
+}
+
+func (dt *DexTrade) SetStore(store *chain_db.Store) {
+	dt.store = store
+}
+
+func (dt *DexTrade) InsertAccountBlock(batch *leveldb.Batch, accountBlock *ledger.AccountBlock) error {
+	return nil
+}
+
+func (dt *DexTrade) InsertSnapshotBlock(batch *leveldb.Batch, snapshotBlock *ledger.SnapshotBlock, confirmedBlocks []*ledger.AccountBlock) error {
+	klines := make(map[string]*DexKline)
+	for _, block := range confirmedBlocks {
+		trades, err := dt.getTrades(block)
+		if err != nil {
+			return err
+		}
+		for _, trade := range trades {
+			batch.Put(trade.key, trade.data)
+
+			for i, interval := range KlineIntervals {
+				klineTime := trade.Timestamp - trade.Timestamp%interval.Seconds
+				key := createDexKlineKey(trade.marketId, byte(i), klineTime)
+				kline, ok := klines[string(key)]
+				if !ok {
+					if kline, err = dt.getKline(key); err != nil {
+						return err
+					}
+					if kline == nil {
+						kline = &DexKline{Time: klineTime}
+					}
+					klines[string(key)] = kline
+				}
+				kline.add(trade.TransactionEvent)
+			}
+		}
+	}
+	for key, kline := range klines {
+		batch.Put([]byte(key), kline.serialize())
+	}
+	return nil
+}
+
+// DeleteAccountBlocks does nothing, the unconfirmed blocks are not indexed.
+func (dt *DexTrade) DeleteAccountBlocks(batch *leveldb.Batch, accountBlocks []*ledger.AccountBlock) error {
+	return nil
+}
+
+// DeleteSnapshotBlocks deletes the trades of the confirmed blocks and rebuilds the klines they were in
+// from the remaining trades.
+func (dt *DexTrade) DeleteSnapshotBlocks(batch *leveldb.Batch, chunks []*ledger.SnapshotChunk) error {
+	deleted := make(map[string]struct{})
+	klineKeys := make(map[string]struct{})
+	for _, chunk := range chunks {
+		if chunk.SnapshotBlock == nil {
+			continue
+		}
+		for _, block := range chunk.AccountBlocks {
+			trades, err := dt.getTrades(block)
+			if err != nil {
+				return err
+			}
+			for _, trade := range trades {
+				deleted[string(trade.key)] = struct{}{}
+				batch.Delete(trade.key)
+				for i, interval := range KlineIntervals {
+					klineKeys[string(createDexKlineKey(trade.marketId, byte(i), trade.Timestamp-trade.Timestamp%interval.Seconds))] = struct{}{}
+				}
+			}
+		}
+	}
+
+	for key := range klineKeys {
+		kline, err := dt.rebuildKline([]byte(key), deleted)
+		if err != nil {
+			return err
+		}
+		if kline == nil {
+			batch.Delete([]byte(key))
+		} else {
+			batch.Put([]byte(key), kline.serialize())
+		}
+	}
+	return nil
+}
+
+func (dt *DexTrade) RemoveNewUnconfirmed(*leveldb.Batch, []*ledger.AccountBlock) error {
+	return nil
+}
+
+// GetTrades returns at most limit trades of the market in [startTime, endTime), the latest trade first.
+func (dt *DexTrade) GetTrades(marketId int32, startTime, endTime int64, limit int) ([]*dex.TransactionEvent, error) {
+	iter := dt.store.NewIterator(&util.Range{Start: createDexTradeTimeKey(marketId, startTime), Limit: createDexTradeTimeKey(marketId, endTime)})
+	defer iter.Release()
+
+	trades := make([]*dex.TransactionEvent, 0)
+	for ok := iter.Last(); ok && len(trades) < limit; ok = iter.Prev() {
+		trade := &dex.TransactionEvent{}
+		if err := trade.FromBytes(iter.Value()); err != nil {
+			return nil, err
+		}
+		trades = append(trades, trade)
+	}
+	return trades, iter.Error()
+}
+
+// GetKlines returns the latest limit klines of the market in [startTime, endTime), in ascending order of time.
+func (dt *DexTrade) GetKlines(marketId int32, interval string, startTime, endTime int64, limit int) ([]*DexKline, error) {
+	index := -1
+	for i, v := range KlineIntervals {
+		if v.Name == interval {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, ErrUnknownKlineInterval
+	}
+
+	iter := dt.store.NewIterator(&util.Range{Start: createDexKlineKey(marketId, byte(index), startTime), Limit: createDexKlineKey(marketId, byte(index), endTime)})
+	defer iter.Release()
+
+	klines := make([]*DexKline, 0)
+	for ok := iter.Last(); ok && len(klines) < limit; ok = iter.Prev() {
+		kline, err := deserializeDexKline(iter.Key(), iter.Value())
+		if err != nil {
+			return nil, err
+		}
+		klines = append(klines, kline)
+	}
+	for i, j := 0, len(klines)-1; i < j; i, j = i+1, j-1 {
+		klines[i], klines[j] = klines[j], klines[i]
+	}
+	return klines, iter.Error()
+}
+
+type dexTradeRecord struct {
+	*dex.TransactionEvent
+	marketId int32
+	key      []byte
+	data     []byte
+}
+
+func (dt *DexTrade) getTrades(block *ledger.AccountBlock) ([]*dexTradeRecord, error) {
+	if block.AccountAddress != types.AddressDexTrade || block.LogHash == nil {
+		return nil, nil
+	}
+	logList, err := dt.chain.GetVmLogList(block.LogHash)
+	if err != nil {
+		return nil, fmt.Errorf("dt.chain.GetVmLogList failed. Error: %s", err)
+	}
+
+	var trades []*dexTradeRecord
+	for index, log := range logList {
+		if len(log.Topics) == 0 || log.Topics[0] != txEventTopic {
+			continue
+		}
+		trade := &dexTradeRecord{TransactionEvent: &dex.TransactionEvent{}, data: log.Data}
+		if err := trade.FromBytes(log.Data); err != nil {
+			dtLog.Error(fmt.Sprintf("decode trade failed, err:%v, block[%v %v]", err, block.Height, block.Hash), "method", "getTrades")
+			continue
+		}
+		if trade.marketId, _, _, _, err = dex.DeComposeOrderId(trade.TakerId); err != nil {
+			dtLog.Error(fmt.Sprintf("decode taker id failed, err:%v, block[%v %v]", err, block.Height, block.Hash), "method", "getTrades")
+			continue
+		}
+		trade.key = createDexTradeKey(trade.marketId, trade.Timestamp, block.Height, uint32(index))
+		trades = append(trades, trade)
+	}
+	return trades, nil
+}
+
+func (dt *DexTrade) getKline(key []byte) (*DexKline, error) {
+	value, err := dt.store.Get(key)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	return deserializeDexKline(key, value)
+}
+
+// rebuildKline builds the kline of key from the stored trades except the deleted ones, it returns nil if no trade is left.
+func (dt *DexTrade) rebuildKline(key []byte, deleted map[string]struct{}) (*DexKline, error) {
+	marketId := int32(binary.BigEndian.Uint32(key[1:5]))
+	startTime := int64(binary.BigEndian.Uint64(key[6:14]))
+	endTime := startTime + KlineIntervals[key[5]].Seconds
+
+	iter := dt.store.NewIterator(&util.Range{Start: createDexTradeTimeKey(marketId, startTime), Limit: createDexTradeTimeKey(marketId, endTime)})
+	defer iter.Release()
+
+	var kline *DexKline
+	for iter.Next() {
+		if _, ok := deleted[string(iter.Key())]; ok {
+			continue
+		}
+		trade := &dex.TransactionEvent{}
+		if err := trade.FromBytes(iter.Value()); err != nil {
+			return nil, err
+		}
+		if kline == nil {
+			kline = &DexKline{Time: startTime}
+		}
+		kline.add(trade)
+	}
+	return kline, iter.Error()
+}
+
+func (kline *DexKline) add(trade *dex.TransactionEvent) {
+	price := trade.Price
+	if kline.TradeCount == 0 {
+		kline.Open, kline.High, kline.Low = price, price, price
+		kline.Quantity, kline.Amount = new(big.Int), new(big.Int)
+	}
+	if bytes.Compare(price, kline.High) > 0 {
+		kline.High = price
+	}
+	if bytes.Compare(price, kline.Low) < 0 {
+		kline.Low = price
+	}
+	kline.Close = price
+	kline.Quantity.Add(kline.Quantity, new(big.Int).SetBytes(trade.Quantity))
+	kline.Amount.Add(kline.Amount, new(big.Int).SetBytes(trade.Amount))
+	kline.TradeCount++
+}
+
+// serialize encodes the kline as open|high|low|close|tradeCount|len(quantity)|quantity|amount.
+func (kline *DexKline) serialize() []byte {
+	quantity, amount := kline.Quantity.Bytes(), kline.Amount.Bytes()
+	buf := make([]byte, 0, 4*dex.PriceBytesLength+9+len(quantity)+len(amount))
+	for _, price := range [][]byte{kline.Open, kline.High, kline.Low, kline.Close} {
+		buf = append(buf, padPrice(price)...)
+	}
+	buf = append(buf, chain_utils.Uint64ToBytes(kline.TradeCount)...)
+	buf = append(buf, byte(len(quantity)))
+	buf = append(buf, quantity...)
+	return append(buf, amount...)
+}
+
+func deserializeDexKline(key, value []byte) (*DexKline, error) {
+	pricesLen := 4 * dex.PriceBytesLength
+	if len(key) != 14 || int(key[5]) >= len(KlineIntervals) || len(value) < pricesLen+9 || len(value) < pricesLen+9+int(value[pricesLen+8]) {
+		return nil, errors.New("invalid dex kline")
+	}
+	kline := &DexKline{Time: int64(binary.BigEndian.Uint64(key[6:14]))}
+	prices := make([][]byte, 4)
+	for i := range prices {
+		prices[i] = value[i*dex.PriceBytesLength : (i+1)*dex.PriceBytesLength]
+	}
+	kline.Open, kline.High, kline.Low, kline.Close = prices[0], prices[1], prices[2], prices[3]
+	kline.TradeCount = binary.BigEndian.Uint64(value[pricesLen : pricesLen+8])
+	quantityEnd := pricesLen + 9 + int(value[pricesLen+8])
+	kline.Quantity = new(big.Int).SetBytes(value[pricesLen+9 : quantityEnd])
+	kline.Amount = new(big.Int).SetBytes(value[quantityEnd:])
+	return kline, nil
+}
+
+func padPrice(price []byte) []byte {
+	padded := make([]byte, dex.PriceBytesLength)
+	copy(padded[dex.PriceBytesLength-len(price):], price)
+	return padded
+}
+
+func createDexTradeTimeKey(marketId int32, timestamp int64) []byte {
+	key := make([]byte, 0, 1+4+8)
+	key = append(key, DexTradeKeyPrefix)
+	key = append(key, dex.Uint32ToBytes(uint32(marketId))...)
+	key = append(key, chain_utils.Uint64ToBytes(uint64(timestamp))...)
+	return key
+}
+
+func createDexTradeKey(marketId int32, timestamp int64, height uint64, index uint32) []byte {
+	key := make([]byte, 0, 1+4+8+8+4)
+	key = append(key, createDexTradeTimeKey(marketId, timestamp)...)
+	key = append(key, chain_utils.Uint64ToBytes(height)...)
+	key = append(key, dex.Uint32ToBytes(index)...)
+	return key
+}
+
+func createDexKlineKey(marketId int32, interval byte, timestamp int64) []byte {
+	key := make([]byte, 0, 1+4+1+8)
+	key = append(key, DexKlineKeyPrefix)
+	key = append(key, dex.Uint32ToBytes(uint32(marketId))...)
+	key = append(key, interval)
+	key = append(key, chain_utils.Uint64ToBytes(uint64(timestamp))...)
+	return key
+}
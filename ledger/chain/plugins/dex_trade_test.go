@@ -0,0 +1,134 @@
+package chain_plugins
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
+	"github.com/vitelabs/go-vite/v2/vm/contracts/dex"
+	dexproto "github.com/vitelabs/go-vite/v2/vm/contracts/dex/proto"
+)
+
+type logListChain struct {
+	Chain
+	logs map[types.Hash]ledger.VmLogList
+}
+
+func (c *logListChain) GetVmLogList(logListHash *types.Hash) (ledger.VmLogList, error) {
+	return c.logs[*logListHash], nil
+}
+
+func newTradeBlock(t *testing.T, c *logListChain, height uint64, trades ...*dexproto.Transaction) *ledger.AccountBlock {
+	logHash := types.DataHash([]byte{byte(height)})
+	block := &ledger.AccountBlock{AccountAddress: types.AddressDexTrade, Height: height, Hash: logHash, LogHash: &logHash}
+	for _, trade := range trades {
+		data, err := proto.Marshal(trade)
+		assert.NoError(t, err)
+		c.logs[logHash] = append(c.logs[logHash], &ledger.VmLog{Topics: []types.Hash{txEventTopic}, Data: data})
+	}
+	return block
+}
+
+func newTrade(marketId int32, price string, quantity, amount byte, timestamp int64) *dexproto.Transaction {
+	takerId := make([]byte, dex.OrderIdBytesLength)
+	copy(takerId, dex.Uint32ToBytes(uint32(marketId))[1:])
+	return &dexproto.Transaction{
+		TakerId:   takerId,
+		Price:     dex.PriceToBytes(price),
+		Quantity:  []byte{quantity},
+		Amount:    []byte{amount},
+		Timestamp: timestamp,
+	}
+}
+
+func TestDexTrade(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dex_trade")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := chain_db.NewStore(dir, "plugins")
+	assert.NoError(t, err)
+	defer store.Close()
+
+	c := &logListChain{logs: make(map[types.Hash]ledger.VmLogList)}
+	dt := newDexTrade(store, c).(*DexTrade)
+
+	block1 := newTradeBlock(t, c, 1, newTrade(1, "1.5", 10, 15, 120), newTrade(1, "1.8", 1, 2, 130), newTrade(2, "3", 1, 3, 130))
+	block2 := newTradeBlock(t, c, 2, newTrade(1, "1.2", 5, 6, 170), newTrade(1, "1.6", 2, 3, 190))
+	other := &ledger.AccountBlock{AccountAddress: types.AddressDexFund, Height: 1}
+
+	batch := store.NewBatch()
+	assert.NoError(t, dt.InsertSnapshotBlock(batch, &ledger.SnapshotBlock{Height: 1}, []*ledger.AccountBlock{block1, other}))
+	store.WriteDirectly(batch)
+	batch = store.NewBatch()
+	assert.NoError(t, dt.InsertSnapshotBlock(batch, &ledger.SnapshotBlock{Height: 2}, []*ledger.AccountBlock{block2}))
+	store.WriteDirectly(batch)
+
+	trades, err := dt.GetTrades(1, 0, 1000, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(trades))
+	assert.Equal(t, int64(190), trades[0].Timestamp)
+	assert.Equal(t, int64(120), trades[3].Timestamp)
+
+	trades, err = dt.GetTrades(1, 130, 180, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(trades))
+
+	klines, err := dt.GetKlines(1, "1m", 0, 1000, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(klines))
+	assert.Equal(t, int64(120), klines[0].Time)
+	assert.Equal(t, "1.5", dex.BytesToPrice(klines[0].Open))
+	assert.Equal(t, "1.8", dex.BytesToPrice(klines[0].High))
+	assert.Equal(t, "1.2", dex.BytesToPrice(klines[0].Low))
+	assert.Equal(t, "1.2", dex.BytesToPrice(klines[0].Close))
+	assert.Equal(t, int64(16), klines[0].Quantity.Int64())
+	assert.Equal(t, int64(23), klines[0].Amount.Int64())
+	assert.Equal(t, uint64(3), klines[0].TradeCount)
+	assert.Equal(t, int64(180), klines[1].Time)
+	assert.Equal(t, uint64(1), klines[1].TradeCount)
+
+	klines, err = dt.GetKlines(1, "1m", 0, 1000, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(klines))
+	assert.Equal(t, int64(180), klines[0].Time)
+
+	klines, err = dt.GetKlines(1, "1h", 0, 3600, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(klines))
+	assert.Equal(t, "1.6", dex.BytesToPrice(klines[0].Close))
+	assert.Equal(t, uint64(4), klines[0].TradeCount)
+
+	_, err = dt.GetKlines(1, "2m", 0, 1000, 10)
+	assert.Equal(t, ErrUnknownKlineInterval, err)
+
+	// rollback the second snapshot block
+	batch = store.NewBatch()
+	assert.NoError(t, dt.DeleteSnapshotBlocks(batch, []*ledger.SnapshotChunk{
+		{SnapshotBlock: &ledger.SnapshotBlock{Height: 2}, AccountBlocks: []*ledger.AccountBlock{block2}},
+	}))
+	store.RollbackSnapshot(batch)
+
+	trades, err = dt.GetTrades(1, 0, 1000, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(trades))
+
+	klines, err = dt.GetKlines(1, "1m", 0, 1000, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(klines))
+	assert.Equal(t, "1.5", dex.BytesToPrice(klines[0].Low))
+	assert.Equal(t, "1.8", dex.BytesToPrice(klines[0].Close))
+	assert.Equal(t, int64(11), klines[0].Quantity.Int64())
+	assert.Equal(t, uint64(2), klines[0].TradeCount)
+
+	klines, err = dt.GetKlines(2, "1d", 0, 86400, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(klines))
+	assert.Equal(t, "3", dex.BytesToPrice(klines[0].Open))
+}
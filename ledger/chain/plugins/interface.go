@@ -10,6 +10,8 @@ import (
 
 type Chain interface {
 	Flusher() *chain_flusher.Flusher
+	StopWrite()
+	RecoverWrite()
 	GetLatestSnapshotBlock() *ledger.SnapshotBlock
 	GetSnapshotBlocksByHeight(height uint64, higher bool, count uint64) ([]*ledger.SnapshotBlock, error)
 	GetSubLedgerAfterHeight(height uint64) ([]*ledger.SnapshotChunk, error)
@@ -22,6 +24,8 @@ type Chain interface {
 	GetAllUnconfirmedBlocks() []*ledger.AccountBlock
 
 	LoadAllOnRoad() (map[types.Address][]types.Hash, error)
+
+	GetVmLogList(logListHash *types.Hash) (ledger.VmLogList, error)
 }
 
 type Plugin interface {
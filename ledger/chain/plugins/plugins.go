@@ -3,18 +3,27 @@ package chain_plugins
 import (
 	"errors"
 	"fmt"
-	"os"
 	"path"
+	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
 	"github.com/vitelabs/go-vite/v2/interfaces"
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
 	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
 	"github.com/vitelabs/go-vite/v2/log15"
 )
 
-const roundSize = uint64(10)
+const (
+	roundSize = uint64(10)
+
+	// the max count of keys deleted in one round of clearing
+	clearSize = 10000
+
+	buildInterval = 5 * time.Second
+)
 
 const (
 	stop  = 0
@@ -29,13 +38,17 @@ type Plugins struct {
 	store   *chain_db.Store
 	plugins map[string]Plugin
 
-	writeStatus uint32
-	mu          sync.RWMutex
+	checkpoints map[string]*pluginCheckpoint
+
+	status  uint32
+	wakeCh  chan struct{}
+	closeCh chan struct{}
+	wg      sync.WaitGroup
+
+	mu sync.RWMutex
 }
 
 func NewPlugins(chainDir string, chain Chain) (*Plugins, error) {
-	var err error
-
 	dataDir := path.Join(chainDir, "plugins")
 
 	store, err := chain_db.NewStore(dataDir, "plugins")
@@ -43,135 +56,339 @@ func NewPlugins(chainDir string, chain Chain) (*Plugins, error) {
 		return nil, err
 	}
 
+	return NewPluginsWithStore(dataDir, store, chain)
+}
+
+func NewPluginsWithStore(dataDir string, store *chain_db.Store, chain Chain) (*Plugins, error) {
+	var err error
+
 	plugins := map[string]Plugin{
 		"filterToken": newFilterToken(store, chain),
 		"onRoadInfo":  newOnRoadInfo(store, chain),
+		"dexTrade":    newDexTrade(store, chain),
+		"accountTx":   newAccountTx(store, chain),
 	}
 
-	return &Plugins{
-		dataDir:     dataDir,
-		chain:       chain,
-		store:       store,
-		plugins:     plugins,
-		writeStatus: start,
-		log:         log15.New("module", "chain_plugins"),
-	}, nil
+	p := &Plugins{
+		dataDir: dataDir,
+		chain:   chain,
+		store:   store,
+		plugins: plugins,
+		status:  stop,
+		wakeCh:  make(chan struct{}, 1),
+		log:     log15.New("module", "chain_plugins"),
+	}
+
+	if p.checkpoints, err = loadCheckpoints(store, p.names()); err != nil {
+		store.Close()
+		return nil, err
+	}
+	return p, nil
 }
 
-func (p *Plugins) StopWrite() {
-	if !atomic.CompareAndSwapUint32(&p.writeStatus, start, stop) {
+// Start builds the plugins that are not live in the background, the node keeps inserting blocks meanwhile.
+func (p *Plugins) Start() {
+	if !atomic.CompareAndSwapUint32(&p.status, stop, start) {
 		return
 	}
 
-	p.mu.Lock()
+	p.closeCh = make(chan struct{})
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(buildInterval)
+		defer ticker.Stop()
+		for {
+			if err := p.buildData(p.closeCh); err != nil {
+				p.log.Error(fmt.Sprintf("build plugin data failed. Error: %s", err), "method", "Start")
+			}
+
+			select {
+			case <-p.closeCh:
+				return
+			case <-p.wakeCh:
+			case <-ticker.C:
+			}
+		}
+	}()
 }
 
-func (p *Plugins) StartWrite() {
-	if !atomic.CompareAndSwapUint32(&p.writeStatus, stop, start) {
+func (p *Plugins) Stop() {
+	if !atomic.CompareAndSwapUint32(&p.status, start, stop) {
 		return
 	}
 
-	p.mu.Unlock()
+	close(p.closeCh)
+	p.wg.Wait()
 }
 
+// RebuildData rebuilds the data of all plugins, returns after all plugins are live.
 func (p *Plugins) RebuildData() error {
-	p.StopWrite()
-	defer p.StartWrite()
-
 	p.log.Info("Start rebuild plugin data")
 
-	if err := p.store.Close(); err != nil {
-		return err
+	for _, name := range p.names() {
+		if err := p.RebuildPlugin(name); err != nil {
+			return err
+		}
 	}
 
-	// remove data
-	os.RemoveAll(p.dataDir)
-
-	// set new store
-	store, err := chain_db.NewStore(p.dataDir, "plugins")
-	if err != nil {
+	if err := p.BuildData(); err != nil {
 		return err
 	}
 
-	p.store = store
+	// success
+	p.log.Info("Succeed rebuild plugin data")
+	return nil
+}
 
-	for _, plugin := range p.plugins {
-		plugin.SetStore(store)
+// RebuildPlugin clears the data of the plugin and rebuilds it in the background.
+func (p *Plugins) RebuildPlugin(name string) error {
+	p.chain.StopWrite()
+	defer p.chain.RecoverWrite()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	plugin, ok := p.plugins[name]
+	if !ok {
+		return fmt.Errorf("plugin %s is not existed", name)
 	}
+	cp := p.checkpoints[name]
 
-	// replace flusher store
-	flusher := p.chain.Flusher()
-	flusher.ReplaceStore(p.store.Id(), store)
+	if cp.status == PluginStatusLive {
+		// revert the data written for the unconfirmed blocks, those blocks will be built after they are confirmed
+		for _, block := range p.chain.GetAllUnconfirmedBlocks() {
+			batch := p.store.NewBatch()
+			if err := plugin.DeleteAccountBlocks(batch, []*ledger.AccountBlock{block}); err != nil {
+				return err
+			}
+			p.store.AppendAccountBlock(batch, block)
+		}
+	}
 
-	// get latest snapshot block
-	latestSnapshot := p.chain.GetLatestSnapshotBlock()
-	if latestSnapshot == nil {
-		return errors.New("GetLatestSnapshotBlock fail")
+	cp.status = PluginStatusClearing
+	cp.height = 0
+
+	batch := p.store.NewBatch()
+	batch.Put(createPluginStatusKey(name), cp.serialize())
+	p.store.WriteDirectly(batch)
+
+	p.log.Info(fmt.Sprintf("rebuild plugin %s", name), "method", "RebuildPlugin")
+
+	select {
+	case p.wakeCh <- struct{}{}:
+	default:
 	}
+	return nil
+}
 
-	p.log.Info(fmt.Sprintf("latestSnapshot[%v %v]", latestSnapshot.Hash, latestSnapshot.Height), "method", "RebuildData")
+// BuildData builds the plugins that are not live up to the latest snapshot block, returns after all plugins are live.
+func (p *Plugins) BuildData() error {
+	return p.buildData(nil)
+}
 
-	// build data
-	h := uint64(0)
+// Status returns the build progress of every plugin.
+func (p *Plugins) Status() []PluginStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 
-	for h < latestSnapshot.Height {
-		targetH := h + roundSize
-		if targetH > latestSnapshot.Height {
-			targetH = latestSnapshot.Height
+	var latestHeight uint64
+	if latestSnapshot := p.chain.GetLatestSnapshotBlock(); latestSnapshot != nil {
+		latestHeight = latestSnapshot.Height
+	}
+
+	names := p.names()
+	statusList := make([]PluginStatus, 0, len(names))
+	for _, name := range names {
+		cp := p.checkpoints[name]
+		status := PluginStatus{
+			Name:   name,
+			Status: pluginStatusText[cp.status],
+			Height: cp.height,
 		}
+		if cp.status == PluginStatusLive {
+			status.Height = latestHeight
+		}
+		statusList = append(statusList, status)
+	}
+	return statusList
+}
 
-		chunks, err := p.chain.GetSubLedger(h, targetH)
+func (p *Plugins) buildData(closeCh chan struct{}) error {
+	for {
+		busy, err := p.buildRound()
 		if err != nil {
 			return err
 		}
+		if !busy {
+			return nil
+		}
 
-		p.log.Info(fmt.Sprintf("rebuild %d - %d", h+1, targetH), "method", "RebuildData")
+		// flush to disk
+		p.chain.Flusher().Flush()
 
-		for _, chunk := range chunks {
+		select {
+		case <-closeCh:
+			return nil
+		default:
+		}
+	}
+}
 
-			if chunk.SnapshotBlock != nil &&
-				chunk.SnapshotBlock.Height == h {
-				continue
-			}
-			// write ab
-			for _, ab := range chunk.AccountBlocks {
+// buildRound clears or builds a few snapshot blocks of each plugin that is not live, returns false if all plugins are live.
+// The chain write is stopped during a round, so no blocks are inserted or deleted meanwhile.
+func (p *Plugins) buildRound() (bool, error) {
+	p.chain.StopWrite()
+	defer p.chain.RecoverWrite()
 
-				batch := p.store.NewBatch()
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-				for _, plugin := range p.plugins {
-					if err := plugin.InsertAccountBlock(batch, ab); err != nil {
-						return err
-					}
-				}
-				p.store.WriteAccountBlock(batch, ab)
+	busy := false
+	for _, name := range p.names() {
+		cp := p.checkpoints[name]
+
+		switch cp.status {
+		case PluginStatusClearing:
+			if err := p.clearPlugin(name, cp); err != nil {
+				return false, err
+			}
+		case PluginStatusBuilding:
+			if err := p.buildPlugin(name, cp); err != nil {
+				return false, err
 			}
+		default:
+			continue
+		}
+		busy = true
+	}
+	return busy, nil
+}
 
-			// write sb
-			batch := p.store.NewBatch()
+func (p *Plugins) clearPlugin(name string, cp *pluginCheckpoint) error {
+	batch := p.store.NewBatch()
 
-			for _, plugin := range p.plugins {
-				if err := plugin.InsertSnapshotBlock(batch, chunk.SnapshotBlock, chunk.AccountBlocks); err != nil {
-					pErr := fmt.Errorf("InsertSnapshotBlock fail, err:%v, sb[%v, %v,len=%v] ", err, chunk.SnapshotBlock.Height, chunk.SnapshotBlock.Hash, len(chunk.AccountBlocks))
-					p.log.Error(pErr.Error(), "method", "RebuildData")
-					return pErr
-				}
-			}
+	count := 0
+	for _, prefix := range pluginKeyPrefixes[name] {
+		iter := p.store.NewIterator(util.BytesPrefix([]byte{prefix}))
+		for count < clearSize && iter.Next() {
+			batch.Delete(append([]byte{}, iter.Key()...))
+			count++
+		}
+		err := iter.Error()
+		iter.Release()
+		if err != nil {
+			return err
+		}
+	}
+
+	if count < clearSize {
+		cp.status = PluginStatusBuilding
+		cp.height = 0
+		p.log.Info(fmt.Sprintf("cleared plugin %s", name), "method", "clearPlugin")
+	}
+
+	batch.Put(createPluginStatusKey(name), cp.serialize())
+	p.store.WriteDirectly(batch)
+	return nil
+}
+
+func (p *Plugins) buildPlugin(name string, cp *pluginCheckpoint) error {
+	plugin := p.plugins[name]
+
+	// get latest snapshot block
+	latestSnapshot := p.chain.GetLatestSnapshotBlock()
+	if latestSnapshot == nil {
+		return errors.New("GetLatestSnapshotBlock fail")
+	}
+
+	if cp.height >= latestSnapshot.Height {
+		return p.switchToLive(name, cp)
+	}
 
-			p.store.WriteSnapshot(batch, chunk.AccountBlocks)
+	targetH := cp.height + roundSize
+	if targetH > latestSnapshot.Height {
+		targetH = latestSnapshot.Height
+	}
+
+	chunks, err := p.chain.GetSubLedger(cp.height, targetH)
+	if err != nil {
+		return err
+	}
 
+	p.log.Info(fmt.Sprintf("build plugin %s %d - %d", name, cp.height+1, targetH), "method", "buildPlugin")
+
+	for _, chunk := range chunks {
+		if chunk.SnapshotBlock == nil ||
+			chunk.SnapshotBlock.Height <= cp.height {
+			continue
 		}
 
-		// flush to disk
-		flusher.Flush()
+		batch := p.store.NewBatch()
 
-		h = targetH
+		for _, ab := range chunk.AccountBlocks {
+			if err := plugin.InsertAccountBlock(batch, ab); err != nil {
+				return err
+			}
+		}
+		if err := plugin.InsertSnapshotBlock(batch, chunk.SnapshotBlock, chunk.AccountBlocks); err != nil {
+			pErr := fmt.Errorf("InsertSnapshotBlock fail, plugin %s, err:%v, sb[%v, %v,len=%v] ", name, err, chunk.SnapshotBlock.Height, chunk.SnapshotBlock.Hash, len(chunk.AccountBlocks))
+			p.log.Error(pErr.Error(), "method", "buildPlugin")
+			return pErr
+		}
+
+		cp.height = chunk.SnapshotBlock.Height
+		batch.Put(createPluginStatusKey(name), cp.serialize())
+
+		p.store.WriteDirectly(batch)
 	}
 
-	// success
-	p.log.Info("Succeed rebuild plugin data")
 	return nil
 }
 
+// switchToLive writes the data of the unconfirmed blocks for the plugin that has caught up to the latest snapshot block.
+func (p *Plugins) switchToLive(name string, cp *pluginCheckpoint) error {
+	plugin := p.plugins[name]
+
+	for _, block := range p.chain.GetAllUnconfirmedBlocks() {
+		batch := p.store.NewBatch()
+		if err := plugin.InsertAccountBlock(batch, block); err != nil {
+			return err
+		}
+		p.store.AppendAccountBlock(batch, block)
+	}
+
+	cp.status = PluginStatusLive
+
+	batch := p.store.NewBatch()
+	batch.Put(createPluginStatusKey(name), cp.serialize())
+	p.store.WriteDirectly(batch)
+
+	p.log.Info(fmt.Sprintf("plugin %s is live at %d", name, cp.height), "method", "switchToLive")
+	return nil
+}
+
+func (p *Plugins) names() []string {
+	names := make([]string, 0, len(p.plugins))
+	for name := range p.plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// livePlugins returns the plugins updated by the inserted and deleted blocks, assume p.mu is locked.
+func (p *Plugins) livePlugins() []Plugin {
+	plugins := make([]Plugin, 0, len(p.plugins))
+	for _, name := range p.names() {
+		if p.checkpoints[name].status == PluginStatusLive {
+			plugins = append(plugins, p.plugins[name])
+		}
+	}
+	return plugins
+}
+
 func (p *Plugins) Close() error {
 	if err := p.store.Close(); err != nil {
 		return err
@@ -188,18 +405,24 @@ func (p *Plugins) GetPlugin(name string) Plugin {
 }
 
 func (p *Plugins) RemovePlugin(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	delete(p.plugins, name)
+	delete(p.checkpoints, name)
 }
 
 func (p *Plugins) PrepareInsertAccountBlocks(vmBlocks []*interfaces.VmAccountBlock) error {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
+	plugins := p.livePlugins()
+
 	// for recover
 	for _, vmBlock := range vmBlocks {
 		batch := p.store.NewBatch()
 
-		for _, plugin := range p.plugins {
+		for _, plugin := range plugins {
 			if err := plugin.InsertAccountBlock(batch, vmBlock.AccountBlock); err != nil {
 				return err
 			}
@@ -213,10 +436,12 @@ func (p *Plugins) PrepareInsertAccountBlocks(vmBlocks []*interfaces.VmAccountBlo
 func (p *Plugins) PrepareInsertSnapshotBlocks(chunks []*ledger.SnapshotChunk) error {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
+
+	plugins := p.livePlugins()
 	for _, chunk := range chunks {
 		batch := p.store.NewBatch()
 
-		for _, plugin := range p.plugins {
+		for _, plugin := range plugins {
 
 			if err := plugin.InsertSnapshotBlock(batch, chunk.SnapshotBlock, chunk.AccountBlocks); err != nil {
 				return err
@@ -233,9 +458,11 @@ func (p *Plugins) PrepareDeleteAccountBlocks(blocks []*ledger.AccountBlock) erro
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
+	plugins := p.livePlugins()
+
 	batch := p.store.NewBatch()
 
-	for _, plugin := range p.plugins {
+	for _, plugin := range plugins {
 		if err := plugin.DeleteAccountBlocks(batch, blocks); err != nil {
 			return err
 		}
@@ -246,17 +473,42 @@ func (p *Plugins) PrepareDeleteAccountBlocks(blocks []*ledger.AccountBlock) erro
 }
 
 func (p *Plugins) PrepareDeleteSnapshotBlocks(chunks []*ledger.SnapshotChunk) error {
-	p.mu.RLock()
-	defer p.mu.RUnlock()
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
 	batch := p.store.NewBatch()
 
-	for _, plugin := range p.plugins {
+	for _, name := range p.names() {
+		plugin := p.plugins[name]
+		cp := p.checkpoints[name]
 
-		if err := plugin.DeleteSnapshotBlocks(batch, chunks); err != nil {
-			return err
+		switch cp.status {
+		case PluginStatusLive:
+			if err := plugin.DeleteSnapshotBlocks(batch, chunks); err != nil {
+				return err
+			}
+		case PluginStatusBuilding:
+			// rollback the built snapshot blocks and the checkpoint
+			builtChunks := make([]*ledger.SnapshotChunk, 0, len(chunks))
+			height := cp.height
+			for _, chunk := range chunks {
+				if chunk.SnapshotBlock == nil || chunk.SnapshotBlock.Height > cp.height {
+					continue
+				}
+				builtChunks = append(builtChunks, chunk)
+				if chunk.SnapshotBlock.Height-1 < height {
+					height = chunk.SnapshotBlock.Height - 1
+				}
+			}
+			if len(builtChunks) <= 0 {
+				continue
+			}
+			if err := plugin.DeleteSnapshotBlocks(batch, builtChunks); err != nil {
+				return err
+			}
+			cp.height = height
+			batch.Put(createPluginStatusKey(name), cp.serialize())
 		}
-
 	}
 	p.store.RollbackSnapshot(batch)
 
@@ -267,11 +519,13 @@ func (p *Plugins) DeleteSnapshotBlocks(chunks []*ledger.SnapshotChunk) error {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
+	plugins := p.livePlugins()
+
 	allUnconfirmedBlocks := p.chain.GetAllUnconfirmedBlocks()
 
 	rollbackBatch := p.store.NewBatch()
 
-	for _, plugin := range p.plugins {
+	for _, plugin := range plugins {
 		if err := plugin.RemoveNewUnconfirmed(rollbackBatch, allUnconfirmedBlocks); err != nil {
 			return err
 		}
@@ -279,15 +533,14 @@ func (p *Plugins) DeleteSnapshotBlocks(chunks []*ledger.SnapshotChunk) error {
 
 	p.store.RollbackSnapshot(rollbackBatch)
 
-	for _, plugin := range p.plugins {
+	for _, unconfirmedBlock := range allUnconfirmedBlocks {
 		batch := p.store.NewBatch()
-		for _, unconfirmedBlock := range allUnconfirmedBlocks {
-			if err := plugin.InsertAccountBlock(rollbackBatch, unconfirmedBlock); err != nil {
+		for _, plugin := range plugins {
+			if err := plugin.InsertAccountBlock(batch, unconfirmedBlock); err != nil {
 				return err
 			}
-			p.store.WriteAccountBlock(batch, unconfirmedBlock)
 		}
-
+		p.store.WriteAccountBlock(batch, unconfirmedBlock)
 	}
 	return nil
 }
@@ -0,0 +1,261 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_block "github.com/vitelabs/go-vite/v2/ledger/chain/block"
+	chain_file_manager "github.com/vitelabs/go-vite/v2/ledger/chain/file_manager"
+)
+
+var errChunkStartUnknown = errors.New("start of the chunk is unknown, the snapshot block record before it is damaged")
+
+// DamagedChunk is a snapshot chunk with a damaged record in the block files,
+// Err is a *chain_block.CorruptionError telling the file and the offset of the record
+type DamagedChunk struct {
+	Height uint64
+	Err    error
+}
+
+func (d *DamagedChunk) String() string {
+	return fmt.Sprintf("snapshot chunk %d: %s", d.Height, d.Err)
+}
+
+// ScrubBlocks re-reads the records of the snapshot chunks from fromHeight to toHeight in the block files,
+// verifies the checksums and the hashes of the blocks, and the locations of the blocks in the index db.
+func (c *chain) ScrubBlocks(fromHeight, toHeight uint64) ([]*DamagedChunk, error) {
+	if fromHeight < 1 {
+		fromHeight = 1
+	}
+	if latest := c.GetLatestSnapshotBlock().Height; toHeight > latest {
+		toHeight = latest
+	}
+	if fromHeight > toHeight {
+		return nil, nil
+	}
+
+	start, err := c.chunkStart(fromHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	var damagedChunks []*DamagedChunk
+	for height := fromHeight; height <= toHeight; height++ {
+		next, damage, err := c.scrubChunk(height, start)
+		if err != nil {
+			return damagedChunks, err
+		}
+		if damage != nil {
+			damagedChunks = append(damagedChunks, &DamagedChunk{Height: height, Err: damage})
+		}
+		start = next
+	}
+
+	return damagedChunks, nil
+}
+
+// chunkStart returns the location of the first record of the snapshot chunk, or nil if the record before it is damaged
+func (c *chain) chunkStart(height uint64) (*chain_file_manager.Location, error) {
+	if height <= 1 {
+		return chain_file_manager.NewLocation(1, 0), nil
+	}
+
+	prevLocation, err := c.indexDB.GetSnapshotBlockLocation(height - 1)
+	if err != nil {
+		return nil, err
+	}
+	if prevLocation == nil {
+		return nil, fmt.Errorf("snapshot block %d is not existed", height-1)
+	}
+
+	next, err := c.blockDB.GetNextLocation(prevLocation)
+	if err != nil {
+		return nil, nil
+	}
+	return next, nil
+}
+
+// scrubChunk verifies the records of the snapshot chunk from start, and returns the location of the next chunk
+// and the damage of the chunk. start is nil if it is unknown.
+func (c *chain) scrubChunk(height uint64, start *chain_file_manager.Location) (next *chain_file_manager.Location, damage error, err error) {
+	hash, sbLocation, err := c.indexDB.GetSnapshotBlockByHeight(height)
+	if err != nil {
+		return nil, nil, err
+	}
+	if hash == nil {
+		return nil, nil, fmt.Errorf("snapshot block %d is not existed", height)
+	}
+
+	if start == nil || start.Compare(sbLocation) > 0 {
+		damage = c.blockDB.Corruption(sbLocation, errChunkStartUnknown)
+	} else {
+		current := start
+		for current.Compare(sbLocation) < 0 {
+			_, ab, nextLocation, rErr := c.blockDB.ReadUnit(current)
+			if rErr != nil {
+				damage = c.blockDB.Corruption(current, rErr)
+				break
+			}
+			if ab == nil {
+				damage = c.blockDB.Corruption(current, errors.New("record is not an account block"))
+				break
+			}
+			if cErr := c.checkAccountBlockRecord(ab, current); cErr != nil {
+				damage = c.blockDB.Corruption(current, cErr)
+				break
+			}
+			current = nextLocation
+		}
+
+		if damage == nil && current.Compare(sbLocation) != 0 {
+			damage = c.blockDB.Corruption(current, fmt.Errorf("record overlaps the snapshot block at %s", sbLocation))
+		}
+	}
+
+	sb, _, next, rErr := c.blockDB.ReadUnit(sbLocation)
+	if rErr != nil || sb == nil {
+		if damage == nil {
+			if rErr == nil {
+				rErr = errors.New("record is not a snapshot block")
+			}
+			damage = c.blockDB.Corruption(sbLocation, rErr)
+		}
+		// the size of the record may be still right
+		next, _ = c.blockDB.GetNextLocation(sbLocation)
+		return next, damage, nil
+	}
+
+	if damage == nil {
+		if sb.Height != height || sb.Hash != *hash {
+			damage = c.blockDB.Corruption(sbLocation, fmt.Errorf("snapshot block is %d %s, not %d %s in the index db", sb.Height, sb.Hash, height, hash))
+		} else if sb.ComputeHash() != sb.Hash {
+			damage = c.blockDB.Corruption(sbLocation, fmt.Errorf("hash of snapshot block %d is %s, not %s", sb.Height, sb.ComputeHash(), sb.Hash))
+		}
+	}
+	return next, damage, nil
+}
+
+func (c *chain) checkAccountBlockRecord(ab *ledger.AccountBlock, location *chain_file_manager.Location) error {
+	if _, ok := c.genesisAccountBlockHash[ab.Hash]; !ok {
+		if computed := ab.ComputeHash(); computed != ab.Hash {
+			return fmt.Errorf("hash of account block %s %d is %s, not %s", ab.AccountAddress, ab.Height, computed, ab.Hash)
+		}
+	}
+
+	indexLocation, err := c.indexDB.GetAccountBlockLocationByHash(&ab.Hash)
+	if err != nil {
+		return err
+	}
+	if indexLocation == nil || indexLocation.Compare(location) != 0 {
+		return fmt.Errorf("account block %s is at %v in the index db", ab.Hash, indexLocation)
+	}
+	return nil
+}
+
+// RepairChunk rewrites the records of the snapshot chunk in the block files. The chunk must be the same as
+// the chunk in the ledger, usually fetched from peers, and its records must fit in the locations in the index db.
+func (c *chain) RepairChunk(chunk *ledger.SnapshotChunk) error {
+	if chunk == nil || chunk.SnapshotBlock == nil {
+		return errors.New("snapshot block of the chunk is nil")
+	}
+
+	return c.flusher.Exclusive(func() error {
+		c.flushMu.Lock()
+		defer c.flushMu.Unlock()
+
+		return c.repairChunk(chunk)
+	})
+}
+
+func (c *chain) repairChunk(chunk *ledger.SnapshotChunk) error {
+	sb := chunk.SnapshotBlock
+	height := sb.Height
+
+	hash, sbLocation, err := c.indexDB.GetSnapshotBlockByHeight(height)
+	if err != nil {
+		return err
+	}
+	if hash == nil || *hash != sb.Hash || sb.ComputeHash() != sb.Hash {
+		return fmt.Errorf("snapshot block %d %s is not in the ledger", height, sb.Hash)
+	}
+
+	start, err := c.chunkStart(height)
+	if err != nil {
+		return err
+	}
+	if start == nil {
+		return fmt.Errorf("start of snapshot chunk %d is unknown, repair snapshot chunk %d first", height, height-1)
+	}
+
+	// the records are in the order of the locations in the index db
+	accountBlocks := make([]*ledger.AccountBlock, len(chunk.AccountBlocks))
+	locations := make(map[*ledger.AccountBlock]*chain_file_manager.Location, len(chunk.AccountBlocks))
+	for i, ab := range chunk.AccountBlocks {
+		if _, ok := c.genesisAccountBlockHash[ab.Hash]; !ok && ab.ComputeHash() != ab.Hash {
+			return fmt.Errorf("hash of account block %s is wrong", ab.Hash)
+		}
+		location, err := c.indexDB.GetAccountBlockLocationByHash(&ab.Hash)
+		if err != nil {
+			return err
+		}
+		if location == nil || location.Compare(start) < 0 || location.Compare(sbLocation) >= 0 {
+			return fmt.Errorf("account block %s is not in snapshot chunk %d", ab.Hash, height)
+		}
+		accountBlocks[i] = ab
+		locations[ab] = location
+	}
+	sort.Slice(accountBlocks, func(i, j int) bool {
+		return locations[accountBlocks[i]].Compare(locations[accountBlocks[j]]) < 0
+	})
+	ordered := &ledger.SnapshotChunk{SnapshotBlock: sb, AccountBlocks: accountBlocks}
+
+	// the chunk was written in one of the formats
+	formats := []struct {
+		checksum    bool
+		compression chain_block.Compression
+	}{
+		{true, chain_block.CompressionSnappy},
+		{true, chain_block.CompressionZstd},
+		{false, chain_block.CompressionSnappy},
+	}
+
+	fileSize := c.blockDB.FileSize()
+	for _, format := range formats {
+		records, offsets, err := chain_block.EncodeChunk(ordered, format.checksum, format.compression)
+		if err != nil {
+			return err
+		}
+
+		fit := start.Distance(fileSize, sbLocation) == offsets[len(offsets)-1]
+		for i := 0; fit && i < len(accountBlocks); i++ {
+			fit = start.Distance(fileSize, locations[accountBlocks[i]]) == offsets[i]
+		}
+		if !fit {
+			continue
+		}
+
+		if err := c.blockDB.Overwrite(start, records); err != nil {
+			return err
+		}
+		c.log.Info(fmt.Sprintf("rewrite snapshot chunk %d, %d bytes from %s", height, len(records), start), "method", "RepairChunk")
+
+		_, damage, err := c.scrubChunk(height, start)
+		if err != nil {
+			return err
+		}
+		return damage
+	}
+
+	return fmt.Errorf("records of snapshot chunk %d do not fit in the locations of the block files", height)
+}
+
+// genesisChunk returns the snapshot chunk of the genesis snapshot block, which is repaired from the genesis config
+func (c *chain) genesisChunk() *ledger.SnapshotChunk {
+	chunk := &ledger.SnapshotChunk{SnapshotBlock: c.genesisSnapshotBlock}
+	for _, vmBlock := range c.genesisAccountBlocks {
+		chunk.AccountBlocks = append(chunk.AccountBlocks, vmBlock.AccountBlock)
+	}
+	return chunk
+}
@@ -0,0 +1,62 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	chain_block "github.com/vitelabs/go-vite/v2/ledger/chain/block"
+)
+
+func TestScrubBlocks(t *testing.T) {
+	chainInstance, _, snapshotBlockList := SetUp(t, 10, 100, 5)
+	defer TearDown(chainInstance)
+
+	chainInstance.flusher.Flush()
+
+	latestHeight := chainInstance.GetLatestSnapshotBlock().Height
+	damagedChunks, err := chainInstance.ScrubBlocks(1, latestHeight)
+	assert.NoError(t, err)
+	assert.Empty(t, damagedChunks)
+
+	height := snapshotBlockList[len(snapshotBlockList)/2].Height
+	// the first chunk of the sub ledger is the snapshot block of height-1 only
+	chunks, err := chainInstance.GetSubLedger(height-1, height)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(chunks))
+	chunk := chunks[1]
+	assert.Equal(t, height, chunk.SnapshotBlock.Height)
+
+	// flip the last byte of the snapshot block record
+	sbLocation, err := chainInstance.indexDB.GetSnapshotBlockLocation(height)
+	assert.NoError(t, err)
+	_, next, err := chainInstance.blockDB.ReadUnitBytes(sbLocation)
+	assert.NoError(t, err)
+	raw := make([]byte, sbLocation.Distance(chainInstance.blockDB.FileSize(), next))
+	_, _, err = chainInstance.blockDB.ReadRaw(sbLocation, raw)
+	assert.NoError(t, err)
+
+	raw[len(raw)-1] ^= 0x01
+	assert.NoError(t, chainInstance.blockDB.Overwrite(sbLocation, raw))
+
+	damagedChunks, err = chainInstance.ScrubBlocks(1, latestHeight)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(damagedChunks)) {
+		assert.Equal(t, height, damagedChunks[0].Height)
+		cErr, ok := damagedChunks[0].Err.(*chain_block.CorruptionError)
+		if assert.True(t, ok) {
+			assert.Equal(t, chain_block.ErrChecksumMismatch, cErr.Err)
+			assert.Equal(t, 0, cErr.Location.Compare(sbLocation))
+		}
+	}
+
+	// reading the damaged record reports the corruption
+	_, _, err = chainInstance.blockDB.ReadUnitBytes(sbLocation)
+	assert.IsType(t, &chain_block.CorruptionError{}, err)
+
+	assert.NoError(t, chainInstance.RepairChunk(chunk))
+
+	damagedChunks, err = chainInstance.ScrubBlocks(1, latestHeight)
+	assert.NoError(t, err)
+	assert.Empty(t, damagedChunks)
+}
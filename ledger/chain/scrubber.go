@@ -0,0 +1,166 @@
+package chain
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+const (
+	scrubStop  = 0
+	scrubStart = 1
+
+	scrubInterval = 24 * time.Hour
+
+	// count of snapshot chunks scrubbed in a batch, and the pause between batches
+	scrubBatchSize  = 1000
+	scrubBatchPause = 100 * time.Millisecond
+)
+
+// ChunkFetcher downloads the snapshot chunks from peers
+type ChunkFetcher interface {
+	FetchChunks(from, to uint64, prevHash, hash types.Hash) ([]*ledger.SnapshotChunk, error)
+}
+
+// blockScrubber scrubs the whole block files every scrubInterval, the damaged snapshot chunks
+// are fetched from peers and rewritten if repair is true.
+type blockScrubber struct {
+	chain  *chain
+	repair bool
+
+	log log15.Logger
+
+	status   int32
+	terminal chan struct{}
+	wg       sync.WaitGroup
+}
+
+func newBlockScrubber(chain *chain, repair bool) *blockScrubber {
+	return &blockScrubber{
+		chain:  chain,
+		repair: repair,
+		log:    log15.New("module", "blockScrubber"),
+	}
+}
+
+func (s *blockScrubber) Start() {
+	if !atomic.CompareAndSwapInt32(&s.status, scrubStop, scrubStart) {
+		return
+	}
+	s.terminal = make(chan struct{})
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(scrubInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := s.scrub(); err != nil {
+				s.log.Error(fmt.Sprintf("scrub failed. Error: %s", err), "method", "Start")
+			}
+
+			select {
+			case <-s.terminal:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *blockScrubber) Stop() {
+	if !atomic.CompareAndSwapInt32(&s.status, scrubStart, scrubStop) {
+		return
+	}
+	close(s.terminal)
+	s.wg.Wait()
+}
+
+func (s *blockScrubber) scrub() error {
+	startTime := time.Now()
+	latestHeight := s.chain.GetLatestSnapshotBlock().Height
+
+	damagedCount := 0
+	for from := uint64(1); from <= latestHeight; from += scrubBatchSize {
+		to := from + scrubBatchSize - 1
+		if to > latestHeight {
+			to = latestHeight
+		}
+
+		damagedChunks, err := s.chain.ScrubBlocks(from, to)
+		if err != nil {
+			return err
+		}
+
+		for _, damaged := range damagedChunks {
+			// the chunk may be rolled back while scrubbing
+			if recheck, err := s.chain.ScrubBlocks(damaged.Height, damaged.Height); err != nil || len(recheck) == 0 {
+				continue
+			}
+
+			damagedCount++
+			s.log.Error(fmt.Sprintf("damaged %s", damaged), "method", "scrub")
+
+			if s.repair {
+				if err := s.repairChunk(damaged.Height); err != nil {
+					s.log.Error(fmt.Sprintf("failed to repair snapshot chunk %d. Error: %s", damaged.Height, err), "method", "scrub")
+				} else {
+					s.log.Info(fmt.Sprintf("repair snapshot chunk %d", damaged.Height), "method", "scrub")
+				}
+			}
+		}
+
+		select {
+		case <-s.terminal:
+			return nil
+		case <-time.After(scrubBatchPause):
+		}
+	}
+
+	s.log.Info(fmt.Sprintf("scrub %d snapshot chunks, %d damaged, cost %s", latestHeight, damagedCount, time.Since(startTime)), "method", "scrub")
+	return nil
+}
+
+func (s *blockScrubber) repairChunk(height uint64) error {
+	if height <= 1 {
+		return s.chain.RepairChunk(s.chain.genesisChunk())
+	}
+
+	fetcher := s.chain.chunkFetcher
+	if fetcher == nil {
+		return fmt.Errorf("no chunk fetcher")
+	}
+
+	prevHash, _, err := s.chain.indexDB.GetSnapshotBlockByHeight(height - 1)
+	if err != nil {
+		return err
+	}
+	hash, _, err := s.chain.indexDB.GetSnapshotBlockByHeight(height)
+	if err != nil {
+		return err
+	}
+	if prevHash == nil || hash == nil {
+		return fmt.Errorf("snapshot block %d is not existed", height)
+	}
+
+	chunks, err := fetcher.FetchChunks(height, height, *prevHash, *hash)
+	if err != nil {
+		return err
+	}
+	if len(chunks) != 1 {
+		return fmt.Errorf("fetch %d chunks of snapshot height %d", len(chunks), height)
+	}
+	return s.chain.RepairChunk(chunks[0])
+}
+
+// SetChunkFetcher sets the fetcher of the snapshot chunks repaired by the block scrubber
+func (c *chain) SetChunkFetcher(fetcher ChunkFetcher) {
+	c.chunkFetcher = fetcher
+}
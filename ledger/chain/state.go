@@ -172,6 +172,49 @@ func (c *chain) GetStorageIterator(address types.Address, prefix []byte) (interf
 	return ss, nil
 }
 
+// GetStateBeforeAccountBlock returns the state of the account before the account block was inserted and the
+// snapshot block the state is based on, it fails if the redo log confirming the account block has been deleted.
+func (c *chain) GetStateBeforeAccountBlock(block *ledger.AccountBlock) (interfaces.StateSnapshot, *ledger.SnapshotBlock, error) {
+	confirmedHeight := c.GetLatestSnapshotBlock().Height + 1
+
+	confirmSnapshotBlock, err := c.GetConfirmSnapshotHeaderByAbHash(block.Hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if confirmSnapshotBlock != nil {
+		confirmedHeight = confirmSnapshotBlock.Height
+	}
+
+	state, err := c.stateDB.NewHistoryState(block.AccountAddress, confirmedHeight, block.Height)
+	if err != nil {
+		cErr := fmt.Errorf("c.stateDB.NewHistoryState failed, blockHash is %s. Error: %s", block.Hash, err)
+		c.log.Error(cErr.Error(), "method", "GetStateBeforeAccountBlock")
+		return nil, nil, cErr
+	}
+
+	snapshotBlock, err := c.GetSnapshotHeaderByHeight(confirmedHeight - 1)
+	if err != nil {
+		state.Release()
+		return nil, nil, err
+	}
+	if snapshotBlock == nil {
+		state.Release()
+		return nil, nil, fmt.Errorf("snapshot block %d is not exist", confirmedHeight-1)
+	}
+	return state, snapshotBlock, nil
+}
+
+// GetSnapshotState returns the state of the account at the snapshot block of snapshotHeight.
+func (c *chain) GetSnapshotState(address types.Address, snapshotHeight uint64) (interfaces.StateSnapshot, error) {
+	state, err := c.stateDB.NewSnapshotState(address, snapshotHeight)
+	if err != nil {
+		cErr := fmt.Errorf("c.stateDB.NewSnapshotState failed, snapshotHeight is %d. Error: %s", snapshotHeight, err)
+		c.log.Error(cErr.Error(), "method", "GetSnapshotState")
+		return nil, cErr
+	}
+	return state, nil
+}
+
 func (c *chain) GetValue(address types.Address, key []byte) ([]byte, error) {
 	value, err := c.stateDB.GetStorageValue(&address, key)
 	if err != nil {
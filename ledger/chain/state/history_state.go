@@ -0,0 +1,128 @@
+package chain_state
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/vitelabs/go-vite/v2/common/db"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/comparer"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/memdb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+)
+
+// historyState is the state of an account before one of its account blocks was inserted.
+// It is the snapshot state below the snapshot block confirming the account block,
+// overlaid with the redo logs of the prior account blocks confirmed by the same snapshot block.
+type historyState struct {
+	sDB *StateDB
+
+	addr           types.Address
+	snapshotHeight uint64
+
+	storage     *memdb.DB
+	deletedKeys map[string]struct{}
+	balanceMap  map[types.TokenTypeId]*big.Int
+}
+
+// NewHistoryState returns the state of addr before the account block at accountBlockHeight was inserted,
+// the account block is confirmed by the snapshot block at confirmedHeight, or confirmedHeight is
+// the latest snapshot height + 1 if the account block is unconfirmed.
+// The redo log of confirmedHeight must be retained.
+func (sDB *StateDB) NewHistoryState(addr types.Address, confirmedHeight uint64, accountBlockHeight uint64) (interfaces.StateSnapshot, error) {
+	if confirmedHeight <= 1 {
+		return nil, fmt.Errorf("can't get the history state before snapshot block %d", confirmedHeight)
+	}
+	if err := sDB.checkPruned(confirmedHeight - 1); err != nil {
+		return nil, err
+	}
+
+	logList, ok, err := sDB.redo.QueryAccountLog(confirmedHeight, addr)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("redo log of snapshot block %d is not retained", confirmedHeight)
+	}
+
+	state := newHistoryState(sDB, addr, confirmedHeight-1)
+
+	for _, logItem := range logList {
+		if logItem.Height >= accountBlockHeight {
+			continue
+		}
+		for _, kv := range logItem.Storage {
+			if len(kv[1]) <= 0 {
+				state.deletedKeys[string(kv[0])] = struct{}{}
+			} else {
+				delete(state.deletedKeys, string(kv[0]))
+			}
+			state.storage.Put(kv[0], kv[1])
+		}
+		for tokenId, balance := range logItem.BalanceMap {
+			state.balanceMap[tokenId] = balance
+		}
+	}
+	return state, nil
+}
+
+// NewSnapshotState returns the state of addr at the snapshot block of snapshotHeight.
+func (sDB *StateDB) NewSnapshotState(addr types.Address, snapshotHeight uint64) (interfaces.StateSnapshot, error) {
+	if err := sDB.checkPruned(snapshotHeight); err != nil {
+		return nil, err
+	}
+	return newHistoryState(sDB, addr, snapshotHeight), nil
+}
+
+func newHistoryState(sDB *StateDB, addr types.Address, snapshotHeight uint64) *historyState {
+	return &historyState{
+		sDB:            sDB,
+		addr:           addr,
+		snapshotHeight: snapshotHeight,
+		storage:        memdb.New2(comparer.DefaultComparer, 0),
+		deletedKeys:    make(map[string]struct{}),
+		balanceMap:     make(map[types.TokenTypeId]*big.Int),
+	}
+}
+
+func (state *historyState) GetBalance(tokenId *types.TokenTypeId) (*big.Int, error) {
+	if balance, ok := state.balanceMap[*tokenId]; ok {
+		return new(big.Int).Set(balance), nil
+	}
+
+	balanceMap := make(map[types.Address]*big.Int, 1)
+	if err := state.sDB.getSnapshotBalanceListByHeight(balanceMap, state.snapshotHeight, []types.Address{state.addr}, *tokenId); err != nil {
+		return nil, err
+	}
+	if balance, ok := balanceMap[state.addr]; ok {
+		return balance, nil
+	}
+	return big.NewInt(0), nil
+}
+
+func (state *historyState) GetValue(key []byte) ([]byte, error) {
+	if value, err := state.storage.Get(key); err == nil {
+		if len(value) <= 0 {
+			return nil, nil
+		}
+		return value, nil
+	}
+	return state.sDB.GetSnapshotValue(state.snapshotHeight, state.addr, key)
+}
+
+func (state *historyState) NewStorageIterator(prefix []byte) interfaces.StorageIterator {
+	return db.NewMergedIterator([]interfaces.StorageIterator{
+		state.storage.NewIterator(util.BytesPrefix(prefix)),
+		newStateStorageIterator(state.sDB.NewRawSnapshotStorageIteratorByHeight(state.snapshotHeight, state.addr, prefix), state.addr, state.snapshotHeight),
+	}, state.isDelete)
+}
+
+func (state *historyState) isDelete(key []byte) bool {
+	_, ok := state.deletedKeys[string(key)]
+	return ok
+}
+
+func (state *historyState) Release() {
+	state.storage.Reset()
+}
@@ -0,0 +1,80 @@
+package chain_state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_utils "github.com/vitelabs/go-vite/v2/ledger/chain/utils"
+)
+
+func TestStateDB_NewHistoryState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sDB := newTestPrunerStateDB(t, ctrl, 12)
+	addr := types.Address{1, 2, 3}
+	storageKey := []byte("key")
+
+	// snapshot state at height 5
+	batch := sDB.store.NewBatch()
+	batch.Put(chain_utils.CreateHistoryStorageValueKey(&addr, storageKey, 5).Bytes(), []byte("5"))
+	batch.Put(chain_utils.CreateHistoryBalanceKey(addr, ledger.ViteTokenId, 5).Bytes(), big.NewInt(5).Bytes())
+	sDB.store.WriteDirectly(batch)
+
+	// account blocks 1, 2, 3 are confirmed by snapshot block 8, block 2 deletes the key
+	snapshotLog := SnapshotLog{addr: {
+		{Height: 1, Storage: [][2][]byte{{storageKey, []byte("1")}}, BalanceMap: map[types.TokenTypeId]*big.Int{ledger.ViteTokenId: big.NewInt(1)}},
+		{Height: 2, Storage: [][2][]byte{{storageKey, nil}}},
+		{Height: 3, Storage: [][2][]byte{{storageKey, []byte("3")}}},
+	}}
+	value, err := snapshotLog.Serialize()
+	assert.NoError(t, err)
+	redoBatch := sDB.redo.store.NewBatch()
+	redoBatch.Put(chain_utils.CreateRedoSnapshot(8).Bytes(), value)
+	sDB.redo.store.WriteDirectly(redoBatch)
+
+	for height, expected := range map[uint64][]byte{1: []byte("5"), 2: []byte("1"), 3: nil, 4: []byte("3")} {
+		state, err := sDB.NewHistoryState(addr, 8, height)
+		assert.NoError(t, err)
+
+		value, err := state.GetValue(storageKey)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, value, "height %d", height)
+
+		iter := state.NewStorageIterator(nil)
+		count := 0
+		for iter.Next() {
+			assert.Equal(t, storageKey, iter.Key())
+			assert.Equal(t, expected, iter.Value())
+			count++
+		}
+		iter.Release()
+		assert.Equal(t, len(expected) > 0, count == 1, "height %d", height)
+
+		balance, err := state.GetBalance(&ledger.ViteTokenId)
+		assert.NoError(t, err)
+		if height == 1 {
+			assert.Equal(t, int64(5), balance.Int64())
+		} else {
+			assert.Equal(t, int64(1), balance.Int64())
+		}
+		state.Release()
+	}
+
+	_, err = sDB.NewHistoryState(addr, 1, 1)
+	assert.Error(t, err)
+
+	for height, expected := range map[uint64][]byte{4: nil, 5: []byte("5"), 7: []byte("5")} {
+		state, err := sDB.NewSnapshotState(addr, height)
+		assert.NoError(t, err)
+		value, err := state.GetValue(storageKey)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, value, "snapshot height %d", height)
+		state.Release()
+	}
+}
@@ -19,6 +19,9 @@ func (sDB *StateDB) NewStorageIterator(addr types.Address, prefix []byte) interf
 }
 
 func (sDB *StateDB) NewSnapshotStorageIteratorByHeight(snapshotHeight uint64, addr types.Address, prefix []byte) (interfaces.StorageIterator, error) {
+	if err := sDB.checkPruned(snapshotHeight); err != nil {
+		return nil, err
+	}
 	return newStateStorageIterator(sDB.NewRawSnapshotStorageIteratorByHeight(snapshotHeight, addr, prefix), addr, snapshotHeight), nil
 }
 
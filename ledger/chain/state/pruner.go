@@ -0,0 +1,347 @@
+package chain_state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	leveldb "github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	chain_utils "github.com/vitelabs/go-vite/v2/ledger/chain/utils"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+var ErrStatePruned = errors.New("the history state of the snapshot height has been pruned")
+
+const (
+	pruneStop  = 0
+	pruneStart = 1
+
+	pruneInterval = 10 * time.Minute
+
+	pruneResumeInterval = 10 * time.Second
+
+	// max count of deleted keys in one write
+	pruneBatchSize = 10000
+
+	// max count of history keys scanned by one round
+	pruneScanSize = 1000000
+)
+
+var maxHeightBytes = chain_utils.Uint64ToBytes(math.MaxUint64)
+
+// Pruner deletes the history storage, the history balances and the redo logs
+// which are older than the retain window of chainCfg.LedgerGcRetain.
+// For every storage key and balance, the latest value at or below the pruned height is kept unless it is empty,
+// so queries and rollbacks at or above the pruned height still see the complete state.
+// Every round scans a bounded part of the history keys and resumes from where the last round stopped.
+type Pruner struct {
+	sDB *StateDB
+
+	retain uint64
+
+	// max count of history keys of a prefix scanned by one round
+	scanSize int
+	// the key where the next round of a history prefix starts, nil means the first key
+	cursors map[byte][]byte
+
+	log log15.Logger
+
+	status   int32
+	terminal chan struct{}
+	wg       sync.WaitGroup
+}
+
+func newPruner(sDB *StateDB, retain uint64) *Pruner {
+	// never prune the snapshot heights that still have redo logs
+	if retain < sDB.redo.retainHeight {
+		retain = sDB.redo.retainHeight
+	}
+
+	return &Pruner{
+		sDB:      sDB,
+		retain:   retain,
+		scanSize: pruneScanSize,
+		cursors:  make(map[byte][]byte),
+		log:      log15.New("module", "statePruner"),
+	}
+}
+
+func (p *Pruner) Start() {
+	if !atomic.CompareAndSwapInt32(&p.status, pruneStop, pruneStart) {
+		return
+	}
+	p.terminal = make(chan struct{})
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		for {
+			if err := p.Prune(); err != nil {
+				p.log.Error(fmt.Sprintf("prune failed. Error: %s", err), "method", "Start")
+			}
+
+			// an unfinished round goes on soon, the writes of blocks run in between
+			interval := pruneInterval
+			if !p.finished() {
+				interval = pruneResumeInterval
+			}
+
+			select {
+			case <-p.terminal:
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+}
+
+func (p *Pruner) Stop() {
+	if !atomic.CompareAndSwapInt32(&p.status, pruneStart, pruneStop) {
+		return
+	}
+	close(p.terminal)
+	p.wg.Wait()
+}
+
+// Prune deletes the history state below latest snapshot height - retain
+func (p *Pruner) Prune() error {
+	latestSnapshotBlock := p.sDB.chain.GetLatestSnapshotBlock()
+	if latestSnapshotBlock == nil || latestSnapshotBlock.Height <= p.retain {
+		return nil
+	}
+
+	return p.PruneTo(latestSnapshotBlock.Height - p.retain)
+}
+
+// PruneTo deletes the history state below targetHeight, or goes on with the unfinished round of the pruned height
+func (p *Pruner) PruneTo(targetHeight uint64) error {
+	startTime := time.Now()
+
+	if prunedHeight := p.sDB.PrunedHeight(); targetHeight > prunedHeight {
+		// raise the pruned height before deleting, queries below it fail instead of reading partial history
+		p.sDB.setPrunedHeight(targetHeight)
+	} else if p.finished() {
+		return nil
+	} else {
+		targetHeight = prunedHeight
+	}
+
+	storageCount, err := p.pruneHistory(chain_utils.StorageHistoryKeyPrefix, targetHeight)
+	if err != nil {
+		return err
+	}
+
+	balanceCount, err := p.pruneHistory(chain_utils.BalanceHistoryKeyPrefix, targetHeight)
+	if err != nil {
+		return err
+	}
+
+	redoCount, err := p.pruneRedo(targetHeight)
+	if err != nil {
+		return err
+	}
+
+	p.log.Info(fmt.Sprintf("prune to %d, delete %d history storage, %d history balance, %d redo logs, cost %s",
+		targetHeight, storageCount, balanceCount, redoCount, time.Since(startTime)), "method", "PruneTo")
+	return nil
+}
+
+// pruneHistory deletes the history values of prefix which are shadowed at targetHeight, and the snapshot values at
+// targetHeight which are empty (a deleted storage key or a zero balance), because a missing snapshot value reads the same.
+// A round scans at most p.scanSize keys, the next round resumes from the saved cursor.
+func (p *Pruner) pruneHistory(prefix byte, targetHeight uint64) (uint64, error) {
+	iter := p.sDB.store.NewIterator(util.BytesPrefix([]byte{prefix}))
+	defer iter.Release()
+
+	batch := p.sDB.store.NewBatch()
+	deletedCount := uint64(0)
+	scannedCount := 0
+
+	start := p.cursors[prefix]
+	if start == nil {
+		start = []byte{prefix}
+	}
+	ok := iter.Seek(start)
+
+	var lastGroup []byte
+	var pendingKey []byte
+	pendingEmpty := false
+
+	// the deletes of a key group are written in one batch, so a query never sees an older value of a deleted snapshot value
+	finishGroup := func() {
+		if pendingKey != nil && pendingEmpty {
+			batch.Delete(pendingKey)
+			deletedCount++
+		}
+		pendingKey = nil
+	}
+
+	for ok {
+		key := iter.Key()
+		group := key[:len(key)-types.HeightSize]
+
+		if !bytes.Equal(group, lastGroup) {
+			finishGroup()
+
+			if batch.Len() >= pruneBatchSize {
+				if !p.writeBatch(p.sDB.store.WriteDirectly, batch) {
+					p.cursors[prefix] = append(make([]byte, 0, len(key)), key...)
+					return deletedCount, nil
+				}
+				batch = p.sDB.store.NewBatch()
+			}
+
+			if scannedCount >= p.scanSize {
+				p.cursors[prefix] = append(make([]byte, 0, len(key)), key...)
+				break
+			}
+
+			lastGroup = append(lastGroup[:0], group...)
+		}
+		scannedCount++
+
+		// the rest of the group is in the retain window, seek to the next group
+		if chain_utils.BytesToUint64(key[len(key)-types.HeightSize:]) > targetHeight {
+			ok = iter.Seek(append(append(make([]byte, 0, len(key)), group...), maxHeightBytes...))
+			continue
+		}
+
+		// an older value of the same key is shadowed by the current one
+		if pendingKey != nil {
+			batch.Delete(pendingKey)
+			deletedCount++
+		}
+
+		pendingKey = append(make([]byte, 0, len(key)), key...)
+		pendingEmpty = len(iter.Value()) == 0
+
+		ok = iter.Next()
+	}
+
+	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
+		return deletedCount, err
+	}
+
+	// the whole keyspace is scanned, the next round starts from the beginning
+	if !ok {
+		finishGroup()
+		p.cursors[prefix] = nil
+	}
+
+	if batch.Len() > 0 {
+		p.writeBatch(p.sDB.store.WriteDirectly, batch)
+	}
+
+	return deletedCount, nil
+}
+
+func (p *Pruner) pruneRedo(targetHeight uint64) (uint64, error) {
+	redoStore := p.sDB.redo.store
+
+	iter := redoStore.NewIterator(&util.Range{
+		Start: chain_utils.CreateRedoSnapshot(0).Bytes(),
+		Limit: chain_utils.CreateRedoSnapshot(targetHeight).Bytes(),
+	})
+	defer iter.Release()
+
+	batch := redoStore.NewBatch()
+	deletedCount := uint64(0)
+
+	for iter.Next() {
+		batch.Delete(append(make([]byte, 0, len(iter.Key())), iter.Key()...))
+		deletedCount++
+
+		if batch.Len() >= pruneBatchSize {
+			if !p.writeBatch(redoStore.WriteDirectly, batch) {
+				return deletedCount, nil
+			}
+			batch = redoStore.NewBatch()
+		}
+	}
+
+	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
+		return deletedCount, err
+	}
+
+	if batch.Len() > 0 {
+		p.writeBatch(redoStore.WriteDirectly, batch)
+	}
+
+	return deletedCount, nil
+}
+
+// finished returns true if no round stopped in the middle of the history keys
+func (p *Pruner) finished() bool {
+	for _, cursor := range p.cursors {
+		if cursor != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// writeBatch returns false if the pruner has been stopped
+func (p *Pruner) writeBatch(write func(batch *leveldb.Batch), batch *leveldb.Batch) bool {
+	p.sDB.chain.StopWrite()
+	write(batch)
+	p.sDB.chain.RecoverWrite()
+
+	if p.terminal == nil {
+		return true
+	}
+	select {
+	case <-p.terminal:
+		return false
+	default:
+		return true
+	}
+}
+
+// PrunedHeight returns the snapshot height below which the history state has been pruned
+func (sDB *StateDB) PrunedHeight() uint64 {
+	return atomic.LoadUint64(&sDB.prunedHeight)
+}
+
+// Pruner returns nil if ledger gc is closed
+func (sDB *StateDB) Pruner() *Pruner {
+	return sDB.pruner
+}
+
+func (sDB *StateDB) checkPruned(snapshotHeight uint64) error {
+	if prunedHeight := sDB.PrunedHeight(); snapshotHeight < prunedHeight {
+		return fmt.Errorf("snapshot height %d is below the pruned height %d: %w", snapshotHeight, prunedHeight, ErrStatePruned)
+	}
+	return nil
+}
+
+func (sDB *StateDB) initPrunedHeight() error {
+	value, err := sDB.store.Get(chain_utils.CreatePrunedHeightKey().Bytes())
+	if err != nil {
+		return err
+	}
+
+	prunedHeight := uint64(0)
+	if len(value) == types.HeightSize {
+		prunedHeight = chain_utils.BytesToUint64(value)
+	}
+	atomic.StoreUint64(&sDB.prunedHeight, prunedHeight)
+	return nil
+}
+
+func (sDB *StateDB) setPrunedHeight(height uint64) {
+	batch := sDB.store.NewBatch()
+	batch.Put(chain_utils.CreatePrunedHeightKey().Bytes(), chain_utils.Uint64ToBytes(height))
+
+	sDB.chain.StopWrite()
+	defer sDB.chain.RecoverWrite()
+
+	sDB.store.WriteDirectly(batch)
+	atomic.StoreUint64(&sDB.prunedHeight, height)
+}
@@ -0,0 +1,153 @@
+package chain_state
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
+	chain_utils "github.com/vitelabs/go-vite/v2/ledger/chain/utils"
+)
+
+func newTestPrunerStateDB(t *testing.T, ctrl *gomock.Controller, latestHeight uint64) *StateDB {
+
+	store, err := chain_db.OpenStore(chain_db.BackendMemory, "", "stateDb")
+	assert.NoError(t, err)
+	redoStore, err := chain_db.OpenStore(chain_db.BackendMemory, "", "stateDbRedo")
+	assert.NoError(t, err)
+
+	chain := NewMockChain(ctrl)
+	chain.EXPECT().QueryLatestSnapshotBlock().Return(nil, nil).AnyTimes()
+	chain.EXPECT().GetLatestSnapshotBlock().Return(&ledger.SnapshotBlock{Height: latestHeight}).AnyTimes()
+	chain.EXPECT().GetSnapshotHeightByHash(gomock.Any()).DoAndReturn(func(hash types.Hash) (uint64, error) {
+		return chain_utils.BytesToUint64(hash.Bytes()[types.HashSize-8:]), nil
+	}).AnyTimes()
+	chain.EXPECT().StopWrite().AnyTimes()
+	chain.EXPECT().RecoverWrite().AnyTimes()
+
+	sDB, err := NewStateDBWithStore(chain, &config.Chain{LedgerGc: true, LedgerGcRetain: 1}, store, redoStore)
+	assert.NoError(t, err)
+	assert.NoError(t, sDB.Init())
+	return sDB
+}
+
+func TestPruner_PruneTo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sDB := newTestPrunerStateDB(t, ctrl, 12)
+	addr := types.Address{1, 2, 3}
+	storageKey := []byte("key")
+
+	// history at snapshot height 1, 3, 5, 8, 12
+	batch := sDB.store.NewBatch()
+	redoBatch := sDB.redo.store.NewBatch()
+	for _, height := range []uint64{1, 3, 5, 8, 12} {
+		batch.Put(chain_utils.CreateHistoryStorageValueKey(&addr, storageKey, height).Bytes(), chain_utils.Uint64ToBytes(height))
+		batch.Put(chain_utils.CreateHistoryBalanceKey(addr, ledger.ViteTokenId, height).Bytes(), big.NewInt(int64(height)).Bytes())
+	}
+	for height := uint64(1); height <= 12; height++ {
+		redoBatch.Put(chain_utils.CreateRedoSnapshot(height).Bytes(), []byte{1})
+	}
+	sDB.store.WriteDirectly(batch)
+	sDB.redo.store.WriteDirectly(redoBatch)
+
+	assert.NoError(t, sDB.Pruner().PruneTo(6))
+	assert.Equal(t, uint64(6), sDB.PrunedHeight())
+
+	// the latest history below the pruned height is retained
+	for height, expected := range map[uint64]uint64{6: 5, 7: 5, 8: 8, 11: 8, 12: 12} {
+		value, err := sDB.GetSnapshotValue(height, addr, storageKey)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, chain_utils.BytesToUint64(value), "height %d", height)
+
+		balanceMap := make(map[types.Address]*big.Int)
+		snapshotHash := types.Hash{}
+		copy(snapshotHash[types.HashSize-8:], chain_utils.Uint64ToBytes(height))
+		assert.NoError(t, sDB.GetSnapshotBalanceList(balanceMap, snapshotHash, []types.Address{addr}, ledger.ViteTokenId))
+		assert.Equal(t, int64(expected), balanceMap[addr].Int64(), "height %d", height)
+	}
+
+	// queries below the pruned height fail
+	_, err := sDB.GetSnapshotValue(5, addr, storageKey)
+	assert.True(t, errors.Is(err, ErrStatePruned))
+	_, err = sDB.NewSnapshotStorageIteratorByHeight(3, addr, nil)
+	assert.True(t, errors.Is(err, ErrStatePruned))
+
+	// stale history and redo logs are deleted
+	for _, height := range []uint64{1, 3} {
+		ok, err := sDB.store.Has(chain_utils.CreateHistoryStorageValueKey(&addr, storageKey, height).Bytes())
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	}
+	for height := uint64(1); height <= 12; height++ {
+		ok, err := sDB.redo.store.Has(chain_utils.CreateRedoSnapshot(height).Bytes())
+		assert.NoError(t, err)
+		assert.Equal(t, height >= 6, ok, "redo height %d", height)
+	}
+
+	// the pruned height is persisted
+	sDB.prunedHeight = 0
+	assert.NoError(t, sDB.initPrunedHeight())
+	assert.Equal(t, uint64(6), sDB.PrunedHeight())
+}
+
+func TestPruner_Resume(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sDB := newTestPrunerStateDB(t, ctrl, 12)
+	pruner := sDB.Pruner()
+	pruner.scanSize = 2
+
+	storageKey := []byte("key")
+	addrList := []types.Address{{1}, {2}, {3}}
+
+	// every address has the history at snapshot height 1, 3, 5, and the key is deleted at snapshot height 5
+	batch := sDB.store.NewBatch()
+	for _, addr := range addrList {
+		for _, height := range []uint64{1, 3, 5} {
+			value := chain_utils.Uint64ToBytes(height)
+			if height == 5 {
+				value = nil
+			}
+			batch.Put(chain_utils.CreateHistoryStorageValueKey(&addr, storageKey, height).Bytes(), value)
+		}
+	}
+	sDB.store.WriteDirectly(batch)
+
+	hasHistory := func(addr types.Address, height uint64) bool {
+		ok, err := sDB.store.Has(chain_utils.CreateHistoryStorageValueKey(&addr, storageKey, height).Bytes())
+		assert.NoError(t, err)
+		return ok
+	}
+
+	// a round stops at the first key group after 2 scanned keys
+	assert.NoError(t, pruner.PruneTo(6))
+	assert.False(t, pruner.finished())
+	assert.False(t, hasHistory(addrList[0], 1))
+	assert.True(t, hasHistory(addrList[1], 1))
+
+	// the next rounds resume from the cursor without raising the pruned height
+	assert.NoError(t, pruner.PruneTo(6))
+	assert.False(t, pruner.finished())
+	assert.NoError(t, pruner.PruneTo(6))
+	assert.True(t, pruner.finished())
+	assert.Equal(t, uint64(6), sDB.PrunedHeight())
+
+	// the deleted key leaves no history at or below the pruned height
+	for _, addr := range addrList {
+		for _, height := range []uint64{1, 3, 5} {
+			assert.False(t, hasHistory(addr, height), "address %s height %d", addr, height)
+		}
+		value, err := sDB.GetSnapshotValue(6, addr, storageKey)
+		assert.NoError(t, err)
+		assert.Empty(t, value)
+	}
+}
@@ -222,6 +222,18 @@ func (redo *Redo) QueryLog(snapshotHeight uint64) (SnapshotLog, bool, error) {
 	return snapshotLog, true, nil
 }
 
+func (redo *Redo) QueryAccountLog(snapshotHeight uint64, addr types.Address) ([]LogItem, bool, error) {
+	if logList, ok := redo.cache.GetLogList(snapshotHeight, addr); ok {
+		return logList, ok, nil
+	}
+
+	snapshotLog, ok, err := redo.QueryLog(snapshotHeight)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+	return snapshotLog[addr], true, nil
+}
+
 func (redo *Redo) SetCurrentSnapshot(snapshotHeight uint64, logMap SnapshotLog) {
 	redo.cache.SetCurrent(snapshotHeight, logMap)
 }
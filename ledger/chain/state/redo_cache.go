@@ -44,6 +44,20 @@ func (redoCache *RedoCache) Get(snapshotHeight uint64) (SnapshotLog, bool) {
 	return snapshotLog, ok
 }
 
+// GetLogList returns a copy of the logs of addr, the logs of the current snapshot are appended concurrently
+func (redoCache *RedoCache) GetLogList(snapshotHeight uint64, addr types.Address) ([]LogItem, bool) {
+	redoCache.mu.RLock()
+	defer redoCache.mu.RUnlock()
+
+	snapshotLog, ok := redoCache.snapshotLogMap[snapshotHeight]
+	if !ok {
+		return nil, false
+	}
+	logList := make([]LogItem, len(snapshotLog[addr]))
+	copy(logList, snapshotLog[addr])
+	return logList, true
+}
+
 func (redoCache *RedoCache) Delete(snapshotHeight uint64) {
 	redoCache.mu.Lock()
 	defer redoCache.mu.Unlock()
@@ -44,6 +44,10 @@ type StateDB struct {
 
 	consensusCacheLevel uint32
 	roundCache          *RoundCache
+
+	// history state below prunedHeight has been deleted by pruner
+	prunedHeight uint64
+	pruner       *Pruner
 }
 
 func NewStateDB(chain Chain, chainCfg *config.Chain, chainDir string) (*StateDB, error) {
@@ -84,6 +88,11 @@ func NewStateDBWithStore(chain Chain, chainCfg *config.Chain, store *chain_db.St
 		return nil, err
 	}
 	stateDb.roundCache = NewRoundCache(chain, stateDb, 3)
+
+	// LedgerGcRetain is 0 means retain all history state
+	if chainCfg.LedgerGc && chainCfg.LedgerGcRetain > 0 {
+		stateDb.pruner = newPruner(stateDb, chainCfg.LedgerGcRetain)
+	}
 	return stateDb, nil
 }
 
@@ -93,6 +102,10 @@ func (sDB *StateDB) Init() error {
 	if err := sDB.initCache(); err != nil {
 		return err
 	}
+
+	if err := sDB.initPrunedHeight(); err != nil {
+		return err
+	}
 	//if err := sDB.roundCache.Init(); err != nil {
 	//	return err
 	//}
@@ -314,6 +327,10 @@ func (sDB *StateDB) GetSnapshotValue(snapshotBlockHeight uint64, addr types.Addr
 		return sDB.getValueInCache(append(addr.Bytes(), key...), snapshotValuePrefix)
 	}
 
+	if err := sDB.checkPruned(snapshotBlockHeight); err != nil {
+		return nil, err
+	}
+
 	startHistoryStorageKey := chain_utils.CreateHistoryStorageValueKey(&addr, key, 0)
 	endHistoryStorageKey := chain_utils.CreateHistoryStorageValueKey(&addr, key, snapshotBlockHeight+1)
 
@@ -380,6 +397,13 @@ func (sDB *StateDB) getSnapshotBalanceList(balanceMap map[types.Address]*big.Int
 	if snapshotHeight <= 0 {
 		return nil
 	}
+	return sDB.getSnapshotBalanceListByHeight(balanceMap, snapshotHeight, addrList, tokenId)
+}
+
+func (sDB *StateDB) getSnapshotBalanceListByHeight(balanceMap map[types.Address]*big.Int, snapshotHeight uint64, addrList []types.Address, tokenId types.TokenTypeId) error {
+	if err := sDB.checkPruned(snapshotHeight); err != nil {
+		return err
+	}
 
 	// prepare iterator
 	prefix := chain_utils.BalanceHistoryKeyPrefix
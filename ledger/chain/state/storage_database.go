@@ -16,6 +16,9 @@ func (sDB *StateDB) NewStorageDatabase(snapshotHash types.Hash, addr types.Addre
 	if snapshotHeight <= 0 {
 		return nil, fmt.Errorf("snapshot hash %s is not existed", snapshotHash)
 	}
+	if err := sDB.checkPruned(snapshotHeight); err != nil {
+		return nil, err
+	}
 
 	return NewStorageDatabase(sDB, ledger.HashHeight{
 		Height: snapshotHeight,
@@ -2,11 +2,10 @@ package chain
 
 import (
 	"fmt"
-	"io"
 
 	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/interfaces"
-	chain_file_manager "github.com/vitelabs/go-vite/v2/ledger/chain/file_manager"
+	chain_block "github.com/vitelabs/go-vite/v2/ledger/chain/block"
 	"github.com/vitelabs/go-vite/v2/ledger/chain/sync_cache"
 )
 
@@ -38,10 +37,8 @@ type ledgerReader struct {
 	chunkPrevHash types.Hash
 	chunkHash     types.Hash
 
-	fromLocation *chain_file_manager.Location
-	toLocation   *chain_file_manager.Location
-
-	currentLocation *chain_file_manager.Location
+	size   int
+	reader *chain_block.LegacyReader
 }
 
 func newLedgerReader(chain *chain, from, to uint64) (interfaces.LedgerReader, error) {
@@ -93,6 +90,13 @@ func newLedgerReader(chain *chain, from, to uint64) (interfaces.LedgerReader, er
 		return nil, fmt.Errorf("toSnapshotBlock is nil, to is %d", to)
 	}
 
+	// peers read the records of version 1, the size is counted by converting the records once,
+	// a damaged record fails here before the chunk is sent
+	size, err := chain.blockDB.LegacySize(fromLocation, toLocation)
+	if err != nil {
+		return nil, err
+	}
+
 	return &ledgerReader{
 		chain: chain,
 		from:  from,
@@ -101,9 +105,8 @@ func newLedgerReader(chain *chain, from, to uint64) (interfaces.LedgerReader, er
 		chunkPrevHash: fromPrevSnapshotBlock.Hash,
 		chunkHash:     toSnapshotBlock.Hash,
 
-		fromLocation:    fromLocation,
-		currentLocation: fromLocation,
-		toLocation:      toLocation,
+		size:   int(size),
+		reader: chain.blockDB.NewLegacyReader(fromLocation, toLocation),
 	}, nil
 }
 
@@ -113,32 +116,13 @@ func (reader *ledgerReader) Seg() interfaces.Segment {
 }
 
 func (reader *ledgerReader) Size() int {
-	return int(reader.fromLocation.Distance(reader.chain.blockDB.FileSize(), reader.toLocation))
+	return reader.size
 }
 
 func (reader *ledgerReader) Read(p []byte) (n int, err error) {
-	readN := int(reader.currentLocation.Distance(reader.chain.blockDB.FileSize(), reader.toLocation))
-	isEnd := false
-	if readN <= len(p) {
-		isEnd = true
-	} else {
-		readN = len(p)
-	}
-	currentLocation, n, err := reader.chain.blockDB.ReadRaw(reader.currentLocation, p[:readN])
-
-	reader.currentLocation = currentLocation
-	if err != nil {
-		return n, err
-	}
-
-	if isEnd {
-		err = io.EOF
-	}
-
-	return n, err
+	return reader.reader.Read(p)
 }
 
 func (reader *ledgerReader) Close() error {
-	reader.currentLocation = reader.toLocation
 	return nil
 }
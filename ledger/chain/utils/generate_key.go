@@ -199,6 +199,12 @@ func CreateCallDepthKey(blockHash types.Hash) CallDepthKey {
 	return key
 }
 
+func CreatePrunedHeightKey() PrunedHeightKey {
+	key := PrunedHeightKey{}
+	key[0] = PrunedHeightKeyPrefix
+	return key
+}
+
 // ====== state redo ======
 
 func CreateRedoSnapshot(snapshotHeight uint64) SnapshotKey {
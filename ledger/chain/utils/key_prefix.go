@@ -52,6 +52,9 @@ const (
 	VmLogListKeyPrefix = byte(10)
 
 	CallDepthKeyPrefix = byte(11)
+
+	// -> the snapshot height below which history state has been pruned
+	PrunedHeightKeyPrefix = byte(12)
 )
 
 // state redo db
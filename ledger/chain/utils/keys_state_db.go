@@ -266,3 +266,14 @@ func (key CallDepthKey) String() string {
 func (key *CallDepthKey) HashRefill(hash types.Hash) {
 	copy(key[1:1+types.HashSize], hash.Bytes())
 }
+
+// -------------------------------
+type PrunedHeightKey [1]byte
+
+func (key PrunedHeightKey) Bytes() []byte {
+	return key[:]
+}
+
+func (key PrunedHeightKey) String() string {
+	return string(key[:])
+}
@@ -4,10 +4,9 @@ import (
 	"encoding/binary"
 	"fmt"
 
-	"github.com/syndtr/goleveldb/leveldb"
-	"github.com/syndtr/goleveldb/leveldb/util"
-
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
 	"github.com/vitelabs/go-vite/v2/common/types"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
 )
 
 const (
@@ -51,10 +50,10 @@ func (addrs AddrArr) SetBytes(byt []byte) ([]types.Address, error) {
 
 // ConsensusDB is leveldb for
 type ConsensusDB struct {
-	db *leveldb.DB
+	db chain_db.Backend
 }
 
-func NewConsensusDB(db *leveldb.DB) *ConsensusDB {
+func NewConsensusDB(db chain_db.Backend) *ConsensusDB {
 	return &ConsensusDB{
 		db: db,
 	}
@@ -62,9 +61,9 @@ func NewConsensusDB(db *leveldb.DB) *ConsensusDB {
 
 func (self *ConsensusDB) GetPointByHeight(prefix byte, height uint64) (*Point, error) {
 	key := CreatePointKey(prefix, height)
-	value, err := self.db.Get(key, nil)
+	value, err := self.db.Get(key)
 	if err != nil {
-		if err == leveldb.ErrNotFound {
+		if err == chain_db.ErrNotFound {
 			return nil, nil
 		}
 		return nil, err
@@ -80,7 +79,7 @@ func (self *ConsensusDB) GetPointByHeight(prefix byte, height uint64) (*Point, e
 
 func (self *ConsensusDB) DeletePointByHeight(prefix byte, height uint64) error {
 	key := CreatePointKey(prefix, height)
-	return self.db.Delete(key, nil)
+	return self.db.Delete(key)
 }
 
 func (self *ConsensusDB) StorePointByHeight(prefix byte, height uint64, p *Point) error {
@@ -89,15 +88,15 @@ func (self *ConsensusDB) StorePointByHeight(prefix byte, height uint64, p *Point
 	if err != nil {
 		return err
 	}
-	return self.db.Put(key, byt, nil)
+	return self.db.Put(key, byt)
 }
 
 func (self *ConsensusDB) GetElectionResultByHash(hash types.Hash) ([]types.Address, error) {
 	key := CreateElectionResultKey(hash)
-	value, err := self.db.Get(key, nil)
+	value, err := self.db.Get(key)
 
 	if err != nil {
-		if err == leveldb.ErrNotFound {
+		if err == chain_db.ErrNotFound {
 			return nil, nil
 		}
 		return nil, err
@@ -113,20 +112,20 @@ func (self *ConsensusDB) GetElectionResultByHash(hash types.Hash) ([]types.Addre
 
 func (self *ConsensusDB) DeleteElectionResultByHash(hash types.Hash) error {
 	key := CreateElectionResultKey(hash)
-	err := self.db.Delete(key, nil)
+	err := self.db.Delete(key)
 	return err
 }
 
 func (self *ConsensusDB) StoreElectionResultByHash(hash types.Hash, addrArr []types.Address) error {
 	data := AddrArr(addrArr).Bytes()
 	key := CreateElectionResultKey(hash)
-	return self.db.Put(key, data, nil)
+	return self.db.Put(key, data)
 }
 
 func (self *ConsensusDB) Check() {
 	db := self.db
 	key := CreateElectionResultPrefixKey()
-	iter := db.NewIterator(util.BytesPrefix(key), nil)
+	iter := db.NewIterator(util.BytesPrefix(key))
 	i := uint64(0)
 	for ; iter.Next(); i++ {
 		bytes := iter.Key()
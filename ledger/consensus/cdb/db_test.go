@@ -8,21 +8,15 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
-	"github.com/syndtr/goleveldb/leveldb"
 
 	"github.com/vitelabs/go-vite/v2/common/helper"
 	"github.com/vitelabs/go-vite/v2/common/types"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
 )
 
 func prepareConsensusDB() *ConsensusDB {
 	clearConsensusDB(nil)
-	d, err := leveldb.OpenFile("testdata-consensus", nil)
-	if err != nil {
-		panic(err)
-	}
-
-	db := NewConsensusDB(d)
-	return db
+	return NewConsensusDB(chain_db.NewMemoryBackend())
 }
 
 func clearConsensusDB(db *ConsensusDB) {
@@ -35,7 +29,7 @@ func clearConsensusDB(db *ConsensusDB) {
 func TestConsensusDB_read(t *testing.T) {
 	t.Skip("Skipped by default. This test can be used to inspect consensus db.")
 
-	d, err := leveldb.OpenFile("/Users/jie/Library/GVite/maindata/ledger/consensus", nil)
+	d, err := chain_db.OpenLevelDBBackend("/Users/jie/Library/GVite/maindata/ledger/consensus")
 	if err != nil {
 		panic(err)
 	}
@@ -126,11 +120,11 @@ func TestPasrse(t *testing.T) {
 func TestConsensusDB_compare(t *testing.T) {
 	t.Skip("Skipped by default. This test can be used to inspect consensus db.")
 
-	d, err := leveldb.OpenFile("/Users/jie/Library/GVite/maindata/ledger/consensus", nil)
+	d, err := chain_db.OpenLevelDBBackend("/Users/jie/Library/GVite/maindata/ledger/consensus")
 	if err != nil {
 		panic(err)
 	}
-	d2, err2 := leveldb.OpenFile("/Users/jie/Library/GVite/maindata/ledger_normal/consensus", nil)
+	d2, err2 := chain_db.OpenLevelDBBackend("/Users/jie/Library/GVite/maindata/ledger_normal/consensus")
 	if err2 != nil {
 		panic(err2)
 	}
@@ -9,11 +9,11 @@ import (
 
 	lru "github.com/hashicorp/golang-lru"
 	"github.com/pkg/errors"
-	"github.com/syndtr/goleveldb/leveldb"
 
 	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/interfaces"
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
 
 	"github.com/vitelabs/go-vite/v2/ledger/consensus/cdb"
 	"github.com/vitelabs/go-vite/v2/ledger/consensus/core"
@@ -47,7 +47,7 @@ type Chain interface {
 	IsGenesisSnapshotBlock(hash types.Hash) bool
 	GetRandomSeed(snapshotHash types.Hash, n int) uint64
 	GetLastUnpublishedSeedSnapshotHeader(producer types.Address, beforeTime time.Time) (*ledger.SnapshotBlock, error)
-	NewDb(dbDir string) (*leveldb.DB, error)
+	NewBackend(dbDir string) (chain_db.Backend, error)
 }
 
 type chainRw struct {
@@ -76,7 +76,7 @@ func newChainRw(rw Chain, log log15.Logger, rollbackLock lock.ChainRollback) *ch
 
 	cRw.genesisTime = *rw.GetGenesisSnapshotBlock().Timestamp
 
-	db, err := rw.NewDb("consensus")
+	db, err := rw.NewBackend("consensus")
 	if err != nil {
 		panic(err)
 	}
@@ -58,7 +58,7 @@ func TestChainRw_GetMemberInfo(t *testing.T) {
 	genesisBlock.ComputeHash()
 	mch.EXPECT().GetLatestSnapshotBlock().Return(genesisBlock).AnyTimes()
 	mch.EXPECT().GetGenesisSnapshotBlock().Return(genesisBlock).AnyTimes()
-	mch.EXPECT().NewDb(gomock.Any()).Return(db, nil).MaxTimes(1)
+	mch.EXPECT().NewBackend(gomock.Any()).Return(db, nil).MaxTimes(1)
 	infos, err := GetConsensusGroupList()
 	mch.EXPECT().GetConsensusGroupList(genesisBlock.Hash).Return(infos, err).MaxTimes(1)
 
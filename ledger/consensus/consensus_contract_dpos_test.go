@@ -28,7 +28,7 @@ func TestContractDposCs_ElectionIndex(t *testing.T) {
 	})
 	db := NewDb(t, UnitTestDir)
 	defer ClearDb(t, UnitTestDir)
-	mock_chain.EXPECT().NewDb(gomock.Any()).Return(db, nil)
+	mock_chain.EXPECT().NewBackend(gomock.Any()).Return(db, nil)
 
 	group := types.ConsensusGroupInfo{
 		Gid:                    types.DELEGATE_GID,
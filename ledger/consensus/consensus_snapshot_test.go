@@ -32,7 +32,7 @@ func TestSnapshotCs_ElectionIndex(t *testing.T) {
 	})
 	db := NewDb(t, tempDir)
 	defer ClearDb(t, tempDir)
-	mock_chain.EXPECT().NewDb(gomock.Any()).Return(db, nil)
+	mock_chain.EXPECT().NewBackend(gomock.Any()).Return(db, nil)
 
 	group := types.ConsensusGroupInfo{
 		Gid:                    types.SNAPSHOT_GID,
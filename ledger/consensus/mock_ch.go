@@ -10,10 +10,10 @@ import (
 	time "time"
 
 	gomock "github.com/golang/mock/gomock"
-	leveldb "github.com/syndtr/goleveldb/leveldb"
 	types "github.com/vitelabs/go-vite/v2/common/types"
 	interfaces "github.com/vitelabs/go-vite/v2/interfaces"
 	core "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
 )
 
 // MockChain is a mock of Chain interface.
@@ -260,19 +260,19 @@ func (mr *MockChainMockRecorder) IsGenesisSnapshotBlock(hash interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsGenesisSnapshotBlock", reflect.TypeOf((*MockChain)(nil).IsGenesisSnapshotBlock), hash)
 }
 
-// NewDb mocks base method.
-func (m *MockChain) NewDb(dbDir string) (*leveldb.DB, error) {
+// NewBackend mocks base method.
+func (m *MockChain) NewBackend(dbDir string) (chain_db.Backend, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "NewDb", dbDir)
-	ret0, _ := ret[0].(*leveldb.DB)
+	ret := m.ctrl.Call(m, "NewBackend", dbDir)
+	ret0, _ := ret[0].(chain_db.Backend)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// NewDb indicates an expected call of NewDb.
-func (mr *MockChainMockRecorder) NewDb(dbDir interface{}) *gomock.Call {
+// NewBackend indicates an expected call of NewBackend.
+func (mr *MockChainMockRecorder) NewBackend(dbDir interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewDb", reflect.TypeOf((*MockChain)(nil).NewDb), dbDir)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewBackend", reflect.TypeOf((*MockChain)(nil).NewBackend), dbDir)
 }
 
 // Register mocks base method.
@@ -5,10 +5,9 @@ import (
 	"os"
 	"testing"
 
-	"github.com/syndtr/goleveldb/leveldb"
-
 	"github.com/vitelabs/go-vite/v2/common/config"
 	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
 	"github.com/vitelabs/go-vite/v2/vm/quota"
 )
 
@@ -16,8 +15,8 @@ var GenesisJson = "{\"GenesisAccountAddress\":\"vite_ab24ef68b84e642c0ddca06beec
 
 var UnitTestDir = "testdata-unittest"
 
-func NewDb(t *testing.T, dirName string) *leveldb.DB {
-	db, err := leveldb.OpenFile(dirName, nil)
+func NewDb(t *testing.T, dirName string) chain_db.Backend {
+	db, err := chain_db.OpenLevelDBBackend(dirName)
 	if err != nil {
 		t.Error(err)
 		t.FailNow()
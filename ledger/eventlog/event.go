@@ -0,0 +1,105 @@
+package eventlog
+
+import (
+	"math/big"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+const (
+	EventAccountBlock        = "accountBlock"
+	EventSnapshotBlock       = "snapshotBlock"
+	EventRevertAccountBlock  = "revertAccountBlock"
+	EventRevertSnapshotBlock = "revertSnapshotBlock"
+)
+
+// Event is an insertion or a rollback of a block, the cursors of the events increase one by one
+type Event struct {
+	Cursor        uint64         `json:"cursor,omitempty"`
+	Type          string         `json:"type"`
+	AccountBlock  *AccountBlock  `json:"accountBlock,omitempty"`
+	SnapshotBlock *SnapshotBlock `json:"snapshotBlock,omitempty"`
+}
+
+type AccountBlock struct {
+	BlockType     byte              `json:"blockType"`
+	Hash          types.Hash        `json:"hash"`
+	Height        uint64            `json:"height"`
+	Address       types.Address     `json:"address"`
+	ToAddress     types.Address     `json:"toAddress"`
+	FromBlockHash types.Hash        `json:"fromBlockHash"`
+	TokenId       types.TokenTypeId `json:"tokenId"`
+	Amount        string            `json:"amount"`
+	Logs          []*ledger.VmLog   `json:"logs,omitempty"`
+	SendBlocks    []*SendBlock      `json:"sendBlocks,omitempty"`
+}
+
+// SendBlock is a send block generated by a contract in its receive block
+type SendBlock struct {
+	Hash      types.Hash        `json:"hash"`
+	ToAddress types.Address     `json:"toAddress"`
+	TokenId   types.TokenTypeId `json:"tokenId"`
+	Amount    string            `json:"amount"`
+}
+
+type SnapshotBlock struct {
+	Hash     types.Hash `json:"hash"`
+	Height   uint64     `json:"height"`
+	PrevHash types.Hash `json:"prevHash"`
+
+	// the account blocks confirmed by the snapshot block
+	AccountBlocks []*ConfirmedBlock `json:"accountBlocks,omitempty"`
+}
+
+type ConfirmedBlock struct {
+	Address types.Address `json:"address"`
+	Hash    types.Hash    `json:"hash"`
+	Height  uint64        `json:"height"`
+}
+
+func amountString(amount *big.Int) string {
+	if amount == nil {
+		return "0"
+	}
+	return amount.String()
+}
+
+func NewAccountBlock(block *ledger.AccountBlock, logs []*ledger.VmLog) *AccountBlock {
+	ab := &AccountBlock{
+		BlockType:     block.BlockType,
+		Hash:          block.Hash,
+		Height:        block.Height,
+		Address:       block.AccountAddress,
+		ToAddress:     block.ToAddress,
+		FromBlockHash: block.FromBlockHash,
+		TokenId:       block.TokenId,
+		Amount:        amountString(block.Amount),
+		Logs:          logs,
+	}
+	for _, send := range block.SendBlockList {
+		ab.SendBlocks = append(ab.SendBlocks, &SendBlock{
+			Hash:      send.Hash,
+			ToAddress: send.ToAddress,
+			TokenId:   send.TokenId,
+			Amount:    amountString(send.Amount),
+		})
+	}
+	return ab
+}
+
+func NewSnapshotBlock(chunk *ledger.SnapshotChunk) *SnapshotBlock {
+	sb := &SnapshotBlock{
+		Hash:     chunk.SnapshotBlock.Hash,
+		Height:   chunk.SnapshotBlock.Height,
+		PrevHash: chunk.SnapshotBlock.PrevHash,
+	}
+	for _, block := range chunk.AccountBlocks {
+		sb.AccountBlocks = append(sb.AccountBlocks, &ConfirmedBlock{
+			Address: block.AccountAddress,
+			Hash:    block.Hash,
+			Height:  block.Height,
+		})
+	}
+	return sb
+}
@@ -0,0 +1,429 @@
+// Package eventlog keeps the chain events in a persistent, ordered log. Every insertion and rollback of
+// a block gets a cursor, so a client can replay the events from the last cursor it has seen after a
+// disconnection without missing any of them.
+//
+// The log is written by the chain events before the chain flushes, and the chain doesn't stop on the
+// errors of its listeners. So the log keeps the latest snapshot block it has seen, and when it starts it
+// reverts the blocks the chain doesn't have any more and inserts the blocks it misses.
+package eventlog
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	leveldb "github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+const (
+	eventKeyPrefix = byte(1)
+	headKeyPrefix  = byte(2)
+
+	// the max count of events read by Follow at a time
+	followBatchSize = 100
+
+	// the max count of events pruned in one write
+	pruneSize = 10000
+
+	// the count of snapshot blocks read from the chain at a time by the reconciliation
+	reconcileBatchSize = 100
+
+	// the count of snapshot blocks before the reconciliation point whose account blocks are kept
+	reconcileLookback = 10
+)
+
+var (
+	ErrCursorPruned = errors.New("the events from the cursor are pruned")
+	ErrClosed       = errors.New("the event log is closed")
+	ErrBroken       = errors.New("the event log failed to write the events, restart the node to recover them")
+)
+
+type Chain interface {
+	Register(listener interfaces.EventListener)
+	UnRegister(listener interfaces.EventListener)
+	GetVmLogList(logListHash *types.Hash) (ledger.VmLogList, error)
+
+	GetLatestSnapshotBlock() *ledger.SnapshotBlock
+	GetSnapshotHashByHeight(height uint64) (*types.Hash, error)
+	GetSubLedger(startHeight, endHeight uint64) ([]*ledger.SnapshotChunk, error)
+	GetAllUnconfirmedBlocks() []*ledger.AccountBlock
+	IsAccountBlockExisted(hash types.Hash) (bool, error)
+}
+
+// EventLog is an interfaces.EventListener of the chain writing the events into a leveldb
+type EventLog struct {
+	db    *leveldb.DB
+	chain Chain
+
+	// the count of the events kept, 0 means all
+	retention uint64
+
+	// the first cursors the consumers still need, the events from them are not pruned
+	retainers []func() uint64
+
+	// the cursors of the first and the last event, the log is empty if last is 0
+	first, last uint64
+
+	// the latest snapshot block of the events, the height is 0 before the first reconciliation
+	head ledger.HashHeight
+
+	// the error of a failed write, the later events are dropped until the reconciliation of the next start
+	err error
+
+	// closed and replaced when new events are written
+	newEvents chan struct{}
+	closed    chan struct{}
+
+	// the rollbacks collected by the prepare calls, written by the delete calls
+	pendingReverts []*Event
+
+	mu  sync.RWMutex
+	log log15.Logger
+}
+
+func New(dir string, chain Chain, retention uint64) (*EventLog, error) {
+	db, err := leveldb.OpenFile(dir, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	el := &EventLog{
+		db:        db,
+		chain:     chain,
+		retention: retention,
+		newEvents: make(chan struct{}),
+		closed:    make(chan struct{}),
+		log:       log15.New("module", "eventlog"),
+	}
+
+	iter := db.NewIterator(util.BytesPrefix([]byte{eventKeyPrefix}), nil)
+	if iter.First() {
+		el.first = cursorOfKey(iter.Key())
+	}
+	if iter.Last() {
+		el.last = cursorOfKey(iter.Key())
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if el.last == 0 {
+		el.first = 1
+	}
+
+	value, err := db.Get([]byte{headKeyPrefix}, nil)
+	if err == nil {
+		if len(value) != 8+types.HashSize {
+			db.Close()
+			return nil, fmt.Errorf("invalid head of the event log: %x", value)
+		}
+		el.head.Height = binary.BigEndian.Uint64(value)
+		copy(el.head.Hash[:], value[8:])
+	} else if err != leveldb.ErrNotFound {
+		db.Close()
+		return nil, err
+	}
+	return el, nil
+}
+
+func eventKey(cursor uint64) []byte {
+	key := make([]byte, 9)
+	key[0] = eventKeyPrefix
+	binary.BigEndian.PutUint64(key[1:], cursor)
+	return key
+}
+
+func cursorOfKey(key []byte) uint64 {
+	return binary.BigEndian.Uint64(key[1:])
+}
+
+func headValue(head ledger.HashHeight) []byte {
+	value := make([]byte, 8+types.HashSize)
+	binary.BigEndian.PutUint64(value, head.Height)
+	copy(value[8:], head.Hash.Bytes())
+	return value
+}
+
+// Start reconciles the event log with the chain and registers it to the chain
+func (el *EventLog) Start() error {
+	if err := el.reconcile(); err != nil {
+		return fmt.Errorf("failed to reconcile the event log with the chain: %v", err)
+	}
+	el.chain.Register(el)
+	return nil
+}
+
+// Stop unregisters the event log and closes the db, the followers return ErrClosed
+func (el *EventLog) Stop() error {
+	el.chain.UnRegister(el)
+
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	select {
+	case <-el.closed:
+		return nil
+	default:
+	}
+	close(el.closed)
+	return el.db.Close()
+}
+
+// AddRetainer keeps the events from the cursor returned by fn out of the pruning, for the consumers
+// following the log from persisted cursors
+func (el *EventLog) AddRetainer(fn func() uint64) {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	el.retainers = append(el.retainers, fn)
+}
+
+// Cursors returns the cursors of the first and the last event kept, last is 0 if there is no event
+func (el *EventLog) Cursors() (first, last uint64) {
+	el.mu.RLock()
+	defer el.mu.RUnlock()
+	return el.first, el.last
+}
+
+// Events returns at most count events from the cursor, from 0 means from the first event kept.
+// It returns ErrCursorPruned if the events from the cursor are not kept any more, and ErrBroken after
+// the last event if a write has failed.
+func (el *EventLog) Events(from uint64, count int) ([]*Event, error) {
+	el.mu.RLock()
+	defer el.mu.RUnlock()
+
+	select {
+	case <-el.closed:
+		return nil, ErrClosed
+	default:
+	}
+	if from == 0 {
+		from = el.first
+	} else if from < el.first {
+		return nil, ErrCursorPruned
+	}
+
+	var events []*Event
+	iter := el.db.NewIterator(&util.Range{Start: eventKey(from), Limit: eventKey(el.last + 1)}, nil)
+	defer iter.Release()
+	for iter.Next() && len(events) < count {
+		event := &Event{}
+		if err := json.Unmarshal(iter.Value(), event); err != nil {
+			return nil, fmt.Errorf("failed to decode event %d: %v", cursorOfKey(iter.Key()), err)
+		}
+		events = append(events, event)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	if len(events) == 0 && from > el.last && el.err != nil {
+		return nil, el.err
+	}
+	return events, nil
+}
+
+// Follow calls fn with the events from the cursor in order, it waits for the new events once all the
+// events are read. It returns when quit is closed, fn returns an error or the event log is stopped.
+func (el *EventLog) Follow(from uint64, quit <-chan struct{}, fn func(events []*Event) error) error {
+	for {
+		el.mu.RLock()
+		newEvents := el.newEvents
+		el.mu.RUnlock()
+
+		events, err := el.Events(from, followBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(events) > 0 {
+			if err := fn(events); err != nil {
+				return err
+			}
+			from = events[len(events)-1].Cursor + 1
+			select {
+			case <-quit:
+				return nil
+			default:
+			}
+			continue
+		}
+
+		select {
+		case <-quit:
+			return nil
+		case <-el.closed:
+			return ErrClosed
+		case <-newEvents:
+		}
+	}
+}
+
+// write appends the events with the next cursors and prunes the events out of the retention.
+// Once a write fails, the later events are dropped and the followers get ErrBroken after the last event,
+// so the clients see the failure instead of a gap in the cursors.
+func (el *EventLog) write(events []*Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	select {
+	case <-el.closed:
+		return ErrClosed
+	default:
+	}
+	if el.err != nil {
+		return el.err
+	}
+
+	if err := el.writeBatch(events); err != nil {
+		el.err = fmt.Errorf("%w: %v", ErrBroken, err)
+		el.log.Error(fmt.Sprintf("write %d events failed, error is %s", len(events), err), "cursor", el.last+1)
+
+		close(el.newEvents)
+		el.newEvents = make(chan struct{})
+		return el.err
+	}
+	return nil
+}
+
+func (el *EventLog) writeBatch(events []*Event) error {
+	batch := new(leveldb.Batch)
+	last := el.last
+	head := el.head
+	for _, event := range events {
+		last++
+		event.Cursor = last
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		batch.Put(eventKey(last), data)
+
+		switch event.Type {
+		case EventSnapshotBlock:
+			head = ledger.HashHeight{Height: event.SnapshotBlock.Height, Hash: event.SnapshotBlock.Hash}
+		case EventRevertSnapshotBlock:
+			head = ledger.HashHeight{Height: event.SnapshotBlock.Height - 1, Hash: event.SnapshotBlock.PrevHash}
+		}
+	}
+	if head != el.head {
+		batch.Put([]byte{headKeyPrefix}, headValue(head))
+	}
+
+	first := el.first
+	if el.retention > 0 {
+		retained := last + 1
+		for _, fn := range el.retainers {
+			if cursor := fn(); cursor < retained {
+				retained = cursor
+			}
+		}
+		for i := 0; i < pruneSize && last-first+1 > el.retention && first < retained; i++ {
+			batch.Delete(eventKey(first))
+			first++
+		}
+	}
+
+	if err := el.db.Write(batch, nil); err != nil {
+		return err
+	}
+	el.first, el.last = first, last
+	el.head = head
+
+	close(el.newEvents)
+	el.newEvents = make(chan struct{})
+	return nil
+}
+
+func (el *EventLog) accountBlockWithLogs(block *ledger.AccountBlock) *AccountBlock {
+	logs, err := el.chain.GetVmLogList(block.LogHash)
+	if err != nil {
+		el.log.Error(fmt.Sprintf("get vm logs failed, error is %s", err), "hash", block.Hash)
+	}
+	return NewAccountBlock(block, logs)
+}
+
+func (el *EventLog) PrepareInsertAccountBlocks(blocks []*interfaces.VmAccountBlock) error {
+	return nil
+}
+
+func (el *EventLog) InsertAccountBlocks(blocks []*interfaces.VmAccountBlock) error {
+	events := make([]*Event, 0, len(blocks))
+	for _, block := range blocks {
+		events = append(events, &Event{
+			Type:         EventAccountBlock,
+			AccountBlock: NewAccountBlock(block.AccountBlock, block.VmDb.GetLogList()),
+		})
+	}
+	return el.write(events)
+}
+
+func (el *EventLog) PrepareInsertSnapshotBlocks(chunks []*ledger.SnapshotChunk) error {
+	return nil
+}
+
+func (el *EventLog) InsertSnapshotBlocks(chunks []*ledger.SnapshotChunk) error {
+	events := make([]*Event, 0, len(chunks))
+	for _, chunk := range chunks {
+		events = append(events, &Event{
+			Type:          EventSnapshotBlock,
+			SnapshotBlock: NewSnapshotBlock(chunk),
+		})
+	}
+	return el.write(events)
+}
+
+// PrepareDeleteAccountBlocks keeps the rollbacks of the blocks, the vm logs are not available after
+// the blocks are deleted
+func (el *EventLog) PrepareDeleteAccountBlocks(blocks []*ledger.AccountBlock) error {
+	for i := len(blocks) - 1; i >= 0; i-- {
+		el.pendingReverts = append(el.pendingReverts, &Event{
+			Type:         EventRevertAccountBlock,
+			AccountBlock: el.accountBlockWithLogs(blocks[i]),
+		})
+	}
+	return nil
+}
+
+func (el *EventLog) DeleteAccountBlocks(blocks []*ledger.AccountBlock) error {
+	return el.writePendingReverts()
+}
+
+// PrepareDeleteSnapshotBlocks keeps the rollbacks of the snapshot blocks and their account blocks,
+// from the highest to the lowest
+func (el *EventLog) PrepareDeleteSnapshotBlocks(chunks []*ledger.SnapshotChunk) error {
+	for i := len(chunks) - 1; i >= 0; i-- {
+		chunk := chunks[i]
+		if chunk.SnapshotBlock != nil {
+			el.pendingReverts = append(el.pendingReverts, &Event{
+				Type:          EventRevertSnapshotBlock,
+				SnapshotBlock: NewSnapshotBlock(chunk),
+			})
+		}
+		for j := len(chunk.AccountBlocks) - 1; j >= 0; j-- {
+			el.pendingReverts = append(el.pendingReverts, &Event{
+				Type:         EventRevertAccountBlock,
+				AccountBlock: el.accountBlockWithLogs(chunk.AccountBlocks[j]),
+			})
+		}
+	}
+	return nil
+}
+
+func (el *EventLog) DeleteSnapshotBlocks(chunks []*ledger.SnapshotChunk) error {
+	return el.writePendingReverts()
+}
+
+func (el *EventLog) writePendingReverts() error {
+	events := el.pendingReverts
+	el.pendingReverts = nil
+	return el.write(events)
+}
@@ -0,0 +1,199 @@
+package eventlog
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	leveldb "github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/opt"
+	"github.com/vitelabs/go-vite/v2/common/fileutils"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+func newBlock(height uint64) *ledger.AccountBlock {
+	return &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		Hash:           types.DataHash(big.NewInt(int64(height)).Bytes()),
+		Height:         height,
+		AccountAddress: types.AddressQuota,
+		Amount:         big.NewInt(int64(height)),
+	}
+}
+
+func TestEventLog(t *testing.T) {
+	dir := fileutils.CreateTempDir()
+	defer os.RemoveAll(dir)
+
+	chain := NewMockChain()
+	el, err := New(dir, chain, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, el.Start())
+
+	b1, b2, b3 := newBlock(1), newBlock(2), newBlock(3)
+	logHash := types.DataHash([]byte("logs"))
+	b3.LogHash = &logHash
+	chain.Logs[logHash] = ledger.VmLogList{{Data: []byte("log")}}
+
+	assert.NoError(t, chain.InsertAccountBlocks(b1, b2))
+	_, err = chain.InsertSnapshotBlock("s2")
+	assert.NoError(t, err)
+	assert.NoError(t, chain.InsertAccountBlocks(b3))
+
+	// the rollbacks are written from the highest block
+	assert.NoError(t, chain.DeleteSnapshotBlocks(1))
+
+	events, err := el.Events(0, 100)
+	assert.NoError(t, err)
+	var eventTypes []string
+	for i, event := range events {
+		assert.Equal(t, uint64(i+1), event.Cursor)
+		eventTypes = append(eventTypes, event.Type)
+	}
+	assert.Equal(t, []string{
+		EventAccountBlock, EventAccountBlock, EventSnapshotBlock, EventAccountBlock,
+		EventRevertAccountBlock, EventRevertSnapshotBlock, EventRevertAccountBlock, EventRevertAccountBlock,
+	}, eventTypes)
+	assert.Equal(t, b3.Hash, events[4].AccountBlock.Hash)
+	assert.Equal(t, 1, len(events[4].AccountBlock.Logs))
+	assert.Equal(t, 2, len(events[5].SnapshotBlock.AccountBlocks))
+	assert.Equal(t, b1.Hash, events[7].AccountBlock.Hash)
+	assert.Equal(t, "1", events[7].AccountBlock.Amount)
+
+	events, err = el.Events(7, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(events))
+	assert.NoError(t, el.Stop())
+
+	// the cursors continue after a restart, the old events are pruned out of the retention
+	el, err = New(dir, chain, 5)
+	assert.NoError(t, err)
+	assert.NoError(t, el.Start())
+	defer el.Stop()
+	assert.NoError(t, chain.InsertAccountBlocks(b1))
+	first, last := el.Cursors()
+	assert.Equal(t, uint64(5), first)
+	assert.Equal(t, uint64(9), last)
+	_, err = el.Events(4, 100)
+	assert.Equal(t, ErrCursorPruned, err)
+}
+
+func TestEventLog_Follow(t *testing.T) {
+	dir := fileutils.CreateTempDir()
+	defer os.RemoveAll(dir)
+
+	chain := NewMockChain()
+	el, err := New(dir, chain, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, el.Start())
+
+	assert.NoError(t, chain.InsertAccountBlocks(newBlock(1), newBlock(2)))
+
+	cursors := make(chan uint64, 10)
+	done := make(chan error)
+	go func() {
+		done <- el.Follow(2, nil, func(events []*Event) error {
+			for _, event := range events {
+				cursors <- event.Cursor
+			}
+			return nil
+		})
+	}()
+
+	assert.NoError(t, chain.InsertAccountBlocks(newBlock(3)))
+	for _, expected := range []uint64{2, 3} {
+		select {
+		case cursor := <-cursors:
+			assert.Equal(t, expected, cursor)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the event is not followed")
+		}
+	}
+
+	assert.NoError(t, el.Stop())
+	assert.Equal(t, ErrClosed, <-done)
+}
+
+func eventsOf(t *testing.T, el *EventLog, from uint64) ([]string, []types.Hash) {
+	events, err := el.Events(from, 100)
+	assert.NoError(t, err)
+	var eventTypes []string
+	var hashes []types.Hash
+	for _, event := range events {
+		eventTypes = append(eventTypes, event.Type)
+		if event.AccountBlock != nil {
+			hashes = append(hashes, event.AccountBlock.Hash)
+		} else {
+			hashes = append(hashes, event.SnapshotBlock.Hash)
+		}
+	}
+	return eventTypes, hashes
+}
+
+func TestEventLog_Reconcile(t *testing.T) {
+	dir := fileutils.CreateTempDir()
+	defer os.RemoveAll(dir)
+
+	chain := NewMockChain()
+	el, err := New(dir, chain, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, el.Start())
+
+	b1, b2, b3, b4, b5 := newBlock(1), newBlock(2), newBlock(3), newBlock(4), newBlock(5)
+	assert.NoError(t, chain.InsertAccountBlocks(b1, b2))
+	s2, err := chain.InsertSnapshotBlock("s2")
+	assert.NoError(t, err)
+	assert.NoError(t, chain.InsertAccountBlocks(b3))
+	assert.NoError(t, el.Stop())
+
+	// the chain loses the snapshot block and b3 in a crash
+	chain.Chunks = chain.Chunks[:1]
+	chain.Unconfirmed = []*ledger.AccountBlock{b1, b2}
+	el, err = New(dir, chain, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, el.Start())
+	eventTypes, hashes := eventsOf(t, el, 5)
+	assert.Equal(t, []string{EventRevertAccountBlock, EventRevertSnapshotBlock}, eventTypes)
+	assert.Equal(t, []types.Hash{b3.Hash, s2.SnapshotBlock.Hash}, hashes)
+	assert.NoError(t, el.Stop())
+
+	// the chain inserts blocks while the log is stopped
+	s2, err = chain.InsertSnapshotBlock("s2'")
+	assert.NoError(t, err)
+	assert.NoError(t, chain.InsertAccountBlocks(b4))
+	s3, err := chain.InsertSnapshotBlock("s3")
+	assert.NoError(t, err)
+	assert.NoError(t, chain.InsertAccountBlocks(b5))
+	el, err = New(dir, chain, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, el.Start())
+	eventTypes, hashes = eventsOf(t, el, 7)
+	assert.Equal(t, []string{EventSnapshotBlock, EventAccountBlock, EventSnapshotBlock, EventAccountBlock}, eventTypes)
+	assert.Equal(t, []types.Hash{s2.SnapshotBlock.Hash, b4.Hash, s3.SnapshotBlock.Hash, b5.Hash}, hashes)
+	assert.NoError(t, el.Stop())
+
+	// nothing changes
+	el, err = New(dir, chain, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, el.Start())
+	_, last := el.Cursors()
+	assert.Equal(t, uint64(10), last)
+
+	// the followers get the error after the last event once a write fails
+	assert.NoError(t, el.db.Close())
+	el.db, err = leveldb.OpenFile(dir, &opt.Options{ReadOnly: true})
+	assert.NoError(t, err)
+	defer el.Stop()
+	_, err = chain.InsertSnapshotBlock("s4")
+	assert.Error(t, err)
+	events, err := el.Events(10, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(events))
+	_, err = el.Events(11, 100)
+	assert.True(t, errors.Is(err, ErrBroken))
+}
@@ -0,0 +1,142 @@
+package eventlog
+
+import (
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+// MockChain is a chain in memory for the tests of the event log and its consumers. The insertions and
+// the rollbacks call the registered listener like the chain does.
+type MockChain struct {
+	Listener interfaces.EventListener
+	Logs     map[types.Hash]ledger.VmLogList
+
+	// the snapshot chunks from the genesis, and the unconfirmed blocks
+	Chunks      []*ledger.SnapshotChunk
+	Unconfirmed []*ledger.AccountBlock
+}
+
+func NewMockChain() *MockChain {
+	genesis := &ledger.SnapshotBlock{Hash: types.DataHash([]byte("genesis")), Height: 1}
+	return &MockChain{
+		Logs:   make(map[types.Hash]ledger.VmLogList),
+		Chunks: []*ledger.SnapshotChunk{{SnapshotBlock: genesis}},
+	}
+}
+
+func (c *MockChain) Register(listener interfaces.EventListener) {
+	c.Listener = listener
+}
+
+func (c *MockChain) UnRegister(listener interfaces.EventListener) {
+	c.Listener = nil
+}
+
+func (c *MockChain) GetVmLogList(logListHash *types.Hash) (ledger.VmLogList, error) {
+	if logListHash == nil {
+		return nil, nil
+	}
+	return c.Logs[*logListHash], nil
+}
+
+func (c *MockChain) GetLatestSnapshotBlock() *ledger.SnapshotBlock {
+	return c.Chunks[len(c.Chunks)-1].SnapshotBlock
+}
+
+func (c *MockChain) GetSnapshotHashByHeight(height uint64) (*types.Hash, error) {
+	if height == 0 || height > uint64(len(c.Chunks)) {
+		return nil, nil
+	}
+	return &c.Chunks[height-1].SnapshotBlock.Hash, nil
+}
+
+func (c *MockChain) GetSubLedger(startHeight, endHeight uint64) ([]*ledger.SnapshotChunk, error) {
+	if endHeight > uint64(len(c.Chunks)) {
+		endHeight = uint64(len(c.Chunks))
+	}
+	return c.Chunks[startHeight:endHeight], nil
+}
+
+func (c *MockChain) GetAllUnconfirmedBlocks() []*ledger.AccountBlock {
+	return c.Unconfirmed
+}
+
+func (c *MockChain) GetAccountBlockByHash(blockHash types.Hash) (*ledger.AccountBlock, error) {
+	for _, block := range c.Unconfirmed {
+		if block.Hash == blockHash {
+			return block, nil
+		}
+	}
+	for _, chunk := range c.Chunks {
+		for _, block := range chunk.AccountBlocks {
+			if block.Hash == blockHash {
+				return block, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (c *MockChain) IsAccountBlockExisted(hash types.Hash) (bool, error) {
+	block, err := c.GetAccountBlockByHash(hash)
+	return block != nil, err
+}
+
+type mockVmDb struct {
+	interfaces.VmDb
+	logs ledger.VmLogList
+}
+
+func (db *mockVmDb) GetLogList() ledger.VmLogList {
+	return db.logs
+}
+
+// InsertAccountBlocks appends the unconfirmed blocks, their vm logs are the ones of their log hashes
+func (c *MockChain) InsertAccountBlocks(blocks ...*ledger.AccountBlock) error {
+	vmBlocks := make([]*interfaces.VmAccountBlock, len(blocks))
+	for i, block := range blocks {
+		logs, _ := c.GetVmLogList(block.LogHash)
+		vmBlocks[i] = &interfaces.VmAccountBlock{AccountBlock: block, VmDb: &mockVmDb{logs: logs}}
+	}
+	c.Unconfirmed = append(c.Unconfirmed, blocks...)
+	if c.Listener == nil {
+		return nil
+	}
+	return c.Listener.InsertAccountBlocks(vmBlocks)
+}
+
+// InsertSnapshotBlock appends a snapshot block named name confirming all the unconfirmed blocks
+func (c *MockChain) InsertSnapshotBlock(name string) (*ledger.SnapshotChunk, error) {
+	latest := c.GetLatestSnapshotBlock()
+	chunk := &ledger.SnapshotChunk{
+		SnapshotBlock: &ledger.SnapshotBlock{
+			Hash:     types.DataHash([]byte(name)),
+			Height:   latest.Height + 1,
+			PrevHash: latest.Hash,
+		},
+		AccountBlocks: c.Unconfirmed,
+	}
+	c.Chunks = append(c.Chunks, chunk)
+	c.Unconfirmed = nil
+	if c.Listener == nil {
+		return chunk, nil
+	}
+	return chunk, c.Listener.InsertSnapshotBlocks([]*ledger.SnapshotChunk{chunk})
+}
+
+// DeleteSnapshotBlocks deletes the snapshot blocks higher than height and all the unconfirmed blocks
+func (c *MockChain) DeleteSnapshotBlocks(height uint64) error {
+	chunks := make([]*ledger.SnapshotChunk, 0, uint64(len(c.Chunks))-height+1)
+	chunks = append(chunks, c.Chunks[height:]...)
+	chunks = append(chunks, &ledger.SnapshotChunk{AccountBlocks: c.Unconfirmed})
+	c.Chunks = c.Chunks[:height]
+	c.Unconfirmed = nil
+	if c.Listener == nil {
+		return nil
+	}
+	if err := c.Listener.PrepareDeleteSnapshotBlocks(chunks); err != nil {
+		return err
+	}
+	return c.Listener.DeleteSnapshotBlocks(chunks)
+}
@@ -0,0 +1,204 @@
+package eventlog
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+// reconcile makes the events follow the chain. The blocks of the events after the latest snapshot block
+// the chain still has are reverted if the chain doesn't have them, then the blocks after that snapshot
+// block and the unconfirmed blocks of the chain are inserted if the events miss them.
+// A new log starts from the latest snapshot block of the chain.
+func (el *EventLog) reconcile() error {
+	latest := el.chain.GetLatestSnapshotBlock()
+	if latest == nil {
+		return fmt.Errorf("the latest snapshot block is nil")
+	}
+
+	el.mu.RLock()
+	first, last, head := el.first, el.last, el.head
+	el.mu.RUnlock()
+
+	// the account blocks of the events that the chain still has
+	kept := make(map[types.Hash]struct{})
+	reverts, ancestor, found, err := el.revertsAfterChain(first, last, latest.Height, kept)
+	if err != nil {
+		return err
+	}
+	if !found {
+		// the snapshot blocks of the events have been pruned, the head is before the reverted ones
+		for _, event := range reverts {
+			if event.Type == EventRevertSnapshotBlock {
+				head = ledger.HashHeight{Height: event.SnapshotBlock.Height - 1, Hash: event.SnapshotBlock.PrevHash}
+			}
+		}
+		if head.Height == 0 {
+			head = ledger.HashHeight{Height: latest.Height, Hash: latest.Hash}
+		} else if ok, err := el.onChain(head.Height, head.Hash, latest.Height); err != nil {
+			return err
+		} else if !ok {
+			return fmt.Errorf("snapshot block %s/%d of the events is not on the chain", head.Hash, head.Height)
+		}
+		ancestor = head.Height
+	}
+
+	if len(reverts) > 0 {
+		el.log.Info(fmt.Sprintf("revert %d blocks the chain doesn't have", len(reverts)), "ancestor", ancestor)
+		if err := el.write(reverts); err != nil {
+			return err
+		}
+	}
+
+	inserted := 0
+	for height := ancestor; height < latest.Height; {
+		end := height + reconcileBatchSize
+		if end > latest.Height {
+			end = latest.Height
+		}
+		chunks, err := el.chain.GetSubLedger(height, end)
+		if err != nil {
+			return err
+		}
+
+		var events []*Event
+		for _, chunk := range chunks {
+			if chunk.SnapshotBlock == nil || chunk.SnapshotBlock.Height <= height {
+				continue
+			}
+			events = append(events, el.insertsOfMissing(chunk.AccountBlocks, kept)...)
+			events = append(events, &Event{
+				Type:          EventSnapshotBlock,
+				SnapshotBlock: NewSnapshotBlock(chunk),
+			})
+		}
+		if err := el.write(events); err != nil {
+			return err
+		}
+		inserted += len(events)
+		height = end
+	}
+
+	events := el.insertsOfMissing(el.chain.GetAllUnconfirmedBlocks(), kept)
+	if err := el.write(events); err != nil {
+		return err
+	}
+	inserted += len(events)
+	if inserted > 0 {
+		el.log.Info(fmt.Sprintf("insert %d blocks the events miss", inserted), "ancestor", ancestor)
+	}
+
+	// a new log records where it starts
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	if el.head.Height == 0 {
+		if err := el.db.Put([]byte{headKeyPrefix}, headValue(head), nil); err != nil {
+			return err
+		}
+		el.head = head
+	}
+	return nil
+}
+
+// revertsAfterChain walks the events back to the latest snapshot block which is on the chain, it returns
+// the reverts of the blocks after it the chain doesn't have, from the highest. The account blocks inserted
+// a few snapshot blocks before it are kept too, they may be confirmed after it.
+func (el *EventLog) revertsAfterChain(first, last, latestHeight uint64, kept map[types.Hash]struct{}) (reverts []*Event, ancestor uint64, found bool, err error) {
+	if last == 0 {
+		return nil, 0, false, nil
+	}
+
+	// the blocks reverted by the events, their insertions are skipped
+	reverted := make(map[types.Hash]int)
+	lookback := 0
+
+	iter := el.db.NewIterator(&util.Range{Start: eventKey(first), Limit: eventKey(last + 1)}, nil)
+	defer iter.Release()
+	for ok := iter.Last(); ok; ok = iter.Prev() {
+		event := &Event{}
+		if err := json.Unmarshal(iter.Value(), event); err != nil {
+			return nil, 0, false, fmt.Errorf("failed to decode event %d: %v", cursorOfKey(iter.Key()), err)
+		}
+
+		switch event.Type {
+		case EventRevertAccountBlock:
+			reverted[event.AccountBlock.Hash]++
+		case EventRevertSnapshotBlock:
+			reverted[event.SnapshotBlock.Hash]++
+		case EventAccountBlock:
+			hash := event.AccountBlock.Hash
+			if reverted[hash] > 0 {
+				reverted[hash]--
+				continue
+			}
+			if _, ok := kept[hash]; ok {
+				continue
+			}
+			if found {
+				kept[hash] = struct{}{}
+				continue
+			}
+			existed, err := el.chain.IsAccountBlockExisted(hash)
+			if err != nil {
+				return nil, 0, false, err
+			}
+			if existed {
+				kept[hash] = struct{}{}
+				continue
+			}
+			reverts = append(reverts, &Event{Type: EventRevertAccountBlock, AccountBlock: event.AccountBlock})
+		case EventSnapshotBlock:
+			sb := event.SnapshotBlock
+			if reverted[sb.Hash] > 0 {
+				reverted[sb.Hash]--
+				continue
+			}
+			if found {
+				if lookback++; lookback >= reconcileLookback {
+					return reverts, ancestor, found, nil
+				}
+				continue
+			}
+			onChain, err := el.onChain(sb.Height, sb.Hash, latestHeight)
+			if err != nil {
+				return nil, 0, false, err
+			}
+			if onChain {
+				ancestor, found = sb.Height, true
+				continue
+			}
+			reverts = append(reverts, &Event{Type: EventRevertSnapshotBlock, SnapshotBlock: sb})
+		}
+	}
+	return reverts, ancestor, found, iter.Error()
+}
+
+func (el *EventLog) onChain(height uint64, hash types.Hash, latestHeight uint64) (bool, error) {
+	if height > latestHeight {
+		return false, nil
+	}
+	chainHash, err := el.chain.GetSnapshotHashByHeight(height)
+	if err != nil {
+		return false, err
+	}
+	return chainHash != nil && *chainHash == hash, nil
+}
+
+// insertsOfMissing returns the insertions of the blocks not kept by the events, they are kept then
+func (el *EventLog) insertsOfMissing(blocks []*ledger.AccountBlock, kept map[types.Hash]struct{}) []*Event {
+	var events []*Event
+	for _, block := range blocks {
+		if _, ok := kept[block.Hash]; ok {
+			continue
+		}
+		kept[block.Hash] = struct{}{}
+		events = append(events, &Event{
+			Type:         EventAccountBlock,
+			AccountBlock: el.accountBlockWithLogs(block),
+		})
+	}
+	return events
+}
@@ -0,0 +1,389 @@
+// Package eventsink pushes the chain events matching the filters of the sinks to webhooks and jsonl
+// files. Every sink follows the event log from its own cursor, which is persisted after each delivery,
+// so the events are delivered in order and at least once, across the restarts too. A sink failing longer
+// than its retries no longer keeps the events from the pruning, it skips the events pruned meanwhile.
+// The events keep the cursors of the event log, the receivers can drop the duplicated ones and find the
+// skipped ones by them.
+package eventsink
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	leveldb "github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/eventlog"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+const (
+	defaultMaxRetries       = 10
+	defaultRetryInterval    = time.Second
+	defaultMaxRetryInterval = time.Minute
+	defaultTimeout          = 10 * time.Second
+)
+
+var errStopped = errors.New("the sink is stopped")
+
+type Chain interface {
+	GetVmLogList(logListHash *types.Hash) (ledger.VmLogList, error)
+	GetAccountBlockByHash(blockHash types.Hash) (*ledger.AccountBlock, error)
+}
+
+type sink interface {
+	send(events []*eventlog.Event) error
+	close() error
+}
+
+// Manager runs the sinks following the event log, the cursors of the sinks are kept in a leveldb
+type Manager struct {
+	el      *eventlog.EventLog
+	chain   Chain
+	db      *leveldb.DB
+	runners []*runner
+
+	log log15.Logger
+}
+
+// New opens the cursors of the sinks in dir, a new sink starts from the events after the last one
+func New(el *eventlog.EventLog, chain Chain, dir string, cfgs []*config.EventSink) (*Manager, error) {
+	db, err := leveldb.OpenFile(dir, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manager{
+		el:    el,
+		chain: chain,
+		db:    db,
+		log:   log15.New("module", "eventsink"),
+	}
+
+	names := make(map[string]bool)
+	for _, cfg := range cfgs {
+		if names[cfg.Name] {
+			db.Close()
+			return nil, fmt.Errorf("duplicated event sink %q", cfg.Name)
+		}
+		names[cfg.Name] = true
+
+		r, err := m.newRunner(cfg)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("event sink %q: %v", cfg.Name, err)
+		}
+		m.runners = append(m.runners, r)
+	}
+
+	el.AddRetainer(m.firstCursor)
+	return m, nil
+}
+
+func cursorKey(name string) []byte {
+	return []byte("cursor_" + name)
+}
+
+// firstCursor returns the first cursor of the events not delivered to all the sinks, except the failing ones
+func (m *Manager) firstCursor() uint64 {
+	first := uint64(math.MaxUint64)
+	for _, r := range m.runners {
+		if atomic.LoadInt32(&r.failing) == 1 {
+			continue
+		}
+		if cursor := atomic.LoadUint64(&r.cursor) + 1; cursor < first {
+			first = cursor
+		}
+	}
+	return first
+}
+
+// Start starts the deliveries, the event log should be started first
+func (m *Manager) Start() {
+	for _, r := range m.runners {
+		r.start()
+	}
+}
+
+// Stop stops the deliveries, the events not delivered are delivered after the next start
+func (m *Manager) Stop() {
+	for _, r := range m.runners {
+		r.stop()
+	}
+	if err := m.db.Close(); err != nil {
+		m.log.Error(fmt.Sprintf("close the cursors failed, error is %s", err))
+	}
+}
+
+// runner delivers the events to a sink in order, a failed delivery is retried with backoff until it succeeds
+type runner struct {
+	m      *Manager
+	name   string
+	filter *filter
+	sink   sink
+
+	maxRetries       int
+	retryInterval    time.Duration
+	maxRetryInterval time.Duration
+
+	// the cursor of the last event delivered
+	cursor uint64
+	// 1 once a delivery failed after maxRetries retries, until it succeeds. The events of a failing sink
+	// are not retained.
+	failing int32
+
+	// the account blocks confirmed by the reverted snapshot blocks, their reverts are pushed to the
+	// sinks waiting for the confirmations
+	confirmedReverts map[types.Hash]bool
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+
+	log log15.Logger
+}
+
+func (m *Manager) newRunner(cfg *config.EventSink) (*runner, error) {
+	f, err := newFilter(cfg.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &runner{
+		m:                m,
+		name:             cfg.Name,
+		filter:           f,
+		maxRetries:       cfg.MaxRetries,
+		retryInterval:    time.Duration(cfg.RetryInterval) * time.Millisecond,
+		maxRetryInterval: time.Duration(cfg.MaxRetryInterval) * time.Millisecond,
+		confirmedReverts: make(map[types.Hash]bool),
+		log:              m.log.New("sink", cfg.Name),
+	}
+	if r.maxRetries == 0 {
+		r.maxRetries = defaultMaxRetries
+	}
+	if r.retryInterval == 0 {
+		r.retryInterval = defaultRetryInterval
+	}
+	if r.maxRetryInterval == 0 {
+		r.maxRetryInterval = defaultMaxRetryInterval
+	}
+
+	switch cfg.Type {
+	case config.EventSinkWebhook:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("the url of the webhook is empty")
+		}
+		timeout := time.Duration(cfg.Timeout) * time.Second
+		if timeout == 0 {
+			timeout = defaultTimeout
+		}
+		r.sink = newWebhookSink(cfg.URL, cfg.Secret, timeout)
+	case config.EventSinkFile:
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("the path of the file is empty")
+		}
+		r.sink = newFileSink(cfg.Path, cfg.MaxSize, cfg.MaxFiles)
+	default:
+		return nil, fmt.Errorf("unknown type %q", cfg.Type)
+	}
+
+	value, err := m.db.Get(cursorKey(r.name), nil)
+	switch {
+	case err == leveldb.ErrNotFound:
+		_, r.cursor = m.el.Cursors()
+		if err := r.saveCursor(r.cursor); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case len(value) != 8:
+		return nil, fmt.Errorf("invalid cursor %x", value)
+	default:
+		r.cursor = binary.BigEndian.Uint64(value)
+	}
+	return r, nil
+}
+
+func (r *runner) saveCursor(cursor uint64) error {
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, cursor)
+	return r.m.db.Put(cursorKey(r.name), value, nil)
+}
+
+func (r *runner) start() {
+	r.quit = make(chan struct{})
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		for {
+			from := atomic.LoadUint64(&r.cursor) + 1
+			err := r.m.el.Follow(from, r.quit, r.handle)
+			if err == eventlog.ErrCursorPruned {
+				if err = r.skipPruned(from); err == nil {
+					continue
+				}
+			}
+			if err != nil && err != errStopped && err != eventlog.ErrClosed {
+				atomic.StoreInt32(&r.failing, 1)
+				r.log.Error(fmt.Sprintf("the sink stops until a restart, the events after %d are not retained, error is %s",
+					atomic.LoadUint64(&r.cursor), err))
+			}
+			return
+		}
+	}()
+}
+
+// skipPruned moves the cursor to the first event kept, the events from the cursor were pruned while the
+// sink was failing
+func (r *runner) skipPruned(from uint64) error {
+	first, _ := r.m.el.Cursors()
+	r.log.Warn(fmt.Sprintf("the events from %d to %d are pruned while the sink was failing, they are skipped", from, first-1))
+	if err := r.saveCursor(first - 1); err != nil {
+		return err
+	}
+	atomic.StoreUint64(&r.cursor, first-1)
+	return nil
+}
+
+func (r *runner) stop() {
+	close(r.quit)
+	r.wg.Wait()
+	if err := r.sink.close(); err != nil {
+		r.log.Error(fmt.Sprintf("close failed, error is %s", err))
+	}
+}
+
+// handle delivers the events matching the filter, and saves the cursor of the last event
+func (r *runner) handle(events []*eventlog.Event) error {
+	if matched := r.match(events); len(matched) > 0 {
+		if err := r.deliver(matched); err != nil {
+			return err
+		}
+	}
+
+	cursor := events[len(events)-1].Cursor
+	if err := r.saveCursor(cursor); err != nil {
+		return err
+	}
+	atomic.StoreUint64(&r.cursor, cursor)
+	return nil
+}
+
+// deliver sends the events until it succeeds or the sink is stopped, the interval doubles after each
+// retry up to maxRetryInterval. The sink is failing after maxRetries retries, until the events are sent.
+func (r *runner) deliver(events []*eventlog.Event) error {
+	interval := r.retryInterval
+	for i := 0; ; i++ {
+		err := r.sink.send(events)
+		if err == nil {
+			if atomic.CompareAndSwapInt32(&r.failing, 1, 0) {
+				r.log.Info(fmt.Sprintf("deliver %d events succeeded after %d retries", len(events), i))
+			}
+			return nil
+		}
+		if i == r.maxRetries {
+			atomic.StoreInt32(&r.failing, 1)
+			r.log.Error(fmt.Sprintf("deliver %d events failed after %d retries, the events are not retained "+
+				"for the sink until they are delivered, error is %s", len(events), i, err))
+		} else {
+			r.log.Warn(fmt.Sprintf("deliver %d events failed, error is %s", len(events), err), "retry", i+1)
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-r.quit:
+			return errStopped
+		}
+		if interval *= 2; interval > r.maxRetryInterval {
+			interval = r.maxRetryInterval
+		}
+	}
+}
+
+// match returns the events for the sink. The sinks waiting for the confirmations get the account blocks
+// confirmed by the snapshot blocks with the cursors of the snapshot blocks.
+func (r *runner) match(events []*eventlog.Event) []*eventlog.Event {
+	var matched []*eventlog.Event
+	for _, event := range events {
+		switch event.Type {
+		case eventlog.EventAccountBlock:
+			if !r.filter.confirmed && r.matchAccountBlock(event.AccountBlock) {
+				matched = append(matched, event)
+			}
+		case eventlog.EventRevertAccountBlock:
+			if r.filter.confirmed {
+				if !r.confirmedReverts[event.AccountBlock.Hash] {
+					continue
+				}
+				delete(r.confirmedReverts, event.AccountBlock.Hash)
+			}
+			if r.matchAccountBlock(event.AccountBlock) {
+				matched = append(matched, event)
+			}
+		case eventlog.EventSnapshotBlock:
+			if r.filter.snapshotBlocks {
+				matched = append(matched, event)
+			}
+			if r.filter.confirmed {
+				matched = append(matched, r.confirmedEvents(event)...)
+			}
+		case eventlog.EventRevertSnapshotBlock:
+			if r.filter.snapshotBlocks {
+				matched = append(matched, event)
+			}
+			if r.filter.confirmed {
+				for _, block := range event.SnapshotBlock.AccountBlocks {
+					r.confirmedReverts[block.Hash] = true
+				}
+			}
+		}
+	}
+	return matched
+}
+
+// confirmedEvents returns the events of the account blocks confirmed by the snapshot block, the blocks
+// rolled back by the chain later are skipped, their reverts follow in the event log
+func (r *runner) confirmedEvents(event *eventlog.Event) []*eventlog.Event {
+	var events []*eventlog.Event
+	for _, confirmed := range event.SnapshotBlock.AccountBlocks {
+		block, err := r.m.chain.GetAccountBlockByHash(confirmed.Hash)
+		if err != nil || block == nil {
+			r.log.Warn(fmt.Sprintf("get the confirmed block failed, error is %v", err), "hash", confirmed.Hash)
+			continue
+		}
+		logs, err := r.m.chain.GetVmLogList(block.LogHash)
+		if err != nil {
+			r.log.Error(fmt.Sprintf("get vm logs failed, error is %s", err), "hash", block.Hash)
+		}
+		ab := eventlog.NewAccountBlock(block, logs)
+		if r.matchAccountBlock(ab) {
+			events = append(events, &eventlog.Event{
+				Cursor:       event.Cursor,
+				Type:         eventlog.EventAccountBlock,
+				AccountBlock: ab,
+			})
+		}
+	}
+	return events
+}
+
+// matchAccountBlock fills the token and the amount of the send block into a receive block, then
+// matches it with the filter
+func (r *runner) matchAccountBlock(ab *eventlog.AccountBlock) bool {
+	if ledger.IsReceiveBlock(ab.BlockType) {
+		sendBlock, err := r.m.chain.GetAccountBlockByHash(ab.FromBlockHash)
+		if err != nil {
+			r.log.Error(fmt.Sprintf("get the send block failed, error is %s", err), "hash", ab.FromBlockHash)
+		} else if sendBlock != nil {
+			ab.TokenId = sendBlock.TokenId
+			ab.Amount = eventlog.NewAccountBlock(sendBlock, nil).Amount
+		}
+	}
+	return r.filter.matchAccountBlock(ab)
+}
@@ -0,0 +1,294 @@
+package eventsink
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/common/fileutils"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/eventlog"
+)
+
+func newTransfer(from, to types.Address, amount int64) (send, receive *ledger.AccountBlock) {
+	send = &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		Hash:           types.DataHash([]byte(from.String() + to.String() + "send")),
+		Height:         1,
+		AccountAddress: from,
+		ToAddress:      to,
+		TokenId:        ledger.ViteTokenId,
+		Amount:         big.NewInt(amount),
+	}
+	receive = &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeReceive,
+		Hash:           types.DataHash([]byte(from.String() + to.String() + "receive")),
+		Height:         1,
+		AccountAddress: to,
+		FromBlockHash:  send.Hash,
+	}
+	return
+}
+
+// newEventLog starts an event log in dir on the chain
+func newEventLog(t *testing.T, dir string, chain *eventlog.MockChain) *eventlog.EventLog {
+	el, err := eventlog.New(filepath.Join(dir, "events"), chain, 0)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.NoError(t, el.Start()) {
+		t.FailNow()
+	}
+	return el
+}
+
+func receiveEvents(t *testing.T, received <-chan []*eventlog.Event) []*eventlog.Event {
+	select {
+	case events := <-received:
+		return events
+	case <-time.After(5 * time.Second):
+		t.Fatal("the events are not posted")
+		return nil
+	}
+}
+
+func TestManager_Webhook(t *testing.T) {
+	dir := fileutils.CreateTempDir()
+	defer os.RemoveAll(dir)
+	deposit, other := types.AddressGovernance, types.AddressQuota
+
+	received := make(chan []*eventlog.Event, 10)
+	failures := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get(signatureHeader) != Sign([]byte("secret"), body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		// the first post fails and is retried
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var events []*eventlog.Event
+		json.Unmarshal(body, &events)
+		received <- events
+	}))
+	defer server.Close()
+
+	chain := eventlog.NewMockChain()
+	el := newEventLog(t, dir, chain)
+	defer el.Stop()
+	m, err := New(el, chain, filepath.Join(dir, "eventsinks"), []*config.EventSink{{
+		Name:          "deposits",
+		Type:          config.EventSinkWebhook,
+		URL:           server.URL,
+		Secret:        "secret",
+		MaxRetries:    3,
+		RetryInterval: 10,
+		Filter: &config.EventFilter{
+			Addresses:      []types.Address{deposit},
+			OnroadReceived: true,
+			Confirmed:      true,
+		},
+	}})
+	assert.NoError(t, err)
+	m.Start()
+	defer m.Stop()
+
+	send, receive := newTransfer(other, deposit, 100)
+	_, otherReceive := newTransfer(deposit, other, 1)
+	assert.NoError(t, chain.InsertAccountBlocks(send))
+	_, err = chain.InsertSnapshotBlock("sb1")
+	assert.NoError(t, err)
+	assert.NoError(t, chain.InsertAccountBlocks(receive, otherReceive))
+	_, err = chain.InsertSnapshotBlock("sb2")
+	assert.NoError(t, err)
+
+	// the receive block is pushed once it is confirmed, its revert once the confirmation is rolled back
+	events := receiveEvents(t, received)
+	if assert.Equal(t, 1, len(events)) {
+		assert.Equal(t, eventlog.EventAccountBlock, events[0].Type)
+		assert.Equal(t, receive.Hash, events[0].AccountBlock.Hash)
+	}
+	assert.NoError(t, chain.DeleteSnapshotBlocks(2))
+	reverts := receiveEvents(t, received)
+	if assert.Equal(t, 1, len(reverts)) {
+		assert.Equal(t, eventlog.EventRevertAccountBlock, reverts[0].Type)
+		assert.Equal(t, receive.Hash, reverts[0].AccountBlock.Hash)
+		assert.True(t, reverts[0].Cursor > events[0].Cursor)
+	}
+	for _, event := range append(events, reverts...) {
+		assert.Equal(t, ledger.ViteTokenId, event.AccountBlock.TokenId)
+		assert.Equal(t, "100", event.AccountBlock.Amount)
+	}
+}
+
+func TestManager_Resume(t *testing.T) {
+	dir := fileutils.CreateTempDir()
+	defer os.RemoveAll(dir)
+
+	var down int32 = 1
+	received := make(chan []*eventlog.Event, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var events []*eventlog.Event
+		json.NewDecoder(r.Body).Decode(&events)
+		received <- events
+	}))
+	defer server.Close()
+
+	cfgs := []*config.EventSink{{
+		Name:          "all",
+		Type:          config.EventSinkWebhook,
+		URL:           server.URL,
+		MaxRetries:    1,
+		RetryInterval: 10,
+	}}
+	chain := eventlog.NewMockChain()
+	el := newEventLog(t, dir, chain)
+	m, err := New(el, chain, filepath.Join(dir, "eventsinks"), cfgs)
+	assert.NoError(t, err)
+	m.Start()
+
+	send, _ := newTransfer(types.AddressQuota, types.AddressGovernance, 1)
+	assert.NoError(t, chain.InsertAccountBlocks(send))
+
+	// the event is kept during the retries, and released once the sink is failing
+	assert.Equal(t, uint64(1), m.firstCursor())
+	assert.Eventually(t, func() bool {
+		return m.firstCursor() == math.MaxUint64
+	}, 5*time.Second, 10*time.Millisecond)
+	m.Stop()
+	assert.NoError(t, el.Stop())
+
+	// the event is delivered from the persisted cursor after a restart
+	atomic.StoreInt32(&down, 0)
+	el = newEventLog(t, dir, chain)
+	defer el.Stop()
+	m, err = New(el, chain, filepath.Join(dir, "eventsinks"), cfgs)
+	assert.NoError(t, err)
+	m.Start()
+	defer m.Stop()
+
+	events := receiveEvents(t, received)
+	if assert.Equal(t, 1, len(events)) {
+		assert.Equal(t, uint64(1), events[0].Cursor)
+		assert.Equal(t, send.Hash, events[0].AccountBlock.Hash)
+	}
+	assert.Eventually(t, func() bool {
+		return m.firstCursor() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestManager_SkipPruned(t *testing.T) {
+	dir := fileutils.CreateTempDir()
+	defer os.RemoveAll(dir)
+
+	var down int32 = 1
+	received := make(chan []*eventlog.Event, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var events []*eventlog.Event
+		json.NewDecoder(r.Body).Decode(&events)
+		received <- events
+	}))
+	defer server.Close()
+
+	chain := eventlog.NewMockChain()
+	el, err := eventlog.New(filepath.Join(dir, "events"), chain, 1)
+	assert.NoError(t, err)
+	assert.NoError(t, el.Start())
+	defer el.Stop()
+	m, err := New(el, chain, filepath.Join(dir, "eventsinks"), []*config.EventSink{{
+		Name:             "all",
+		Type:             config.EventSinkWebhook,
+		URL:              server.URL,
+		MaxRetries:       1,
+		RetryInterval:    10,
+		MaxRetryInterval: 10,
+	}})
+	assert.NoError(t, err)
+	m.Start()
+	defer m.Stop()
+
+	send, receive := newTransfer(types.AddressQuota, types.AddressGovernance, 1)
+	assert.NoError(t, chain.InsertAccountBlocks(send))
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&m.runners[0].failing) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the failing sink keeps retrying the first event, the next ones are pruned but the last one
+	other, _ := newTransfer(types.AddressGovernance, types.AddressQuota, 1)
+	assert.NoError(t, chain.InsertAccountBlocks(receive))
+	assert.NoError(t, chain.InsertAccountBlocks(other))
+	first, last := el.Cursors()
+	assert.Equal(t, uint64(3), first)
+	assert.Equal(t, uint64(3), last)
+
+	atomic.StoreInt32(&down, 0)
+	events := receiveEvents(t, received)
+	if assert.Equal(t, 1, len(events)) {
+		assert.Equal(t, uint64(1), events[0].Cursor)
+	}
+	events = receiveEvents(t, received)
+	if assert.Equal(t, 1, len(events)) {
+		assert.Equal(t, uint64(3), events[0].Cursor)
+		assert.Equal(t, other.Hash, events[0].AccountBlock.Hash)
+	}
+	assert.Eventually(t, func() bool {
+		return m.firstCursor() == 4
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestFileSink(t *testing.T) {
+	dir := fileutils.CreateTempDir()
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "events.jsonl")
+
+	s := newFileSink(path, 1, 2)
+	from := types.AddressQuota
+	for i := int64(1); i <= 3; i++ {
+		send, _ := newTransfer(from, from, i)
+		send.Height = uint64(i)
+		event := &eventlog.Event{Cursor: uint64(i), Type: eventlog.EventAccountBlock, AccountBlock: eventlog.NewAccountBlock(send, nil)}
+		assert.NoError(t, s.send([]*eventlog.Event{event}))
+	}
+	assert.NoError(t, s.close())
+
+	// every event is rotated to a file, only the last 2 files are kept
+	files, err := filepath.Glob(path + ".*")
+	assert.NoError(t, err)
+	if !assert.Equal(t, 2, len(files)) {
+		t.FailNow()
+	}
+	file, err := os.Open(files[1])
+	assert.NoError(t, err)
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	assert.True(t, scanner.Scan())
+	event := &eventlog.Event{}
+	assert.NoError(t, json.Unmarshal(scanner.Bytes(), event))
+	assert.Equal(t, uint64(3), event.AccountBlock.Height)
+	assert.False(t, scanner.Scan())
+}
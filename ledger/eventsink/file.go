@@ -0,0 +1,110 @@
+package eventsink
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/ledger/eventlog"
+)
+
+// fileSink appends the events to a file as json lines. The file is renamed to path.<time> once it
+// is larger than maxSize, only the last maxFiles rotated files are kept.
+type fileSink struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	file *os.File
+	size int64
+}
+
+func newFileSink(path string, maxSize int64, maxFiles int) *fileSink {
+	return &fileSink{
+		path:     path,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+	}
+}
+
+func (s *fileSink) open() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	s.file, s.size = file, info.Size()
+	return nil
+}
+
+func (s *fileSink) send(events []*eventlog.Event) error {
+	if s.file == nil {
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+
+	var data []byte
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	n, err := s.file.Write(data)
+	s.size += int64(n)
+	if err != nil {
+		return err
+	}
+
+	if s.maxSize > 0 && s.size >= s.maxSize {
+		return s.rotate()
+	}
+	return nil
+}
+
+func (s *fileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	s.file = nil
+
+	rotated := s.path + "." + time.Now().UTC().Format("20060102T150405.000000000")
+	if err := os.Rename(s.path, rotated); err != nil {
+		return err
+	}
+	if s.maxFiles <= 0 {
+		return nil
+	}
+
+	files, err := filepath.Glob(s.path + ".*")
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for i := 0; i < len(files)-s.maxFiles; i++ {
+		if err := os.Remove(files[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *fileSink) close() error {
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,122 @@
+package eventsink
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/eventlog"
+)
+
+// filter is a config.EventFilter with the lists in maps
+type filter struct {
+	addresses map[types.Address]bool
+	tokenIds  map[types.TokenTypeId]bool
+	logTopics map[types.Hash]bool
+
+	onroadReceived bool
+	confirmed      bool
+	snapshotBlocks bool
+}
+
+func newFilter(cfg *config.EventFilter) (*filter, error) {
+	f := &filter{
+		addresses: make(map[types.Address]bool),
+		tokenIds:  make(map[types.TokenTypeId]bool),
+		logTopics: make(map[types.Hash]bool),
+	}
+	if cfg == nil {
+		return f, nil
+	}
+
+	for _, addr := range cfg.Addresses {
+		f.addresses[addr] = true
+	}
+	if cfg.AddressFile != "" {
+		if err := readAddresses(cfg.AddressFile, f.addresses); err != nil {
+			return nil, err
+		}
+	}
+	for _, tokenId := range cfg.TokenIds {
+		f.tokenIds[tokenId] = true
+	}
+	for _, topic := range cfg.LogTopics {
+		f.logTopics[topic] = true
+	}
+	f.onroadReceived = cfg.OnroadReceived
+	f.confirmed = cfg.Confirmed
+	f.snapshotBlocks = cfg.SnapshotBlocks
+	return f, nil
+}
+
+// readAddresses reads an address per line, the empty lines and the lines starting with # are skipped
+func readAddresses(filename string, addresses map[types.Address]bool) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		addr, err := types.HexToAddress(line)
+		if err != nil {
+			return err
+		}
+		addresses[addr] = true
+	}
+	return scanner.Err()
+}
+
+func (f *filter) matchAccountBlock(ab *eventlog.AccountBlock) bool {
+	if f.onroadReceived && !ledger.IsReceiveBlock(ab.BlockType) {
+		return false
+	}
+
+	if len(f.addresses) > 0 && !f.addresses[ab.Address] && !f.addresses[ab.ToAddress] {
+		matched := false
+		for _, send := range ab.SendBlocks {
+			if f.addresses[send.ToAddress] {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(f.tokenIds) > 0 && !f.tokenIds[ab.TokenId] {
+		matched := false
+		for _, send := range ab.SendBlocks {
+			if f.tokenIds[send.TokenId] {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(f.logTopics) > 0 {
+		matched := false
+		for _, log := range ab.Logs {
+			if len(log.Topics) > 0 && f.logTopics[log.Topics[0]] {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
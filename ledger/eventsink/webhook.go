@@ -0,0 +1,78 @@
+package eventsink
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/ledger/eventlog"
+)
+
+const (
+	signatureHeader = "X-Vite-Signature"
+	deliveryHeader  = "X-Vite-Delivery"
+)
+
+// webhookSink posts the events as a json array. The delivery id is the same when a post is retried,
+// so the receiver can ignore the duplicated posts.
+type webhookSink struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+func newWebhookSink(url, secret string, timeout time.Duration) *webhookSink {
+	return &webhookSink{
+		url:    url,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Sign returns the value of the X-Vite-Signature header of the body
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *webhookSink) send(events []*eventlog.Event) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(deliveryHeader, types.DataHash(body).String())
+	if len(s.secret) > 0 {
+		req.Header.Set(signatureHeader, Sign(s.secret, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s responds %s", s.url, resp.Status)
+	}
+	return nil
+}
+
+func (s *webhookSink) close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
@@ -60,6 +60,21 @@ func NewGenerator(chain vm_db.Chain, sbpStatReader core.SBPStatReader, addr type
 	return gen, nil
 }
 
+// NewGeneratorWithVmDb is NewGenerator with a prepared vmDb, eg. a vmDb on the history state to re-execute a block,
+// the vm reports the execution to the tracer if it is not nil.
+func NewGeneratorWithVmDb(chain vm_db.Chain, sbpStatReader core.SBPStatReader, vmDb interfaces.VmDb, tracer vm.Tracer) interfaces.Generator {
+	gen := &generator{
+		chain: chain,
+		vmDb:  vmDb,
+		vm:    vm.NewVM(util.NewVMConsensusReader(sbpStatReader)),
+		log:   log15.New("module", "Generator"),
+	}
+	if tracer != nil {
+		gen.vm.SetTracer(tracer)
+	}
+	return gen
+}
+
 // GenerateWithBlock implements the method to generate a transaction with VM execution results
 // from a block which contains the complete transaction info.
 func (gen *generator) GenerateWithBlock(block *ledger.AccountBlock, fromBlock *ledger.AccountBlock) (*interfaces.GenResult, error) {
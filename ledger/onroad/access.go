@@ -53,3 +53,15 @@ func (manager *Manager) deleteDirect(sendBlock *ledger.AccountBlock) {
 func (manager *Manager) insertBlockToPool(block *interfaces.VmAccountBlock) error {
 	return manager.pool.AddDirectAccountBlock(block.AccountBlock.AccountAddress, block)
 }
+
+// GetOnRoadBacklog method returns the num of OnRoad blocks waiting to be received in all contract OnRoad pools.
+func (manager *Manager) GetOnRoadBacklog() uint64 {
+	backlog := uint64(0)
+	manager.onRoadPools.Range(func(_, value interface{}) bool {
+		if sum, ok := value.(onroad_pool.OnRoadPool).Info()["Sum"].(int); ok {
+			backlog += uint64(sum)
+		}
+		return true
+	})
+	return backlog
+}
@@ -119,6 +119,18 @@ func logfmt(buf *bytes.Buffer, ctx []interface{}, color int) {
 	buf.WriteByte('\n')
 }
 
+// FormatFromString returns the format of the name, "logfmt" or "json". The empty name is logfmt.
+func FormatFromString(name string) (Format, error) {
+	switch name {
+	case "", "logfmt":
+		return LogfmtFormat(), nil
+	case "json":
+		return JsonFormat(), nil
+	default:
+		return nil, fmt.Errorf("Unknown format: %v", name)
+	}
+}
+
 // JsonFormat formats log records as JSON objects separated by newlines.
 // It is the equivalent of JsonFormatEx(false, true).
 func JsonFormat() Format {
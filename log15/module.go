@@ -0,0 +1,101 @@
+package log15
+
+import (
+	"strings"
+	"sync"
+)
+
+// moduleKey is the context key the loggers of the modules are created with,
+// eg. log15.New("module", "pool")
+const moduleKey = "module"
+
+// ModuleLvls are the max levels of the records of the modules, they can be changed at runtime.
+// The level of a module is inherited by its submodules, eg. the level of "pool" applies to
+// "pool/tree" unless "pool/tree" has its own one. The other records use the default level.
+type ModuleLvls struct {
+	mu      sync.RWMutex
+	def     Lvl
+	modules map[string]Lvl
+}
+
+func NewModuleLvls(def Lvl) *ModuleLvls {
+	return &ModuleLvls{
+		def:     def,
+		modules: make(map[string]Lvl),
+	}
+}
+
+func (m *ModuleLvls) Default() Lvl {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.def
+}
+
+func (m *ModuleLvls) SetDefault(lvl Lvl) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.def = lvl
+}
+
+// Set overrides the level of the module and its submodules
+func (m *ModuleLvls) Set(module string, lvl Lvl) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.modules[module] = lvl
+}
+
+// Reset removes the override of the module
+func (m *ModuleLvls) Reset(module string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.modules, module)
+}
+
+// Get returns the level of the module
+func (m *ModuleLvls) Get(module string) Lvl {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for {
+		if lvl, ok := m.modules[module]; ok {
+			return lvl
+		}
+		i := strings.LastIndex(module, "/")
+		if i < 0 {
+			return m.def
+		}
+		module = module[:i]
+	}
+}
+
+// Modules returns a copy of the overrides
+func (m *ModuleLvls) Modules() map[string]Lvl {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	modules := make(map[string]Lvl, len(m.modules))
+	for module, lvl := range m.modules {
+		modules[module] = lvl
+	}
+	return modules
+}
+
+// ModuleLvlFilterHandler returns a Handler that only writes the records at or below the level of
+// their module.
+func ModuleLvlFilterHandler(lvls *ModuleLvls, h Handler) Handler {
+	return FilterHandler(func(r *Record) (pass bool) {
+		return r.Lvl <= lvls.Get(recordModule(r))
+	}, h)
+}
+
+// recordModule returns the module of the record, the last one wins if a logger is created from
+// the logger of another module
+func recordModule(r *Record) string {
+	module := ""
+	for i := 0; i+1 < len(r.Ctx); i += 2 {
+		if key, ok := r.Ctx[i].(string); ok && key == moduleKey {
+			if value, ok := r.Ctx[i+1].(string); ok {
+				module = value
+			}
+		}
+	}
+	return module
+}
@@ -0,0 +1,260 @@
+package log15
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the suffix of the rotated files, it sorts in the order of the rotations
+const backupTimeFormat = "20060102T150405.000"
+
+const compressSuffix = ".gz"
+
+// RotateOptions are the options of a RotatingWriter
+type RotateOptions struct {
+	// MaxSize is the size in bytes the file is rotated at, 0 disables the rotation by size
+	MaxSize int64
+	// Interval rotates the file periodically, the periods are aligned to UTC. 0 disables the
+	// rotation by time
+	Interval time.Duration
+	// MaxBackups is the count of the rotated files kept, 0 keeps all of them
+	MaxBackups int
+	// MaxAge removes the rotated files older than it, 0 keeps all of them
+	MaxAge time.Duration
+	// Compress gzips the rotated files
+	Compress bool
+}
+
+// RotatingWriter is an io.WriteCloser appending to a file, the file is renamed to
+// path.<UTC time> once it is larger than MaxSize or its interval is over. The rotated files are
+// compressed and removed in the background.
+type RotatingWriter struct {
+	path string
+	opts RotateOptions
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+
+	cleanMu sync.Mutex
+	wg      sync.WaitGroup
+}
+
+func NewRotatingWriter(path string, opts RotateOptions) *RotatingWriter {
+	return &RotatingWriter{
+		path: path,
+		opts: opts,
+	}
+}
+
+// RotatingFileHandler returns a handler writing the records to a RotatingWriter of the path
+func RotatingFileHandler(path string, opts RotateOptions, fmtr Format) Handler {
+	w := NewRotatingWriter(path, opts)
+	return closingHandler{w, StreamHandler(w, fmtr)}
+}
+
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	if w.file == nil {
+		if err := w.open(now); err != nil {
+			return 0, err
+		}
+	}
+	if w.size > 0 && w.shouldRotate(int64(len(p)), now) {
+		if err := w.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate rotates the file even if it is not full
+func (w *RotatingWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.rotate(time.Now())
+}
+
+// Close closes the file and waits for the compressions in progress
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	err := w.close()
+	w.mu.Unlock()
+
+	w.wg.Wait()
+	return err
+}
+
+func (w *RotatingWriter) shouldRotate(n int64, now time.Time) bool {
+	if w.opts.MaxSize > 0 && w.size+n > w.opts.MaxSize {
+		return true
+	}
+	if w.opts.Interval > 0 && !now.Truncate(w.opts.Interval).Equal(w.openedAt.Truncate(w.opts.Interval)) {
+		return true
+	}
+	return false
+}
+
+// open appends to the existing file, the file belongs to the interval it was last written in
+func (w *RotatingWriter) open(now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	w.file, w.size, w.openedAt = file, info.Size(), now
+	if w.size > 0 {
+		w.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+func (w *RotatingWriter) close() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func (w *RotatingWriter) rotate(now time.Time) error {
+	if err := w.close(); err != nil {
+		return err
+	}
+
+	backup := w.path + "." + now.UTC().Format(backupTimeFormat)
+	if err := os.Rename(w.path, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := w.open(now); err != nil {
+		return err
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.cleanMu.Lock()
+		defer w.cleanMu.Unlock()
+		if err := w.cleanup(now); err != nil {
+			fmt.Fprintf(os.Stderr, "clean up the rotated files of %s failed, error is %s\n", w.path, err)
+		}
+	}()
+	return nil
+}
+
+type backupFile struct {
+	name string
+	time time.Time
+}
+
+// backups returns the rotated files, the oldest first
+func (w *RotatingWriter) backups() ([]backupFile, error) {
+	names, err := filepath.Glob(w.path + ".*")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []backupFile
+	for _, name := range names {
+		suffix := strings.TrimSuffix(name[len(w.path)+1:], compressSuffix)
+		t, err := time.Parse(backupTimeFormat, suffix)
+		if err != nil {
+			continue
+		}
+		files = append(files, backupFile{name: name, time: t})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].time.Before(files[j].time)
+	})
+	return files, nil
+}
+
+// cleanup removes the rotated files beyond MaxBackups or older than MaxAge, and compresses the rest
+func (w *RotatingWriter) cleanup(now time.Time) error {
+	files, err := w.backups()
+	if err != nil {
+		return err
+	}
+
+	var kept []backupFile
+	for i, file := range files {
+		expired := w.opts.MaxAge > 0 && now.Sub(file.time) > w.opts.MaxAge
+		if (w.opts.MaxBackups > 0 && i < len(files)-w.opts.MaxBackups) || expired {
+			if err := os.Remove(file.name); err != nil {
+				return err
+			}
+			continue
+		}
+		kept = append(kept, file)
+	}
+
+	if !w.opts.Compress {
+		return nil
+	}
+	for _, file := range kept {
+		if strings.HasSuffix(file.name, compressSuffix) {
+			continue
+		}
+		if err := compressFile(file.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compressFile replaces the file with name.gz
+func compressFile(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+compressSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(name + compressSuffix)
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(name)
+}
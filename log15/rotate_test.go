@@ -0,0 +1,81 @@
+package log15
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sub", "test.log")
+
+	w := NewRotatingWriter(path, RotateOptions{MaxSize: 10, MaxBackups: 2, Compress: true})
+	for i := 0; i < 4; i++ {
+		if _, err := w.Write([]byte("0123456789")); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 3 files are rotated, the oldest one is removed and the others are compressed
+	backups, err := w.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups, got %d", len(backups))
+	}
+	for _, backup := range backups {
+		if !strings.HasSuffix(backup.name, compressSuffix) {
+			t.Fatalf("%s is not compressed", backup.name)
+		}
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "0123456789" {
+		t.Fatalf("unexpected content %q", data)
+	}
+}
+
+func TestModuleLvlFilterHandler(t *testing.T) {
+	lvls := NewModuleLvls(LvlInfo)
+	lvls.Set("pool", LvlDebug)
+	lvls.Set("pool/tree", LvlError)
+
+	var records []*Record
+	l := New()
+	l.SetHandler(ModuleLvlFilterHandler(lvls, FuncHandler(func(r *Record) error {
+		records = append(records, r)
+		return nil
+	})))
+
+	l.New("module", "pool").Debug("pass")
+	l.New("module", "pool/batch").Debug("pass")
+	l.New("module", "pool/tree").Warn("drop")
+	l.New("module", "net").Debug("drop")
+	l.New("module", "net").Info("pass")
+	lvls.Reset("pool")
+	l.New("module", "pool").Debug("drop")
+
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	for _, r := range records {
+		if r.Msg != "pass" {
+			t.Fatalf("unexpected record of module %s", recordModule(r))
+		}
+	}
+}
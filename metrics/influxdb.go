@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+// InfluxDBReporter pushes the metrics of the registry to influxdb in the line protocol periodically.
+type InfluxDBReporter struct {
+	registry *Registry
+	interval time.Duration
+
+	writeUrl string
+	username string
+	password string
+	hostTag  string
+
+	client *http.Client
+	log    log15.Logger
+
+	term chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewInfluxDBReporter(r *Registry, interval time.Duration, endpoint, database, username, password, hostTag string) (*InfluxDBReporter, error) {
+	u, err := url.Parse(strings.TrimRight(endpoint, "/") + "/write")
+	if err != nil {
+		return nil, fmt.Errorf("invalid influxdb endpoint %s: %v", endpoint, err)
+	}
+	query := u.Query()
+	query.Set("db", database)
+	query.Set("precision", "s")
+	u.RawQuery = query.Encode()
+
+	return &InfluxDBReporter{
+		registry: r,
+		interval: interval,
+		writeUrl: u.String(),
+		username: username,
+		password: password,
+		hostTag:  hostTag,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		log:      log15.New("module", "metrics/influxdb"),
+	}, nil
+}
+
+func (reporter *InfluxDBReporter) Start() {
+	reporter.term = make(chan struct{})
+
+	reporter.wg.Add(1)
+	go func() {
+		defer reporter.wg.Done()
+
+		ticker := time.NewTicker(reporter.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-reporter.term:
+				return
+			case <-ticker.C:
+				if err := reporter.Push(); err != nil {
+					reporter.log.Warn(fmt.Sprintf("push metrics to influxdb failed: %v", err))
+				}
+			}
+		}
+	}()
+}
+
+func (reporter *InfluxDBReporter) Stop() {
+	if reporter.term == nil {
+		return
+	}
+	close(reporter.term)
+	reporter.wg.Wait()
+	reporter.term = nil
+}
+
+// Push writes the current metrics to influxdb once
+func (reporter *InfluxDBReporter) Push() error {
+	var buf bytes.Buffer
+	WriteInfluxDBLines(&buf, reporter.registry, reporter.hostTag, time.Now())
+
+	req, err := http.NewRequest(http.MethodPost, reporter.writeUrl, &buf)
+	if err != nil {
+		return err
+	}
+	if len(reporter.username) > 0 {
+		req.SetBasicAuth(reporter.username, reporter.password)
+	}
+
+	resp, err := reporter.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("influxdb responds %s: %s", resp.Status, body)
+	}
+	return nil
+}
+
+// WriteInfluxDBLines writes every sample of the registry as one line, the labels and the host are tags.
+func WriteInfluxDBLines(w io.Writer, r *Registry, hostTag string, now time.Time) {
+	timestamp := now.Unix()
+
+	r.Each(func(c Collector) {
+		for _, s := range c.collect() {
+			var line strings.Builder
+			line.WriteString(measurementEscaper.Replace(c.Name() + s.suffix))
+
+			labels := s.labels
+			if len(hostTag) > 0 {
+				labels = make(Labels, len(s.labels)+1)
+				for k, v := range s.labels {
+					labels[k] = v
+				}
+				labels["host"] = hostTag
+			}
+
+			names := make([]string, 0, len(labels))
+			for name := range labels {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				line.WriteString("," + tagEscaper.Replace(name) + "=" + tagEscaper.Replace(labels[name]))
+			}
+
+			fmt.Fprintf(w, "%s value=%s %d\n", line.String(), formatFloat(s.value), timestamp)
+		}
+	})
+}
+
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	tagEscaper         = strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+)
@@ -0,0 +1,341 @@
+// Package metrics keeps the gauges, counters and histograms of the node in a registry,
+// which is exported as a prometheus endpoint or pushed to influxdb.
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Enabled is set by the node when MetricsEnable is true, the hot paths skip observations when it is false.
+var Enabled = false
+
+// DefaultRegistry holds the metrics registered by the packages of the node.
+var DefaultRegistry = NewRegistry()
+
+// DefaultBuckets are the upper bounds in seconds of the latency histograms.
+var DefaultBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+// Labels are the label pairs of a sample, label names are sorted when exported.
+type Labels map[string]string
+
+type sample struct {
+	suffix string
+	labels Labels
+	value  float64
+}
+
+// Collector is a metric which can be registered in a Registry.
+type Collector interface {
+	Name() string
+	Help() string
+	Type() string
+	collect() []sample
+}
+
+type desc struct {
+	name string
+	help string
+}
+
+func (d desc) Name() string {
+	return d.name
+}
+
+func (d desc) Help() string {
+	return d.help
+}
+
+// ---------------------------------- counter
+
+type Counter struct {
+	desc
+	value uint64
+}
+
+func NewCounter(name, help string) *Counter {
+	return &Counter{desc: desc{name: name, help: help}}
+}
+
+func (c *Counter) Type() string {
+	return typeCounter
+}
+
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+func (c *Counter) Add(delta uint64) {
+	atomic.AddUint64(&c.value, delta)
+}
+
+func (c *Counter) Value() uint64 {
+	return atomic.LoadUint64(&c.value)
+}
+
+func (c *Counter) collect() []sample {
+	return []sample{{value: float64(c.Value())}}
+}
+
+// ---------------------------------- gauge
+
+type Gauge struct {
+	desc
+	bits uint64
+}
+
+func NewGauge(name, help string) *Gauge {
+	return &Gauge{desc: desc{name: name, help: help}}
+}
+
+func (g *Gauge) Type() string {
+	return typeGauge
+}
+
+func (g *Gauge) Set(value float64) {
+	atomic.StoreUint64(&g.bits, math.Float64bits(value))
+}
+
+func (g *Gauge) Value() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&g.bits))
+}
+
+func (g *Gauge) collect() []sample {
+	return []sample{{value: g.Value()}}
+}
+
+// FuncGauge reads its value when it is collected, it is used to sample the size of queues and caches.
+type FuncGauge struct {
+	desc
+	fn func() float64
+}
+
+func NewFuncGauge(name, help string, fn func() float64) *FuncGauge {
+	return &FuncGauge{desc: desc{name: name, help: help}, fn: fn}
+}
+
+func (g *FuncGauge) Type() string {
+	return typeGauge
+}
+
+func (g *FuncGauge) collect() []sample {
+	return []sample{{value: g.fn()}}
+}
+
+// ---------------------------------- histogram
+
+type Histogram struct {
+	desc
+	labels Labels
+
+	upperBounds []float64
+
+	mu     sync.Mutex
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	return newHistogram(desc{name: name, help: help}, buckets, nil)
+}
+
+func newHistogram(d desc, buckets []float64, labels Labels) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	upperBounds := make([]float64, len(buckets))
+	copy(upperBounds, buckets)
+	sort.Float64s(upperBounds)
+
+	return &Histogram{
+		desc:        d,
+		labels:      labels,
+		upperBounds: upperBounds,
+		counts:      make([]uint64, len(upperBounds)),
+	}
+}
+
+func (h *Histogram) Type() string {
+	return typeHistogram
+}
+
+func (h *Histogram) Observe(value float64) {
+	if !Enabled {
+		return
+	}
+	index := sort.SearchFloat64s(h.upperBounds, value)
+
+	h.mu.Lock()
+	if index < len(h.counts) {
+		h.counts[index]++
+	}
+	h.count++
+	h.sum += value
+	h.mu.Unlock()
+}
+
+// UpdateSince observes the seconds elapsed since start
+func (h *Histogram) UpdateSince(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+func (h *Histogram) collect() []sample {
+	h.mu.Lock()
+	counts := make([]uint64, len(h.counts))
+	copy(counts, h.counts)
+	count, sum := h.count, h.sum
+	h.mu.Unlock()
+
+	samples := make([]sample, 0, len(counts)+3)
+	cumulative := uint64(0)
+	for i, upperBound := range h.upperBounds {
+		cumulative += counts[i]
+		samples = append(samples, sample{suffix: "_bucket", labels: h.withLabel("le", formatFloat(upperBound)), value: float64(cumulative)})
+	}
+	samples = append(samples,
+		sample{suffix: "_bucket", labels: h.withLabel("le", "+Inf"), value: float64(count)},
+		sample{suffix: "_sum", labels: h.labels, value: sum},
+		sample{suffix: "_count", labels: h.labels, value: float64(count)})
+	return samples
+}
+
+func (h *Histogram) withLabel(name, value string) Labels {
+	labels := make(Labels, len(h.labels)+1)
+	for k, v := range h.labels {
+		labels[k] = v
+	}
+	labels[name] = value
+	return labels
+}
+
+// HistogramVec is a group of histograms partitioned by the values of one label, eg. rpc method.
+type HistogramVec struct {
+	desc
+	label   string
+	buckets []float64
+
+	children sync.Map // label value -> *Histogram
+}
+
+func NewHistogramVec(name, help, label string, buckets []float64) *HistogramVec {
+	return &HistogramVec{desc: desc{name: name, help: help}, label: label, buckets: buckets}
+}
+
+func (v *HistogramVec) Type() string {
+	return typeHistogram
+}
+
+func (v *HistogramVec) WithLabelValue(value string) *Histogram {
+	if h, ok := v.children.Load(value); ok {
+		return h.(*Histogram)
+	}
+	h, _ := v.children.LoadOrStore(value, newHistogram(v.desc, v.buckets, Labels{v.label: value}))
+	return h.(*Histogram)
+}
+
+func (v *HistogramVec) collect() []sample {
+	var values []string
+	v.children.Range(func(key, _ interface{}) bool {
+		values = append(values, key.(string))
+		return true
+	})
+	sort.Strings(values)
+
+	var samples []sample
+	for _, value := range values {
+		samples = append(samples, v.WithLabelValue(value).collect()...)
+	}
+	return samples
+}
+
+// ---------------------------------- registry
+
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]Collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]Collector)}
+}
+
+func (r *Registry) Register(c Collector) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.collectors[c.Name()]; ok {
+		return fmt.Errorf("metric %s is registered", c.Name())
+	}
+	r.collectors[c.Name()] = c
+	return nil
+}
+
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.collectors, name)
+}
+
+// Each calls fn with the collectors sorted by name
+func (r *Registry) Each(fn func(c Collector)) {
+	r.mu.RLock()
+	collectors := make([]Collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		collectors = append(collectors, c)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(collectors, func(i, j int) bool {
+		return collectors[i].Name() < collectors[j].Name()
+	})
+	for _, c := range collectors {
+		fn(c)
+	}
+}
+
+func mustRegister(c Collector) {
+	if err := DefaultRegistry.Register(c); err != nil {
+		panic(err)
+	}
+}
+
+func NewRegisteredCounter(name, help string) *Counter {
+	c := NewCounter(name, help)
+	mustRegister(c)
+	return c
+}
+
+func NewRegisteredGauge(name, help string) *Gauge {
+	g := NewGauge(name, help)
+	mustRegister(g)
+	return g
+}
+
+func NewRegisteredHistogram(name, help string, buckets []float64) *Histogram {
+	h := NewHistogram(name, help, buckets)
+	mustRegister(h)
+	return h
+}
+
+func NewRegisteredHistogramVec(name, help, label string, buckets []float64) *HistogramVec {
+	v := NewHistogramVec(name, help, label, buckets)
+	mustRegister(v)
+	return v
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	Enabled = true
+	defer func() { Enabled = false }()
+
+	r := NewRegistry()
+	counter := NewCounter("vite_test_total", "test counter")
+	gauge := NewGauge("vite_test_gauge", "")
+	histogram := NewHistogramVec("vite_test_seconds", "test\nhistogram", "method", []float64{0.1, 1})
+	assert.NoError(t, r.Register(counter))
+	assert.NoError(t, r.Register(gauge))
+	assert.NoError(t, r.Register(histogram))
+	assert.NoError(t, r.Register(NewFuncGauge("vite_test_func", "", func() float64 { return 7 })))
+	assert.Error(t, r.Register(NewGauge("vite_test_gauge", "")))
+
+	counter.Add(3)
+	gauge.Set(1.5)
+	histogram.WithLabelValue("ledger_getSnapshotChainHeight").Observe(0.05)
+	histogram.WithLabelValue("ledger_getSnapshotChainHeight").Observe(0.5)
+	histogram.WithLabelValue("ledger_getSnapshotChainHeight").Observe(5)
+
+	var buf bytes.Buffer
+	assert.NoError(t, WritePrometheus(&buf, r))
+	assert.Equal(t, `# TYPE vite_test_func gauge
+vite_test_func 7
+# TYPE vite_test_gauge gauge
+vite_test_gauge 1.5
+# HELP vite_test_seconds test\nhistogram
+# TYPE vite_test_seconds histogram
+vite_test_seconds_bucket{le="0.1",method="ledger_getSnapshotChainHeight"} 1
+vite_test_seconds_bucket{le="1",method="ledger_getSnapshotChainHeight"} 2
+vite_test_seconds_bucket{le="+Inf",method="ledger_getSnapshotChainHeight"} 3
+vite_test_seconds_sum{method="ledger_getSnapshotChainHeight"} 5.55
+vite_test_seconds_count{method="ledger_getSnapshotChainHeight"} 3
+# HELP vite_test_total test counter
+# TYPE vite_test_total counter
+vite_test_total 3
+`, buf.String())
+}
+
+func TestHistogram_Disabled(t *testing.T) {
+	h := NewHistogram("vite_test_disabled", "", nil)
+	h.Observe(1)
+	assert.Equal(t, uint64(0), h.count)
+}
+
+func TestInfluxDBReporter_Push(t *testing.T) {
+	var body string
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+		query = r.URL.RawQuery
+		user, _, _ := r.BasicAuth()
+		assert.Equal(t, "vite", user)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	r := NewRegistry()
+	gauge := NewGauge("vite_test_gauge", "")
+	gauge.Set(2)
+	assert.NoError(t, r.Register(gauge))
+
+	reporter, err := NewInfluxDBReporter(r, time.Second, server.URL, "metrics", "vite", "pass", "node 1")
+	assert.NoError(t, err)
+	assert.NoError(t, reporter.Push())
+
+	assert.True(t, strings.HasPrefix(body, `vite_test_gauge,host=node\ 1 value=2 `), body)
+	assert.Contains(t, query, "db=metrics")
+}
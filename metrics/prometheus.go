@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// PrometheusHandler serves the metrics of the registry in the prometheus text format.
+func PrometheusHandler(r *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", prometheusContentType)
+		if err := WritePrometheus(w, r); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// WritePrometheus writes the metrics of the registry in the prometheus text format.
+func WritePrometheus(w io.Writer, r *Registry) error {
+	bw := bufio.NewWriter(w)
+
+	r.Each(func(c Collector) {
+		if help := c.Help(); len(help) > 0 {
+			bw.WriteString("# HELP " + c.Name() + " " + escapeHelp(help) + "\n")
+		}
+		bw.WriteString("# TYPE " + c.Name() + " " + c.Type() + "\n")
+
+		for _, s := range c.collect() {
+			bw.WriteString(c.Name() + s.suffix)
+			writeLabels(bw, s.labels)
+			bw.WriteString(" " + formatFloat(s.value) + "\n")
+		}
+	})
+
+	return bw.Flush()
+}
+
+func writeLabels(bw *bufio.Writer, labels Labels) {
+	if len(labels) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bw.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteString(name + "=\"" + escapeLabelValue(labels[name]) + "\"")
+	}
+	bw.WriteByte('}')
+}
+
+var (
+	helpEscaper       = strings.NewReplacer("\\", `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer("\\", `\\`, "\n", `\n`, "\"", `\"`)
+)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
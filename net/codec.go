@@ -19,6 +19,10 @@
 package net
 
 import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
@@ -33,7 +37,11 @@ const maxPayloadSize = (1 << (maxPayloadLength * 8)) - 1 // 15MB
 const readMsgTimeout = 30 * time.Second
 const writeMsgTimeout = 30 * time.Second
 
+const sealedLengthSize = 4
+const maxSealedSize = 2 + 4 + maxPayloadLength + maxPayloadSize + 16
+
 var errMsgPayloadTooLarge = errors.New("message payload is too large")
+var errMsgAuthFailed = errors.New("message authentication failed")
 
 // Codec is an transport can encode messages to bytes, transmit bytes, then decode bytes to messages
 type Codec interface {
@@ -65,13 +73,27 @@ type CodecFactory interface {
  * Length size: the bytes-number of `Payload Length` field, min 0 bytes ~ max 3 bytes
  * Id size: the bytes-number of `Id size` field: 0 bytes, 1 byte, 2 bytes, 4 bytes
  * Compress: 0 no compressed, 1 compressed
+ *
+ * sealed message structure, used after both peers negotiated encryption in handshake
+ *  +------------------+-----------------------------------------+
+ *  |  Sealed Length   |              Sealed Message             |
+ *  |     4 bytes      |   message above + 16 bytes AES-GCM tag  |
+ *  +------------------+-----------------------------------------+
+ * The nonce is the count of sealed messages in each direction, begin at 1, the Sealed Length is additional data.
  */
 
+// cipherCodec is implemented by codecs can seal messages after handshake
+type cipherCodec interface {
+	// setCipher seals messages written by writeKey and opens messages read by readKey, keys are AES-256 keys
+	setCipher(readKey, writeKey []byte) error
+}
+
 // idLength to bits
-//  0 --> 00
-//  1 --> 01
-//  2 --> 10
-//  4 --> 11
+//
+//	0 --> 00
+//	1 --> 01
+//	2 --> 10
+//	4 --> 11
 func idLengthToBits(idLength byte) byte {
 	switch idLength {
 	case 4:
@@ -145,6 +167,11 @@ type transport struct {
 	readHeadBuf       [4]byte
 	writeHeadBuf      [9]byte
 	writeBuf          []byte
+
+	opener, sealer             cipher.AEAD
+	openNonce, sealNonce       uint64
+	openNonceBuf, sealNonceBuf [12]byte
+	sealBuf                    []byte
 }
 
 func (t *transport) Address() _net.Addr {
@@ -183,12 +210,104 @@ func (t *transport) SetTimeout(timeout time.Duration) {
 	t.writeTimeout = timeout
 }
 
+func (t *transport) setCipher(readKey, writeKey []byte) (err error) {
+	block, err := aes.NewCipher(readKey)
+	if err != nil {
+		return
+	}
+	opener, err := cipher.NewGCM(block)
+	if err != nil {
+		return
+	}
+
+	block, err = aes.NewCipher(writeKey)
+	if err != nil {
+		return
+	}
+	sealer, err := cipher.NewGCM(block)
+	if err != nil {
+		return
+	}
+
+	t.opener, t.sealer = opener, sealer
+	return nil
+}
+
+// readSealed read and open a sealed message, return the reader of the message
+func (t *transport) readSealed() (r io.Reader, err error) {
+	buf := t.readHeadBuf[:sealedLengthSize]
+	_, err = io.ReadFull(t.Conn, buf)
+	if err != nil {
+		err = fmt.Errorf("failed to read sealed message length: %v", err)
+		return
+	}
+
+	length := binary.BigEndian.Uint32(buf)
+	if length > maxSealedSize {
+		err = errMsgPayloadTooLarge
+		return
+	}
+
+	sealed := make([]byte, length)
+	_, err = io.ReadFull(t.Conn, sealed)
+	if err != nil {
+		err = fmt.Errorf("failed to read sealed message: %v", err)
+		return
+	}
+
+	t.openNonce++
+	binary.BigEndian.PutUint64(t.openNonceBuf[4:], t.openNonce)
+	data, err := t.opener.Open(sealed[:0], t.openNonceBuf[:], sealed, buf)
+	if err != nil {
+		err = errMsgAuthFailed
+		return
+	}
+
+	return bytes.NewReader(data), nil
+}
+
+// writeSealed seal the message head and payload, then send them
+func (t *transport) writeSealed(head, payload []byte) (err error) {
+	length := len(head) + len(payload)
+	size := sealedLengthSize + length + t.sealer.Overhead()
+	if cap(t.sealBuf) < size {
+		t.sealBuf = make([]byte, size)
+	}
+
+	buf := t.sealBuf[:sealedLengthSize+length]
+	binary.BigEndian.PutUint32(buf, uint32(length+t.sealer.Overhead()))
+	copy(buf[sealedLengthSize:], head)
+	copy(buf[sealedLengthSize+len(head):], payload)
+
+	t.sealNonce++
+	binary.BigEndian.PutUint64(t.sealNonceBuf[4:], t.sealNonce)
+	t.sealer.Seal(buf[sealedLengthSize:sealedLengthSize], t.sealNonceBuf[:], buf[sealedLengthSize:], buf[:sealedLengthSize])
+
+	wsize, err := t.Conn.Write(t.sealBuf[:size])
+	if err != nil {
+		return
+	}
+	if wsize != size {
+		return errWriteTooShort
+	}
+
+	return
+}
+
 // ReadMsg is NOT thread-safe
 func (t *transport) ReadMsg() (msg Msg, err error) {
 	_ = t.SetReadDeadline(time.Now().Add(t.readTimeout))
 
+	var r io.Reader = t.Conn
+	if t.opener != nil {
+		r, err = t.readSealed()
+		if err != nil {
+			return
+		}
+	}
+
 	buf := t.readHeadBuf[:]
-	_, err = io.ReadFull(t.Conn, buf[:2])
+	_, err = io.ReadFull(r, buf[:2])
 	if err != nil {
 		err = fmt.Errorf("failed to read message meta: %v", err)
 		return
@@ -201,7 +320,7 @@ func (t *transport) ReadMsg() (msg Msg, err error) {
 
 	// retrieve id
 	if isize > 0 {
-		_, err = io.ReadFull(t.Conn, buf[:isize])
+		_, err = io.ReadFull(r, buf[:isize])
 		if err != nil {
 			err = fmt.Errorf("failed to read message id: %v", err)
 			return
@@ -211,7 +330,7 @@ func (t *transport) ReadMsg() (msg Msg, err error) {
 
 	// retrieve payload
 	if lsize > 0 {
-		_, err = io.ReadFull(t.Conn, buf[:lsize])
+		_, err = io.ReadFull(r, buf[:lsize])
 		if err != nil {
 			err = fmt.Errorf("failed to read message length: %v", err)
 			return
@@ -224,7 +343,7 @@ func (t *transport) ReadMsg() (msg Msg, err error) {
 		}
 
 		msg.Payload = make([]byte, length)
-		_, err = io.ReadFull(t.Conn, msg.Payload)
+		_, err = io.ReadFull(r, msg.Payload)
 		if err != nil {
 			err = fmt.Errorf("failed to read message payload: %v", err)
 			return
@@ -291,6 +410,10 @@ func (t *transport) WriteMsg(msg Msg) (err error) {
 
 	head[0] = storeMeta(isize, lsize, compress)
 
+	if t.sealer != nil {
+		return t.writeSealed(head[:headLen], msg.Payload)
+	}
+
 	var wsize int
 	// send head
 	wsize, err = t.Conn.Write(head[:headLen])
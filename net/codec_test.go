@@ -504,3 +504,61 @@ func TestParallel(t *testing.T) {
 	})
 }
 */
+
+func TestCodec_cipher(t *testing.T) {
+	conn1, conn2 := _net.Pipe()
+	c1 := NewTransport(conn1, 100, readMsgTimeout, writeMsgTimeout)
+	c2 := NewTransport(conn2, 100, readMsgTimeout, writeMsgTimeout)
+
+	key1, key2 := make([]byte, 32), make([]byte, 32)
+	_, _ = crand.Read(key1)
+	_, _ = crand.Read(key2)
+	if err := c1.(cipherCodec).setCipher(key1, key2); err != nil {
+		t.Fatal(err)
+	}
+	if err := c2.(cipherCodec).setCipher(key2, key1); err != nil {
+		t.Fatal(err)
+	}
+
+	var msgs []Msg
+	for i := 0; i < 10; i++ {
+		payload := make([]byte, i*50)
+		_, _ = crand.Read(payload)
+		msgs = append(msgs, Msg{Code: byte(i), Id: MsgId(i * 300), Payload: payload})
+	}
+	// compressible payload
+	msgs = append(msgs, Msg{Code: 10, Payload: bytes.Repeat([]byte{1}, 1000)})
+
+	go func() {
+		for _, msg := range msgs {
+			if err := c1.WriteMsg(msg); err != nil {
+				t.Errorf("write error: %v", err)
+				return
+			}
+		}
+	}()
+
+	for _, msg := range msgs {
+		msg2, err := c2.ReadMsg()
+		if err != nil {
+			t.Fatalf("read error: %v", err)
+		}
+		if msg.Code != msg2.Code || msg.Id != msg2.Id || !bytes.Equal(msg.Payload, msg2.Payload) {
+			t.Fatalf("different message: %d/%d %d/%d", msg.Code, msg.Id, msg2.Code, msg2.Id)
+		}
+	}
+
+	// c2 opens messages of c1 by another key
+	if err := c2.(cipherCodec).setCipher(key1, key1); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = c1.WriteMsg(Msg{Code: 1, Payload: []byte("hello")})
+	}()
+	if _, err := c2.ReadMsg(); err != errMsgAuthFailed {
+		t.Fatalf("should be auth failed, but got %v", err)
+	}
+
+	_ = conn1.Close()
+	_ = conn2.Close()
+}
@@ -0,0 +1,633 @@
+package net
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+const EdgeDBDirName = "edge"
+
+const edgeSyncInterval = 5 * time.Second
+const edgeSyncBatch = syncTaskSize
+const edgeRequestTimeout = 10 * time.Second
+
+// edgeQuorum is how many peers should give the same response to the queries can not be proved
+const edgeQuorum = 3
+
+// cached elections, one election is a period
+const edgeElectionCache = 16
+
+// edgeForkDepth is how many snapshot blocks a peer can show to prove a fork from a stored block
+const edgeForkDepth = edgeSyncBatch
+
+var errInvalidSnapshotBlock = errors.New("invalid snapshot block")
+var errInvalidProducer = errors.New("snapshot block is not produced by the elected producer")
+var errInvalidProof = errors.New("invalid account block proof")
+var errNotSynced = errors.New("snapshot block has not been synced")
+var errNoQuorum = errors.New("not enough peers give the same response")
+var errStaleSnapshotBlocks = errors.New("snapshot blocks are not after the latest one")
+var errForkTooDeep = errors.New("no stored snapshot block in the fork")
+
+// genesisReader makes an edge node show the genesis as its head, so full nodes won`t sync from it
+type genesisReader struct {
+	genesis *ledger.SnapshotBlock
+}
+
+func (g genesisReader) GetLatestSnapshotBlock() *ledger.SnapshotBlock {
+	return g.genesis
+}
+
+func (g genesisReader) GetGenesisSnapshotBlock() *ledger.SnapshotBlock {
+	return g.genesis
+}
+
+// Edge is a light node. It syncs snapshot blocks only, every snapshot block is verified by the hash, the signature
+// and the producer elected at its timestamp. Account blocks are fetched from full nodes on demand and verified by
+// the hash chain to the SnapshotContent of a synced snapshot block.
+// There is no state root in snapshot blocks, so balances and storage values can not be proved, they are accepted
+// only when the queried peers give the same response.
+type Edge struct {
+	n     *net
+	store *edgeStore
+	idGen MsgIder
+
+	mu        sync.Mutex
+	pending   map[MsgId]chan Msg
+	elections []*Election
+
+	term chan struct{}
+	wg   sync.WaitGroup
+	log  log15.Logger
+}
+
+// NewEdge creates an edge node, genesis is the genesis snapshot block of the network.
+func NewEdge(cfg *config.Net, genesis *ledger.SnapshotBlock) (e *Edge, err error) {
+	n, err := newTransport(cfg, genesisReader{genesis}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dir string
+	if cfg.DataDir != "" {
+		dir = path.Join(cfg.DataDir, EdgeDBDirName)
+	}
+	store, err := newEdgeStore(dir, genesis)
+	if err != nil {
+		return nil, err
+	}
+
+	e = &Edge{
+		n:       n,
+		store:   store,
+		idGen:   new(gid),
+		pending: make(map[MsgId]chan Msg),
+		log:     netLog.New("module", "edge"),
+	}
+
+	// CodeSnapshotBlocks, CodeAccountBlockProof, CodeAccountState, CodeElection, CodeException
+	if err = n.handlers.register(e); err != nil {
+		panic(fmt.Errorf("cannot register handler: edge: %v", err))
+	}
+
+	return e, nil
+}
+
+func (e *Edge) Start() (err error) {
+	if !atomic.CompareAndSwapInt32(&e.n.running, 0, 1) {
+		return errNetIsRunning
+	}
+
+	if e.n.discover != nil {
+		if err = e.n.discover.Start(); err != nil {
+			return
+		}
+	}
+
+	e.n.finder.start()
+
+	e.term = make(chan struct{})
+
+	e.n.wg.Add(1)
+	go e.n.beatLoop()
+
+	e.wg.Add(1)
+	go e.syncLoop()
+
+	return nil
+}
+
+func (e *Edge) Stop() error {
+	if !atomic.CompareAndSwapInt32(&e.n.running, 1, 0) {
+		return errNetIsNotRunning
+	}
+
+	if e.n.discover != nil {
+		_ = e.n.discover.Stop()
+	}
+
+	e.n.finder.stop()
+
+	close(e.term)
+	e.wg.Wait()
+	e.n.wg.Wait()
+
+	for _, p := range e.n.peers.peers() {
+		_ = p.Close(PeerQuitting)
+	}
+
+	return e.store.close()
+}
+
+func (e *Edge) Info() NodeInfo {
+	ps := e.n.peers.info()
+	return NodeInfo{
+		ID:        e.n.node.ID,
+		Name:      e.n.config.Name,
+		NetID:     e.n.config.NetID,
+		Version:   version,
+		PeerCount: len(ps),
+		Peers:     ps,
+		Height:    e.store.getLatest().Height,
+	}
+}
+
+// GetLatestSnapshotBlock returns the latest verified snapshot block
+func (e *Edge) GetLatestSnapshotBlock() *ledger.SnapshotBlock {
+	return e.store.getLatest()
+}
+
+// GetSnapshotBlockByHeight returns the verified snapshot block, nil if it has not been synced
+func (e *Edge) GetSnapshotBlockByHeight(height uint64) (*ledger.SnapshotBlock, error) {
+	if height > e.store.getLatest().Height {
+		return nil, nil
+	}
+	return e.store.getByHeight(height)
+}
+
+// GetAccountBlockByHash fetches the account block and verifies it is confirmed by the returned snapshot block
+func (e *Edge) GetAccountBlockByHash(hash types.Hash) (block *ledger.AccountBlock, snapshotBlock *ledger.SnapshotBlock, err error) {
+	ps := e.n.peers.sortPeers(true)
+	if len(ps) == 0 {
+		return nil, nil, errNoSuitablePeer
+	}
+	rand.Shuffle(len(ps), func(i, j int) {
+		ps[i], ps[j] = ps[j], ps[i]
+	})
+	if len(ps) > edgeQuorum {
+		ps = ps[:edgeQuorum]
+	}
+
+	// try peers until one gives a valid proof
+	err = errNoSuitablePeer
+	for _, p := range ps {
+		var msg Msg
+		msg, err = e.request(p, CodeGetAccountBlockProof, &GetAccountBlockProof{Hash: hash})
+		if err != nil {
+			continue
+		}
+
+		proof := new(AccountBlockProof)
+		if err = proof.Deserialize(msg.Payload); err != nil {
+			continue
+		}
+
+		snapshotBlock, err = e.GetSnapshotBlockByHeight(proof.SnapshotHeight)
+		if err != nil {
+			return nil, nil, err
+		}
+		if snapshotBlock == nil {
+			err = errNotSynced
+			continue
+		}
+
+		block, err = verifyAccountBlockProof(hash, proof, snapshotBlock)
+		if err == nil {
+			return block, snapshotBlock, nil
+		}
+		e.log.Warn(fmt.Sprintf("invalid proof of accountblock %s from %s: %v", hash, p, err))
+	}
+
+	return nil, nil, err
+}
+
+// GetAccountState queries the balances and the storage values of the account at the latest verified snapshot block
+func (e *Edge) GetAccountState(addr types.Address, tokenIds []types.TokenTypeId, storageKeys [][]byte) (*AccountState, error) {
+	latest := e.store.getLatest()
+
+	req := &GetAccountState{
+		Address:     addr,
+		Snapshot:    ledger.HashHeight{Height: latest.Height, Hash: latest.Hash},
+		TokenIds:    tokenIds,
+		StorageKeys: storageKeys,
+	}
+
+	payload, err := e.quorum(CodeGetAccountState, req, latest.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	state := new(AccountState)
+	if err = state.Deserialize(payload); err != nil {
+		return nil, err
+	}
+	if state.Snapshot != req.Snapshot || len(state.Balances) != len(tokenIds) || len(state.StorageValues) != len(storageKeys) {
+		return nil, errDeserialize
+	}
+
+	return state, nil
+}
+
+func (e *Edge) name() string {
+	return "edge"
+}
+
+func (e *Edge) codes() []Code {
+	return []Code{CodeSnapshotBlocks, CodeAccountBlockProof, CodeAccountState, CodeElection, CodeException}
+}
+
+// handle passes the responses to the pending requests
+func (e *Edge) handle(msg Msg) error {
+	e.mu.Lock()
+	ch, ok := e.pending[msg.Id]
+	delete(e.pending, msg.Id)
+	e.mu.Unlock()
+
+	if ok {
+		ch <- msg
+	}
+
+	return nil
+}
+
+// request sends the query to the peer and waits for the response
+func (e *Edge) request(p *Peer, code Code, payload Serializable) (msg Msg, err error) {
+	id := e.idGen.MsgID()
+	ch := make(chan Msg, 1)
+
+	e.mu.Lock()
+	e.pending[id] = ch
+	e.mu.Unlock()
+
+	defer func() {
+		e.mu.Lock()
+		delete(e.pending, id)
+		e.mu.Unlock()
+	}()
+
+	if err = p.send(code, id, payload); err != nil {
+		return
+	}
+
+	timer := time.NewTimer(edgeRequestTimeout)
+	defer timer.Stop()
+
+	select {
+	case msg = <-ch:
+		if msg.Code == CodeException {
+			err = errNoResource
+			if len(msg.Payload) > 0 {
+				exp := Exception(msg.Payload[0])
+				err = exp
+			}
+		}
+	case <-timer.C:
+		err = errFetchTimeout
+	case <-e.term:
+		err = errNetIsNotRunning
+	}
+
+	return
+}
+
+// quorum sends the query to edgeQuorum reliable peers higher than height, all of them should give
+// the same response. It fails if there are fewer peers, one peer can not be trusted alone.
+func (e *Edge) quorum(code Code, payload Serializable, height uint64) ([]byte, error) {
+	ps := e.n.peers.pickReliable(height)
+	if len(ps) < edgeQuorum {
+		return nil, errNoQuorum
+	}
+	rand.Shuffle(len(ps), func(i, j int) {
+		ps[i], ps[j] = ps[j], ps[i]
+	})
+	if len(ps) > edgeQuorum {
+		ps = ps[:edgeQuorum]
+	}
+
+	results := make([][]byte, len(ps))
+	errs := make([]error, len(ps))
+
+	var wg sync.WaitGroup
+	for i, p := range ps {
+		wg.Add(1)
+		go func(i int, p *Peer) {
+			defer wg.Done()
+			var msg Msg
+			msg, errs[i] = e.request(p, code, payload)
+			results[i] = msg.Payload
+		}(i, p)
+	}
+	wg.Wait()
+
+	for i := range ps {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if !bytes.Equal(results[i], results[0]) {
+			return nil, errNoQuorum
+		}
+	}
+
+	return results[0], nil
+}
+
+func (e *Edge) syncLoop() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(edgeSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.term:
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			count, err := e.syncSnapshotBlocks()
+			if err != nil {
+				e.log.Warn(fmt.Sprintf("failed to sync snapshot blocks: %v", err))
+			}
+			if err != nil || count == 0 {
+				break
+			}
+		}
+	}
+}
+
+// syncSnapshotBlocks fetches and verifies the next batch of snapshot blocks, returns how many blocks are stored.
+func (e *Edge) syncSnapshotBlocks() (count int, err error) {
+	latest := e.store.getLatest()
+
+	p := e.n.peers.syncPeer()
+	if p == nil || p.Height <= latest.Height {
+		return 0, nil
+	}
+
+	total := p.Height - latest.Height
+	if total > edgeSyncBatch {
+		total = edgeSyncBatch
+	}
+
+	msg, err := e.request(p, CodeGetSnapshotBlocks, &GetSnapshotBlocks{
+		From:    ledger.HashHeight{Height: latest.Height + 1},
+		Count:   total,
+		Forward: true,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	bs := new(SnapshotBlocks)
+	if err = bs.Deserialize(msg.Payload); err != nil {
+		return 0, err
+	}
+	if len(bs.Blocks) == 0 {
+		return 0, nil
+	}
+
+	// the peer replays old blocks
+	if bs.Blocks[0].Height != latest.Height+1 {
+		e.n.blackList.Ban(p.Id.Bytes(), 60)
+		return 0, fmt.Errorf("snapshot block %s/%d from %s: %v", bs.Blocks[0].Hash, bs.Blocks[0].Height, p, errStaleSnapshotBlocks)
+	}
+	if err = checkContinuous(bs.Blocks); err != nil {
+		e.n.blackList.Ban(p.Id.Bytes(), 60)
+		return 0, fmt.Errorf("snapshot blocks from %s: %v", p, err)
+	}
+
+	if err = e.verifySnapshotBlocks(p, bs.Blocks); err != nil {
+		return 0, err
+	}
+
+	// the verified blocks belong to another fork
+	if bs.Blocks[0].PrevHash != latest.Hash {
+		e.log.Warn(fmt.Sprintf("snapshot block %s/%d is not after %s/%d", bs.Blocks[0].Hash, bs.Blocks[0].Height, latest.Hash, latest.Height))
+		return e.switchFork(p, latest, bs.Blocks)
+	}
+
+	if err = e.store.append(bs.Blocks); err != nil {
+		return 0, err
+	}
+
+	return len(bs.Blocks), nil
+}
+
+// switchFork asks p for the blocks before blocks, they should reach a snapshot block we have stored. The fork is
+// longer than ours as blocks start at latest.Height + 1, so our blocks after the fork point are replaced once
+// the fork is verified.
+func (e *Edge) switchFork(p *Peer, latest *ledger.SnapshotBlock, blocks []*ledger.SnapshotBlock) (count int, err error) {
+	msg, err := e.request(p, CodeGetSnapshotBlocks, &GetSnapshotBlocks{
+		From:    ledger.HashHeight{Height: latest.Height, Hash: blocks[0].PrevHash},
+		Count:   edgeForkDepth,
+		Forward: false,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	bs := new(SnapshotBlocks)
+	if err = bs.Deserialize(msg.Payload); err != nil {
+		return 0, err
+	}
+
+	fork := append(bs.Blocks, blocks...)
+	if err = checkContinuous(fork); err != nil || fork[0].Height > latest.Height {
+		e.n.blackList.Ban(p.Id.Bytes(), 60)
+		return 0, fmt.Errorf("fork from %s: %v", p, errInvalidSnapshotBlock)
+	}
+
+	// the last stored block of the fork
+	point := -1
+	for i := len(bs.Blocks) - 1; i >= 0; i-- {
+		stored, err := e.store.getByHeight(fork[i].Height)
+		if err != nil {
+			return 0, err
+		}
+		if stored != nil && stored.Hash == fork[i].Hash {
+			point = i
+			break
+		}
+	}
+	if point < 0 {
+		return 0, fmt.Errorf("fork from %s at %d: %v", p, fork[0].Height, errForkTooDeep)
+	}
+
+	if err = e.verifySnapshotBlocks(p, fork[point+1:len(bs.Blocks)]); err != nil {
+		return 0, err
+	}
+
+	e.log.Warn(fmt.Sprintf("switch to the fork from %s at %s/%d", p, fork[point].Hash, fork[point].Height))
+	if err = e.store.replace(fork[point].Height, fork[point+1:]); err != nil {
+		return 0, err
+	}
+
+	return len(blocks), nil
+}
+
+// verifySnapshotBlocks verifies the blocks from p, the peer is banned if any block is invalid
+func (e *Edge) verifySnapshotBlocks(p *Peer, blocks []*ledger.SnapshotBlock) error {
+	for _, block := range blocks {
+		if err := e.verifySnapshotBlock(block); err != nil {
+			e.n.blackList.Ban(p.Id.Bytes(), 60)
+			return fmt.Errorf("snapshot block %s/%d from %s: %v", block.Hash, block.Height, p, err)
+		}
+	}
+	return nil
+}
+
+// checkContinuous checks the blocks are a hash chain by heights
+func checkContinuous(blocks []*ledger.SnapshotBlock) error {
+	for i := 1; i < len(blocks); i++ {
+		if blocks[i].Height != blocks[i-1].Height+1 || blocks[i].PrevHash != blocks[i-1].Hash {
+			return fmt.Errorf("snapshot blocks are not continuous at %d", blocks[i].Height)
+		}
+	}
+	return nil
+}
+
+// verifySnapshotBlock checks the hash, the signature and the producer of the snapshot block
+func (e *Edge) verifySnapshotBlock(block *ledger.SnapshotBlock) error {
+	if block.Timestamp == nil || block.ComputeHash() != block.Hash || !block.VerifySignature() {
+		return errInvalidSnapshotBlock
+	}
+
+	election, err := e.getElection(block.Timestamp.Unix())
+	if err != nil {
+		return err
+	}
+
+	return verifyProducer(election, block)
+}
+
+// getElection returns the election of the period contains timestamp, the election is accepted when queried peers
+// give the same response.
+func (e *Edge) getElection(timestamp int64) (*Election, error) {
+	e.mu.Lock()
+	for _, election := range e.elections {
+		if timestamp >= election.PeriodStime && timestamp < election.PeriodEtime {
+			e.mu.Unlock()
+			return election, nil
+		}
+	}
+	e.mu.Unlock()
+
+	payload, err := e.quorum(CodeGetElection, &GetElection{Timestamp: timestamp}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	election := new(Election)
+	if err = election.Deserialize(payload); err != nil {
+		return nil, err
+	}
+	if timestamp < election.PeriodStime || timestamp >= election.PeriodEtime {
+		return nil, fmt.Errorf("election %d is not at %d", election.Index, timestamp)
+	}
+
+	e.mu.Lock()
+	e.elections = append(e.elections, election)
+	if len(e.elections) > edgeElectionCache {
+		e.elections = e.elections[1:]
+	}
+	e.mu.Unlock()
+
+	return election, nil
+}
+
+func verifyProducer(election *Election, block *ledger.SnapshotBlock) error {
+	producer := block.Producer()
+	timestamp := block.Timestamp.Unix()
+	for _, slot := range election.Slots {
+		if slot.Address == producer && slot.Stime == timestamp {
+			return nil
+		}
+	}
+
+	return errInvalidProducer
+}
+
+// verifyAccountBlockProof checks the blocks of the proof are a hash chain ends with the block SnapshotContent of
+// the snapshot block refers to, and the first block is or creates the block of hash.
+func verifyAccountBlockProof(hash types.Hash, proof *AccountBlockProof, snapshotBlock *ledger.SnapshotBlock) (*ledger.AccountBlock, error) {
+	if len(proof.Blocks) == 0 {
+		return nil, errInvalidProof
+	}
+
+	var prev *ledger.AccountBlock
+	for _, block := range proof.Blocks {
+		if err := verifyAccountBlock(block); err != nil {
+			return nil, err
+		}
+		if prev != nil && (block.AccountAddress != prev.AccountAddress || block.Height != prev.Height+1 || block.PrevHash != prev.Hash) {
+			return nil, errInvalidProof
+		}
+		prev = block
+	}
+
+	confirmed, ok := snapshotBlock.SnapshotContent[prev.AccountAddress]
+	if !ok || confirmed.Hash != prev.Hash || confirmed.Height != prev.Height {
+		return nil, errInvalidProof
+	}
+
+	first := proof.Blocks[0]
+	if first.Hash == hash {
+		return first, nil
+	}
+	for _, sendBlock := range first.SendBlockList {
+		if sendBlock.Hash == hash {
+			return sendBlock, nil
+		}
+	}
+
+	return nil, errInvalidProof
+}
+
+func verifyAccountBlock(block *ledger.AccountBlock) error {
+	if block.ComputeHash() != block.Hash {
+		return errInvalidProof
+	}
+
+	if types.IsContractAddr(block.AccountAddress) {
+		for idx, sendBlock := range block.SendBlockList {
+			if sendBlock.Hash != sendBlock.ComputeSendHash(block, uint8(idx)) {
+				return errInvalidProof
+			}
+		}
+		// the send blocks of contracts have no signature
+		if block.IsSendBlock() {
+			return nil
+		}
+	}
+
+	if block.BlockType == ledger.BlockTypeGenesisReceive {
+		return nil
+	}
+
+	if !block.VerifySignature() {
+		return errInvalidProof
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package net
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+var (
+	edgeHeaderPrefix = []byte("edge:header:") // height -> snapshot block
+	edgeLatestKey    = []byte("edge:latest")  // height of the latest snapshot block
+)
+
+// edgeStore keeps the verified snapshot blocks of an edge node, the snapshot blocks are contiguous from genesis.
+type edgeStore struct {
+	db *leveldb.DB
+
+	mu      sync.RWMutex
+	genesis *ledger.SnapshotBlock
+	latest  *ledger.SnapshotBlock
+}
+
+// newEdgeStore opens the store at path, memory is used if path is empty.
+func newEdgeStore(path string, genesis *ledger.SnapshotBlock) (s *edgeStore, err error) {
+	var db *leveldb.DB
+	if path == "" {
+		db, err = leveldb.Open(storage.NewMemStorage(), nil)
+	} else {
+		db, err = leveldb.OpenFile(path, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s = &edgeStore{
+		db:      db,
+		genesis: genesis,
+	}
+
+	stored, err := s.getByHeight(genesis.Height)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	if stored == nil {
+		if err = s.append([]*ledger.SnapshotBlock{genesis}); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+		return s, nil
+	}
+
+	if stored.Hash != genesis.Hash {
+		_ = db.Close()
+		return nil, fmt.Errorf("genesis of edge store is %s, not %s", stored.Hash, genesis.Hash)
+	}
+
+	buf, err := db.Get(edgeLatestKey, nil)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if s.latest, err = s.getByHeight(binary.BigEndian.Uint64(buf)); err != nil || s.latest == nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to read latest snapshot block of edge store: %v", err)
+	}
+
+	return s, nil
+}
+
+func edgeHeaderKey(height uint64) []byte {
+	key := make([]byte, len(edgeHeaderPrefix)+8)
+	copy(key, edgeHeaderPrefix)
+	binary.BigEndian.PutUint64(key[len(edgeHeaderPrefix):], height)
+	return key
+}
+
+func (s *edgeStore) getLatest() *ledger.SnapshotBlock {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.latest
+}
+
+func (s *edgeStore) getByHeight(height uint64) (*ledger.SnapshotBlock, error) {
+	buf, err := s.db.Get(edgeHeaderKey(height), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	block := new(ledger.SnapshotBlock)
+	if err = block.Deserialize(buf); err != nil {
+		return nil, err
+	}
+
+	return block, nil
+}
+
+// append stores the snapshot blocks after the latest one, the caller should verify them first.
+func (s *edgeStore) append(blocks []*ledger.SnapshotBlock) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch := new(leveldb.Batch)
+	for _, block := range blocks {
+		buf, err := block.Serialize()
+		if err != nil {
+			return err
+		}
+		batch.Put(edgeHeaderKey(block.Height), buf)
+	}
+
+	last := blocks[len(blocks)-1]
+	heightBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(heightBytes, last.Height)
+	batch.Put(edgeLatestKey, heightBytes)
+
+	if err := s.db.Write(batch, nil); err != nil {
+		return err
+	}
+
+	s.latest = last
+	return nil
+}
+
+// rollback deletes the snapshot blocks higher than height, genesis is kept.
+func (s *edgeStore) rollback(height uint64) error {
+	return s.replace(height, nil)
+}
+
+// replace deletes the snapshot blocks higher than height and stores blocks after it in one write, genesis is kept.
+// The caller should verify the blocks first.
+func (s *edgeStore) replace(height uint64, blocks []*ledger.SnapshotBlock) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if height < s.genesis.Height {
+		height = s.genesis.Height
+	}
+	if height >= s.latest.Height && len(blocks) == 0 {
+		return nil
+	}
+
+	latest, err := s.getByHeight(height)
+	if err != nil {
+		return err
+	}
+	if latest == nil {
+		return fmt.Errorf("missing snapshot block %d in edge store", height)
+	}
+
+	batch := new(leveldb.Batch)
+	for h := height + 1; h <= s.latest.Height; h++ {
+		batch.Delete(edgeHeaderKey(h))
+	}
+	for _, block := range blocks {
+		if block.Height != latest.Height+1 || block.PrevHash != latest.Hash {
+			return fmt.Errorf("snapshot block %s/%d is not after %s/%d", block.Hash, block.Height, latest.Hash, latest.Height)
+		}
+		buf, err := block.Serialize()
+		if err != nil {
+			return err
+		}
+		batch.Put(edgeHeaderKey(block.Height), buf)
+		latest = block
+	}
+	heightBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(heightBytes, latest.Height)
+	batch.Put(edgeLatestKey, heightBytes)
+
+	if err = s.db.Write(batch, nil); err != nil {
+		return err
+	}
+
+	s.latest = latest
+	return nil
+}
+
+func (s *edgeStore) close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,241 @@
+package net
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+func mockSignedAccountBlocks(t *testing.T, count int) []*ledger.AccountBlock {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := types.PubkeyToAddress(pub)
+
+	var blocks []*ledger.AccountBlock
+	var prevHash types.Hash
+	for i := 1; i <= count; i++ {
+		block := &ledger.AccountBlock{
+			BlockType:      ledger.BlockTypeSendCall,
+			PrevHash:       prevHash,
+			Height:         uint64(i),
+			AccountAddress: addr,
+			PublicKey:      pub,
+			ToAddress:      addr,
+			Amount:         big.NewInt(int64(i)),
+			TokenId:        ledger.ViteTokenId,
+			Fee:            big.NewInt(0),
+		}
+		block.Hash = block.ComputeHash()
+		block.Signature = ed25519.Sign(priv, block.Hash.Bytes())
+		prevHash = block.Hash
+		blocks = append(blocks, block)
+	}
+
+	return blocks
+}
+
+func TestVerifyAccountBlockProof(t *testing.T) {
+	upgrade.CleanupUpgradeBox()
+	upgrade.InitUpgradeBox(upgrade.NewLatestUpgradeBox())
+
+	blocks := mockSignedAccountBlocks(t, 3)
+	last := blocks[2]
+
+	snapshotBlock := &ledger.SnapshotBlock{
+		Height: 10,
+		SnapshotContent: ledger.SnapshotContent{
+			last.AccountAddress: &ledger.HashHeight{Height: last.Height, Hash: last.Hash},
+		},
+	}
+
+	proof := &AccountBlockProof{
+		SnapshotHeight: 10,
+		Blocks:         blocks,
+	}
+
+	block, err := verifyAccountBlockProof(blocks[0].Hash, proof, snapshotBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Hash != blocks[0].Hash {
+		t.Errorf("should return block %s, not %s", blocks[0].Hash, block.Hash)
+	}
+
+	// the requested block is not in the proof
+	if _, err = verifyAccountBlockProof(types.Hash{1}, proof, snapshotBlock); err != errInvalidProof {
+		t.Errorf("should be invalid proof: %v", err)
+	}
+
+	// the proof does not reach the confirmed block
+	proof.Blocks = blocks[:2]
+	if _, err = verifyAccountBlockProof(blocks[0].Hash, proof, snapshotBlock); err != errInvalidProof {
+		t.Errorf("should be invalid proof: %v", err)
+	}
+
+	// the hash chain is broken
+	proof.Blocks = []*ledger.AccountBlock{blocks[0], blocks[2]}
+	if _, err = verifyAccountBlockProof(blocks[0].Hash, proof, snapshotBlock); err != errInvalidProof {
+		t.Errorf("should be invalid proof: %v", err)
+	}
+
+	// the block is modified
+	tampered := *blocks[1]
+	tampered.Amount = big.NewInt(1000)
+	proof.Blocks = []*ledger.AccountBlock{blocks[0], &tampered, blocks[2]}
+	if _, err = verifyAccountBlockProof(blocks[0].Hash, proof, snapshotBlock); err != errInvalidProof {
+		t.Errorf("should be invalid proof: %v", err)
+	}
+
+	// the block is rehashed but can not be signed
+	tampered.Hash = tampered.ComputeHash()
+	if _, err = verifyAccountBlockProof(tampered.Hash, &AccountBlockProof{Blocks: []*ledger.AccountBlock{&tampered}}, snapshotBlock); err != errInvalidProof {
+		t.Errorf("should be invalid proof: %v", err)
+	}
+}
+
+func TestVerifyProducer(t *testing.T) {
+	upgrade.CleanupUpgradeBox()
+	upgrade.InitUpgradeBox(upgrade.NewLatestUpgradeBox())
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timestamp := time.Unix(1003, 0)
+	block := &ledger.SnapshotBlock{
+		Height:    2,
+		PublicKey: pub,
+		Timestamp: &timestamp,
+	}
+	block.Hash = block.ComputeHash()
+	block.Signature = ed25519.Sign(priv, block.Hash.Bytes())
+
+	election := &Election{
+		Index:       1,
+		PeriodStime: 1000,
+		PeriodEtime: 1075,
+		Slots: []ElectionSlot{
+			{Address: types.Address{1}, Stime: 1000, Etime: 1003},
+			{Address: types.PubkeyToAddress(pub), Stime: 1003, Etime: 1006},
+		},
+	}
+
+	if err = verifyProducer(election, block); err != nil {
+		t.Error(err)
+	}
+
+	election.Slots[1].Stime = 1006
+	if err = verifyProducer(election, block); err != errInvalidProducer {
+		t.Errorf("should be invalid producer: %v", err)
+	}
+}
+
+func TestEdgeStore(t *testing.T) {
+	upgrade.CleanupUpgradeBox()
+	upgrade.InitUpgradeBox(upgrade.NewLatestUpgradeBox())
+
+	dir, err := ioutil.TempDir("", "edge_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Unix(1000, 0)
+	genesis := &ledger.SnapshotBlock{Height: 1, Timestamp: &now}
+	genesis.Hash = genesis.ComputeHash()
+
+	store, err := newEdgeStore(dir, genesis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.getLatest().Hash != genesis.Hash {
+		t.Errorf("latest should be genesis")
+	}
+
+	var blocks []*ledger.SnapshotBlock
+	prev := genesis
+	for i := 0; i < 5; i++ {
+		timestamp := prev.Timestamp.Add(time.Second)
+		block := &ledger.SnapshotBlock{Height: prev.Height + 1, PrevHash: prev.Hash, Timestamp: &timestamp}
+		block.Hash = block.ComputeHash()
+		blocks = append(blocks, block)
+		prev = block
+	}
+
+	if err = store.append(blocks); err != nil {
+		t.Fatal(err)
+	}
+	if store.getLatest().Height != 6 {
+		t.Errorf("latest height should be 6, not %d", store.getLatest().Height)
+	}
+
+	if err = store.rollback(4); err != nil {
+		t.Fatal(err)
+	}
+	if store.getLatest().Hash != blocks[2].Hash {
+		t.Errorf("latest should be %s, not %s", blocks[2].Hash, store.getLatest().Hash)
+	}
+	if block, _ := store.getByHeight(5); block != nil {
+		t.Errorf("block 5 should be deleted")
+	}
+
+	// switch to a fork from block 3
+	var fork []*ledger.SnapshotBlock
+	prev = blocks[1]
+	for i := 0; i < 3; i++ {
+		timestamp := prev.Timestamp.Add(2 * time.Second)
+		block := &ledger.SnapshotBlock{Height: prev.Height + 1, PrevHash: prev.Hash, Timestamp: &timestamp}
+		block.Hash = block.ComputeHash()
+		fork = append(fork, block)
+		prev = block
+	}
+	if err = checkContinuous(append([]*ledger.SnapshotBlock{blocks[1]}, fork...)); err != nil {
+		t.Fatal(err)
+	}
+	if err = checkContinuous(append([]*ledger.SnapshotBlock{blocks[0]}, fork...)); err == nil {
+		t.Errorf("fork should not be after block 2")
+	}
+	if err = store.replace(3, fork[1:]); err == nil {
+		t.Errorf("fork should not be after block 4")
+	}
+	if err = store.replace(3, fork); err != nil {
+		t.Fatal(err)
+	}
+	if store.getLatest().Hash != fork[2].Hash {
+		t.Errorf("latest should be %s, not %s", fork[2].Hash, store.getLatest().Hash)
+	}
+	if block, _ := store.getByHeight(4); block == nil || block.Hash != fork[0].Hash {
+		t.Errorf("block 4 should be replaced")
+	}
+	if err = store.replace(3, blocks[2:3]); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.close()
+
+	// reopen
+	store, err = newEdgeStore(dir, genesis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.getLatest().Hash != blocks[2].Hash {
+		t.Errorf("latest should be %s, not %s", blocks[2].Hash, store.getLatest().Hash)
+	}
+	_ = store.close()
+
+	// another network
+	other := &ledger.SnapshotBlock{Height: 1, Timestamp: blocks[0].Timestamp}
+	other.Hash = other.ComputeHash()
+	if _, err = newEdgeStore(dir, other); err == nil {
+		t.Errorf("should fail to open store of another genesis")
+	}
+}
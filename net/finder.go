@@ -115,7 +115,10 @@ func newFinder(self types.Address, peers *peerSet, minPeers int, staticNodes []s
 		f.staticNodes = append(f.staticNodes, node)
 	}
 
-	consensus.SubscribeProducers(types.SNAPSHOT_GID, "sbpn", f.receiveProducers)
+	// edge nodes have no consensus
+	if consensus != nil {
+		consensus.SubscribeProducers(types.SNAPSHOT_GID, "sbpn", f.receiveProducers)
+	}
 
 	return
 }
@@ -151,17 +154,19 @@ func (f *finder) start() {
 	f.term = make(chan struct{})
 
 	// should invoked after consensus.Init()
-	details, _, err := f.consensus.API().ReadVoteMap(time.Now())
-	if err == nil {
-		now := time.Now().Unix()
-		f.rw.Lock()
-		for _, d := range details {
-			f.sbps[d.CurrentAddr] = now
-			if d.CurrentAddr == f.self {
-				f._selfIsSBP = true
+	if f.consensus != nil {
+		details, _, err := f.consensus.API().ReadVoteMap(time.Now())
+		if err == nil {
+			now := time.Now().Unix()
+			f.rw.Lock()
+			for _, d := range details {
+				f.sbps[d.CurrentAddr] = now
+				if d.CurrentAddr == f.self {
+					f._selfIsSBP = true
+				}
 			}
+			f.rw.Unlock()
 		}
-		f.rw.Unlock()
 	}
 
 	go f.loop()
@@ -188,7 +193,9 @@ func (f *finder) isSBP(addr types.Address) bool {
 }
 
 func (f *finder) clean() {
-	f.consensus.UnSubscribe(types.SNAPSHOT_GID, "sbpn")
+	if f.consensus != nil {
+		f.consensus.UnSubscribe(types.SNAPSHOT_GID, "sbpn")
+	}
 }
 
 func (f *finder) receiveProducers(event consensus.ProducersEvent) {
@@ -305,12 +312,16 @@ func (f *finder) loop() {
 	}
 }
 
-func setNodeExt(mineKey ed25519.PrivateKey, node *vnode.Node) {
+func setNodeExt(mineKey mineSigner, node *vnode.Node) error {
 	// minePUB + minePriv.Sign(node.ID)
+	pub, sign, err := mineSign(mineKey, node.ID.Bytes())
+	if err != nil {
+		return err
+	}
 	node.Ext = make([]byte, extLen)
-	copy(node.Ext[:32], mineKey.PubByte())
-	sign := ed25519.Sign(mineKey, node.ID.Bytes())
+	copy(node.Ext[:32], pub)
 	copy(node.Ext[32:], sign)
+	return nil
 }
 
 func parseNodeExt(node *vnode.Node) (addr types.Address, ok bool) {
@@ -20,12 +20,15 @@ package net
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/binary"
 	"fmt"
+	"io"
 	_net "net"
 	"time"
 
 	"github.com/golang/protobuf/proto"
+	"golang.org/x/crypto/hkdf"
 
 	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/common/vitepb"
@@ -37,6 +40,19 @@ import (
 
 const handshakeTimeout = 10 * time.Second
 
+// transport encryption versions in HandshakeMsg, peers use the lower one, encryptionNone means clear text
+const (
+	encryptionNone   uint32 = 0
+	encryptionAESGCM uint32 = 1
+)
+
+const (
+	ephemeralKeyLength = 32
+
+	handshakeLabel    = "vite handshake"
+	transportKeyLabel = "vite transport keys"
+)
+
 type HandshakeMsg struct {
 	Version int64
 
@@ -57,6 +73,13 @@ type HandshakeMsg struct {
 
 	FileAddress   []byte
 	PublicAddress []byte
+
+	// Encryption is the transport encryption version supported. If it is not encryptionNone,
+	// EphemeralKey is the x25519 public key of the connection, Signature is signed by the node key
+	// and Token is signed by the producer key.
+	Encryption   uint32
+	EphemeralKey []byte
+	Signature    []byte
 }
 
 func (b *HandshakeMsg) Serialize() (data []byte, err error) {
@@ -73,6 +96,9 @@ func (b *HandshakeMsg) Serialize() (data []byte, err error) {
 		Key:           b.Key,
 		Token:         b.Token,
 		PublicAddress: b.PublicAddress,
+		Encryption:    b.Encryption,
+		EphemeralKey:  b.EphemeralKey,
+		Signature:     b.Signature,
 	}
 
 	return proto.Marshal(pb)
@@ -110,6 +136,10 @@ func (b *HandshakeMsg) Deserialize(data []byte) (err error) {
 	b.Key = pb.Key
 	b.Token = pb.Token
 
+	b.Encryption = pb.Encryption
+	b.EphemeralKey = pb.EphemeralKey
+	b.Signature = pb.Signature
+
 	return nil
 }
 
@@ -123,7 +153,12 @@ type handshaker struct {
 	publicAddress []byte
 
 	peerKey ed25519.PrivateKey
-	key     ed25519.PrivateKey
+	key     mineSigner
+
+	// encryption is the transport encryption version we support,
+	// handshake with clear text peers will fail if requireEncryption is true
+	encryption        uint32
+	requireEncryption bool
 
 	codecFactory CodecFactory
 
@@ -208,27 +243,75 @@ func (h *handshaker) getSecret(theirId peerId) (secret []byte, err error) {
 	return
 }
 
-func (h *handshaker) verifyHandshake(their *HandshakeMsg, secret []byte) (err error) {
+// legacyToken is the token of the clear text handshake, it is derived from the static secret of the node keys.
+// It is never sent in an encrypted handshake.
+func legacyToken(msg *HandshakeMsg, secret []byte) []byte {
 	t := make([]byte, 8)
-	binary.BigEndian.PutUint64(t, uint64(their.Timestamp))
+	binary.BigEndian.PutUint64(t, uint64(msg.Timestamp))
 	hash := crypto.Hash256(t)
-	token := xor(hash, secret)
-	if len(their.Key) != 0 {
-		if false == ed25519.Verify(their.Key, token, their.Token) {
-			err = PeerInvalidSignature
-			return
-		}
-	} else {
-		if false == bytes.Equal(token, their.Token) {
-			err = PeerInvalidToken
-			return
+
+	return xor(hash, secret)
+}
+
+// handshakeDigest is signed in the encrypted handshake. The reply covers the ephemeral key of the initiator,
+// so it can not be replayed in another connection.
+func handshakeDigest(msg *HandshakeMsg, initiatorKey []byte) []byte {
+	buf := make([]byte, 28)
+	binary.BigEndian.PutUint64(buf, uint64(msg.Version))
+	binary.BigEndian.PutUint64(buf[8:], uint64(msg.NetID))
+	binary.BigEndian.PutUint64(buf[16:], uint64(msg.Timestamp))
+	binary.BigEndian.PutUint32(buf[24:], msg.Encryption)
+
+	return crypto.Hash256([]byte(handshakeLabel), buf, msg.ID.Bytes(), msg.Genesis.Bytes(), msg.EphemeralKey,
+		[]byte{byte(len(msg.Key))}, msg.Key, initiatorKey)
+}
+
+// verifyHandshake checks the legacy token of a clear text handshake, or the signatures of an encrypted one.
+// initiatorKey is our ephemeral key if their is the reply.
+func (h *handshaker) verifyHandshake(their *HandshakeMsg, secret, initiatorKey []byte) (err error) {
+	if len(their.Key) != 0 && len(their.Key) != ed25519.PublicKeySize {
+		err = PeerInvalidMessage
+		return
+	}
+
+	if their.Encryption == encryptionNone {
+		token := legacyToken(their, secret)
+		if len(their.Key) != 0 {
+			if false == ed25519.Verify(their.Key, token, their.Token) {
+				err = PeerInvalidSignature
+				return
+			}
+		} else {
+			if false == bytes.Equal(token, their.Token) {
+				err = PeerInvalidToken
+				return
+			}
 		}
+
+		return
+	}
+
+	if len(their.EphemeralKey) != ephemeralKeyLength {
+		err = PeerInvalidMessage
+		return
+	}
+
+	digest := handshakeDigest(their, initiatorKey)
+	if false == ed25519.Verify(their.ID.Bytes(), digest, their.Signature) {
+		err = PeerInvalidSignature
+		return
+	}
+	if len(their.Key) != 0 && false == ed25519.Verify(their.Key, digest, their.Token) {
+		err = PeerInvalidSignature
+		return
 	}
 
 	return
 }
 
-func (h *handshaker) makeHandshake(secret []byte) (our *HandshakeMsg) {
+// makeHandshake makes an encrypted handshake if we support encryption and their is nil or encrypted,
+// ephemeral is the private key of the connection. Otherwise it makes a clear text handshake with the legacy token.
+func (h *handshaker) makeHandshake(secret []byte, their *HandshakeMsg) (our *HandshakeMsg, ephemeral []byte, err error) {
 	latestBlock := h.chain.GetLatestSnapshotBlock()
 	our = &HandshakeMsg{
 		Version:       int64(h.version),
@@ -245,14 +328,97 @@ func (h *handshaker) makeHandshake(secret []byte) (our *HandshakeMsg) {
 		PublicAddress: h.publicAddress,
 	}
 
-	t := make([]byte, 8)
-	binary.BigEndian.PutUint64(t, uint64(our.Timestamp))
-	hash := crypto.Hash256(t)
+	if h.encryption == encryptionNone || (their != nil && their.Encryption == encryptionNone) {
+		our.Token = legacyToken(our, secret)
+		if h.key != nil {
+			// handshake as a common node if the signer is unavailable
+			if pub, sign, err := mineSign(h.key, our.Token); err == nil {
+				our.Key = pub
+				our.Token = sign
+			} else {
+				netLog.Warn(fmt.Sprintf("failed to sign handshake with mine key: %v", err))
+			}
+		}
+
+		return
+	}
+
+	ephemeral, our.EphemeralKey, err = crypto.X25519GenerateKey()
+	if err != nil {
+		err = PeerNotEncrypted
+		return
+	}
+	our.Encryption = h.encryption
+
+	var initiatorKey []byte
+	if their != nil {
+		initiatorKey = their.EphemeralKey
+	}
 
-	our.Token = xor(hash, secret)
 	if h.key != nil {
-		our.Key = h.key.PubByte()
-		our.Token = ed25519.Sign(h.key, our.Token)
+		// the mine key is covered by the digest
+		if our.Key, err = minePubKey(h.key); err == nil {
+			_, our.Token, err = mineSign(h.key, handshakeDigest(our, initiatorKey))
+		}
+		if err != nil {
+			// handshake as a common node if the signer is unavailable
+			netLog.Warn(fmt.Sprintf("failed to sign handshake with mine key: %v", err))
+			our.Key, our.Token, err = nil, nil, nil
+		}
+	}
+
+	our.Signature = ed25519.Sign(h.peerKey, handshakeDigest(our, initiatorKey))
+
+	return
+}
+
+// setCipher makes the codec seal messages if both peers support encryption. The keys of the two directions
+// are derived by HKDF from the shared secret of the ephemeral keys and the digests of the two handshakes.
+func (h *handshaker) setCipher(c Codec, ephemeral []byte, our, their *HandshakeMsg, initiator bool) (err error) {
+	version := our.Encryption
+	if their.Encryption < version {
+		version = their.Encryption
+	}
+
+	if version == encryptionNone {
+		if h.requireEncryption {
+			err = PeerNotEncrypted
+		}
+		return
+	}
+
+	cc, ok := c.(cipherCodec)
+	if !ok {
+		err = PeerNotEncrypted
+		return
+	}
+
+	shared, err := crypto.X25519SharedSecret(ephemeral, their.EphemeralKey)
+	if err != nil {
+		err = PeerInvalidMessage
+		return
+	}
+
+	initiatorMsg, receiverMsg := our, their
+	if !initiator {
+		initiatorMsg, receiverMsg = their, our
+	}
+	transcript := crypto.Hash256(handshakeDigest(initiatorMsg, nil), handshakeDigest(receiverMsg, initiatorMsg.EphemeralKey))
+
+	keys := make([]byte, 64)
+	if _, err = io.ReadFull(hkdf.New(sha256.New, shared, transcript, []byte(transportKeyLabel)), keys); err != nil {
+		err = PeerNotEncrypted
+		return
+	}
+	outboundKey, inboundKey := keys[:32], keys[32:]
+
+	if initiator {
+		err = cc.setCipher(inboundKey, outboundKey)
+	} else {
+		err = cc.setCipher(outboundKey, inboundKey)
+	}
+	if err != nil {
+		err = PeerNotEncrypted
 	}
 
 	return
@@ -303,11 +469,17 @@ func (h *handshaker) ReceiveHandshake(conn _net.Conn) (c Codec, their *Handshake
 		return
 	}
 
-	err = h.verifyHandshake(their, secret)
+	err = h.verifyHandshake(their, secret, nil)
 	if err != nil {
 		return
 	}
 
+	// refuse the clear text peer before replying the legacy token
+	if their.Encryption == encryptionNone && h.requireEncryption {
+		err = PeerNotEncrypted
+		return
+	}
+
 	err = h.doHandshake(c, PeerFlagInbound, their)
 	if err != nil {
 		return
@@ -318,8 +490,16 @@ func (h *handshaker) ReceiveHandshake(conn _net.Conn) (c Codec, their *Handshake
 		return
 	}
 
-	our := h.makeHandshake(secret)
+	our, ephemeral, err := h.makeHandshake(secret, their)
+	if err != nil {
+		return
+	}
 	err = h.sendHandshake(c, our, msgId)
+	if err != nil {
+		return
+	}
+
+	err = h.setCipher(c, ephemeral, our, their, false)
 	return
 }
 
@@ -337,7 +517,10 @@ func (h *handshaker) InitiateHandshake(conn _net.Conn, id vnode.NodeID) (c Codec
 		}
 	}()
 
-	our := h.makeHandshake(secret)
+	our, ephemeral, err := h.makeHandshake(secret, nil)
+	if err != nil {
+		return
+	}
 	err = h.sendHandshake(c, our, 0)
 	if err != nil {
 		return
@@ -348,7 +531,12 @@ func (h *handshaker) InitiateHandshake(conn _net.Conn, id vnode.NodeID) (c Codec
 		return
 	}
 
-	err = h.verifyHandshake(their, secret)
+	if their.ID != id {
+		err = PeerInvalidSignature
+		return
+	}
+
+	err = h.verifyHandshake(their, secret, our.EphemeralKey)
 	if err != nil {
 		return
 	}
@@ -363,6 +551,7 @@ func (h *handshaker) InitiateHandshake(conn _net.Conn, id vnode.NodeID) (c Codec
 		return
 	}
 
+	err = h.setCipher(c, ephemeral, our, their, true)
 	return
 }
 
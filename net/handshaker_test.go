@@ -326,10 +326,16 @@ check:
 		panic(err)
 	}
 
-	our := hkr.makeHandshake(secret)
-	err = hkr.verifyHandshake(our, secret)
-	if err != nil {
-		panic(err)
+	for _, encryption := range []uint32{encryptionNone, encryptionAESGCM} {
+		hkr.encryption = encryption
+		our, _, err := hkr.makeHandshake(secret, nil)
+		if err != nil {
+			panic(err)
+		}
+		err = hkr.verifyHandshake(our, secret, nil)
+		if err != nil {
+			panic(err)
+		}
 	}
 
 	hkr.key = nil
@@ -402,13 +408,13 @@ func TestHandshaker_verifyHandshake(t *testing.T) {
 	}
 
 	secret, our := makeHandshake(priv2, priv4, hkr.id)
-	err = hkr.verifyHandshake(our, secret)
+	err = hkr.verifyHandshake(our, secret, nil)
 	if err != nil {
 		panic(err)
 	}
 
 	our.Timestamp = time.Now().Unix()
-	err = hkr.verifyHandshake(our, secret)
+	err = hkr.verifyHandshake(our, secret, nil)
 	if err == nil {
 		t.Fatal("should verify failed")
 	} else {
@@ -416,8 +422,150 @@ func TestHandshaker_verifyHandshake(t *testing.T) {
 	}
 
 	_, our = makeHandshake(priv2, nil, hkr.id)
-	err = hkr.verifyHandshake(our, secret)
+	err = hkr.verifyHandshake(our, secret, nil)
 	if err != nil {
 		panic(err)
 	}
 }
+
+func TestHandshaker_encryption(t *testing.T) {
+	newHandshaker := func(encryption uint32, require bool) *handshaker {
+		pub, priv, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			panic(err)
+		}
+		id, _ := vnode.Bytes2NodeID(pub)
+		hk := &handshaker{
+			version:           1,
+			netId:             7,
+			id:                id,
+			peerKey:           priv,
+			encryption:        encryption,
+			requireEncryption: require,
+			codecFactory: &transportFactory{
+				minCompressLength: 100,
+				readTimeout:       readMsgTimeout,
+				writeTimeout:      writeMsgTimeout,
+			},
+			blackList: netool.NewBlackList(func(t int64, count int) bool {
+				return false
+			}),
+			onHandshaker: func(c Codec, flag PeerFlag, their *HandshakeMsg) (superior bool, err error) {
+				return false, nil
+			},
+		}
+		hk.setChain(mockChain{
+			height: 100,
+		})
+		return hk
+	}
+
+	handshake := func(initiator, receiver *handshaker) (c1, c2 Codec, err1, err2 error) {
+		conn1, conn2 := _net.Pipe()
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c2, _, _, err2 = receiver.ReceiveHandshake(conn2)
+		}()
+		c1, _, _, err1 = initiator.InitiateHandshake(conn1, receiver.id)
+		wg.Wait()
+		return
+	}
+
+	// both encrypt
+	c1, c2, err1, err2 := handshake(newHandshaker(encryptionAESGCM, false), newHandshaker(encryptionAESGCM, true))
+	if err1 != nil || err2 != nil {
+		t.Fatalf("failed to handshake: %v %v", err1, err2)
+	}
+	if c1.(*transport).sealer == nil || c2.(*transport).opener == nil {
+		t.Fatal("transport should be encrypted")
+	}
+	go func() {
+		_ = c1.WriteMsg(Msg{Code: CodeHandshake, Payload: []byte("hello")})
+	}()
+	msg, err := c2.ReadMsg()
+	if err != nil || string(msg.Payload) != "hello" {
+		t.Fatalf("failed to read encrypted message: %v", err)
+	}
+	go func() {
+		_ = c2.WriteMsg(Msg{Code: CodeHandshake, Payload: []byte("world")})
+	}()
+	msg, err = c1.ReadMsg()
+	if err != nil || string(msg.Payload) != "world" {
+		t.Fatalf("failed to read encrypted message: %v", err)
+	}
+
+	// old peer
+	c1, c2, err1, err2 = handshake(newHandshaker(encryptionAESGCM, false), newHandshaker(encryptionNone, false))
+	if err1 != nil || err2 != nil {
+		t.Fatalf("failed to handshake: %v %v", err1, err2)
+	}
+	if c1.(*transport).sealer != nil || c2.(*transport).sealer != nil {
+		t.Fatal("transport should not be encrypted")
+	}
+
+	// old peer is refused
+	_, _, err1, err2 = handshake(newHandshaker(encryptionAESGCM, true), newHandshaker(encryptionNone, false))
+	if err1 != PeerNotEncrypted || err2 != nil {
+		t.Fatalf("initiator should refuse the old peer: %v %v", err1, err2)
+	}
+
+	// the encryption fields are covered by the signature, and nothing derived from the static secret is sent
+	hk1, hk2 := newHandshaker(encryptionAESGCM, false), newHandshaker(encryptionAESGCM, false)
+	secret, err := hk1.getSecret(hk2.id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	our, _, err := hk1.makeHandshake(secret, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = hk2.verifyHandshake(our, secret, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(our.Token) != 0 || len(our.EphemeralKey) != ephemeralKeyLength {
+		t.Fatalf("unexpected encrypted handshake %+v", our)
+	}
+	stripped := *our
+	stripped.Encryption, stripped.EphemeralKey, stripped.Signature = encryptionNone, nil, nil
+	if err = hk2.verifyHandshake(&stripped, secret, nil); err != PeerInvalidToken {
+		t.Fatalf("stripped handshake should be invalid: %v", err)
+	}
+	tampered := *our
+	tampered.EphemeralKey = make([]byte, ephemeralKeyLength)
+	if err = hk2.verifyHandshake(&tampered, secret, nil); err != PeerInvalidSignature {
+		t.Fatalf("tampered handshake should be invalid: %v", err)
+	}
+
+	// the reply is bound to the ephemeral key of the initiator
+	reply, _, err := hk2.makeHandshake(secret, our)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = hk1.verifyHandshake(reply, secret, our.EphemeralKey); err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := hk1.makeHandshake(secret, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = hk1.verifyHandshake(reply, secret, other.EphemeralKey); err != PeerInvalidSignature {
+		t.Fatalf("replayed reply should be invalid: %v", err)
+	}
+
+	// the session keys differ in every connection
+	c1, _, err1, err2 = handshake(newHandshaker(encryptionAESGCM, false), hk2)
+	if err1 != nil || err2 != nil {
+		t.Fatalf("failed to handshake: %v %v", err1, err2)
+	}
+	c3, _, err1, err2 := handshake(newHandshaker(encryptionAESGCM, false), hk2)
+	if err1 != nil || err2 != nil {
+		t.Fatalf("failed to handshake: %v %v", err1, err2)
+	}
+	sealed1 := c1.(*transport).sealer.Seal(nil, make([]byte, 12), []byte("hello"), nil)
+	sealed3 := c3.(*transport).sealer.Seal(nil, make([]byte, 12), []byte("hello"), nil)
+	if bytes.Equal(sealed1, sealed3) {
+		t.Fatal("connections should use different keys")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/vitelabs/go-vite/v2/interfaces"
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
 	"github.com/vitelabs/go-vite/v2/ledger/consensus"
+	"github.com/vitelabs/go-vite/v2/ledger/consensus/core"
 	"github.com/vitelabs/go-vite/v2/net/vnode"
 )
 
@@ -31,6 +32,12 @@ type ledgerReader interface {
 	GetLedgerReaderByHeight(startHeight uint64, endHeight uint64) (cr interfaces.LedgerReader, err error)
 }
 
+type lightReader interface {
+	GetConfirmSnapshotBlockByAbHash(abHash types.Hash) (*ledger.SnapshotBlock, error)
+	GetAccountBlocksByRange(addr types.Address, start uint64, end uint64) ([]*ledger.AccountBlock, error)
+	GetSnapshotState(address types.Address, snapshotHeight uint64) (interfaces.StateSnapshot, error)
+}
+
 type chainReader interface {
 	GetLatestSnapshotBlock() *ledger.SnapshotBlock
 	GetGenesisSnapshotBlock() *ledger.SnapshotBlock
@@ -52,6 +59,7 @@ type Chain interface {
 	chainReader
 	ledgerReader
 	syncCacher
+	lightReader
 }
 
 type IrreversibleReader interface {
@@ -62,6 +70,7 @@ type Consensus interface {
 	SubscribeProducers(gid types.Gid, id string, fn func(event consensus.ProducersEvent))
 	UnSubscribe(gid types.Gid, id string)
 	API() consensus.APIReader
+	SBPReader() core.SBPStatReader
 }
 
 type Verifier interface {
@@ -294,4 +303,5 @@ type Net interface {
 	Nodes() []*vnode.Node
 	PeerCount() int
 	PeerKey() ed25519.PrivateKey
+	FetchChunks(fromHeight, toHeight uint64, prevHash, hash types.Hash) ([]*ledger.SnapshotChunk, error)
 }
@@ -21,6 +21,7 @@ package net
 import (
 	"errors"
 	"io"
+	"math/big"
 	"strconv"
 
 	"github.com/golang/protobuf/proto"
@@ -45,6 +46,13 @@ const (
 	CodeNewSnapshotBlock  Code = 31
 	CodeNewAccountBlock   Code = 32
 
+	CodeGetAccountBlockProof Code = 33
+	CodeAccountBlockProof    Code = 34
+	CodeGetAccountState      Code = 35
+	CodeAccountState         Code = 36
+	CodeGetElection          Code = 37
+	CodeElection             Code = 38
+
 	CodeSyncHandshake   Code = 60
 	CodeSyncHandshakeOK Code = 61
 	CodeSyncRequest     Code = 62
@@ -399,6 +407,265 @@ func (b *NewAccountBlock) Deserialize(buf []byte) error {
 	return nil
 }
 
+// @section light client
+
+// GetAccountBlockProof is used by edge nodes to fetch an account block and the proof it is confirmed
+type GetAccountBlockProof struct {
+	Hash types.Hash
+}
+
+func (b *GetAccountBlockProof) String() string {
+	return "GetAccountBlockProof<" + b.Hash.String() + ">"
+}
+
+func (b *GetAccountBlockProof) Serialize() ([]byte, error) {
+	pb := new(vitepb.GetAccountBlockProof)
+	pb.Hash = b.Hash.Bytes()
+
+	return proto.Marshal(pb)
+}
+
+func (b *GetAccountBlockProof) Deserialize(buf []byte) (err error) {
+	pb := new(vitepb.GetAccountBlockProof)
+
+	err = proto.Unmarshal(buf, pb)
+	if err != nil {
+		return err
+	}
+
+	b.Hash, err = types.BytesToHash(pb.Hash)
+
+	return
+}
+
+// AccountBlockProof is the account blocks from low to high, the first block is or creates the requested block,
+// the last block is the one SnapshotContent of the snapshot block at SnapshotHeight refers to
+type AccountBlockProof struct {
+	SnapshotHeight uint64
+	Blocks         []*ledger.AccountBlock
+}
+
+func (b *AccountBlockProof) String() string {
+	return "AccountBlockProof<" + strconv.FormatUint(b.SnapshotHeight, 10) + "/" + strconv.Itoa(len(b.Blocks)) + ">"
+}
+
+func (b *AccountBlockProof) Serialize() ([]byte, error) {
+	pb := new(vitepb.AccountBlockProof)
+	pb.SnapshotHeight = b.SnapshotHeight
+
+	pb.Blocks = make([]*vitepb.AccountBlock, len(b.Blocks))
+	for i, block := range b.Blocks {
+		pb.Blocks[i] = block.Proto()
+	}
+
+	return proto.Marshal(pb)
+}
+
+func (b *AccountBlockProof) Deserialize(buf []byte) error {
+	pb := new(vitepb.AccountBlockProof)
+
+	err := proto.Unmarshal(buf, pb)
+	if err != nil {
+		return err
+	}
+
+	b.SnapshotHeight = pb.SnapshotHeight
+	b.Blocks = make([]*ledger.AccountBlock, len(pb.Blocks))
+	for i, bp := range pb.Blocks {
+		if bp == nil {
+			return errDeserialize
+		}
+		block := new(ledger.AccountBlock)
+		if err = block.DeProto(bp); err != nil {
+			return err
+		}
+		b.Blocks[i] = block
+	}
+
+	return nil
+}
+
+// GetAccountState is used by edge nodes to query balances and storage values of an account at a snapshot block
+type GetAccountState struct {
+	Address     types.Address
+	Snapshot    ledger.HashHeight
+	TokenIds    []types.TokenTypeId
+	StorageKeys [][]byte
+}
+
+func (s *GetAccountState) String() string {
+	return "GetAccountState<" + s.Address.String() + "/" + strconv.FormatUint(s.Snapshot.Height, 10) + ">"
+}
+
+func (s *GetAccountState) Serialize() ([]byte, error) {
+	pb := new(vitepb.GetAccountState)
+	pb.Address = s.Address.Bytes()
+	pb.Snapshot = s.Snapshot.Proto()
+	pb.TokenIds = make([][]byte, len(s.TokenIds))
+	for i, tokenId := range s.TokenIds {
+		pb.TokenIds[i] = tokenId.Bytes()
+	}
+	pb.StorageKeys = s.StorageKeys
+
+	return proto.Marshal(pb)
+}
+
+func (s *GetAccountState) Deserialize(buf []byte) (err error) {
+	pb := new(vitepb.GetAccountState)
+
+	err = proto.Unmarshal(buf, pb)
+	if err != nil {
+		return err
+	}
+
+	if pb.Snapshot == nil {
+		return errDeserialize
+	}
+
+	if s.Address, err = types.BytesToAddress(pb.Address); err != nil {
+		return
+	}
+	if err = s.Snapshot.DeProto(pb.Snapshot); err != nil {
+		return
+	}
+	s.TokenIds = make([]types.TokenTypeId, len(pb.TokenIds))
+	for i, buf := range pb.TokenIds {
+		if s.TokenIds[i], err = types.BytesToTokenTypeId(buf); err != nil {
+			return
+		}
+	}
+	s.StorageKeys = pb.StorageKeys
+
+	return nil
+}
+
+// AccountState is the balances and storage values in the same order of the request
+type AccountState struct {
+	Snapshot      ledger.HashHeight
+	Balances      []*big.Int
+	StorageValues [][]byte
+}
+
+func (s *AccountState) Serialize() ([]byte, error) {
+	pb := new(vitepb.AccountState)
+	pb.Snapshot = s.Snapshot.Proto()
+	pb.Balances = make([][]byte, len(s.Balances))
+	for i, balance := range s.Balances {
+		if balance != nil {
+			pb.Balances[i] = balance.Bytes()
+		}
+	}
+	pb.StorageValues = s.StorageValues
+
+	return proto.Marshal(pb)
+}
+
+func (s *AccountState) Deserialize(buf []byte) (err error) {
+	pb := new(vitepb.AccountState)
+
+	err = proto.Unmarshal(buf, pb)
+	if err != nil {
+		return err
+	}
+
+	if pb.Snapshot == nil {
+		return errDeserialize
+	}
+
+	if err = s.Snapshot.DeProto(pb.Snapshot); err != nil {
+		return
+	}
+	s.Balances = make([]*big.Int, len(pb.Balances))
+	for i, buf := range pb.Balances {
+		s.Balances[i] = new(big.Int).SetBytes(buf)
+	}
+	s.StorageValues = pb.StorageValues
+
+	return nil
+}
+
+// GetElection is used by edge nodes to query the producers of the snapshot consensus period contains Timestamp
+type GetElection struct {
+	Timestamp int64
+}
+
+func (e *GetElection) Serialize() ([]byte, error) {
+	return proto.Marshal(&vitepb.GetElection{
+		Timestamp: e.Timestamp,
+	})
+}
+
+func (e *GetElection) Deserialize(buf []byte) error {
+	pb := new(vitepb.GetElection)
+
+	err := proto.Unmarshal(buf, pb)
+	if err != nil {
+		return err
+	}
+
+	e.Timestamp = pb.Timestamp
+
+	return nil
+}
+
+// ElectionSlot means Address should produce the snapshot block at Stime
+type ElectionSlot struct {
+	Address types.Address
+	Stime   int64
+	Etime   int64
+}
+
+// Election is the producing plan of a snapshot consensus period [PeriodStime, PeriodEtime)
+type Election struct {
+	Index       uint64
+	PeriodStime int64
+	PeriodEtime int64
+	Slots       []ElectionSlot
+}
+
+func (e *Election) Serialize() ([]byte, error) {
+	pb := new(vitepb.Election)
+	pb.Index = e.Index
+	pb.PeriodStime = e.PeriodStime
+	pb.PeriodEtime = e.PeriodEtime
+	pb.Slots = make([]*vitepb.ElectionSlot, len(e.Slots))
+	for i, slot := range e.Slots {
+		pb.Slots[i] = &vitepb.ElectionSlot{
+			Address: slot.Address.Bytes(),
+			Stime:   slot.Stime,
+			Etime:   slot.Etime,
+		}
+	}
+
+	return proto.Marshal(pb)
+}
+
+func (e *Election) Deserialize(buf []byte) (err error) {
+	pb := new(vitepb.Election)
+
+	err = proto.Unmarshal(buf, pb)
+	if err != nil {
+		return err
+	}
+
+	e.Index = pb.Index
+	e.PeriodStime = pb.PeriodStime
+	e.PeriodEtime = pb.PeriodEtime
+	e.Slots = make([]ElectionSlot, len(pb.Slots))
+	for i, slot := range pb.Slots {
+		if slot == nil {
+			return errDeserialize
+		}
+		if e.Slots[i].Address, err = types.BytesToAddress(slot.Address); err != nil {
+			return
+		}
+		e.Slots[i].Stime = slot.Stime
+		e.Slots[i].Etime = slot.Etime
+	}
+
+	return nil
+}
+
 var errMissingPoints = errors.New("missing from points")
 var errNilPoint = errors.New("nil HashHeightPoint")
 
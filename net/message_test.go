@@ -511,3 +511,83 @@ func TestGetHashHeightList_Serialize(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestGetAccountState_Serialize(t *testing.T) {
+	var req = &GetAccountState{
+		Snapshot:    ledger.HashHeight{Height: mrand.Uint64()},
+		TokenIds:    []types.TokenTypeId{ledger.ViteTokenId},
+		StorageKeys: [][]byte{[]byte("hello"), []byte("world")},
+	}
+	_, _ = crand.Read(req.Address[:])
+	_, _ = crand.Read(req.Snapshot.Hash[:])
+
+	data, err := req.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var req2 = &GetAccountState{}
+	if err = req2.Deserialize(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Address != req2.Address || req.Snapshot != req2.Snapshot || len(req2.TokenIds) != 1 || req2.TokenIds[0] != ledger.ViteTokenId {
+		t.Errorf("different request: %v", req2)
+	}
+	if len(req2.StorageKeys) != 2 || string(req2.StorageKeys[1]) != "world" {
+		t.Errorf("different storage keys: %v", req2.StorageKeys)
+	}
+
+	var res = &AccountState{
+		Snapshot:      req.Snapshot,
+		Balances:      []*big.Int{big.NewInt(100), nil},
+		StorageValues: [][]byte{[]byte("value")},
+	}
+	data, err = res.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res2 = &AccountState{}
+	if err = res2.Deserialize(data); err != nil {
+		t.Fatal(err)
+	}
+	if res2.Snapshot != res.Snapshot || len(res2.Balances) != 2 || res2.Balances[0].Int64() != 100 || res2.Balances[1].Sign() != 0 {
+		t.Errorf("different response: %v", res2)
+	}
+}
+
+func TestElection_Serialize(t *testing.T) {
+	var e = &Election{
+		Index:       10,
+		PeriodStime: 1000,
+		PeriodEtime: 1075,
+	}
+	for i := 0; i < 25; i++ {
+		var slot = ElectionSlot{
+			Stime: int64(1000 + i*3),
+			Etime: int64(1003 + i*3),
+		}
+		_, _ = crand.Read(slot.Address[:])
+		e.Slots = append(e.Slots, slot)
+	}
+
+	data, err := e.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var e2 = &Election{}
+	if err = e2.Deserialize(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if e2.Index != e.Index || e2.PeriodStime != e.PeriodStime || e2.PeriodEtime != e.PeriodEtime || len(e2.Slots) != len(e.Slots) {
+		t.Fatalf("different election: %v", e2)
+	}
+	for i := range e.Slots {
+		if e.Slots[i] != e2.Slots[i] {
+			t.Errorf("different slot %d: %v %v", i, e.Slots[i], e2.Slots[i])
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package net
+
+import (
+	stdcrypto "crypto"
+	"errors"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+)
+
+// mineSigner signs with the key of the producer, it is an ed25519.PrivateKey,
+// or a remote signer if the key is kept outside the process
+type mineSigner = stdcrypto.Signer
+
+// newMineSigner returns nil if the node is not a producer
+func newMineSigner(key ed25519.PrivateKey, signer stdcrypto.Signer) mineSigner {
+	if signer != nil {
+		return signer
+	}
+	if len(key) != 0 {
+		return key
+	}
+	return nil
+}
+
+func minePubKey(signer mineSigner) (ed25519.PublicKey, error) {
+	pub, ok := signer.Public().(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("mine key is not an ed25519 key")
+	}
+	return pub, nil
+}
+
+func mineAddress(signer mineSigner) (addr types.Address, err error) {
+	pub, err := minePubKey(signer)
+	if err != nil {
+		return
+	}
+	return types.PubkeyToAddress(pub), nil
+}
+
+// mineSign returns the public key and the signature of msg
+func mineSign(signer mineSigner, msg []byte) (pub ed25519.PublicKey, sign []byte, err error) {
+	if pub, err = minePubKey(signer); err != nil {
+		return
+	}
+	sign, err = signer.Sign(nil, msg, stdcrypto.Hash(0))
+	return
+}
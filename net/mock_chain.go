@@ -63,3 +63,15 @@ func (mc mockChain) GetLedgerReaderByHeight(startHeight uint64, endHeight uint64
 func (mc mockChain) GetSyncCache() interfaces.SyncCache {
 	panic("implement me")
 }
+
+func (mc mockChain) GetConfirmSnapshotBlockByAbHash(abHash types.Hash) (*ledger.SnapshotBlock, error) {
+	panic("implement me")
+}
+
+func (mc mockChain) GetAccountBlocksByRange(addr types.Address, start uint64, end uint64) ([]*ledger.AccountBlock, error) {
+	panic("implement me")
+}
+
+func (mc mockChain) GetSnapshotState(address types.Address, snapshotHeight uint64) (interfaces.StateSnapshot, error) {
+	panic("implement me")
+}
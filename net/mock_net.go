@@ -47,6 +47,10 @@ func (n *mockNet) Detail() SyncDetail {
 	}
 }
 
+func (n *mockNet) FetchChunks(fromHeight, toHeight uint64, prevHash, hash types.Hash) ([]*ledger.SnapshotChunk, error) {
+	return nil, errNoChunkPeer
+}
+
 func (n *mockNet) FetchSnapshotBlocks(start types.Hash, count uint64) {
 }
 
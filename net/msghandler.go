@@ -2,6 +2,8 @@ package net
 
 import (
 	"fmt"
+	"math/big"
+	"sort"
 	"sync"
 	"time"
 
@@ -94,7 +96,7 @@ type queryHandler struct {
 	log   log15.Logger
 }
 
-func newQueryHandler(chain Chain) (q *queryHandler, err error) {
+func newQueryHandler(chain Chain, consensus Consensus) (q *queryHandler, err error) {
 	q = &queryHandler{
 		msgHandlers: newHandlers("query"),
 		queue:       list.New(),
@@ -110,6 +112,9 @@ func newQueryHandler(chain Chain) (q *queryHandler, err error) {
 	if err = q.register(&checkHandler{chain, netLog.New("module", "checkHandler")}); err != nil {
 		return nil, err
 	}
+	if err = q.register(&lightHandler{chain, consensus, netLog.New("module", "lightHandler")}); err != nil {
+		return nil, err
+	}
 
 	return q, nil
 }
@@ -447,6 +452,160 @@ func (a *getAccountBlocksHandler) handle(msg Msg) (err error) {
 	return
 }
 
+// @section light client
+const maxProofBlocks = 100
+const maxStateItems = 100
+
+// lightHandler serves the queries of edge nodes
+type lightHandler struct {
+	chain interface {
+		accountBockReader
+		snapshotBlockReader
+		lightReader
+	}
+	consensus Consensus
+	log       log15.Logger
+}
+
+func (l *lightHandler) name() string {
+	return "Light"
+}
+
+func (l *lightHandler) codes() []Code {
+	return []Code{CodeGetAccountBlockProof, CodeGetAccountState, CodeGetElection}
+}
+
+func (l *lightHandler) handle(msg Msg) (err error) {
+	var code Code
+	var payload Serializable
+
+	switch msg.Code {
+	case CodeGetAccountBlockProof:
+		req := new(GetAccountBlockProof)
+		if err = req.Deserialize(msg.Payload); err != nil {
+			return
+		}
+		code, payload = l.handleGetAccountBlockProof(req)
+
+	case CodeGetAccountState:
+		req := new(GetAccountState)
+		if err = req.Deserialize(msg.Payload); err != nil {
+			return
+		}
+		code, payload = l.handleGetAccountState(req)
+
+	case CodeGetElection:
+		req := new(GetElection)
+		if err = req.Deserialize(msg.Payload); err != nil {
+			return
+		}
+		code, payload = l.handleGetElection(req)
+
+	default:
+		return
+	}
+
+	return msg.Sender.send(code, msg.Id, payload)
+}
+
+func (l *lightHandler) handleGetAccountBlockProof(req *GetAccountBlockProof) (Code, Serializable) {
+	block, err := l.chain.GetAccountBlockByHash(req.Hash)
+	if err != nil || block == nil {
+		l.log.Warn(fmt.Sprintf("failed to find accountblock %s: %v", req.Hash, err))
+		return CodeException, ExpMissing
+	}
+
+	// unconfirmed blocks have no proof
+	snapshotBlock, err := l.chain.GetConfirmSnapshotBlockByAbHash(req.Hash)
+	if err != nil || snapshotBlock == nil {
+		return CodeException, ExpMissing
+	}
+
+	confirmed, ok := snapshotBlock.SnapshotContent[block.AccountAddress]
+	if !ok || confirmed.Height < block.Height || confirmed.Height-block.Height >= maxProofBlocks {
+		return CodeException, ExpMissing
+	}
+
+	blocks, err := l.chain.GetAccountBlocksByRange(block.AccountAddress, block.Height, confirmed.Height)
+	if err != nil || uint64(len(blocks)) != confirmed.Height-block.Height+1 {
+		l.log.Warn(fmt.Sprintf("failed to get accountblocks of %s from %d to %d: %v", block.AccountAddress, block.Height, confirmed.Height, err))
+		return CodeException, ExpMissing
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].Height < blocks[j].Height
+	})
+
+	return CodeAccountBlockProof, &AccountBlockProof{
+		SnapshotHeight: snapshotBlock.Height,
+		Blocks:         blocks,
+	}
+}
+
+func (l *lightHandler) handleGetAccountState(req *GetAccountState) (Code, Serializable) {
+	if len(req.TokenIds)+len(req.StorageKeys) > maxStateItems {
+		return CodeException, ExpUnsolicited
+	}
+
+	snapshotBlock, err := l.chain.GetSnapshotBlockByHeight(req.Snapshot.Height)
+	if err != nil || snapshotBlock == nil || snapshotBlock.Hash != req.Snapshot.Hash {
+		return CodeException, ExpMissing
+	}
+
+	state, err := l.chain.GetSnapshotState(req.Address, req.Snapshot.Height)
+	if err != nil {
+		return CodeException, ExpMissing
+	}
+	defer state.Release()
+
+	res := &AccountState{
+		Snapshot:      req.Snapshot,
+		Balances:      make([]*big.Int, len(req.TokenIds)),
+		StorageValues: make([][]byte, len(req.StorageKeys)),
+	}
+	for i := range req.TokenIds {
+		if res.Balances[i], err = state.GetBalance(&req.TokenIds[i]); err != nil {
+			return CodeException, ExpServerError
+		}
+	}
+	for i, key := range req.StorageKeys {
+		if res.StorageValues[i], err = state.GetValue(key); err != nil {
+			return CodeException, ExpServerError
+		}
+	}
+
+	return CodeAccountState, res
+}
+
+func (l *lightHandler) handleGetElection(req *GetElection) (Code, Serializable) {
+	t := time.Unix(req.Timestamp, 0)
+	if t.After(time.Now()) {
+		return CodeException, ExpUnsolicited
+	}
+
+	index := l.consensus.SBPReader().GetPeriodTimeIndex().Time2Index(t)
+	events, index, err := l.consensus.API().ReadByIndex(types.SNAPSHOT_GID, index)
+	if err != nil || len(events) == 0 {
+		l.log.Warn(fmt.Sprintf("failed to read election at %d: %v", req.Timestamp, err))
+		return CodeException, ExpMissing
+	}
+
+	election := &Election{
+		Index:       index,
+		PeriodStime: events[0].PeriodStime.Unix(),
+		PeriodEtime: events[0].PeriodEtime.Unix(),
+		Slots:       make([]ElectionSlot, len(events)),
+	}
+	for i, e := range events {
+		election.Slots[i] = ElectionSlot{
+			Address: e.Address,
+			Stime:   e.Stime.Unix(),
+			Etime:   e.Etime.Unix(),
+		}
+	}
+
+	return CodeElection, election
+}
+
 // helper
 type accountBlockMap = map[types.Address][]*ledger.AccountBlock
 
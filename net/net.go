@@ -61,8 +61,9 @@ type net struct {
 	*syncer  // use pointer but not interface, because syncer can be start/stop, but interface has no start/stop method
 	*fetcher // use pointer but not interface, because fetcher can be start/stop, but interface has no start/stop method
 	*broadcaster
-	reader     *cacheReader
-	downloader syncDownloader
+	reader       *cacheReader
+	downloader   syncDownloader
+	chunkFetcher *chunkFetcher
 	BlockSubscriber
 	handlers *msgHandlers
 	query    *queryHandler
@@ -377,13 +378,11 @@ func New(cfg *config.Net, chain Chain, verifier Verifier, consensus Consensus, i
 		})
 	}
 
-	var peerKey ed25519.PrivateKey
-	peerKey, err = cfg.Init()
+	n, err := newTransport(cfg, chain, consensus)
 	if err != nil {
 		return nil, err
 	}
-
-	peers := newPeerSet()
+	peers := n.peers
 
 	feed := newBlockFeeder(blackHashList)
 
@@ -395,16 +394,18 @@ func New(cfg *config.Net, chain Chain, verifier Verifier, consensus Consensus, i
 		Verifier:    verifier,
 	}
 
-	var id peerId
-	id, _ = vnode.Bytes2NodeID(peerKey.PubByte())
+	mineKey := newMineSigner(cfg.MineKey, cfg.MineSigner)
 	syncConnFac := &defaultSyncConnectionFactory{
 		chain:   chain,
 		peers:   peers,
-		id:      id,
-		peerKey: peerKey,
-		mineKey: cfg.MineKey,
+		id:      n.node.ID,
+		peerKey: n.peerKey,
+		mineKey: mineKey,
+	}
+	var downloader syncDownloader = newExecutor(50, 10, peers, syncConnFac)
+	if cfg.SyncMirror != "" {
+		downloader = newMirrorDownloader(cfg.SyncMirror, chain, downloader)
 	}
-	downloader := newExecutor(50, 10, peers, syncConnFac)
 
 	reader := newCacheReader(chain, verifier, downloader, irreader, blackHashList)
 
@@ -417,24 +418,76 @@ func New(cfg *config.Net, chain Chain, verifier Verifier, consensus Consensus, i
 	syncer.SubscribeSyncStatus(fetcher.subSyncState)
 	syncer.SubscribeSyncStatus(broadcaster.subSyncState)
 
-	n := &net{
+	n.chain = chain
+	n.BlockSubscriber = feed
+	n.syncer = syncer
+	n.reader = reader
+	n.fetcher = fetcher
+	n.broadcaster = broadcaster
+	n.downloader = downloader
+	n.chunkFetcher = newChunkFetcher(peers, syncConnFac)
+	n.syncServer = newSyncServer(cfg.ListenInterface+":"+strconv.Itoa(cfg.FilePort), chain, syncConnFac)
+	n.confirmedHashHeightList = confirmedHashList
+
+	if n.finder._selfIsSBP {
+		n.fetcher.sbp = true
+		n.syncer.sbp = true
+	}
+
+	n.query, err = newQueryHandler(chain, consensus)
+	if err != nil {
+		panic(fmt.Errorf("cannot construct query handler: %v", err))
+	}
+
+	// GetSubLedgerCode, CodeGetSnapshotBlocks, CodeGetAccountBlocks, GetChunkCode
+	if err = n.handlers.register(n.query); err != nil {
+		panic(fmt.Errorf("cannot register handler: query: %v", err))
+	}
+
+	// CodeNewSnapshotBlock, CodeNewAccountBlock
+	if err = n.handlers.register(broadcaster); err != nil {
+		panic(fmt.Errorf("cannot register handler: broadcaster: %v", err))
+	}
+
+	// CodeSnapshotBlocks, CodeAccountBlocks
+	if err = n.handlers.register(fetcher); err != nil {
+		panic(fmt.Errorf("cannot register handler: fetcher: %v", err))
+	}
+
+	// CodeSnapshotBlocks, CodeAccountBlocks
+	if err = n.handlers.register(syncer); err != nil {
+		panic(fmt.Errorf("cannot register handler: syncer: %v", err))
+	}
+
+	return n, nil
+}
+
+// newTransport creates the part of net shared by full nodes and edge nodes: handshaker, discovery, finder and
+// heartbeat. chain provides the genesis and the head in handshakes and heartbeats, consensus can be nil.
+func newTransport(cfg *config.Net, chain chainReader, consensus Consensus) (n *net, err error) {
+	var peerKey ed25519.PrivateKey
+	peerKey, err = cfg.Init()
+	if err != nil {
+		return nil, err
+	}
+
+	peers := newPeerSet()
+
+	var id peerId
+	id, _ = vnode.Bytes2NodeID(peerKey.PubByte())
+
+	mineKey := newMineSigner(cfg.MineKey, cfg.MineSigner)
+
+	n = &net{
 		config: cfg,
-		chain:  chain,
 		node: &vnode.Node{
 			ID:  id,
 			Net: cfg.NetID,
 		},
-		peerKey:         peerKey,
-		BlockSubscriber: feed,
-		peers:           peers,
-		syncer:          syncer,
-		reader:          reader,
-		fetcher:         fetcher,
-		broadcaster:     broadcaster,
-		downloader:      downloader,
-		syncServer:      newSyncServer(cfg.ListenInterface+":"+strconv.Itoa(cfg.FilePort), chain, syncConnFac),
-		handlers:        newHandlers("vite"),
-		hb:              newHeartBeater(peers, chain),
+		peerKey:  peerKey,
+		peers:    peers,
+		handlers: newHandlers("vite"),
+		hb:       newHeartBeater(peers, chain),
 		blackList: netool.NewBlackList(func(t int64, count int) bool {
 			now := time.Now().Unix()
 
@@ -444,8 +497,7 @@ func New(cfg *config.Net, chain Chain, verifier Verifier, consensus Consensus, i
 
 			return false
 		}),
-		log:                     netLog,
-		confirmedHashHeightList: confirmedHashList,
+		log: netLog,
 	}
 
 	fileAddress, err := retrieveAddressBytesFromConfig(cfg.FilePublicAddress, cfg.FilePort)
@@ -466,7 +518,7 @@ func New(cfg *config.Net, chain Chain, verifier Verifier, consensus Consensus, i
 		fileAddress:   fileAddress,
 		publicAddress: publicAddress,
 		peerKey:       peerKey,
-		key:           cfg.MineKey,
+		key:           mineKey,
 		codecFactory: &transportFactory{
 			minCompressLength: 100,
 			readTimeout:       readMsgTimeout,
@@ -477,6 +529,16 @@ func New(cfg *config.Net, chain Chain, verifier Verifier, consensus Consensus, i
 		onHandshaker: n.authorize,
 	}
 
+	switch cfg.Encryption {
+	case config.EncryptionDisable:
+		n.hkr.encryption = encryptionNone
+	case config.EncryptionRequire:
+		n.hkr.encryption = encryptionAESGCM
+		n.hkr.requireEncryption = true
+	default:
+		n.hkr.encryption = encryptionAESGCM
+	}
+
 	n.db, err = database.New(path.Join(cfg.DataDir, DBDirName), 1, n.node.ID)
 	if err != nil {
 		return nil, err
@@ -487,8 +549,10 @@ func New(cfg *config.Net, chain Chain, verifier Verifier, consensus Consensus, i
 	}
 
 	var addr types.Address
-	if len(n.config.MineKey) != 0 {
-		addr = types.PubkeyToAddress(n.config.MineKey.PubByte())
+	if mineKey != nil {
+		if addr, err = mineAddress(mineKey); err != nil {
+			return nil, err
+		}
 	}
 
 	n.finder, err = newFinder(addr, n.peers, cfg.MinPeers, cfg.StaticNodes, n.db, n, consensus)
@@ -496,15 +560,12 @@ func New(cfg *config.Net, chain Chain, verifier Verifier, consensus Consensus, i
 		return nil, err
 	}
 
-	if n.finder._selfIsSBP {
-		n.fetcher.sbp = true
-		n.syncer.sbp = true
-	}
-
 	if n.discover != nil {
 		n.discover.SetFinder(n.finder)
-		if len(n.config.MineKey) != 0 {
-			setNodeExt(n.config.MineKey, n.node)
+		if mineKey != nil {
+			if err = setNodeExt(mineKey, n.node); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -516,31 +577,6 @@ func New(cfg *config.Net, chain Chain, verifier Verifier, consensus Consensus, i
 		panic(fmt.Errorf("cannot register handler: broadcaster: %v", err))
 	}
 
-	n.query, err = newQueryHandler(chain)
-	if err != nil {
-		panic(fmt.Errorf("cannot construct query handler: %v", err))
-	}
-
-	// GetSubLedgerCode, CodeGetSnapshotBlocks, CodeGetAccountBlocks, GetChunkCode
-	if err = n.handlers.register(n.query); err != nil {
-		panic(fmt.Errorf("cannot register handler: query: %v", err))
-	}
-
-	// CodeNewSnapshotBlock, CodeNewAccountBlock
-	if err = n.handlers.register(broadcaster); err != nil {
-		panic(fmt.Errorf("cannot register handler: broadcaster: %v", err))
-	}
-
-	// CodeSnapshotBlocks, CodeAccountBlocks
-	if err = n.handlers.register(fetcher); err != nil {
-		panic(fmt.Errorf("cannot register handler: fetcher: %v", err))
-	}
-
-	// CodeSnapshotBlocks, CodeAccountBlocks
-	if err = n.handlers.register(syncer); err != nil {
-		panic(fmt.Errorf("cannot register handler: syncer: %v", err))
-	}
-
 	return n, nil
 }
 
@@ -0,0 +1,215 @@
+package net
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	net2 "net"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_block "github.com/vitelabs/go-vite/v2/ledger/chain/block"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+var errNoChunkPeer = errors.New("no peer can supply the chunk")
+
+// memChunkCache keeps the chunks downloaded by the chunkFetcher in memory, they are not written into the sync cache
+type memChunkCache struct {
+	mu     sync.Mutex
+	chunks map[string][]byte
+}
+
+type memChunkWriter struct {
+	bytes.Buffer
+	seg   interfaces.Segment
+	cache *memChunkCache
+}
+
+func (w *memChunkWriter) Close() error {
+	w.cache.mu.Lock()
+	w.cache.chunks[w.seg.String()] = w.Bytes()
+	w.cache.mu.Unlock()
+	return nil
+}
+
+func newMemChunkCache() *memChunkCache {
+	return &memChunkCache{
+		chunks: make(map[string][]byte),
+	}
+}
+
+func (c *memChunkCache) GetSyncCache() interfaces.SyncCache {
+	return c
+}
+
+func (c *memChunkCache) NewWriter(segment interfaces.Segment, size int64) (io.WriteCloser, error) {
+	return &memChunkWriter{seg: segment, cache: c}, nil
+}
+
+func (c *memChunkCache) Chunks() interfaces.SegmentList {
+	return nil
+}
+
+func (c *memChunkCache) NewReader(segment interfaces.Segment) (interfaces.ChunkReader, error) {
+	return nil, errors.New("memory chunk cache can not be read as a chunk reader")
+}
+
+func (c *memChunkCache) Delete(seg interfaces.Segment) error {
+	c.mu.Lock()
+	delete(c.chunks, seg.String())
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *memChunkCache) Close() error {
+	return nil
+}
+
+func (c *memChunkCache) take(seg interfaces.Segment) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.chunks[seg.String()]
+	delete(c.chunks, seg.String())
+	return data
+}
+
+// chunkFetcher downloads snapshot chunks from peers over the sync connection, it is used to repair the block files
+type chunkFetcher struct {
+	peers   *peerSet
+	factory *defaultSyncConnectionFactory
+	cache   *memChunkCache
+	dialer  *net2.Dialer
+	log     log15.Logger
+}
+
+func newChunkFetcher(peers *peerSet, factory *defaultSyncConnectionFactory) *chunkFetcher {
+	cache := newMemChunkCache()
+
+	fac := *factory
+	fac.chain = cache
+
+	return &chunkFetcher{
+		peers:   peers,
+		factory: &fac,
+		cache:   cache,
+		dialer: &net2.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 5 * time.Second,
+		},
+		log: netLog.New("module", "chunkFetcher"),
+	}
+}
+
+// fetch downloads the chunk from peers one by one, until a peer supplies the right chunk
+func (f *chunkFetcher) fetch(from, to uint64, prevHash, hash types.Hash) ([]*ledger.SnapshotChunk, error) {
+	if from == 0 || from > to {
+		return nil, fmt.Errorf("invalid chunk %d-%d", from, to)
+	}
+
+	seg := interfaces.Segment{From: from, To: to, PrevHash: prevHash, Hash: hash}
+
+	err := errNoChunkPeer
+	for _, p := range f.peers.pick(to) {
+		var chunks []*ledger.SnapshotChunk
+		if chunks, err = f.fetchFrom(p, seg); err == nil {
+			return chunks, nil
+		}
+		f.log.Warn(fmt.Sprintf("failed to fetch chunk %s from %s: %v", seg, p, err))
+	}
+
+	return nil, err
+}
+
+func (f *chunkFetcher) fetchFrom(p *Peer, seg interfaces.Segment) ([]*ledger.SnapshotChunk, error) {
+	if p.fileAddress == "" {
+		return nil, errors.New("error file address")
+	}
+
+	tcp, err := f.dialer.Dial("tcp", p.fileAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := f.factory.initiate(tcp, p)
+	if err != nil {
+		_ = tcp.Close()
+		return nil, err
+	}
+	defer c.close()
+
+	if _, err = c.download(&syncTask{Segment: seg}); err != nil {
+		return nil, err
+	}
+
+	return decodeChunks(f.cache.take(seg), seg)
+}
+
+// decodeChunks parses the block records of the chunk, the snapshot blocks must be linked from seg.PrevHash to seg.Hash
+func decodeChunks(data []byte, seg interfaces.Segment) ([]*ledger.SnapshotChunk, error) {
+	r := bytes.NewReader(data)
+
+	var chunks []*ledger.SnapshotChunk
+	var accountBlocks []*ledger.AccountBlock
+
+	next := seg.From
+	prevHash := seg.PrevHash
+	for r.Len() > 0 {
+		record, err := readChunkRecord(r)
+		if err != nil {
+			return nil, err
+		}
+
+		switch record[4] {
+		case chain_block.BlockTypeAccountBlock:
+			buf, err := snappy.Decode(nil, record[5:])
+			if err != nil {
+				return nil, err
+			}
+			ab := &ledger.AccountBlock{}
+			if err = ab.Deserialize(buf); err != nil {
+				return nil, err
+			}
+			accountBlocks = append(accountBlocks, ab)
+
+		case chain_block.BlockTypeSnapshotBlock:
+			sb, err := decodeSnapshotRecord(record)
+			if err != nil {
+				return nil, err
+			}
+			if sb.Height != next || sb.PrevHash != prevHash || sb.ComputeHash() != sb.Hash {
+				return nil, fmt.Errorf("snapshot block %d/%s is not linked to %d/%s", sb.Height, sb.PrevHash, next-1, prevHash)
+			}
+
+			chunks = append(chunks, &ledger.SnapshotChunk{
+				SnapshotBlock: sb,
+				AccountBlocks: accountBlocks,
+			})
+			accountBlocks = nil
+			next++
+			prevHash = sb.Hash
+
+		default:
+			return nil, fmt.Errorf("unknown block type %d", record[4])
+		}
+	}
+
+	if next != seg.To+1 || prevHash != seg.Hash || len(accountBlocks) != 0 {
+		return nil, fmt.Errorf("chunk is not %s", seg)
+	}
+
+	return chunks, nil
+}
+
+// FetchChunks downloads the snapshot chunks from fromHeight to toHeight from peers, the snapshot blocks are
+// linked from prevHash to hash. The chunks are not written into the sync cache.
+func (n *net) FetchChunks(fromHeight, toHeight uint64, prevHash, hash types.Hash) ([]*ledger.SnapshotChunk, error) {
+	return n.chunkFetcher.fetch(fromHeight, toHeight, prevHash, hash)
+}
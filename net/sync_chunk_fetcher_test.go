@@ -0,0 +1,50 @@
+package net
+
+import (
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	chain_block "github.com/vitelabs/go-vite/v2/ledger/chain/block"
+)
+
+func TestDecodeChunks(t *testing.T) {
+	c := newMockMirrorChain(t, 10)
+
+	// snapshot block records only, the account block records of the mock chain can not be decoded
+	var data []byte
+	for i := uint64(3); i <= 6; i++ {
+		sb := c.blocks[i-1]
+		buf, err := sb.Serialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append(data, mockChunkRecord(chain_block.BlockTypeSnapshotBlock, buf)...)
+	}
+
+	chunks, err := decodeChunks(data, c.segment(3, 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 4 {
+		t.Fatalf("should be 4 chunks: %d", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if chunk.SnapshotBlock.Height != uint64(i+3) {
+			t.Errorf("wrong snapshot block %d of chunk %d", chunk.SnapshotBlock.Height, i)
+		}
+	}
+
+	seg := c.segment(3, 6)
+	seg.PrevHash = types.Hash{1}
+	if _, err = decodeChunks(data, seg); err == nil {
+		t.Error("chunks of wrong prev hash should fail")
+	}
+
+	if _, err = decodeChunks(data, c.segment(3, 7)); err == nil {
+		t.Error("incomplete chunks should fail")
+	}
+
+	if _, err = decodeChunks(data[:len(data)-1], c.segment(3, 6)); err == nil {
+		t.Error("truncated record should fail")
+	}
+}
@@ -175,7 +175,7 @@ type defaultSyncConnectionFactory struct {
 	peers   *peerSet
 	id      peerId
 	peerKey ed25519.PrivateKey
-	mineKey ed25519.PrivateKey
+	mineKey mineSigner
 }
 
 func (d *defaultSyncConnectionFactory) makeSyncConn(conn net2.Conn) *syncConn {
@@ -203,9 +203,13 @@ func (d *defaultSyncConnectionFactory) initiate(conn net2.Conn, peer *Peer) (*sy
 	binary.BigEndian.PutUint64(t, uint64(hk.time))
 	hash := crypto.Hash256(t)
 	hk.token = xor(hash, secret)
-	if len(d.mineKey) != 0 {
-		hk.key = d.mineKey.PubByte()
-		hk.token = ed25519.Sign(d.mineKey, hk.token)
+	if d.mineKey != nil {
+		pub, sign, err := mineSign(d.mineKey, hk.token)
+		if err != nil {
+			return nil, err
+		}
+		hk.key = pub
+		hk.token = sign
 	}
 
 	data, err := hk.Serialize()
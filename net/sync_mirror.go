@@ -0,0 +1,618 @@
+/*
+ * Copyright 2019 The go-vite Authors
+ * This file is part of the go-vite library.
+ *
+ * The go-vite library is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The go-vite library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the go-vite library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package net
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_block "github.com/vitelabs/go-vite/v2/ledger/chain/block"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+const MirrorManifestName = "manifest.json"
+
+const mirrorManifestExpire = time.Minute
+
+// mirrorPeerId is the source of the chunks downloaded from the mirror
+var mirrorPeerId = peerId{'m', 'i', 'r', 'r', 'o', 'r'}
+
+var errMirrorNotCover = errors.New("mirror does not cover the chunk")
+
+// MirrorSegment is a chunk file in the sync mirror, the file has the same format as the chunk sent by the sync server
+type MirrorSegment struct {
+	From     uint64     `json:"from"`
+	To       uint64     `json:"to"`
+	PrevHash types.Hash `json:"prevHash"`
+	Hash     types.Hash `json:"hash"`
+	File     string     `json:"file"`
+	Size     int64      `json:"size"`
+}
+
+// MirrorManifest lists the chunk files in the sync mirror
+type MirrorManifest struct {
+	Segments []*MirrorSegment `json:"segments"`
+}
+
+type mirrorSource interface {
+	open(name string) (io.ReadCloser, error)
+	String() string
+}
+
+// newMirrorSource returns a static http mirror if location is an http(s) url, or a local directory
+func newMirrorSource(location string) mirrorSource {
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		return &httpMirror{
+			base:   strings.TrimRight(location, "/"),
+			client: &http.Client{Timeout: 5 * time.Minute},
+		}
+	}
+	return &dirMirror{dir: location}
+}
+
+type dirMirror struct {
+	dir string
+}
+
+func (d *dirMirror) open(name string) (io.ReadCloser, error) {
+	if filepath.Base(name) != name {
+		return nil, fmt.Errorf("invalid mirror file %s", name)
+	}
+	return os.Open(filepath.Join(d.dir, name))
+}
+
+func (d *dirMirror) String() string {
+	return d.dir
+}
+
+type httpMirror struct {
+	base   string
+	client *http.Client
+}
+
+func (h *httpMirror) open(name string) (io.ReadCloser, error) {
+	if strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid mirror file %s", name)
+	}
+	resp, err := h.client.Get(h.base + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("failed to get %s/%s: %s", h.base, name, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func (h *httpMirror) String() string {
+	return h.base
+}
+
+func loadMirrorManifest(source mirrorSource) (*MirrorManifest, error) {
+	r, err := source.open(MirrorManifestName)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	manifest := &MirrorManifest{}
+	if err = json.NewDecoder(r).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("invalid mirror manifest: %v", err)
+	}
+	for _, seg := range manifest.Segments {
+		if seg.From > seg.To || seg.From == 0 {
+			return nil, fmt.Errorf("invalid mirror segment %d-%d", seg.From, seg.To)
+		}
+	}
+	sort.Slice(manifest.Segments, func(i, j int) bool {
+		return manifest.Segments[i].From < manifest.Segments[j].From
+	})
+
+	return manifest, nil
+}
+
+// coverSegments returns the continuous segments covering from-to, or nil
+func coverSegments(segments []*MirrorSegment, from, to uint64) []*MirrorSegment {
+	index := sort.Search(len(segments), func(i int) bool {
+		return segments[i].To >= from
+	})
+	if index == len(segments) || segments[index].From > from {
+		return nil
+	}
+
+	ret := []*MirrorSegment{segments[index]}
+	for i := index + 1; ret[len(ret)-1].To < to; i++ {
+		last := ret[len(ret)-1]
+		if i == len(segments) || segments[i].From != last.To+1 || segments[i].PrevHash != last.Hash {
+			return nil
+		}
+		ret = append(ret, segments[i])
+	}
+
+	return ret
+}
+
+// extractChunk reads the blocks of snapshot blocks from t.From to t.To from the segment files,
+// the snapshot blocks must be linked from t.PrevHash to t.Hash
+func extractChunk(source mirrorSource, segments []*MirrorSegment, t *syncTask) ([]byte, error) {
+	var out bytes.Buffer
+	var pending bytes.Buffer
+
+	next := t.From
+	prevHash := t.PrevHash
+
+	for _, seg := range segments {
+		r, err := source.open(seg.File)
+		if err != nil {
+			return nil, err
+		}
+
+		var read int64
+		var sb *ledger.SnapshotBlock
+		for {
+			var record []byte
+			if record, err = readChunkRecord(r); err != nil {
+				break
+			}
+			read += int64(len(record))
+
+			if record[4] != chain_block.BlockTypeSnapshotBlock {
+				pending.Write(record)
+				continue
+			}
+
+			if sb, err = decodeSnapshotRecord(record); err != nil {
+				break
+			}
+			if sb.Height < next {
+				pending.Reset()
+				continue
+			}
+			if sb.Height != next || sb.PrevHash != prevHash {
+				err = fmt.Errorf("snapshot block %d/%s is not linked to %d/%s", sb.Height, sb.PrevHash, next-1, prevHash)
+				break
+			}
+
+			out.Write(pending.Bytes())
+			out.Write(record)
+			pending.Reset()
+
+			if sb.Height == t.To {
+				if sb.Hash != t.Hash {
+					err = fmt.Errorf("snapshot block %d is %s, not %s", sb.Height, sb.Hash, t.Hash)
+				} else {
+					_ = r.Close()
+					return out.Bytes(), nil
+				}
+				break
+			}
+
+			next++
+			prevHash = sb.Hash
+		}
+		_ = r.Close()
+
+		if err != io.EOF {
+			return nil, fmt.Errorf("failed to read mirror file %s: %v", seg.File, err)
+		}
+		if read != seg.Size {
+			return nil, fmt.Errorf("mirror file %s has %d bytes, not %d", seg.File, read, seg.Size)
+		}
+	}
+
+	return nil, errMirrorNotCover
+}
+
+// readChunkRecord reads one block record: 4 bytes length, 1 byte type and snappy encoded data
+func readChunkRecord(r io.Reader) ([]byte, error) {
+	var head [4]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(head[:])
+	if size == 0 || size > maxPayloadSize {
+		return nil, fmt.Errorf("invalid record size %d", size)
+	}
+
+	record := make([]byte, 4+size)
+	copy(record, head[:])
+	if _, err := io.ReadFull(r, record[4:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	return record, nil
+}
+
+func decodeSnapshotRecord(record []byte) (*ledger.SnapshotBlock, error) {
+	data, err := snappy.Decode(nil, record[5:])
+	if err != nil {
+		return nil, err
+	}
+	sb := &ledger.SnapshotBlock{}
+	if err = sb.Deserialize(data); err != nil {
+		return nil, err
+	}
+	return sb, nil
+}
+
+// WriteSyncMirror writes the chunks from 2 to the height into dir as a sync mirror,
+// every chunk has step snapshot blocks. The genesis snapshot block is not synced.
+func WriteSyncMirror(chain ledgerReader, dir string, height, step uint64) (*MirrorManifest, error) {
+	if step == 0 {
+		return nil, errors.New("step must be larger than 0")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	manifest := &MirrorManifest{Segments: []*MirrorSegment{}}
+	for from := uint64(2); from <= height; from += step {
+		to := from + step - 1
+		if to > height {
+			to = height
+		}
+
+		seg, err := writeMirrorSegment(chain, dir, from, to)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Segments = append(manifest.Segments, seg)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, MirrorManifestName), data, 0644); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+func writeMirrorSegment(chain ledgerReader, dir string, from, to uint64) (*MirrorSegment, error) {
+	reader, err := chain.GetLedgerReaderByHeight(from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	s := reader.Seg()
+	seg := &MirrorSegment{
+		From:     s.From,
+		To:       s.To,
+		PrevHash: s.PrevHash,
+		Hash:     s.Hash,
+		File:     fmt.Sprintf("f_%d_%d", s.From, s.To),
+	}
+
+	f, err := os.Create(filepath.Join(dir, seg.File))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if seg.Size, err = io.Copy(f, reader); err != nil {
+		return nil, err
+	}
+	if seg.Size != int64(reader.Size()) {
+		return nil, fmt.Errorf("write %d/%d bytes of chunk %d-%d", seg.Size, reader.Size(), from, to)
+	}
+	if err = f.Sync(); err != nil {
+		return nil, err
+	}
+	return seg, nil
+}
+
+// mirrorDownloader downloads the chunks covered by the mirror, and hands the others to the fallback downloader.
+// The mirror is disabled once a chunk from it fails the verification of the cache reader.
+type mirrorDownloader struct {
+	source   mirrorSource
+	chain    syncCacher
+	fallback syncDownloader
+	workers  int
+
+	mu        sync.Mutex
+	cond      *sync.Cond
+	tasks     syncTasks
+	max       int
+	segments  []*MirrorSegment
+	loadedAt  time.Time
+	disabled  bool
+	running   bool
+	listeners []taskListener
+	wg        sync.WaitGroup
+
+	log log15.Logger
+}
+
+func newMirrorDownloader(location string, chain syncCacher, fallback syncDownloader) *mirrorDownloader {
+	m := &mirrorDownloader{
+		source:   newMirrorSource(location),
+		chain:    chain,
+		fallback: fallback,
+		workers:  2,
+		max:      50,
+		log:      netLog.New("module", "mirror"),
+	}
+	m.cond = sync.NewCond(&m.mu)
+	return m
+}
+
+func (m *mirrorDownloader) start() {
+	m.mu.Lock()
+	if m.running {
+		m.mu.Unlock()
+		return
+	}
+	m.running = true
+	m.tasks = m.tasks[:0]
+	m.mu.Unlock()
+
+	m.reload()
+
+	for i := 0; i < m.workers; i++ {
+		m.wg.Add(1)
+		go m.loop()
+	}
+
+	if m.fallback != nil {
+		m.fallback.start()
+	}
+}
+
+func (m *mirrorDownloader) stop() {
+	m.mu.Lock()
+	if false == m.running {
+		m.mu.Unlock()
+		return
+	}
+	m.running = false
+	m.mu.Unlock()
+
+	m.cond.Broadcast()
+	m.wg.Wait()
+
+	if m.fallback != nil {
+		m.fallback.stop()
+	}
+}
+
+func (m *mirrorDownloader) reload() {
+	manifest, err := loadMirrorManifest(m.source)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.loadedAt = time.Now()
+	if err != nil {
+		m.log.Warn(fmt.Sprintf("failed to load mirror manifest from %s: %v", m.source, err))
+		return
+	}
+	m.segments = manifest.Segments
+}
+
+func (m *mirrorDownloader) cover(t *syncTask) []*MirrorSegment {
+	m.mu.Lock()
+	if m.disabled {
+		m.mu.Unlock()
+		return nil
+	}
+	segments := coverSegments(m.segments, t.From, t.To)
+	expired := time.Now().Sub(m.loadedAt) > mirrorManifestExpire
+	m.mu.Unlock()
+
+	if segments == nil && expired {
+		m.reload()
+
+		m.mu.Lock()
+		segments = coverSegments(m.segments, t.From, t.To)
+		m.mu.Unlock()
+	}
+
+	return segments
+}
+
+func (m *mirrorDownloader) status() DownloaderStatus {
+	var st DownloaderStatus
+	if m.fallback != nil {
+		st = m.fallback.status()
+	}
+
+	m.mu.Lock()
+	for _, t := range m.tasks {
+		st.Tasks = append(st.Tasks, "mirror "+t.status())
+	}
+	m.mu.Unlock()
+
+	return st
+}
+
+func (m *mirrorDownloader) download(t *syncTask, must bool) bool {
+	if t.From > t.To {
+		m.log.Warn(fmt.Sprintf("from is larger than to: %s", t))
+		return true
+	}
+
+	if m.cover(t) == nil {
+		if m.fallback != nil {
+			return m.fallback.download(t, must)
+		}
+		m.log.Warn(fmt.Sprintf("chunk %s is not in mirror %s", t, m.source))
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if false == must {
+		for len(m.tasks) >= m.max && m.running {
+			m.cond.Wait()
+		}
+	}
+
+	if false == m.running {
+		return false
+	}
+
+	m.tasks = append(m.tasks, t)
+	m.cond.Broadcast()
+
+	return true
+}
+
+func (m *mirrorDownloader) cancelAllTasks() {
+	m.mu.Lock()
+	for _, t := range m.tasks {
+		t.cancel()
+	}
+	m.tasks = m.tasks[:0]
+	m.mu.Unlock()
+	m.cond.Broadcast()
+
+	if m.fallback != nil {
+		m.fallback.cancelAllTasks()
+	}
+}
+
+func (m *mirrorDownloader) cancelTask(t *syncTask) {
+	m.mu.Lock()
+	for i, t2 := range m.tasks {
+		if t.equal(t2) {
+			m.tasks = append(m.tasks[:i], m.tasks[i+1:]...)
+			break
+		}
+	}
+	m.mu.Unlock()
+	m.cond.Broadcast()
+
+	if m.fallback != nil {
+		m.fallback.cancelTask(t)
+	}
+}
+
+func (m *mirrorDownloader) addListener(listener taskListener) {
+	m.listeners = append(m.listeners, listener)
+
+	if m.fallback != nil {
+		m.fallback.addListener(listener)
+	}
+}
+
+func (m *mirrorDownloader) addBlackList(id peerId) {
+	if id == mirrorPeerId {
+		m.mu.Lock()
+		m.disabled = true
+		m.mu.Unlock()
+		m.log.Warn(fmt.Sprintf("disable mirror %s", m.source))
+		return
+	}
+
+	if m.fallback != nil {
+		m.fallback.addBlackList(id)
+	}
+}
+
+func (m *mirrorDownloader) loop() {
+	defer m.wg.Done()
+
+	for {
+		m.mu.Lock()
+		for len(m.tasks) == 0 && m.running {
+			m.cond.Wait()
+		}
+		if false == m.running {
+			m.mu.Unlock()
+			return
+		}
+		t := m.tasks[0]
+		m.tasks = m.tasks[1:]
+		t.pending()
+		m.mu.Unlock()
+		m.cond.Broadcast()
+
+		if err := m.do(t); err != nil {
+			m.log.Warn(fmt.Sprintf("failed to download chunk %s from mirror %s: %v", t, m.source, err))
+			t.error()
+			if m.fallback != nil {
+				m.fallback.download(t, true)
+			}
+			continue
+		}
+
+		t.source = mirrorPeerId
+		t.done()
+		for _, listener := range m.listeners {
+			listener(*t, nil)
+		}
+	}
+}
+
+func (m *mirrorDownloader) do(t *syncTask) error {
+	start := time.Now()
+
+	segments := m.cover(t)
+	if segments == nil {
+		return errMirrorNotCover
+	}
+
+	data, err := extractChunk(m.source, segments, t)
+	if err != nil {
+		return err
+	}
+
+	cache := m.chain.GetSyncCache()
+	writer, err := cache.NewWriter(t.Segment, int64(len(data)))
+	if err != nil {
+		return err
+	}
+	if _, err = writer.Write(data); err != nil {
+		_ = writer.Close()
+		_ = cache.Delete(t.Segment)
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		_ = cache.Delete(t.Segment)
+		return err
+	}
+
+	m.log.Info(fmt.Sprintf("download chunk %s from mirror %s elapse %s", t, m.source, time.Now().Sub(start)))
+	return nil
+}
@@ -0,0 +1,270 @@
+package net
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_block "github.com/vitelabs/go-vite/v2/ledger/chain/block"
+)
+
+func mockChunkRecord(code byte, data []byte) []byte {
+	sData := snappy.Encode(nil, data)
+	record := make([]byte, 5+len(sData))
+	binary.BigEndian.PutUint32(record, uint32(len(sData)+1))
+	record[4] = code
+	copy(record[5:], sData)
+	return record
+}
+
+// mockMirrorChain holds snapshot blocks from 1 to height, every snapshot block follows an account block record
+type mockMirrorChain struct {
+	blocks  []*ledger.SnapshotBlock
+	records [][]byte
+}
+
+func newMockMirrorChain(t *testing.T, height uint64) *mockMirrorChain {
+	upgrade.CleanupUpgradeBox()
+	upgrade.InitUpgradeBox(upgrade.NewLatestUpgradeBox())
+
+	c := &mockMirrorChain{}
+	var prevHash types.Hash
+	for i := uint64(1); i <= height; i++ {
+		timestamp := time.Unix(1600000000+int64(i), 0)
+		sb := &ledger.SnapshotBlock{
+			Height:    i,
+			PrevHash:  prevHash,
+			Timestamp: &timestamp,
+		}
+		sb.Hash = sb.ComputeHash()
+		data, err := sb.Serialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var record []byte
+		record = append(record, mockChunkRecord(chain_block.BlockTypeAccountBlock, []byte{byte(i)})...)
+		record = append(record, mockChunkRecord(chain_block.BlockTypeSnapshotBlock, data)...)
+
+		c.blocks = append(c.blocks, sb)
+		c.records = append(c.records, record)
+		prevHash = sb.Hash
+	}
+	return c
+}
+
+func (c *mockMirrorChain) segment(from, to uint64) interfaces.Segment {
+	seg := interfaces.Segment{From: from, To: to, Hash: c.blocks[to-1].Hash}
+	if from > 1 {
+		seg.PrevHash = c.blocks[from-2].Hash
+	}
+	return seg
+}
+
+func (c *mockMirrorChain) GetLedgerReaderByHeight(from, to uint64) (interfaces.LedgerReader, error) {
+	var data []byte
+	for i := from; i <= to; i++ {
+		data = append(data, c.records[i-1]...)
+	}
+	return &mockLedgerReader{seg: c.segment(from, to), Reader: bytes.NewReader(data), size: len(data)}, nil
+}
+
+type mockLedgerReader struct {
+	seg interfaces.Segment
+	*bytes.Reader
+	size int
+}
+
+func (r *mockLedgerReader) Seg() interfaces.Segment {
+	return r.seg
+}
+
+func (r *mockLedgerReader) Size() int {
+	return r.size
+}
+
+func (r *mockLedgerReader) Close() error {
+	return nil
+}
+
+type mockMirrorCache struct {
+	interfaces.SyncCache
+	mu     sync.Mutex
+	chunks map[string][]byte
+}
+
+type mockMirrorCacheWriter struct {
+	bytes.Buffer
+	seg   interfaces.Segment
+	cache *mockMirrorCache
+}
+
+func (w *mockMirrorCacheWriter) Close() error {
+	w.cache.mu.Lock()
+	w.cache.chunks[w.seg.String()] = w.Bytes()
+	w.cache.mu.Unlock()
+	return nil
+}
+
+func (c *mockMirrorCache) GetSyncCache() interfaces.SyncCache {
+	return c
+}
+
+func (c *mockMirrorCache) NewWriter(seg interfaces.Segment, size int64) (io.WriteCloser, error) {
+	return &mockMirrorCacheWriter{seg: seg, cache: c}, nil
+}
+
+func (c *mockMirrorCache) Delete(seg interfaces.Segment) error {
+	c.mu.Lock()
+	delete(c.chunks, seg.String())
+	c.mu.Unlock()
+	return nil
+}
+
+func TestCoverSegments(t *testing.T) {
+	segments := []*MirrorSegment{
+		{From: 1, To: 10, Hash: types.Hash{10}},
+		{From: 11, To: 20, PrevHash: types.Hash{10}, Hash: types.Hash{20}},
+		{From: 21, To: 30, PrevHash: types.Hash{1}, Hash: types.Hash{30}},
+	}
+
+	if ret := coverSegments(segments, 5, 15); len(ret) != 2 {
+		t.Errorf("5-15 should be covered by 2 segments: %d", len(ret))
+	}
+	if ret := coverSegments(segments, 11, 20); len(ret) != 1 {
+		t.Errorf("11-20 should be covered by 1 segment: %d", len(ret))
+	}
+	if ret := coverSegments(segments, 15, 25); ret != nil {
+		t.Error("15-25 should not be covered, segments are not linked")
+	}
+	if ret := coverSegments(segments, 25, 35); ret != nil {
+		t.Error("25-35 should not be covered")
+	}
+}
+
+func TestExtractChunk(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sync_mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := newMockMirrorChain(t, 30)
+	manifest, err := WriteSyncMirror(c, dir, 30, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Segments) != 3 {
+		t.Fatalf("should be 3 segments: %d", len(manifest.Segments))
+	}
+
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+
+	for _, location := range []string{dir, server.URL} {
+		source := newMirrorSource(location)
+		m, err := loadMirrorManifest(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		task := &syncTask{Segment: c.segment(5, 25)}
+		data, err := extractChunk(source, coverSegments(m.Segments, 5, 25), task)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader, err := c.GetLedgerReaderByHeight(5, 25)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, _ := ioutil.ReadAll(reader)
+		if !bytes.Equal(data, expected) {
+			t.Errorf("wrong chunk from %s", location)
+		}
+
+		// wrong hash
+		task = &syncTask{Segment: c.segment(5, 25)}
+		task.Hash = types.Hash{1}
+		if _, err = extractChunk(source, coverSegments(m.Segments, 5, 25), task); err == nil {
+			t.Errorf("chunk of wrong hash should fail from %s", location)
+		}
+
+		// wrong prev hash
+		task = &syncTask{Segment: c.segment(5, 25)}
+		task.PrevHash = types.Hash{1}
+		if _, err = extractChunk(source, coverSegments(m.Segments, 5, 25), task); err == nil {
+			t.Errorf("chunk of wrong prev hash should fail from %s", location)
+		}
+	}
+
+	if _, err = newMirrorSource(dir).open("../manifest.json"); err == nil {
+		t.Error("should not open file out of the mirror")
+	}
+}
+
+func TestMirrorDownloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sync_mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := newMockMirrorChain(t, 30)
+	if _, err = WriteSyncMirror(c, dir, 21, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := &mockMirrorCache{chunks: make(map[string][]byte)}
+	d := newMirrorDownloader(dir, cache, nil)
+
+	done := make(chan syncTask, 1)
+	d.addListener(func(t syncTask, err error) {
+		done <- t
+	})
+	d.start()
+	defer d.stop()
+
+	if !d.download(&syncTask{Segment: c.segment(2, 15)}, false) {
+		t.Fatal("failed to download 2-15")
+	}
+	select {
+	case task := <-done:
+		if task.source != mirrorPeerId || task.st != reqDone {
+			t.Errorf("wrong task %s from %s", task.status(), task.source)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("download timeout")
+	}
+	if _, ok := cache.chunks[c.segment(2, 15).String()]; !ok {
+		t.Error("chunk 2-15 is not in cache")
+	}
+
+	// not covered and no fallback
+	if d.download(&syncTask{Segment: c.segment(16, 25)}, false) {
+		t.Error("16-25 is not in mirror")
+	}
+
+	// disabled
+	d.addBlackList(mirrorPeerId)
+	if d.download(&syncTask{Segment: c.segment(11, 20)}, false) {
+		t.Error("mirror should be disabled")
+	}
+
+	if _, err = os.Stat(filepath.Join(dir, MirrorManifestName)); err != nil {
+		t.Error(err)
+	}
+}
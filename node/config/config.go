@@ -24,13 +24,23 @@ type Config struct {
 
 	KeyStoreDir string `json:"KeyStoreDir"`
 
+	// wallet
+	WalletPolicyFile    string `json:"WalletPolicyFile"`    // transfer policies of the addresses in the wallet
+	WalletAuditLog      string `json:"WalletAuditLog"`      // file of the audit log of the wallet
+	WalletUnlockTimeout uint64 `json:"WalletUnlockTimeout"` // seconds, an entropy store is locked again after it
+
 	// chain
 	LedgerGcRetain uint64          `json:"LedgerGcRetain"`
 	LedgerGc       *bool           `json:"LedgerGc"`
 	OpenPlugins    *bool           `json:"OpenPlugins"`
+	BlockScrub     *bool           `json:"BlockScrub"`
+	BlockRepair    *bool           `json:"BlockRepair"`
 	VmLogWhiteList []types.Address `json:"vmLogWhiteList"` // contract address white list which save VM logs
 	VmLogAll       *bool           `json:"vmLogAll"`       // save all VM logs, it will cost more disk space
 
+	BlockCompression string `json:"BlockCompression"` // compression of the new records of the block files, snappy or zstd
+	ChainBackend     string `json:"ChainBackend"`     // key-value backend of the chain dbs, leveldb(default) or memory
+
 	// genesis
 	GenesisFile string `json:"GenesisFile"`
 
@@ -58,12 +68,16 @@ type Config struct {
 	BlackBlockHashList []string // from high to low, like: "xxxxxx-11111"
 	WhiteBlockList     []string // from high to low, like: "xxxxxx-10001"
 	ForwardStrategy    string
+	Encryption         string
+	NodeMode           string
+	SyncMirror         string
 
 	//producer
 	EntropyStorePath     string `json:"EntropyStorePath"`
 	EntropyStorePassword string `json:"EntropyStorePassword"`
 	CoinBase             string `json:"CoinBase"`
 	MinerEnabled         bool   `json:"Miner"`
+	SignerEndpoint       string `json:"SignerEndpoint"` // unix socket of the remote signer of the coinbase
 
 	//rpc
 	RPCEnabled  bool  `json:"RPCEnabled"`
@@ -87,12 +101,31 @@ type Config struct {
 	TestTokenHexPrivKey string   `json:"TestTokenHexPrivKey"`
 	TestTokenTti        string   `json:"TestTokenTti"`
 
+	// api keys and rate limits of the public http and websocket endpoints
+	RPCAccess *config.RPCAccess `json:"RPCAccess"`
+
 	PowServerUrl string `json:"PowServerUrl"`
+	// calc the pow of the rpc requests locally if PowServerUrl is empty, at most LocalPowMaxJobs(default 1) at the same time
+	LocalPowEnabled bool `json:"LocalPowEnabled"`
+	LocalPowMaxJobs int  `json:"LocalPowMaxJobs"`
 
 	//Log level
 	LogLevel    string `json:"LogLevel"`
 	ErrorLogDir string `json:"ErrorLogDir"`
 
+	// levels of the modules in vite.log overriding LogLevel, eg. {"pool": "debug"}, they can be
+	// changed at runtime by the private log api
+	LogModuleLevels map[string]string `json:"LogModuleLevels"`
+	// logfmt(default) or json
+	LogFormat string `json:"LogFormat"`
+
+	// rotation of the log files, the zero values keep the defaults
+	LogMaxSize     int   `json:"LogMaxSize"`     // MB
+	LogRotateHours int   `json:"LogRotateHours"` // rotate periodically besides by size
+	LogMaxBackups  int   `json:"LogMaxBackups"`
+	LogMaxAge      int   `json:"LogMaxAge"` // days
+	LogCompress    *bool `json:"LogCompress"`
+
 	//VM
 	VMTestEnabled         bool `json:"VMTestEnabled"`
 	VMTestParamEnabled    bool `json:"VMTestParamEnabled"`
@@ -102,6 +135,13 @@ type Config struct {
 	// subscribe
 	SubscribeEnabled bool `json:"SubscribeEnabled"`
 
+	// persistent chain events with cursors
+	EventLogEnabled   bool   `json:"EventLogEnabled"`
+	EventLogRetention uint64 `json:"EventLogRetention"`
+
+	// webhooks and files receiving the chain events
+	EventSinks []*config.EventSink `json:"EventSinks"`
+
 	// dashboard
 	DashboardTargetURL string
 
@@ -119,7 +159,12 @@ type Config struct {
 }
 
 func (c *Config) MakeWalletConfig() *config.Wallet {
-	return &config.Wallet{DataDir: c.KeyStoreDir}
+	return &config.Wallet{
+		DataDir:       c.KeyStoreDir,
+		PolicyFile:    c.WalletPolicyFile,
+		AuditLogFile:  c.WalletAuditLog,
+		UnlockTimeout: c.WalletUnlockTimeout,
+	}
 }
 
 func (c *Config) MakeViteConfig() *config.Config {
@@ -164,6 +209,9 @@ func (c *Config) MakeNetConfig() *config.Net {
 		AccessDenyKeys:     c.AccessDenyKeys,
 		BlackBlockHashList: c.BlackBlockHashList,
 		WhiteBlockList:     c.WhiteBlockList,
+		Encryption:         c.Encryption,
+		NodeMode:           c.NodeMode,
+		SyncMirror:         c.SyncMirror,
 		MineKey:            nil,
 	}
 }
@@ -186,7 +234,10 @@ func (c *Config) MakeVmConfig() *config.Vm {
 
 func (c *Config) MakeSubscribeConfig() *config.Subscribe {
 	return &config.Subscribe{
-		IsSubscribe: c.SubscribeEnabled,
+		IsSubscribe:       c.SubscribeEnabled,
+		EventLog:          c.EventLogEnabled,
+		EventLogRetention: c.EventLogRetention,
+		EventSinks:        c.EventSinks,
 	}
 }
 func (c *Config) MakeMinerConfig() *config.Producer {
@@ -194,6 +245,7 @@ func (c *Config) MakeMinerConfig() *config.Producer {
 		Producer:                c.MinerEnabled,
 		Coinbase:                c.CoinBase,
 		EntropyStorePath:        c.EntropyStorePath,
+		SignerEndpoint:          c.SignerEndpoint,
 		VirtualSnapshotVerifier: false,
 	}
 	err := cfg.Parse()
@@ -203,6 +255,32 @@ func (c *Config) MakeMinerConfig() *config.Producer {
 	return cfg
 }
 
+func (c *Config) MakeMetricsConfig() *config.Metrics {
+	cfg := &config.Metrics{}
+	if c.MetricsEnable != nil {
+		cfg.Enabled = *c.MetricsEnable
+	}
+	if c.InfluxDBEnable != nil {
+		cfg.InfluxDBEnable = *c.InfluxDBEnable
+	}
+	if c.InfluxDBEndpoint != nil {
+		cfg.InfluxDBEndpoint = *c.InfluxDBEndpoint
+	}
+	if c.InfluxDBDatabase != nil {
+		cfg.InfluxDBDatabase = *c.InfluxDBDatabase
+	}
+	if c.InfluxDBUsername != nil {
+		cfg.InfluxDBUsername = *c.InfluxDBUsername
+	}
+	if c.InfluxDBPassword != nil {
+		cfg.InfluxDBPassword = *c.InfluxDBPassword
+	}
+	if c.InfluxDBHostTag != nil {
+		cfg.InfluxDBHostTag = *c.InfluxDBHostTag
+	}
+	return cfg
+}
+
 func (c *Config) MakeChainConfig() *config.Chain {
 
 	// is open ledger gc
@@ -216,6 +294,16 @@ func (c *Config) MakeChainConfig() *config.Chain {
 		openPlugins = *c.OpenPlugins
 	}
 
+	// scrub and repair the block files in background
+	blockScrub := false
+	if c.BlockScrub != nil {
+		blockScrub = *c.BlockScrub
+	}
+	blockRepair := false
+	if c.BlockRepair != nil {
+		blockRepair = *c.BlockRepair
+	}
+
 	// save all VM logs, it will cost more disk space
 	vmLogAll := false
 	if c.VmLogAll != nil {
@@ -225,8 +313,13 @@ func (c *Config) MakeChainConfig() *config.Chain {
 		LedgerGcRetain: c.LedgerGcRetain,
 		LedgerGc:       ledgerGc,
 		OpenPlugins:    openPlugins,
+		BlockScrub:     blockScrub,
+		BlockRepair:    blockRepair,
 		VmLogWhiteList: c.VmLogWhiteList,
 		VmLogAll:       vmLogAll,
+
+		BlockCompression: c.BlockCompression,
+		Backend:          c.ChainBackend,
 	}
 }
 
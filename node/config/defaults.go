@@ -36,6 +36,8 @@ var DefaultNodeConfig = Config{
 	MaxPendingPeers: config.DefaultMaxPendingPeers,
 	ForwardStrategy: config.DefaultForwardStrategy,
 	AccessControl:   config.DefaultAccessControl,
+	Encryption:      config.DefaultEncryption,
+	NodeMode:        config.NodeModeFull,
 }
 
 // DefaultDataDir is the default data directory to use for the databases and other persistence requirements.
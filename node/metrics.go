@@ -0,0 +1,97 @@
+package node
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/metrics"
+)
+
+const (
+	metricsPath           = "/metrics"
+	influxDBPushInterval  = 10 * time.Second
+	defaultInfluxDatabase = "vite"
+)
+
+// startMetrics registers the gauges of the vite modules, serves them on the private http endpoint
+// and pushes them to influxdb if it is enabled.
+func (node *Node) startMetrics() error {
+	cfg := node.config.MakeMetricsConfig()
+	if !cfg.Enabled {
+		return nil
+	}
+	metrics.Enabled = true
+
+	node.registerMetrics()
+
+	if node.privateHttpListener != nil && node.privateHttpHandler != nil {
+		node.privateHttpHandler.RegisterHTTPHandler(metricsPath, metrics.PrometheusHandler(metrics.DefaultRegistry))
+		log.Info("Metrics endpoint opened", "url", fmt.Sprintf("http://%s%s", node.privateHttpEndpoint, metricsPath))
+	} else {
+		log.Warn("Metrics endpoint is not served, the private http endpoint is not open")
+	}
+
+	if cfg.InfluxDBEnable {
+		database := cfg.InfluxDBDatabase
+		if len(database) == 0 {
+			database = defaultInfluxDatabase
+		}
+		hostTag := cfg.InfluxDBHostTag
+		if len(hostTag) == 0 {
+			hostTag = node.config.Identity
+		}
+		reporter, err := metrics.NewInfluxDBReporter(metrics.DefaultRegistry, influxDBPushInterval,
+			cfg.InfluxDBEndpoint, database, cfg.InfluxDBUsername, cfg.InfluxDBPassword, hostTag)
+		if err != nil {
+			return err
+		}
+		reporter.Start()
+		node.influxDBReporter = reporter
+		log.Info("Metrics are pushed to influxdb", "endpoint", cfg.InfluxDBEndpoint, "database", database)
+	}
+	return nil
+}
+
+func (node *Node) stopMetrics() {
+	if node.influxDBReporter != nil {
+		node.influxDBReporter.Stop()
+		node.influxDBReporter = nil
+	}
+}
+
+func (node *Node) registerMetrics() {
+	v := node.viteServer
+	gauges := []*metrics.FuncGauge{
+		metrics.NewFuncGauge("vite_pool_snapshot_pending", "Num of snapshot blocks pending in the pool.", func() float64 {
+			return float64(v.Pool().SnapshotPendingNum())
+		}),
+		metrics.NewFuncGauge("vite_pool_account_pending", "Num of account blocks pending in the pool.", func() float64 {
+			f, _ := new(big.Float).SetInt(v.Pool().AccountPendingNum()).Float64()
+			return f
+		}),
+		metrics.NewFuncGauge("vite_onroad_backlog", "Num of onroad blocks waiting to be received by contracts.", func() float64 {
+			return float64(v.OnRoad().GetOnRoadBacklog())
+		}),
+		metrics.NewFuncGauge("vite_net_peers", "Num of connected peers.", func() float64 {
+			return float64(v.Net().PeerCount())
+		}),
+		metrics.NewFuncGauge("vite_sync_current_height", "Current height of the snapshot chain sync.", func() float64 {
+			return float64(v.Net().Status().Current)
+		}),
+		metrics.NewFuncGauge("vite_sync_target_height", "Target height of the snapshot chain sync.", func() float64 {
+			return float64(v.Net().Status().To)
+		}),
+		metrics.NewFuncGauge("vite_chain_snapshot_height", "Height of the latest snapshot block.", func() float64 {
+			return float64(v.Chain().GetLatestSnapshotBlock().Height)
+		}),
+	}
+
+	for _, g := range gauges {
+		// the node may be restarted in the same process
+		metrics.DefaultRegistry.Unregister(g.Name())
+		if err := metrics.DefaultRegistry.Register(g); err != nil {
+			log.Error(fmt.Sprintf("register metric %s failed: %v", g.Name(), err))
+		}
+	}
+}
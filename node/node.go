@@ -15,8 +15,12 @@ import (
 	"github.com/vitelabs/go-vite/v2"
 	"github.com/vitelabs/go-vite/v2/cmd/utils/flock"
 	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
+	chain_genesis "github.com/vitelabs/go-vite/v2/ledger/chain/genesis"
 	"github.com/vitelabs/go-vite/v2/log15"
+	"github.com/vitelabs/go-vite/v2/metrics"
 	"github.com/vitelabs/go-vite/v2/monitor"
+	vnet "github.com/vitelabs/go-vite/v2/net"
 	nodeconfig "github.com/vitelabs/go-vite/v2/node/config"
 	"github.com/vitelabs/go-vite/v2/pow"
 	"github.com/vitelabs/go-vite/v2/pow/remote"
@@ -42,6 +46,9 @@ type Node struct {
 	viteConfig *config.Config
 	viteServer *vite.Vite
 
+	// edge is set instead of viteServer in edge mode
+	edge *vnet.Edge
+
 	// List of APIs currently provided by the node
 	rpcAPIs          []rpc.API
 	inProcessHandler *rpc.Server
@@ -64,6 +71,8 @@ type Node struct {
 
 	wsCli *rpc.WebSocketCli
 
+	influxDBReporter *metrics.InfluxDBReporter
+
 	// Channel to wait for termination notifications
 	stop            chan struct{}
 	lock            sync.RWMutex
@@ -105,7 +114,7 @@ func (node *Node) Prepare() (err error) {
 	}
 	node.walletManager = wallet.New(node.walletConfig)
 
-	if node.viteServer != nil {
+	if node.viteServer != nil || node.edge != nil {
 		return ErrNodeRunning
 	}
 
@@ -116,6 +125,10 @@ func (node *Node) Prepare() (err error) {
 		return err
 	}
 
+	if node.isEdge() {
+		return node.prepareEdge()
+	}
+
 	//Initialize the vite server
 	node.viteServer, err = vite.New(node.viteConfig, node.walletManager)
 	if err != nil {
@@ -126,6 +139,7 @@ func (node *Node) Prepare() (err error) {
 	//init rpc_PowServerUrl
 	remote.InitRawUrl(node.Config().PowServerUrl)
 	pow.Init(node.Config().VMTestParamEnabled)
+	pow.InitLocal(node.Config().LocalPowEnabled, node.Config().LocalPowMaxJobs)
 
 	// Start vite
 	if err = node.viteServer.Init(); err != nil {
@@ -152,6 +166,12 @@ func (node *Node) Start() error {
 		log.Error(fmt.Sprintf("Node startRPC error: %v", err))
 		return err
 	}
+
+	//metrics start
+	if err := node.startMetrics(); err != nil {
+		log.Error(fmt.Sprintf("Node startMetrics error: %v", err))
+		return err
+	}
 	monitor.InitNTPChecker(log)
 
 	return nil
@@ -173,6 +193,10 @@ func (node *Node) Stop() error {
 		}
 	}()
 
+	//metrics
+	log.Info(fmt.Sprintf("Begin Stop Metrics... "))
+	node.stopMetrics()
+
 	//wallet
 	log.Info(fmt.Sprintf("Begin Stop Wallet... "))
 	if err := node.stopWallet(); err != nil {
@@ -270,11 +294,40 @@ func (node *Node) startWallet() (err error) {
 	return nil
 }
 
+func (node *Node) isEdge() bool {
+	return node.viteConfig.Net.NodeMode == config.NodeModeEdge
+}
+
+// prepareEdge initializes an edge node, which syncs only snapshot blocks instead of the whole ledger
+func (node *Node) prepareEdge() (err error) {
+	// set upgrade
+	upgrade.InitUpgradeBox(node.viteConfig.UpgradeCfg.MakeUpgradeBox())
+
+	genesisCfg := node.viteConfig.Genesis
+	chain_genesis.UpdateDexFundOwner(genesisCfg)
+	genesis := chain_genesis.NewGenesisSnapshotBlock(chain_genesis.NewGenesisAccountBlocks(genesisCfg))
+
+	node.edge, err = vnet.NewEdge(node.viteConfig.Net, genesis)
+	if err != nil {
+		log.Error(fmt.Sprintf("Edge new error: %v", err))
+		return err
+	}
+
+	return nil
+}
+
 func (node *Node) startVite() error {
+	if node.edge != nil {
+		return node.edge.Start()
+	}
 	return node.viteServer.Start()
 }
 
 func (node *Node) startRPC() (e error) {
+	if node.edge != nil {
+		return node.startEdgeRPC()
+	}
+
 	// start event system
 	if node.config.SubscribeEnabled {
 		filters.Es = filters.NewEventSystem(node.Vite())
@@ -316,7 +369,7 @@ func (node *Node) startRPC() (e error) {
 	}
 
 	if node.config.RPCEnabled {
-		if err := node.startHTTP(node.httpEndpoint, node.privateHttpEndpoint, apis, nil, node.config.HTTPCors, node.config.HttpVirtualHosts, rpc.HTTPTimeouts{}, node.config.HttpExposeAll); err != nil {
+		if err := node.startHTTP(node.httpEndpoint, node.privateHttpEndpoint, apis, nil, node.config.HTTPCors, node.config.HttpVirtualHosts, rpc.HTTPTimeouts{}, node.config.HttpExposeAll, node.config.RPCAccess); err != nil {
 			return err
 		}
 		defer func() {
@@ -327,7 +380,7 @@ func (node *Node) startRPC() (e error) {
 	}
 
 	if node.config.WSEnabled {
-		if err := node.startWS(node.wsEndpoint, apis, nil, node.config.WSOrigins, node.config.WSExposeAll); err != nil {
+		if err := node.startWS(node.wsEndpoint, apis, nil, node.config.WSOrigins, node.config.WSExposeAll, node.config.RPCAccess); err != nil {
 			return err
 		}
 		defer func() {
@@ -366,6 +419,51 @@ func (node *Node) startRPC() (e error) {
 	return nil
 }
 
+func (node *Node) startEdgeRPC() (e error) {
+	rpcapi.Init(node.config.DataDir, node.config.LogLevel, node.config.TestTokenHexPrivKey, node.config.TestTokenTti, uint(node.config.NetID), node.config.TxDexEnable)
+
+	apis := rpcapi.GetEdgeApis(node.edge)
+
+	if err := node.startInProcess(apis); err != nil {
+		return err
+	}
+	defer func() {
+		if e != nil {
+			node.stopInProcess()
+		}
+	}()
+
+	if node.config.IPCEnabled {
+		if err := node.startIPC(apis); err != nil {
+			return err
+		}
+		defer func() {
+			if e != nil {
+				node.stopIPC()
+			}
+		}()
+	}
+
+	if node.config.RPCEnabled {
+		if err := node.startHTTP(node.httpEndpoint, node.privateHttpEndpoint, apis, nil, node.config.HTTPCors, node.config.HttpVirtualHosts, rpc.HTTPTimeouts{}, node.config.HttpExposeAll, node.config.RPCAccess); err != nil {
+			return err
+		}
+		defer func() {
+			if e != nil {
+				node.stopHTTP()
+			}
+		}()
+	}
+
+	if node.config.WSEnabled {
+		if err := node.startWS(node.wsEndpoint, apis, nil, node.config.WSOrigins, node.config.WSExposeAll, node.config.RPCAccess); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (node *Node) stopWallet() error {
 
 	if node.walletManager == nil {
@@ -377,6 +475,9 @@ func (node *Node) stopWallet() error {
 }
 
 func (node *Node) stopVite() error {
+	if node.edge != nil {
+		return node.edge.Stop()
+	}
 
 	if node.viteServer == nil {
 		return ErrNodeStopped
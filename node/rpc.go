@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/vitelabs/go-vite/v2/common/config"
 	"github.com/vitelabs/go-vite/v2/rpc"
 )
 
@@ -37,12 +38,12 @@ func (node *Node) stopIPC() {
 }
 
 // startHTTP initializes and starts the HTTP RPC endpoint.
-func (node *Node) startHTTP(endpoint string, privateEndpoint string, apis []rpc.API, modules []string, cors []string, vhosts []string, timeouts rpc.HTTPTimeouts, exposeAll bool) error {
+func (node *Node) startHTTP(endpoint string, privateEndpoint string, apis []rpc.API, modules []string, cors []string, vhosts []string, timeouts rpc.HTTPTimeouts, exposeAll bool, access *config.RPCAccess) error {
 	// Short circuit if the HTTP endpoint isn't being exposed
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, privateListener, privateHandler, err := rpc.StartHTTPEndpoint(endpoint, privateEndpoint, apis, modules, cors, vhosts, timeouts, exposeAll)
+	listener, handler, privateListener, privateHandler, err := rpc.StartHTTPEndpoint(endpoint, privateEndpoint, apis, modules, cors, vhosts, timeouts, exposeAll, access)
 	if err != nil {
 		return err
 	}
@@ -81,12 +82,12 @@ func (node *Node) stopHTTP() {
 }
 
 // startWS initializes and starts the websocket RPC endpoint.
-func (node *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrigins []string, exposeAll bool) error {
+func (node *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrigins []string, exposeAll bool, access *config.RPCAccess) error {
 	// Short circuit if the WS endpoint isn't being exposed
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll)
+	listener, handler, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll, access)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,7 @@
 package pow
 
 import (
+	"context"
 	"encoding/binary"
 	"errors"
 	"hash"
@@ -27,40 +28,53 @@ func Init(vMTestParamEnabled bool) {
 	VMTestParamEnabled = vMTestParamEnabled
 }
 
+const getPowNonceTimeout = 10 * time.Minute
+
+var (
+	ErrLocalPowDisabled = errors.New("local pow is disabled")
+	ErrLocalPowBusy     = errors.New("too many local pow jobs")
+)
+
+var (
+	localPowEnabled = false
+	localPowJobs    = make(chan struct{}, 1)
+)
+
+// InitLocal enables GetLocalPowNonce, at most maxJobs of them run at the same time, 1 if maxJobs is not positive.
+func InitLocal(enabled bool, maxJobs int) {
+	if maxJobs <= 0 {
+		maxJobs = 1
+	}
+	localPowEnabled = enabled
+	localPowJobs = make(chan struct{}, maxJobs)
+}
+
+// GetLocalPowNonce is GetPowNonce for the requests of rpc clients, it fails if the local pow is disabled
+// or the max jobs are running.
+func GetLocalPowNonce(difficulty *big.Int, dataHash types.Hash) ([]byte, error) {
+	if !localPowEnabled {
+		return nil, ErrLocalPowDisabled
+	}
+	select {
+	case localPowJobs <- struct{}{}:
+	default:
+		return nil, ErrLocalPowBusy
+	}
+	defer func() { <-localPowJobs }()
+	return GetPowNonce(difficulty, dataHash)
+}
+
 // data = Hash(address + prev_hash); data + nonce < target.
+// GetPowNonce calculates by DefaultSolver, it gives up after 10 minutes.
 func GetPowNonce(difficulty *big.Int, dataHash types.Hash) ([]byte, error) {
-	var target *big.Int = nil
-	if VMTestParamEnabled {
-		target = defaultTarget
-	} else {
-		if difficulty == nil {
-			return nil, errors.New("difficulty can't be nil")
-		}
-		target = DifficultyToTarget(difficulty)
-		if target == nil || target.BitLen() > 256 {
-			return nil, errors.New("target too long")
-		}
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), getPowNonceTimeout)
+	defer cancel()
 
-	data := dataHash.Bytes()
-	target256 := helper.LeftPadBytes(target.Bytes(), 32)
-	i := 0
-	t := time.Now()
-	for {
-		nonce := crypto.GetEntropyCSPRNG(8)
-		out := powHash256(nonce, data)
-		if QuickGreater(out, target256) {
-			return nonce, nil
-		}
-		i++
-		if i > 10000 {
-			if time.Now().Sub(t).Minutes() > 10 {
-				break
-			}
-			i = 0
-		}
+	nonce, err := DefaultSolver.Solve(ctx, difficulty, dataHash)
+	if err == context.DeadlineExceeded {
+		return nil, errors.New("get pow nonce error")
 	}
-	return nil, errors.New("get pow nonce error")
+	return nonce, err
 }
 
 // data = Hash(address + prev_hash); data + nonce < target.
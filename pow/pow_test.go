@@ -2,6 +2,7 @@ package pow_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
@@ -40,6 +41,69 @@ func TestGetPowNonce(t *testing.T) {
 	fmt.Println("average", average/1e6, "ms max", max/1e6, "ms min", min/1e6, "sum", timeSum/1e6, "standard deviation", std)
 }
 
+func TestSolver(t *testing.T) {
+	solver := pow.NewSolver(4)
+	assert.Equal(t, 4, solver.Workers())
+
+	bd := big.NewInt(100000)
+	data := types.DataHash([]byte{2})
+	nonce, err := solver.Solve(context.Background(), bd, data)
+	assert.NoError(t, err)
+	assert.True(t, pow.CheckPowNonce(bd, nonce, data.Bytes()))
+	assert.True(t, solver.HashRate() > 0)
+	assert.True(t, solver.EstimateTime(bd) > 0)
+	assert.Equal(t, 0, len(solver.Jobs()))
+
+	// cancel
+	hard := big.NewInt(1e15)
+	done := make(chan error, 1)
+	go func() {
+		_, err := solver.Solve(context.Background(), hard, data)
+		done <- err
+	}()
+	for len(solver.Jobs()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, data, solver.Jobs()[0].DataHash)
+	assert.True(t, solver.Cancel(data))
+	assert.Equal(t, pow.ErrPowCanceled, <-done)
+	assert.False(t, solver.Cancel(data))
+
+	// timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = solver.Solve(ctx, hard, data)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestGetLocalPowNonce(t *testing.T) {
+	defer pow.InitLocal(false, 0)
+	dataHash := types.DataHash([]byte{1})
+
+	pow.InitLocal(false, 1)
+	_, err := pow.GetLocalPowNonce(big.NewInt(1), dataHash)
+	assert.Equal(t, pow.ErrLocalPowDisabled, err)
+
+	pow.InitLocal(true, 1)
+	done := make(chan error, 1)
+	go func() {
+		_, err := pow.GetLocalPowNonce(new(big.Int).Lsh(big.NewInt(1), 60), dataHash)
+		done <- err
+	}()
+	for len(pow.DefaultSolver.Jobs()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	_, err = pow.GetLocalPowNonce(big.NewInt(1), types.DataHash([]byte{2}))
+	assert.Equal(t, pow.ErrLocalPowBusy, err)
+
+	assert.True(t, pow.DefaultSolver.Cancel(dataHash))
+	assert.Equal(t, pow.ErrPowCanceled, <-done)
+
+	nonce, err := pow.GetLocalPowNonce(big.NewInt(1), dataHash)
+	assert.NoError(t, err)
+	assert.True(t, pow.CheckPowNonce(big.NewInt(1), nonce, dataHash.Bytes()))
+}
+
 func TestCheckPowNonce(t *testing.T) {
 
 	bbb, _ := new(big.Int).SetString("96dcde7641923e2a", 16)
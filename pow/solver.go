@@ -0,0 +1,240 @@
+package pow
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/big"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/vitelabs/go-vite/v2/common/helper"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/crypto"
+)
+
+// hashes count of a worker between checking cancellation
+const checkInterval = 4096
+
+var ErrPowCanceled = errors.New("pow canceled")
+
+// DefaultSolver uses all cores, it is used by GetPowNonce
+var DefaultSolver = NewSolver(0)
+
+// Solver calculates pow nonces locally, the nonce space is sharded across workers.
+type Solver struct {
+	workers int
+
+	mu   sync.Mutex
+	jobs map[types.Hash]*solverJob
+
+	// hashes and nanoseconds of the finished jobs
+	hashes  uint64
+	elapsed uint64
+}
+
+type solverJob struct {
+	difficulty *big.Int
+	start      time.Time
+	hashes     uint64
+	cancel     context.CancelFunc
+}
+
+// SolverJob is the progress of a running job.
+type SolverJob struct {
+	DataHash   types.Hash
+	Difficulty *big.Int
+	Hashes     uint64
+	Elapsed    time.Duration
+}
+
+// NewSolver returns a solver with workers goroutines, runtime.NumCPU() if workers is not positive.
+func NewSolver(workers int) *Solver {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return &Solver{
+		workers: workers,
+		jobs:    make(map[types.Hash]*solverJob),
+	}
+}
+
+func (s *Solver) Workers() int {
+	return s.workers
+}
+
+// Solve returns a nonce meets the difficulty, it returns the error of ctx if ctx is done,
+// or ErrPowCanceled if the job is canceled by Cancel.
+func (s *Solver) Solve(ctx context.Context, difficulty *big.Int, dataHash types.Hash) ([]byte, error) {
+	target, err := getTarget256(difficulty)
+	if err != nil {
+		return nil, err
+	}
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	job := &solverJob{
+		difficulty: difficulty,
+		start:      time.Now(),
+		cancel:     cancel,
+	}
+	s.addJob(dataHash, job)
+	defer s.removeJob(dataHash, job)
+
+	data := dataHash.Bytes()
+	span := math.MaxUint64 / uint64(s.workers)
+	base := binary.BigEndian.Uint64(crypto.GetEntropyCSPRNG(8))
+	result := make(chan []byte, s.workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func(from uint64) {
+			defer wg.Done()
+			if nonce := s.work(jobCtx, job, data, target, from, span); nonce != nil {
+				result <- nonce
+				cancel()
+			}
+		}(base + uint64(i)*span)
+	}
+	wg.Wait()
+
+	select {
+	case nonce := <-result:
+		return nonce, nil
+	default:
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return nil, ErrPowCanceled
+}
+
+func (s *Solver) work(ctx context.Context, job *solverJob, data, target []byte, from, span uint64) []byte {
+	nonce := make([]byte, 8)
+	hash, _ := blake2b.New256(nil)
+	var count uint64
+	for i := uint64(0); i < span; i++ {
+		binary.BigEndian.PutUint64(nonce, from+i)
+		out := powHash256FromHash(hash, nonce, data)
+		if QuickGreater(out, target) {
+			atomic.AddUint64(&job.hashes, count+1)
+			return nonce
+		}
+		hash.Reset()
+
+		count++
+		if count == checkInterval {
+			atomic.AddUint64(&job.hashes, count)
+			count = 0
+			if ctx.Err() != nil {
+				return nil
+			}
+		}
+	}
+	atomic.AddUint64(&job.hashes, count)
+	return nil
+}
+
+func (s *Solver) addJob(dataHash types.Hash, job *solverJob) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[dataHash] = job
+}
+
+func (s *Solver) removeJob(dataHash types.Hash, job *solverJob) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.jobs[dataHash] == job {
+		delete(s.jobs, dataHash)
+	}
+	s.hashes += atomic.LoadUint64(&job.hashes)
+	s.elapsed += uint64(time.Since(job.start))
+}
+
+// Cancel stops the running job of dataHash, returns false if there is no such job.
+func (s *Solver) Cancel(dataHash types.Hash) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[dataHash]
+	if ok {
+		job.cancel()
+	}
+	return ok
+}
+
+// Jobs returns the progress of the running jobs.
+func (s *Solver) Jobs() []SolverJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]SolverJob, 0, len(s.jobs))
+	for dataHash, job := range s.jobs {
+		jobs = append(jobs, SolverJob{
+			DataHash:   dataHash,
+			Difficulty: job.difficulty,
+			Hashes:     atomic.LoadUint64(&job.hashes),
+			Elapsed:    time.Since(job.start),
+		})
+	}
+	return jobs
+}
+
+// HashRate returns the hashes per second of a job, measured by the finished and running jobs,
+// it is 0 before any job ran.
+func (s *Solver) HashRate() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hashes, elapsed := s.hashes, s.elapsed
+	for _, job := range s.jobs {
+		hashes += atomic.LoadUint64(&job.hashes)
+		elapsed += uint64(time.Since(job.start))
+	}
+	if elapsed == 0 {
+		return 0
+	}
+	return float64(hashes) / time.Duration(elapsed).Seconds()
+}
+
+// EstimateTime returns the expected time to solve the difficulty at the current hash rate,
+// it is 0 if the hash rate is unknown.
+func (s *Solver) EstimateTime(difficulty *big.Int) time.Duration {
+	rate := s.HashRate()
+	if rate <= 0 {
+		return 0
+	}
+	seconds, _ := new(big.Float).Quo(new(big.Float).SetInt(ExpectedHashes(difficulty)), big.NewFloat(rate)).Float64()
+	if seconds > float64(math.MaxInt64)/float64(time.Second) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// ExpectedHashes returns the expected count of hashes to solve the difficulty, it is difficulty + 1.
+func ExpectedHashes(difficulty *big.Int) *big.Int {
+	if VMTestParamEnabled || difficulty == nil {
+		return big.NewInt(1)
+	}
+	return new(big.Int).Add(difficulty, big.NewInt(1))
+}
+
+func getTarget256(difficulty *big.Int) ([]byte, error) {
+	var target *big.Int = nil
+	if VMTestParamEnabled {
+		target = defaultTarget
+	} else {
+		if difficulty == nil {
+			return nil, errors.New("difficulty can't be nil")
+		}
+		target = DifficultyToTarget(difficulty)
+		if target == nil || target.BitLen() > 256 {
+			return nil, errors.New("target too long")
+		}
+	}
+	return helper.LeftPadBytes(target.Bytes(), 32), nil
+}
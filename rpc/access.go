@@ -0,0 +1,310 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+)
+
+// the idle buckets are removed from the limiter at this interval
+const bucketSweepInterval = time.Minute
+
+var errRequestTooLarge = errors.New("request too large")
+
+type apiKeyCtxKey struct{}
+
+type forwardedCtxKey struct{}
+
+// forwardedHeaders are the client addresses set by the reverse proxies
+type forwardedHeaders struct {
+	forwardedFor string
+	realIP       string
+}
+
+// apiKeyFromRequest returns the key of the Authorization bearer token, the X-API-Key header
+// or the apikey query parameter
+func apiKeyFromRequest(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+			return strings.TrimSpace(auth[7:])
+		}
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return r.URL.Query().Get("apikey")
+}
+
+// withRequestInfo puts the remote address, the forwarded headers and the api key of a http request into the context
+func withRequestInfo(ctx context.Context, r *http.Request) context.Context {
+	ctx = context.WithValue(ctx, "remote", r.RemoteAddr)
+	ctx = context.WithValue(ctx, forwardedCtxKey{}, forwardedHeaders{
+		forwardedFor: r.Header.Get("X-Forwarded-For"),
+		realIP:       r.Header.Get("X-Real-IP"),
+	})
+	return context.WithValue(ctx, apiKeyCtxKey{}, apiKeyFromRequest(r))
+}
+
+// SetAccess authenticates the requests by the api keys and limits their rates, it must be called
+// before the server serves any request. A nil access removes all the checks.
+func (s *Server) SetAccess(access *config.RPCAccess) error {
+	if access == nil {
+		s.access = nil
+		return nil
+	}
+	ac, err := newAccessControl(access)
+	if err != nil {
+		return err
+	}
+	s.access = ac
+	return nil
+}
+
+func (s *Server) maxRequestSize() int64 {
+	if s.access != nil && s.access.cfg.MaxRequestSize > 0 {
+		return s.access.cfg.MaxRequestSize
+	}
+	return maxRequestContentLength
+}
+
+type apiKey struct {
+	*config.APIKey
+	allowed map[string]bool
+}
+
+// accessControl checks the requests against the config.RPCAccess
+type accessControl struct {
+	cfg     *config.RPCAccess
+	keys    map[string]*apiKey
+	proxies []*net.IPNet
+	limiter *rateLimiter
+}
+
+func newAccessControl(cfg *config.RPCAccess) (*accessControl, error) {
+	ac := &accessControl{
+		cfg:     cfg,
+		keys:    make(map[string]*apiKey, len(cfg.Keys)),
+		limiter: newRateLimiter(),
+	}
+	for _, key := range cfg.Keys {
+		if key.Key == "" {
+			return nil, fmt.Errorf("the api key %q is empty", key.Name)
+		}
+		if _, ok := ac.keys[key.Key]; ok {
+			return nil, fmt.Errorf("duplicated api key %q", key.Name)
+		}
+		allowed := make(map[string]bool, len(key.Allowed))
+		for _, name := range key.Allowed {
+			allowed[name] = true
+		}
+		ac.keys[key.Key] = &apiKey{APIKey: key, allowed: allowed}
+	}
+	for _, proxy := range cfg.TrustedProxies {
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			ipNet = &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)}
+		}
+		ac.proxies = append(ac.proxies, ipNet)
+	}
+	return ac, nil
+}
+
+func (ac *accessControl) trusted(ip net.IP) bool {
+	for _, proxy := range ac.proxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the remote ip of the request. If the remote is a trusted proxy, it is the last address
+// in X-Forwarded-For which is not a trusted proxy, or X-Real-IP if there is no X-Forwarded-For.
+func (ac *accessControl) clientIP(ctx context.Context) string {
+	remote, _ := ctx.Value("remote").(string)
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	if ip := net.ParseIP(remote); ip == nil || !ac.trusted(ip) {
+		return remote
+	}
+
+	headers, _ := ctx.Value(forwardedCtxKey{}).(forwardedHeaders)
+	if headers.forwardedFor != "" {
+		// every proxy appends the address it receives the request from, the ones before the last
+		// untrusted address may be forged by the client
+		client := remote
+		addrs := strings.Split(headers.forwardedFor, ",")
+		for i := len(addrs) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(addrs[i]))
+			if ip == nil {
+				break
+			}
+			client = ip.String()
+			if !ac.trusted(ip) {
+				break
+			}
+		}
+		return client
+	}
+	if ip := net.ParseIP(strings.TrimSpace(headers.realIP)); ip != nil {
+		return ip.String()
+	}
+	return remote
+}
+
+func (ac *accessControl) checkBatch(size int) Error {
+	if ac.cfg.MaxBatchSize > 0 && size > ac.cfg.MaxBatchSize {
+		return &batchTooLargeError{size: size, max: ac.cfg.MaxBatchSize}
+	}
+	return nil
+}
+
+// check returns the error if the request is not allowed by its key or exceeds the rate limits
+func (ac *accessControl) check(ctx context.Context, req *serverRequest, now time.Time) Error {
+	keyStr, _ := ctx.Value(apiKeyCtxKey{}).(string)
+
+	var client string
+	var limit *config.RateLimit
+	if keyStr != "" {
+		key, ok := ac.keys[keyStr]
+		if !ok {
+			return &unauthorizedError{"invalid api key"}
+		}
+		if len(key.allowed) > 0 && !key.allowed[req.svcname] && !key.allowed[req.name] {
+			return &methodNotAllowedError{req.name}
+		}
+		client, limit = "key:"+keyStr, key.Limit
+	} else {
+		if ac.cfg.RequireKey {
+			return &unauthorizedError{"missing api key"}
+		}
+		client, limit = "ip:"+ac.clientIP(ctx), ac.cfg.AnonymousLimit
+	}
+
+	// the tokens are taken only if both the client and the method allow the request
+	switch ac.limiter.allow(now, bucketLimit{client, limit}, bucketLimit{client + "/" + req.name, ac.cfg.MethodLimits[req.name]}) {
+	case 0:
+		return &rateLimitError{"rate limit exceeded"}
+	case 1:
+		return &rateLimitError{fmt.Sprintf("rate limit of %s exceeded", req.name)}
+	}
+	return nil
+}
+
+// tokenBucket gets limit.Rate tokens per second up to limit.Burst, a request takes a token
+type tokenBucket struct {
+	limit  config.RateLimit
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.limit.Rate
+		if burst := float64(b.limit.Burst); b.tokens > burst {
+			b.tokens = burst
+		}
+	}
+	b.last = now
+}
+
+func (b *tokenBucket) full() bool {
+	return b.tokens >= float64(b.limit.Burst)
+}
+
+// rateLimiter keeps a token bucket for each client or client and method
+type rateLimiter struct {
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// bucketLimit is the limit of the bucket of id, a nil limit is not limited
+type bucketLimit struct {
+	id    string
+	limit *config.RateLimit
+}
+
+// allow takes a token from each of the buckets if all of them have one, it returns the index of the
+// first bucket without a token, or -1 if the request is allowed
+func (rl *rateLimiter) allow(now time.Time, limits ...bucketLimit) int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if now.Sub(rl.lastSweep) > bucketSweepInterval {
+		rl.sweep(now)
+	}
+
+	buckets := make([]*tokenBucket, 0, len(limits))
+	for i, l := range limits {
+		if l.limit == nil {
+			continue
+		}
+		b, ok := rl.buckets[l.id]
+		if !ok {
+			b = &tokenBucket{limit: *l.limit, tokens: float64(l.limit.Burst), last: now}
+			rl.buckets[l.id] = b
+		}
+		b.refill(now)
+		if b.tokens < 1 {
+			return i
+		}
+		buckets = append(buckets, b)
+	}
+	for _, b := range buckets {
+		b.tokens--
+	}
+	return -1
+}
+
+// sweep removes the full buckets, they are the same as new ones
+func (rl *rateLimiter) sweep(now time.Time) {
+	for id, b := range rl.buckets {
+		b.refill(now)
+		if b.full() {
+			delete(rl.buckets, id)
+		}
+	}
+	rl.lastSweep = now
+}
+
+// sizeLimitedReader fails with errRequestTooLarge once more than n bytes are read
+type sizeLimitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *sizeLimitedReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, errRequestTooLarge
+	}
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n, errRequestTooLarge
+	}
+	return n, err
+}
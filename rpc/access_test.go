@@ -0,0 +1,188 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+)
+
+type accessTestResponse struct {
+	Id    int        `json:"id"`
+	Error *jsonError `json:"error"`
+}
+
+func newAccessTestServer(t *testing.T, access *config.RPCAccess) *Server {
+	server := NewServer()
+	if err := server.RegisterName("test", new(Service)); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.SetAccess(access); err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+// postAccessTest returns the error codes of the responses, 0 for a success
+func postAccessTest(t *testing.T, server *Server, key, body string) []int {
+	return postAccessTestFrom(t, server, "10.0.0.1:1234", nil, key, body)
+}
+
+// postAccessTestFrom posts the request from remote with the headers
+func postAccessTestFrom(t *testing.T, server *Server, remote string, header map[string]string, key, body string) []int {
+	request := httptest.NewRequest(http.MethodPost, "http://url.com", strings.NewReader(body))
+	request.RemoteAddr = remote
+	for name, value := range header {
+		request.Header.Set(name, value)
+	}
+	request.Header.Set("content-type", contentType)
+	if key != "" {
+		request.Header.Set("Authorization", "Bearer "+key)
+	}
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+
+	var responses []accessTestResponse
+	data := recorder.Body.Bytes()
+	if strings.HasPrefix(string(data), "[") {
+		if err := json.Unmarshal(data, &responses); err != nil {
+			t.Fatalf("unexpected response %q", data)
+		}
+	} else {
+		var response accessTestResponse
+		if err := json.Unmarshal(data, &response); err != nil {
+			t.Fatalf("unexpected response %q", data)
+		}
+		responses = append(responses, response)
+	}
+	codes := make([]int, len(responses))
+	for i, response := range responses {
+		if response.Error != nil {
+			codes[i] = response.Error.Code
+		}
+	}
+	return codes
+}
+
+func expectCodes(t *testing.T, codes []int, expected ...int) {
+	t.Helper()
+	if len(codes) != len(expected) {
+		t.Fatalf("expected codes %v, got %v", expected, codes)
+	}
+	for i := range codes {
+		if codes[i] != expected[i] {
+			t.Fatalf("expected codes %v, got %v", expected, codes)
+		}
+	}
+}
+
+const (
+	echoRequest = `{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["a",1,{"S":"b"}]}`
+	retsRequest = `{"jsonrpc":"2.0","id":1,"method":"test_rets","params":[]}`
+)
+
+func TestServerAccessKeys(t *testing.T) {
+	server := newAccessTestServer(t, &config.RPCAccess{
+		Keys: []*config.APIKey{
+			{Key: "all", Name: "all"},
+			{Key: "echo", Name: "echo", Allowed: []string{"test_echo"}},
+			{Key: "ns", Name: "ns", Allowed: []string{"test"}},
+		},
+		RequireKey: true,
+	})
+
+	expectCodes(t, postAccessTest(t, server, "", echoRequest), -32003)
+	expectCodes(t, postAccessTest(t, server, "unknown", echoRequest), -32003)
+	expectCodes(t, postAccessTest(t, server, "all", echoRequest), 0)
+	expectCodes(t, postAccessTest(t, server, "echo", echoRequest), 0)
+	expectCodes(t, postAccessTest(t, server, "echo", retsRequest), -32004)
+	expectCodes(t, postAccessTest(t, server, "ns", retsRequest), 0)
+	expectCodes(t, postAccessTest(t, server, "echo", "["+echoRequest+","+retsRequest+"]"), 0, -32004)
+}
+
+func TestServerAccessRateLimits(t *testing.T) {
+	server := newAccessTestServer(t, &config.RPCAccess{
+		Keys: []*config.APIKey{
+			{Key: "key", Name: "key", Limit: &config.RateLimit{Rate: 0.001, Burst: 3}},
+		},
+		AnonymousLimit: &config.RateLimit{Rate: 0.001, Burst: 1},
+		MethodLimits: map[string]*config.RateLimit{
+			"test_rets": {Rate: 0.001, Burst: 1},
+		},
+		MaxBatchSize: 2,
+	})
+
+	// the anonymous requests are limited by the remote ip
+	expectCodes(t, postAccessTest(t, server, "", echoRequest), 0)
+	expectCodes(t, postAccessTest(t, server, "", echoRequest), -32005)
+
+	expectCodes(t, postAccessTest(t, server, "key", retsRequest), 0)
+	expectCodes(t, postAccessTest(t, server, "key", retsRequest), -32005)
+	expectCodes(t, postAccessTest(t, server, "key", "["+echoRequest+","+echoRequest+"]"), 0, 0)
+	expectCodes(t, postAccessTest(t, server, "key", echoRequest), -32005)
+	expectCodes(t, postAccessTest(t, server, "key", "["+echoRequest+","+echoRequest+","+echoRequest+"]"), -32007)
+}
+
+func TestServerAccessMethodLimits(t *testing.T) {
+	server := newAccessTestServer(t, &config.RPCAccess{
+		AnonymousLimit: &config.RateLimit{Rate: 0.001, Burst: 2},
+		MethodLimits: map[string]*config.RateLimit{
+			"test_rets": {Rate: 0.001, Burst: 1},
+		},
+	})
+
+	// the request rejected by the method limit doesn't take the token of the client
+	expectCodes(t, postAccessTest(t, server, "", retsRequest), 0)
+	expectCodes(t, postAccessTest(t, server, "", retsRequest), -32005)
+	expectCodes(t, postAccessTest(t, server, "", echoRequest), 0)
+	expectCodes(t, postAccessTest(t, server, "", echoRequest), -32005)
+}
+
+func TestServerAccessTrustedProxies(t *testing.T) {
+	server := newAccessTestServer(t, &config.RPCAccess{
+		AnonymousLimit: &config.RateLimit{Rate: 0.001, Burst: 1},
+		TrustedProxies: []string{"10.0.0.0/8", "192.168.0.1"},
+	})
+	proxy := "10.0.0.1:1234"
+	forwarded := func(addrs string) map[string]string {
+		return map[string]string{"X-Forwarded-For": addrs}
+	}
+
+	// the anonymous requests through the proxies are limited by the forwarded client ip
+	expectCodes(t, postAccessTestFrom(t, server, proxy, forwarded("1.1.1.1"), "", echoRequest), 0)
+	expectCodes(t, postAccessTestFrom(t, server, proxy, forwarded("1.1.1.1"), "", echoRequest), -32005)
+	expectCodes(t, postAccessTestFrom(t, server, proxy, forwarded("2.2.2.2"), "", echoRequest), 0)
+	expectCodes(t, postAccessTestFrom(t, server, "192.168.0.1:1234", forwarded("9.9.9.9, 1.1.1.1, 10.0.0.2"), "", echoRequest), -32005)
+	expectCodes(t, postAccessTestFrom(t, server, proxy, map[string]string{"X-Real-IP": "3.3.3.3"}, "", echoRequest), 0)
+	expectCodes(t, postAccessTestFrom(t, server, proxy, map[string]string{"X-Real-IP": "3.3.3.3"}, "", echoRequest), -32005)
+
+	// the headers from the other remotes are ignored
+	expectCodes(t, postAccessTestFrom(t, server, "192.168.0.2:1234", forwarded("4.4.4.4"), "", echoRequest), 0)
+	expectCodes(t, postAccessTestFrom(t, server, "192.168.0.2:1234", forwarded("5.5.5.5"), "", echoRequest), -32005)
+
+	if err := server.SetAccess(&config.RPCAccess{TrustedProxies: []string{"proxy"}}); err == nil {
+		t.Fatal("expected an error of the invalid proxy")
+	}
+}
+
+func TestServerAccessRequestSize(t *testing.T) {
+	server := newAccessTestServer(t, &config.RPCAccess{MaxRequestSize: 100})
+
+	expectCodes(t, postAccessTest(t, server, "", echoRequest), 0)
+	large := `{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["` + strings.Repeat("a", 100) + `",1,{"S":"b"}]}`
+	expectCodes(t, postAccessTest(t, server, "", large), -32006)
+
+	// the size of a chunked body is unknown before reading it
+	request := httptest.NewRequest(http.MethodPost, "http://url.com", strings.NewReader(large))
+	request.ContentLength = -1
+	request.Header.Set("content-type", contentType)
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	var response accessTestResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil || response.Error == nil || response.Error.Code != -32006 {
+		t.Fatalf("unexpected response %q", recorder.Body.String())
+	}
+}
@@ -23,11 +23,13 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/vitelabs/go-vite/v2/common/config"
 	log "github.com/vitelabs/go-vite/v2/log15"
 )
 
-// StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules
-func StartHTTPEndpoint(endpoint string, privateEndpoint string, apis []API, modules []string, cors []string, vhosts []string, timeouts HTTPTimeouts, exposeAll bool) (net.Listener, *Server, net.Listener, *Server, error) {
+// StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules,
+// the access is checked on the public endpoint only
+func StartHTTPEndpoint(endpoint string, privateEndpoint string, apis []API, modules []string, cors []string, vhosts []string, timeouts HTTPTimeouts, exposeAll bool, access *config.RPCAccess) (net.Listener, *Server, net.Listener, *Server, error) {
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
 	for _, module := range modules {
@@ -35,6 +37,9 @@ func StartHTTPEndpoint(endpoint string, privateEndpoint string, apis []API, modu
 	}
 	// Register all the APIs exposed by the services
 	handler := NewServer()
+	if err := handler.SetAccess(access); err != nil {
+		return nil, nil, nil, nil, err
+	}
 	privateHandler := NewServer()
 	privateBind := false
 	for _, api := range apis {
@@ -74,7 +79,7 @@ func StartHTTPEndpoint(endpoint string, privateEndpoint string, apis []API, modu
 }
 
 // StartWSEndpoint starts chain websocket endpoint
-func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []string, exposeAll bool) (net.Listener, *Server, error) {
+func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []string, exposeAll bool, access *config.RPCAccess) (net.Listener, *Server, error) {
 
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
@@ -83,6 +88,9 @@ func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []
 	}
 	// Register all the APIs exposed by the services
 	handler := NewServer()
+	if err := handler.SetAccess(access); err != nil {
+		return nil, nil, err
+	}
 	for _, api := range apis {
 		if exposeAll || whitelist[api.Namespace] || (len(whitelist) == 0 && api.Public) {
 			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
@@ -39,6 +39,45 @@ func (e *callbackError) ErrorCode() int { return -32002 }
 
 func (e *callbackError) Error() string { return e.message }
 
+// request without a valid api key
+type unauthorizedError struct{ message string }
+
+func (e *unauthorizedError) ErrorCode() int { return -32003 }
+
+func (e *unauthorizedError) Error() string { return e.message }
+
+// the method is not allowed for the api key
+type methodNotAllowedError struct{ method string }
+
+func (e *methodNotAllowedError) ErrorCode() int { return -32004 }
+
+func (e *methodNotAllowedError) Error() string {
+	return fmt.Sprintf("The method %s is not allowed for the api key", e.method)
+}
+
+// too many requests of the api key or the remote ip
+type rateLimitError struct{ message string }
+
+func (e *rateLimitError) ErrorCode() int { return -32005 }
+
+func (e *rateLimitError) Error() string { return e.message }
+
+// the request exceeds the max request size
+type requestTooLargeError struct{}
+
+func (e *requestTooLargeError) ErrorCode() int { return -32006 }
+
+func (e *requestTooLargeError) Error() string { return "request too large" }
+
+// the batch has more requests than the max batch size
+type batchTooLargeError struct{ size, max int }
+
+func (e *batchTooLargeError) ErrorCode() int { return -32007 }
+
+func (e *batchTooLargeError) Error() string {
+	return fmt.Sprintf("batch too large (%d>%d)", e.size, e.max)
+}
+
 // received message isn't a valid request
 type invalidRequestError struct {
 	message string
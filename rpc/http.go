@@ -224,6 +224,12 @@ func NewHTTPServer(cors []string, vhosts []string, timeouts HTTPTimeouts, srv *S
 
 // ServeHTTP serves JSON-RPC requests over HTTP.
 func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		if handler := srv.httpHandler(r.URL.Path); handler != nil {
+			handler.ServeHTTP(w, r)
+			return
+		}
+	}
 	// Permit dumb empty requests for remote health-checks (AWS)
 	if r.Method == http.MethodGet && r.ContentLength == 0 && r.URL.RawQuery == "" {
 		if isHealthCheckRouter(r.URL) {
@@ -240,7 +246,15 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	if code, err := validateRequest(r); err != nil {
+	maxSize := srv.maxRequestSize()
+	if code, err := validateRequest(r, maxSize); err != nil {
+		if code == http.StatusRequestEntityTooLarge {
+			w.Header().Set("content-type", contentType)
+			w.WriteHeader(code)
+			err := &requestTooLargeError{}
+			json.NewEncoder(w).Encode(&jsonErrResponse{Version: jsonrpcVersion, Error: jsonError{Code: err.ErrorCode(), Message: err.Error()}})
+			return
+		}
 		http.Error(w, err.Error(), code)
 		return
 	}
@@ -248,11 +262,11 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// untilEOF and writes the response to w and order the server to process chain
 	// single request.
 	ctx := r.Context()
-	ctx = context.WithValue(ctx, "remote", r.RemoteAddr)
+	ctx = withRequestInfo(ctx, r)
 	ctx = context.WithValue(ctx, "scheme", r.Proto)
 	ctx = context.WithValue(ctx, "local", r.Host)
 
-	body := io.LimitReader(r.Body, maxRequestContentLength)
+	body := &sizeLimitedReader{r.Body, maxSize}
 	codec := NewJSONCodec(&httpReadWriteNopCloser{body, w})
 	defer codec.Close()
 
@@ -260,6 +274,24 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	srv.ServeSingleRequest(ctx, codec, OptionMethodInvocation)
 }
 
+// RegisterHTTPHandler serves the GET requests of path with h instead of the json rpc, eg. /metrics
+func (srv *Server) RegisterHTTPHandler(path string, h http.Handler) {
+	srv.handlersMu.Lock()
+	defer srv.handlersMu.Unlock()
+
+	if srv.handlers == nil {
+		srv.handlers = make(map[string]http.Handler)
+	}
+	srv.handlers[path] = h
+}
+
+func (srv *Server) httpHandler(path string) http.Handler {
+	srv.handlersMu.RLock()
+	defer srv.handlersMu.RUnlock()
+
+	return srv.handlers[path]
+}
+
 func (srv *Server) healthRequest() *serverRequest {
 	method := "health"
 	service := "health"
@@ -310,12 +342,12 @@ func (s *Server) execHeader(ctx context.Context, codec ServerCodec, req *serverR
 
 // validateRequest returns chain non-zero response code and error message if the
 // request is invalid.
-func validateRequest(r *http.Request) (int, error) {
+func validateRequest(r *http.Request, maxSize int64) (int, error) {
 	if r.Method == http.MethodPut || r.Method == http.MethodDelete {
 		return http.StatusMethodNotAllowed, errors.New("method not allowed")
 	}
-	if r.ContentLength > maxRequestContentLength {
-		err := fmt.Errorf("content length too large (%d>%d)", r.ContentLength, maxRequestContentLength)
+	if r.ContentLength > maxSize {
+		err := fmt.Errorf("content length too large (%d>%d)", r.ContentLength, maxSize)
 		return http.StatusRequestEntityTooLarge, err
 	}
 	mt, _, err := mime.ParseMediaType(r.Header.Get("content-type"))
@@ -48,7 +48,20 @@ func TestHTTPErrorResponseWithValidRequest(t *testing.T) {
 func testHTTPErrorResponse(t *testing.T, method, contentType, body string, expected int) {
 	request := httptest.NewRequest(method, "http://url.com", strings.NewReader(body))
 	request.Header.Set("content-type", contentType)
-	if code, _ := validateRequest(request); code != expected {
+	if code, _ := validateRequest(request, maxRequestContentLength); code != expected {
 		t.Fatalf("response code should be %d not %d", expected, code)
 	}
 }
+
+func TestHTTPRegisteredHandler(t *testing.T) {
+	server := NewServer()
+	server.RegisterHTTPHandler("/metrics", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("vite_test 1\n"))
+	}))
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://url.com/metrics", nil))
+	if body := recorder.Body.String(); body != "vite_test 1\n" {
+		t.Fatalf("unexpected response %q", body)
+	}
+}
@@ -26,6 +26,8 @@ import (
 	"strings"
 	"sync"
 
+	"golang.org/x/net/websocket"
+
 	log "github.com/vitelabs/go-vite/v2/log15"
 )
 
@@ -142,6 +144,9 @@ func (c *jsonCodec) ReadRequestHeaders() ([]rpcRequest, bool, Error) {
 
 	var incomingMsg json.RawMessage
 	if err := c.decode(&incomingMsg); err != nil {
+		if err == errRequestTooLarge || err == websocket.ErrFrameTooLarge {
+			return nil, false, &requestTooLargeError{}
+		}
 		return nil, false, &invalidRequestError{err.Error(), nil}
 	}
 	if isBatch(incomingMsg) {
@@ -24,7 +24,9 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/vitelabs/go-vite/v2/metrics"
 	"github.com/vitelabs/go-vite/v2/rpcapi/api"
 
 	mapset "github.com/deckarep/golang-set"
@@ -33,6 +35,8 @@ import (
 
 const MetadataApi = "rpc"
 
+var requestTimer = metrics.NewRegisteredHistogramVec("vite_rpc_request_seconds", "Latency of rpc method calls.", "method", nil)
+
 // CodecOption specifies which type of messages this codec supports
 type CodecOption int
 
@@ -215,6 +219,15 @@ func (s *Server) serveRequest(ctx context.Context, codec ServerCodec, singleShot
 			}
 			return nil
 		}
+		if s.access != nil {
+			if err := s.checkAccess(ctx, reqs); err != nil {
+				codec.Write(codec.CreateErrorResponse(nil, err))
+				if singleShot {
+					return nil
+				}
+				continue
+			}
+		}
 		// If chain single shot request is executing, run and return immediately
 		if singleShot {
 			if batch {
@@ -239,12 +252,32 @@ func (s *Server) serveRequest(ctx context.Context, codec ServerCodec, singleShot
 	return nil
 }
 
+// checkAccess returns an error if the batch is too large, the requests rejected by the api keys
+// or the rate limits get their errors.
+func (s *Server) checkAccess(ctx context.Context, reqs []*serverRequest) Error {
+	if err := s.access.checkBatch(len(reqs)); err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, req := range reqs {
+		if req.err != nil || req.isUnsubscribe {
+			continue
+		}
+		req.err = s.access.check(ctx, req, now)
+	}
+	return nil
+}
+
 // ServeCodec reads incoming requests from codec, calls the appropriate callback and writes the
 // response back using the given codec. It will block until the codec is closed or the server is
 // stopped. In either case the codec is closed.
 func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) error {
+	return s.serveCodec(context.Background(), codec, options)
+}
+
+func (s *Server) serveCodec(ctx context.Context, codec ServerCodec, options CodecOption) error {
 	defer codec.Close()
-	return s.serveRequest(context.Background(), codec, false, options)
+	return s.serveRequest(ctx, codec, false, options)
 }
 
 // ServeSingleRequest reads and processes chain single RPC request from the given codec. It will not
@@ -336,6 +369,7 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 		arguments = append(arguments, req.args...)
 	}
 
+	defer requestTimer.WithLabelValue(req.svcname + serviceMethodSeparator + formatName(req.callb.method.Name)).UpdateSince(time.Now())
 	defer func() {
 		if err := recover(); err != nil {
 			log.Error(fmt.Sprintf("%v\n", err))
@@ -451,7 +485,7 @@ func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error)
 
 		if r.isPubSub { // eth_subscribe, r.method contains the subscription method name
 			if callb, ok := svc.subscriptions[r.method]; ok {
-				requests[i] = &serverRequest{id: r.id, svcname: svc.name, callb: callb}
+				requests[i] = &serverRequest{id: r.id, svcname: svc.name, name: r.service + subscribeMethodSuffix, callb: callb}
 				if r.params != nil && len(callb.argTypes) > 0 {
 					argTypes := []reflect.Type{reflect.TypeOf("")}
 					argTypes = append(argTypes, callb.argTypes...)
@@ -468,7 +502,7 @@ func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error)
 		}
 
 		if callb, ok := svc.callbacks[r.method]; ok { // lookup RPC method
-			requests[i] = &serverRequest{id: r.id, svcname: svc.name, callb: callb}
+			requests[i] = &serverRequest{id: r.id, svcname: svc.name, name: r.service + serviceMethodSeparator + r.method, callb: callb}
 			if r.params != nil && len(callb.argTypes) > 0 {
 				if args, err := codec.ParseRequestArguments(callb.argTypes, r.params); err == nil {
 					requests[i].args = args
@@ -17,6 +17,7 @@
 package rpc
 
 import (
+	"net/http"
 	"reflect"
 	"sync"
 
@@ -53,6 +54,7 @@ type service struct {
 type serverRequest struct {
 	id            interface{}
 	svcname       string
+	name          string // service_method, or service_subscribe of a subscription
 	callb         *callback
 	args          []reflect.Value
 	isUnsubscribe bool
@@ -69,6 +71,11 @@ type Server struct {
 	run      int32
 	codecsMu sync.Mutex
 	codecs   mapset.Set
+
+	handlersMu sync.RWMutex
+	handlers   map[string]http.Handler // GET path -> handler, eg. /metrics
+
+	access *accessControl // api keys and rate limits, nil if not checked
 }
 
 // rpcRequest represents a raw incoming RPC request
@@ -60,7 +60,7 @@ func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 		Handshake: wsHandshakeValidator(allowedOrigins),
 		Handler: func(conn *websocket.Conn) {
 			// Create chain custom encode/decode pair to enforce payload size and number encoding
-			conn.MaxPayloadBytes = maxRequestContentLength
+			conn.MaxPayloadBytes = int(srv.maxRequestSize())
 
 			encoder := func(v interface{}) error {
 				return websocketJSONCodec.Send(conn, v)
@@ -68,7 +68,8 @@ func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			decoder := func(v interface{}) error {
 				return websocketJSONCodec.Receive(conn, v)
 			}
-			srv.ServeCodec(NewCodec(conn, encoder, decoder), OptionMethodInvocation|OptionSubscriptions)
+			ctx := withRequestInfo(context.Background(), conn.Request())
+			srv.serveCodec(ctx, NewCodec(conn, encoder, decoder), OptionMethodInvocation|OptionSubscriptions)
 		},
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/interfaces/core"
 	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	chain_plugins "github.com/vitelabs/go-vite/v2/ledger/chain/plugins"
 	"github.com/vitelabs/go-vite/v2/vm/contracts/dex"
 )
 
@@ -441,6 +442,96 @@ func OrdersToRpc(orders []*dex.Order) []*RpcOrder {
 	}
 }
 
+type RpcDepthLevel struct {
+	Price      string `json:"price"`
+	Quantity   string `json:"quantity"`
+	Amount     string `json:"amount"`
+	OrderCount int    `json:"orderCount"`
+}
+
+type MarketDepthRes struct {
+	Asks     []*RpcDepthLevel `json:"asks"`
+	Bids     []*RpcDepthLevel `json:"bids"`
+	Snapshot core.HashHeight  `json:"snapshot"`
+}
+
+func DepthLevelsToRpc(levels []*dex.DepthLevel) []*RpcDepthLevel {
+	rpcLevels := make([]*RpcDepthLevel, len(levels))
+	for i, level := range levels {
+		rpcLevels[i] = &RpcDepthLevel{
+			Price:      dex.BytesToPrice(level.Price),
+			Quantity:   level.Quantity.String(),
+			Amount:     level.Amount.String(),
+			OrderCount: level.OrderCount,
+		}
+	}
+	return rpcLevels
+}
+
+type RpcTrade struct {
+	Id               string `json:"id"`
+	TakerSide        bool   `json:"takerSide"`
+	TakerId          string `json:"takerId"`
+	MakerId          string `json:"makerId"`
+	Price            string `json:"price"`
+	Quantity         string `json:"quantity"`
+	Amount           string `json:"amount"`
+	TakerFee         string `json:"takerFee"`
+	MakerFee         string `json:"makerFee"`
+	TakerOperatorFee string `json:"takerOperatorFee"`
+	MakerOperatorFee string `json:"makerOperatorFee"`
+	Timestamp        int64  `json:"timestamp"`
+}
+
+func TradesToRpc(trades []*dex.TransactionEvent) []*RpcTrade {
+	rpcTrades := make([]*RpcTrade, len(trades))
+	for i, trade := range trades {
+		rpcTrades[i] = &RpcTrade{
+			Id:               hex.EncodeToString(trade.Id),
+			TakerSide:        trade.TakerSide,
+			TakerId:          hex.EncodeToString(trade.TakerId),
+			MakerId:          hex.EncodeToString(trade.MakerId),
+			Price:            dex.BytesToPrice(trade.Price),
+			Quantity:         AmountBytesToString(trade.Quantity),
+			Amount:           AmountBytesToString(trade.Amount),
+			TakerFee:         AmountBytesToString(trade.TakerFee),
+			MakerFee:         AmountBytesToString(trade.MakerFee),
+			TakerOperatorFee: AmountBytesToString(trade.TakerOperatorFee),
+			MakerOperatorFee: AmountBytesToString(trade.MakerOperatorFee),
+			Timestamp:        trade.Timestamp,
+		}
+	}
+	return rpcTrades
+}
+
+type RpcKline struct {
+	Time       int64  `json:"time"`
+	Open       string `json:"open"`
+	High       string `json:"high"`
+	Low        string `json:"low"`
+	Close      string `json:"close"`
+	Quantity   string `json:"quantity"`
+	Amount     string `json:"amount"`
+	TradeCount uint64 `json:"tradeCount"`
+}
+
+func KlinesToRpc(klines []*chain_plugins.DexKline) []*RpcKline {
+	rpcKlines := make([]*RpcKline, len(klines))
+	for i, kline := range klines {
+		rpcKlines[i] = &RpcKline{
+			Time:       kline.Time,
+			Open:       dex.BytesToPrice(kline.Open),
+			High:       dex.BytesToPrice(kline.High),
+			Low:        dex.BytesToPrice(kline.Low),
+			Close:      dex.BytesToPrice(kline.Close),
+			Quantity:   kline.Quantity.String(),
+			Amount:     kline.Amount.String(),
+			TradeCount: kline.TradeCount,
+		}
+	}
+	return rpcKlines
+}
+
 type StakeInfoList struct {
 	StakeAmount string       `json:"totalStakeAmount"`
 	Count       int          `json:"totalStakeCount"`
@@ -2,14 +2,19 @@ package api
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 
 	"github.com/vitelabs/go-vite/v2"
 	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
 	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	chain_plugins "github.com/vitelabs/go-vite/v2/ledger/chain/plugins"
 	"github.com/vitelabs/go-vite/v2/log15"
 	apidex "github.com/vitelabs/go-vite/v2/rpcapi/api/dex"
 	"github.com/vitelabs/go-vite/v2/vm/contracts/dex"
+	"github.com/vitelabs/go-vite/v2/vm_db"
 )
 
 type DexTradeApi struct {
@@ -136,6 +141,133 @@ func (f DexTradeApi) GetMarketOrders(param MarketOrderParam) (ordersRes *apidex.
 	}
 }
 
+type MarketDepthParam struct {
+	TradeToken types.TokenTypeId
+	QuoteToken types.TokenTypeId
+	// Precision is the decimals of the aggregated price, a negative precision rounds the integer part
+	Precision int32
+	// Limit is the max count of price levels of each side
+	Limit int
+	// SnapshotHash is the snapshot block to read the book at, the latest one if nil
+	SnapshotHash *types.Hash
+}
+
+// GetMarketDepth returns the price levels of both sides of a market aggregated to the precision,
+// asks are sorted from the lowest price and bids from the highest price.
+func (f DexTradeApi) GetMarketDepth(param MarketDepthParam) (*apidex.MarketDepthRes, error) {
+	if param.Limit <= 0 || param.Limit > 1000 {
+		return nil, fmt.Errorf("limit must be in (0, 1000]")
+	}
+	if err := dex.ValidDepthPrecision(param.Precision); err != nil {
+		return nil, err
+	}
+	sb := f.chain.GetLatestSnapshotBlock()
+	if param.SnapshotHash != nil {
+		var err error
+		if sb, err = f.chain.GetSnapshotHeaderByHash(*param.SnapshotHash); err != nil {
+			return nil, err
+		}
+		if sb == nil {
+			return nil, fmt.Errorf("snapshot block %s is not exist", param.SnapshotHash)
+		}
+	}
+
+	fundDb, releaseFund, err := f.getSnapshotVmDb(types.AddressDexFund, sb)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseFund()
+	marketInfo, ok := dex.GetMarketInfo(fundDb, param.TradeToken, param.QuoteToken)
+	if !ok {
+		return nil, dex.TradeMarketNotExistsErr
+	}
+	tradeDb, releaseTrade, err := f.getSnapshotVmDb(types.AddressDexTrade, sb)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseTrade()
+
+	matcher := dex.NewMatcherWithMarketInfo(tradeDb, marketInfo)
+	asks, err := matcher.GetMarketDepth(true, param.Precision, param.Limit)
+	if err != nil {
+		return nil, err
+	}
+	bids, err := matcher.GetMarketDepth(false, param.Precision, param.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return &apidex.MarketDepthRes{
+		Asks:     apidex.DepthLevelsToRpc(asks),
+		Bids:     apidex.DepthLevelsToRpc(bids),
+		Snapshot: ledger.HashHeight{Hash: sb.Hash, Height: sb.Height},
+	}, nil
+}
+
+// getSnapshotVmDb returns a read only vmDb of the address on the state at the snapshot block,
+// the returned func releases the state.
+func (f DexTradeApi) getSnapshotVmDb(addr types.Address, sb *ledger.SnapshotBlock) (interfaces.VmDb, func(), error) {
+	state, err := f.chain.GetSnapshotState(addr, sb.Height)
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := vm_db.NewVmDbWithState(f.chain, state, &addr, &sb.Hash, &types.Hash{})
+	if err != nil {
+		state.Release()
+		return nil, nil, err
+	}
+	return db, state.Release, nil
+}
+
+// GetTrades returns at most limit trades of the market in [startTime, endTime) indexed by the dexTrade plugin,
+// the latest trade first.
+func (f DexTradeApi) GetTrades(tradeToken, quoteToken types.TokenTypeId, startTime, endTime int64, limit int) ([]*apidex.RpcTrade, error) {
+	if limit <= 0 || limit > 1000 {
+		return nil, fmt.Errorf("limit must be in (0, 1000]")
+	}
+	plugin, marketInfo, err := f.getDexTradePlugin(tradeToken, quoteToken)
+	if err != nil {
+		return nil, err
+	}
+	trades, err := plugin.GetTrades(marketInfo.MarketId, startTime, endTime, limit)
+	if err != nil {
+		return nil, err
+	}
+	return apidex.TradesToRpc(trades), nil
+}
+
+// GetKlines returns the latest limit klines of the market in [startTime, endTime) in ascending order of time,
+// interval is one of 1m, 5m, 1h and 1d.
+func (f DexTradeApi) GetKlines(tradeToken, quoteToken types.TokenTypeId, interval string, startTime, endTime int64, limit int) ([]*apidex.RpcKline, error) {
+	if limit <= 0 || limit > 1000 {
+		return nil, fmt.Errorf("limit must be in (0, 1000]")
+	}
+	plugin, marketInfo, err := f.getDexTradePlugin(tradeToken, quoteToken)
+	if err != nil {
+		return nil, err
+	}
+	klines, err := plugin.GetKlines(marketInfo.MarketId, interval, startTime, endTime, limit)
+	if err != nil {
+		return nil, err
+	}
+	return apidex.KlinesToRpc(klines), nil
+}
+
+func (f DexTradeApi) getDexTradePlugin(tradeToken, quoteToken types.TokenTypeId) (*chain_plugins.DexTrade, *dex.MarketInfo, error) {
+	plugins := f.chain.Plugins()
+	if plugins == nil {
+		return nil, nil, errors.New("config.OpenPlugins is false, api can't work")
+	}
+	fundDb, err := getVmDb(f.chain, types.AddressDexFund)
+	if err != nil {
+		return nil, nil, err
+	}
+	marketInfo, ok := dex.GetMarketInfo(fundDb, tradeToken, quoteToken)
+	if !ok {
+		return nil, nil, dex.TradeMarketNotExistsErr
+	}
+	return plugins.GetPlugin("dexTrade").(*chain_plugins.DexTrade), marketInfo, nil
+}
+
 func (f DexTradeApi) GetMarketInfoById(marketId int32) (ordersRes *apidex.RpcMarketInfo, err error) {
 	if tradeDb, err := getVmDb(f.chain, types.AddressDexTrade); err != nil {
 		return nil, err
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/log15"
+	"github.com/vitelabs/go-vite/v2/net"
+)
+
+// EdgeApi serves the verified data of an edge node
+type EdgeApi struct {
+	edge *net.Edge
+	log  log15.Logger
+}
+
+func NewEdgeApi(edge *net.Edge) *EdgeApi {
+	return &EdgeApi{
+		edge: edge,
+		log:  log15.New("module", "rpc_api/edge_api"),
+	}
+}
+
+type EdgeAccountBlock struct {
+	*ledger.AccountBlock
+
+	Producer types.Address `json:"producer"`
+
+	ConfirmedHash   types.Hash `json:"confirmedHash"`
+	ConfirmedHeight string     `json:"confirmedHeight"`
+	Confirmations   string     `json:"confirmations"`
+	Timestamp       int64      `json:"timestamp"`
+}
+
+type EdgeAccountState struct {
+	SnapshotHash   types.Hash                   `json:"snapshotHash"`
+	SnapshotHeight string                       `json:"snapshotHeight"`
+	Balances       map[types.TokenTypeId]string `json:"balances"`
+	Storage        map[string]string            `json:"storage"`
+}
+
+func (e *EdgeApi) NodeInfo() net.NodeInfo {
+	return e.edge.Info()
+}
+
+func (e *EdgeApi) GetLatestSnapshotBlock() (*SnapshotBlock, error) {
+	return ledgerSnapshotBlockToRpcBlock(e.edge.GetLatestSnapshotBlock())
+}
+
+func (e *EdgeApi) GetSnapshotBlockByHeight(height interface{}) (*SnapshotBlock, error) {
+	heightUint64, err := parseHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := e.edge.GetSnapshotBlockByHeight(heightUint64)
+	if err != nil {
+		e.log.Error("GetSnapshotBlockByHeight failed, error is "+err.Error(), "method", "GetSnapshotBlockByHeight")
+		return nil, err
+	}
+	return ledgerSnapshotBlockToRpcBlock(block)
+}
+
+// GetAccountBlockByHash fetches the account block from full peers, and verifies it by the confirming snapshot block
+func (e *EdgeApi) GetAccountBlockByHash(blockHash types.Hash) (*EdgeAccountBlock, error) {
+	block, snapshotBlock, err := e.edge.GetAccountBlockByHash(blockHash)
+	if err != nil {
+		e.log.Info("GetAccountBlockByHash failed, error is "+err.Error(), "method", "GetAccountBlockByHash")
+		return nil, err
+	}
+
+	latest := e.edge.GetLatestSnapshotBlock()
+	return &EdgeAccountBlock{
+		AccountBlock:    block,
+		Producer:        block.Producer(),
+		ConfirmedHash:   snapshotBlock.Hash,
+		ConfirmedHeight: strconv.FormatUint(snapshotBlock.Height, 10),
+		Confirmations:   strconv.FormatUint(latest.Height-snapshotBlock.Height+1, 10),
+		Timestamp:       snapshotBlock.Timestamp.Unix(),
+	}, nil
+}
+
+// GetAccountState queries the balances and the storage values (hex encoded) of the account from full peers,
+// the result is accepted only if the peers agree with each other
+func (e *EdgeApi) GetAccountState(addr types.Address, tokenIds []types.TokenTypeId, storageKeys []string) (*EdgeAccountState, error) {
+	keys := make([][]byte, len(storageKeys))
+	for i, key := range storageKeys {
+		buf, err := hex.DecodeString(key)
+		if err != nil {
+			return nil, errors.New("invalid storage key " + key)
+		}
+		keys[i] = buf
+	}
+
+	state, err := e.edge.GetAccountState(addr, tokenIds, keys)
+	if err != nil {
+		e.log.Info("GetAccountState failed, error is "+err.Error(), "method", "GetAccountState")
+		return nil, err
+	}
+
+	result := &EdgeAccountState{
+		SnapshotHash:   state.Snapshot.Hash,
+		SnapshotHeight: strconv.FormatUint(state.Snapshot.Height, 10),
+		Balances:       make(map[types.TokenTypeId]string, len(tokenIds)),
+		Storage:        make(map[string]string, len(storageKeys)),
+	}
+	for i, tokenId := range tokenIds {
+		result.Balances[tokenId] = state.Balances[i].String()
+	}
+	for i, key := range storageKeys {
+		result.Storage[key] = hex.EncodeToString(state.StorageValues[i])
+	}
+
+	return result, nil
+}
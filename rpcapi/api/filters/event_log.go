@@ -0,0 +1,97 @@
+package filters
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vitelabs/go-vite/v2/ledger/eventlog"
+	"github.com/vitelabs/go-vite/v2/rpc"
+)
+
+// the max count of events returned by subscribe_getEvents
+const maxEventsCount = 1000
+
+var ErrEventLogDisabled = errors.New("Set \"EventLogEnabled\" to \"true\" in node_config.json")
+
+// EventsMsg is a page of the event log, the next page starts from NextCursor
+type EventsMsg struct {
+	Events     []*eventlog.Event `json:"events"`
+	NextCursor uint64            `json:"nextCursor"`
+}
+
+func (s *SubscribeApi) eventLog() (*eventlog.EventLog, error) {
+	el := s.vite.EventLog()
+	if el == nil {
+		return nil, ErrEventLogDisabled
+	}
+	return el, nil
+}
+
+// GetEvents returns the events from the cursor, from 0 means from the first event kept by the node
+func (s *SubscribeApi) GetEvents(from uint64, count int) (*EventsMsg, error) {
+	el, err := s.eventLog()
+	if err != nil {
+		return nil, err
+	}
+	if count <= 0 || count > maxEventsCount {
+		count = maxEventsCount
+	}
+	events, err := el.Events(from, count)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &EventsMsg{Events: events, NextCursor: from}
+	if len(events) > 0 {
+		msg.NextCursor = events[len(events)-1].Cursor + 1
+	} else if from == 0 {
+		_, last := el.Cursors()
+		msg.NextCursor = last + 1
+	}
+	return msg, nil
+}
+
+// NewEvents replays the events from the cursor and then pushes the new events in order
+func (s *SubscribeApi) NewEvents(ctx context.Context, from uint64) (*rpc.Subscription, error) {
+	s.log.Info("newEvents", "from", from)
+	el, err := s.eventLog()
+	if err != nil {
+		return nil, err
+	}
+	// fails early if the events from the cursor are pruned
+	if _, err := el.Events(from, 0); err != nil {
+		return nil, err
+	}
+
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		// the events replayed before the client gets the subscription id would be dropped
+		select {
+		case <-rpcSub.Activated():
+		case <-notifier.Closed():
+			return
+		}
+
+		quit := make(chan struct{})
+		go func() {
+			select {
+			case <-rpcSub.Err():
+			case <-notifier.Closed():
+			}
+			close(quit)
+		}()
+
+		err := el.Follow(from, quit, func(events []*eventlog.Event) error {
+			return notifier.Notify(rpcSub.ID, events)
+		})
+		if err != nil {
+			s.log.Info("newEvents stopped", "id", rpcSub.ID, "err", err)
+		}
+	}()
+	return rpcSub, nil
+}
@@ -2,7 +2,9 @@ package api
 
 import (
 	"fmt"
+	"math"
 	"math/big"
+	"strconv"
 
 	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/errors"
 	"github.com/vitelabs/go-vite/v2/common/types"
@@ -135,6 +137,65 @@ func (l *LedgerApi) GetAccountBlocksByAddress(addr types.Address, index int, cou
 	}
 }
 
+type AccountBlocksFilter struct {
+	Counterparty *types.Address     `json:"counterparty"`
+	Direction    string             `json:"direction"` // in, out, or empty for both
+	TokenId      *types.TokenTypeId `json:"tokenId"`
+	StartTime    int64              `json:"startTime"`
+	EndTime      int64              `json:"endTime"` // 0 means no limit
+}
+
+// GetAccountBlocksByFilter returns the confirmed blocks of addr indexed by the accountTx plugin, sorted by snapshot time desc.
+// The send blocks created by a contract are returned as blocks of the contract.
+func (l *LedgerApi) GetAccountBlocksByFilter(addr types.Address, filter AccountBlocksFilter, pageIndex int, pageSize int) ([]*AccountBlock, error) {
+	if pageIndex < 0 || pageSize <= 0 || pageSize > 1000 {
+		return nil, fmt.Errorf("pageIndex must not be negative and pageSize must be in (0, 1000]")
+	}
+	plugins := l.chain.Plugins()
+	if plugins == nil {
+		return nil, errors.New("config.OpenPlugins is false, api can't work")
+	}
+
+	txFilter := chain_plugins.AccountTxFilter{
+		Counterparty: filter.Counterparty,
+		TokenId:      filter.TokenId,
+		StartTime:    filter.StartTime,
+		EndTime:      filter.EndTime,
+	}
+	switch filter.Direction {
+	case "":
+		txFilter.Direction = chain_plugins.DirectionAll
+	case "in":
+		txFilter.Direction = chain_plugins.DirectionIn
+	case "out":
+		txFilter.Direction = chain_plugins.DirectionOut
+	default:
+		return nil, fmt.Errorf("unknown direction %s", filter.Direction)
+	}
+
+	plugin, ok := plugins.GetPlugin("accountTx").(*chain_plugins.AccountTx)
+	if !ok || plugin == nil {
+		return nil, errors.New("plugins-AccountTx's service not provided")
+	}
+	hashes, err := plugin.GetBlockHashes(addr, txFilter, pageIndex*pageSize, pageSize)
+	if err != nil {
+		l.log.Error("GetBlockHashes failed, error is "+err.Error(), "method", "GetAccountBlocksByFilter")
+		return nil, err
+	}
+
+	list := make([]*ledger.AccountBlock, 0, len(hashes))
+	for _, hash := range hashes {
+		block, err := l.chain.GetAccountBlockByHash(hash)
+		if err != nil {
+			return nil, err
+		}
+		if block != nil {
+			list = append(list, block)
+		}
+	}
+	return l.ledgerBlocksToRpcBlocks(list)
+}
+
 // GetAccountBlocksByHeightRange [start,end] sorted by height desc
 func (l *LedgerApi) GetAccountBlocksByHeightRange(addr types.Address, start uint64, end uint64) ([]*AccountBlock, error) {
 	if end - start > 1000 {
@@ -728,3 +789,52 @@ func (l *LedgerApi) GetChunksV2(startHeight interface{}, endHeight interface{})
 func (l LedgerApi) GetUpgradeInfo() (interface{}, error) {
 	return upgrade.GetAllPoints(), nil
 }
+
+type UpgradePointInfo struct {
+	Name             string `json:"name"`
+	Version          uint32 `json:"version"`
+	ActivationHeight string `json:"activationHeight"`
+}
+
+type UpgradeSchedule struct {
+	SnapshotHeight string `json:"snapshotHeight"`
+	Persisted      bool   `json:"persisted"` // whether the schedule is the one saved in the chain db
+
+	Active   *UpgradePointInfo   `json:"active"`   // the latest activated point
+	Upcoming []*UpgradePointInfo `json:"upcoming"` // the points not activated yet
+	Passed   []*UpgradePointInfo `json:"passed"`   // the activated points before the active one
+}
+
+// GetUpgradeSchedule reports the upgrade points relative to the latest snapshot block
+func (l LedgerApi) GetUpgradeSchedule() (*UpgradeSchedule, error) {
+	height := l.chain.GetLatestSnapshotBlock().Height
+	points := upgrade.GetAllPoints()
+
+	stored, err := l.chain.QueryUpgradePoints()
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := &UpgradeSchedule{
+		SnapshotHeight: strconv.FormatUint(height, 10),
+		Persisted:      stored != nil && upgrade.CheckUpgradeSchedule(stored, points, math.MaxUint64) == nil,
+		Upcoming:       []*UpgradePointInfo{},
+		Passed:         []*UpgradePointInfo{},
+	}
+	for _, point := range points {
+		info := &UpgradePointInfo{
+			Name:             point.Name,
+			Version:          point.Version,
+			ActivationHeight: strconv.FormatUint(point.Height, 10),
+		}
+		if point.Height > height {
+			schedule.Upcoming = append(schedule.Upcoming, info)
+			continue
+		}
+		if schedule.Active != nil {
+			schedule.Passed = append(schedule.Passed, schedule.Active)
+		}
+		schedule.Active = info
+	}
+	return schedule, nil
+}
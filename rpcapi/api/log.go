@@ -0,0 +1,62 @@
+package api
+
+import (
+	"github.com/vitelabs/go-vite/v2/common"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+// LogApi changes the levels of the modules in vite.log at runtime, it is registered in the
+// private log namespace.
+type LogApi struct {
+	lvls *log15.ModuleLvls
+	log  log15.Logger
+}
+
+func NewLogApi() *LogApi {
+	return &LogApi{
+		lvls: common.RunLogLevels,
+		log:  log15.New("module", "rpc_api/log_api"),
+	}
+}
+
+func (l LogApi) String() string {
+	return "LogApi"
+}
+
+type LogLevels struct {
+	Default string            `json:"default"`
+	Modules map[string]string `json:"modules"`
+}
+
+// GetLevels returns the default level and the levels of the modules overriding it
+func (l LogApi) GetLevels() *LogLevels {
+	levels := &LogLevels{
+		Default: l.lvls.Default().String(),
+		Modules: make(map[string]string),
+	}
+	for module, lvl := range l.lvls.Modules() {
+		levels.Modules[module] = lvl.String()
+	}
+	return levels
+}
+
+// SetLevel sets the level of the module and its submodules, the empty module sets the default level
+func (l LogApi) SetLevel(module string, level string) error {
+	lvl, err := log15.LvlFromString(level)
+	if err != nil {
+		return err
+	}
+	if module == "" {
+		l.lvls.SetDefault(lvl)
+	} else {
+		l.lvls.Set(module, lvl)
+	}
+	l.log.Info("set log level", "target", module, "level", lvl.String())
+	return nil
+}
+
+// ResetLevel removes the level of the module, the module uses the default level afterwards
+func (l LogApi) ResetLevel(module string) {
+	l.lvls.Reset(module)
+	l.log.Info("reset log level", "target", module)
+}
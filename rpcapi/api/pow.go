@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"math/big"
+	"strconv"
 
 	"github.com/vitelabs/go-vite/v2"
 	"github.com/vitelabs/go-vite/v2/common/hexutil"
@@ -57,6 +58,14 @@ func (p Pow) GetPowNonce(difficulty string, data types.Hash) ([]byte, error) {
 		return nil, ErrPoWNotSupportedUnderCongestion
 	}
 
+	if !remote.Working() {
+		if realDifficulty.Cmp(quota.MaxPoWDifficulty()) > 0 {
+			return nil, ErrDifficultyTooLarge
+		}
+		log.Info("no pow server, calc locally")
+		return pow.GetLocalPowNonce(realDifficulty, data)
+	}
+
 	work, e := remote.GenerateWork(data.Bytes(), realDifficulty)
 	if e != nil {
 		return nil, e
@@ -84,8 +93,68 @@ func (p Pow) GetPowNonce(difficulty string, data types.Hash) ([]byte, error) {
 }
 
 func (p Pow) CancelPow(data types.Hash) error {
+	if !remote.Working() {
+		if !pow.DefaultSolver.Cancel(data) {
+			return errors.New("pow is not running")
+		}
+		return nil
+	}
 	if err := remote.CancelWork(data.Bytes()); err != nil {
 		return errors.New("pow cancel failed")
 	}
 	return nil
 }
+
+type LocalPowJob struct {
+	DataHash      types.Hash `json:"dataHash"`
+	Difficulty    string     `json:"difficulty"`
+	Hashes        string     `json:"hashes"`
+	ElapsedTime   float64    `json:"elapsedTime"`   // seconds
+	EstimatedTime float64    `json:"estimatedTime"` // seconds, expected time of the whole job
+}
+
+type LocalPowStatus struct {
+	Workers  int            `json:"workers"`
+	HashRate float64        `json:"hashRate"` // hashes per second
+	Jobs     []*LocalPowJob `json:"jobs"`
+}
+
+type PrivatePow struct {
+}
+
+func NewPrivatePow() *PrivatePow {
+	return &PrivatePow{}
+}
+
+// GetLocalPowStatus returns the hash rate and running jobs of the local pow solver
+func (p PrivatePow) GetLocalPowStatus() *LocalPowStatus {
+	solver := pow.DefaultSolver
+	status := &LocalPowStatus{
+		Workers:  solver.Workers(),
+		HashRate: solver.HashRate(),
+		Jobs:     make([]*LocalPowJob, 0),
+	}
+	for _, job := range solver.Jobs() {
+		localJob := &LocalPowJob{
+			DataHash:      job.DataHash,
+			Hashes:        strconv.FormatUint(job.Hashes, 10),
+			ElapsedTime:   job.Elapsed.Seconds(),
+			EstimatedTime: solver.EstimateTime(job.Difficulty).Seconds(),
+		}
+		if job.Difficulty != nil {
+			localJob.Difficulty = job.Difficulty.String()
+		}
+		status.Jobs = append(status.Jobs, localJob)
+	}
+	return status
+}
+
+// EstimatePowTime returns the expected seconds to calc the difficulty by the local pow solver,
+// it is 0 before the solver calculated any nonce.
+func (p Pow) EstimatePowTime(difficulty string) (float64, error) {
+	realDifficulty, ok := new(big.Int).SetString(difficulty, 10)
+	if !ok {
+		return 0, ErrStrToBigInt
+	}
+	return pow.DefaultSolver.EstimateTime(realDifficulty).Seconds(), nil
+}
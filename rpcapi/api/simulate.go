@@ -0,0 +1,276 @@
+package api
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	"github.com/vitelabs/go-vite/v2/ledger/consensus/core"
+	"github.com/vitelabs/go-vite/v2/ledger/generator"
+	"github.com/vitelabs/go-vite/v2/vm/abi"
+	"github.com/vitelabs/go-vite/v2/vm_db"
+)
+
+const jsonRevertError = `[{"type":"function","name":"Error","inputs":[{"name":"reason","type":"string"}]}]`
+
+var abiRevertError, _ = abi.JSONToABIContract(strings.NewReader(jsonRevertError))
+
+type SimulateResult struct {
+	// Block is the result of the simulated block
+	Block *SimulateBlockResult `json:"block"`
+	// Receive is the result of the contract receiving the simulated send call block
+	Receive *SimulateBlockResult `json:"receive,omitempty"`
+}
+
+type SimulateBlockResult struct {
+	Block                  *AccountBlock    `json:"accountBlock"`
+	QuotaUsed              string           `json:"quotaUsed"`
+	VmLogList              ledger.VmLogList `json:"vmLogList"`
+	TriggeredSendBlockList []*AccountBlock  `json:"triggeredSendBlockList"`
+	StorageDiff            []*StorageChange `json:"storageDiff"`
+	IsRetry                bool             `json:"isRetry"`
+	Error                  string           `json:"error,omitempty"`
+	RevertData             []byte           `json:"revertData,omitempty"`
+	RevertReason           string           `json:"revertReason,omitempty"`
+}
+
+type StorageChange struct {
+	Key    string `json:"key"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// SimulateRawTx executes an unsigned send or receive block on a throwaway vm_db without adding it to the pool.
+// If snapshotHash is nil, the block is executed on the latest state of the accounts including the unconfirmed
+// blocks, and height and prevHash are filled by the latest account block if height is empty.
+// Otherwise the accounts are read at the snapshot block of snapshotHash, and height and prevHash are filled by
+// the latest account block confirmed by it, it fails if the state of the snapshot block has been pruned.
+// If the block is a send call to an existing contract, the receive block of the contract is simulated too,
+// unless the contract requires the send block to be confirmed.
+func (t Tx) SimulateRawTx(block *AccountBlock, snapshotHash *types.Hash) (*SimulateResult, error) {
+	return simulateRawTx(t.vite.Chain(), t.vite.Consensus().SBPReader(), block, snapshotHash)
+}
+
+func simulateRawTx(c chain.Chain, sbpReader core.SBPStatReader, block *AccountBlock, snapshotHash *types.Hash) (*SimulateResult, error) {
+	if block == nil {
+		return nil, errors.New("empty block")
+	}
+
+	// the snapshot block of the history state, nil means the latest state
+	var historySb *ledger.SnapshotBlock
+	if snapshotHash != nil {
+		sb, err := c.GetSnapshotHeaderByHash(*snapshotHash)
+		if err != nil {
+			return nil, err
+		}
+		if sb == nil {
+			return nil, fmt.Errorf("snapshot block %s is not exist", snapshotHash)
+		}
+		historySb = sb
+	}
+	sb := historySb
+	if sb == nil {
+		if sb = c.GetLatestSnapshotBlock(); sb == nil {
+			return nil, errors.New("failed to get latest snapshotBlock")
+		}
+	}
+
+	if block.Height == "" {
+		addr := block.AccountAddress
+		if !block.Address.IsZero() {
+			addr = block.Address
+		}
+		prevBlock, err := getPrevBlockAt(c, addr, historySb)
+		if err != nil {
+			return nil, err
+		}
+		block.Height = "1"
+		block.PrevHash, block.PreviousHash = types.Hash{}, types.Hash{}
+		if prevBlock != nil {
+			block.Height = strconv.FormatUint(prevBlock.Height+1, 10)
+			block.PrevHash, block.PreviousHash = prevBlock.Hash, prevBlock.Hash
+		}
+	}
+	lb, err := block.RpcToLedgerBlock()
+	if err != nil {
+		return nil, err
+	}
+	if lb.IsSendBlock() {
+		if !checkTxToAddressAvailable(lb.ToAddress) {
+			return nil, errors.New("ToAddress is invalid")
+		}
+		if err := checkTokenIdValid(c, &lb.TokenId); err != nil {
+			return nil, err
+		}
+	}
+
+	var fromBlock *ledger.AccountBlock
+	if lb.IsReceiveBlock() {
+		if fromBlock, err = c.GetAccountBlockByHash(lb.FromBlockHash); err != nil {
+			return nil, err
+		}
+		if fromBlock == nil {
+			return nil, fmt.Errorf("send block %s is not exist", lb.FromBlockHash)
+		}
+	}
+
+	blockResult, vmBlock, err := simulateBlock(c, sbpReader, lb, fromBlock, sb, historySb != nil)
+	if err != nil {
+		return nil, err
+	}
+	result := &SimulateResult{Block: blockResult}
+	if vmBlock == nil || vmBlock.AccountBlock.BlockType != ledger.BlockTypeSendCall || !types.IsContractAddr(lb.ToAddress) {
+		return result, nil
+	}
+	var meta *ledger.ContractMeta
+	if historySb != nil {
+		meta, err = c.GetContractMetaInSnapshot(lb.ToAddress, historySb.Height)
+	} else {
+		meta, err = c.GetContractMeta(lb.ToAddress)
+	}
+	if err != nil {
+		return nil, err
+	} else if meta == nil || meta.SendConfirmedTimes > 0 || meta.SeedConfirmedTimes > 0 {
+		// the receive block reads the snapshot block confirming the send block
+		return result, nil
+	}
+
+	sendBlock := vmBlock.AccountBlock
+	receiveBlock := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeReceive,
+		AccountAddress: sendBlock.ToAddress,
+		FromBlockHash:  sendBlock.Hash,
+		Height:         1,
+	}
+	if prevBlock, err := getPrevBlockAt(c, sendBlock.ToAddress, historySb); err != nil {
+		return nil, err
+	} else if prevBlock != nil {
+		receiveBlock.Height = prevBlock.Height + 1
+		receiveBlock.PrevHash = prevBlock.Hash
+	}
+	if result.Receive, _, err = simulateBlock(c, sbpReader, receiveBlock, sendBlock, sb, historySb != nil); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// getPrevBlockAt returns the latest account block of addr, or the latest one confirmed at or below sb if sb is not nil
+func getPrevBlockAt(c chain.Chain, addr types.Address, sb *ledger.SnapshotBlock) (*ledger.AccountBlock, error) {
+	latest, err := c.GetLatestAccountBlock(addr)
+	if err != nil || latest == nil || sb == nil {
+		return latest, err
+	}
+
+	// the confirming snapshot heights grow with the account heights, search the last block confirmed at or below sb
+	var prevBlock *ledger.AccountBlock
+	low, high := uint64(1), latest.Height
+	for low <= high {
+		mid := low + (high-low)/2
+		block, err := c.GetAccountBlockByHeight(addr, mid)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			return nil, fmt.Errorf("account block %s %d is not exist", addr, mid)
+		}
+		confirmSb, err := c.GetConfirmSnapshotHeaderByAbHash(block.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if confirmSb != nil && confirmSb.Height <= sb.Height {
+			prevBlock, low = block, mid+1
+		} else {
+			high = mid - 1
+		}
+	}
+	return prevBlock, nil
+}
+
+// simulateBlock executes the block on the state of the account at sb if history, or on the latest state
+func simulateBlock(c chain.Chain, sbpReader core.SBPStatReader, block *ledger.AccountBlock, fromBlock *ledger.AccountBlock, sb *ledger.SnapshotBlock, history bool) (*SimulateBlockResult, *interfaces.VmAccountBlock, error) {
+	var db interfaces.VmDb
+	if history {
+		state, err := c.GetSnapshotState(block.AccountAddress, sb.Height)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer state.Release()
+		if db, err = vm_db.NewVmDbWithState(c, state, &block.AccountAddress, &sb.Hash, &block.PrevHash); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		var err error
+		if db, err = vm_db.NewVmDb(c, &block.AccountAddress, &sb.Hash, &block.PrevHash); err != nil {
+			return nil, nil, err
+		}
+	}
+	tracer := &revertTracer{}
+	genResult, err := generator.NewGeneratorWithVmDb(c, sbpReader, db, tracer).GenerateWithBlock(block, fromBlock)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &SimulateBlockResult{
+		IsRetry:    genResult.IsRetry,
+		RevertData: tracer.data,
+	}
+	if genResult.Err != nil {
+		result.Error = genResult.Err.Error()
+	}
+	if len(tracer.data) > 0 {
+		if reason, err := abiRevertError.DirectUnpackMethodInput("Error", tracer.data); err == nil && len(reason) == 1 {
+			result.RevertReason, _ = reason[0].(string)
+		}
+	}
+	if genResult.VMBlock == nil {
+		return result, nil, nil
+	}
+
+	vmBlock := genResult.VMBlock
+	if result.Block, err = ledgerToRpcBlock(c, vmBlock.AccountBlock); err != nil {
+		return nil, nil, err
+	}
+	result.QuotaUsed = strconv.FormatUint(vmBlock.AccountBlock.QuotaUsed, 10)
+	result.TriggeredSendBlockList = result.Block.TriggeredSendBlockList
+	result.VmLogList = vmBlock.VmDb.GetLogList()
+	for _, kv := range vmBlock.VmDb.GetUnsavedStorage() {
+		before, err := vmBlock.VmDb.GetOriginalValue(kv[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		result.StorageDiff = append(result.StorageDiff, &StorageChange{
+			Key:    hex.EncodeToString(kv[0]),
+			Before: hex.EncodeToString(before),
+			After:  hex.EncodeToString(kv[1]),
+		})
+	}
+	return result, vmBlock, nil
+}
+
+// revertTracer keeps the data passed to REVERT, the vm doesn't write it into the receive block.
+type revertTracer struct {
+	data []byte
+}
+
+func (r *revertTracer) CaptureStart(block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) {
+}
+
+func (r *revertTracer) CaptureState(pc uint64, op string, cost, quotaLeft uint64, stack []*big.Int, memory []byte) {
+}
+
+func (r *revertTracer) CaptureFault(pc uint64, op string, cost, quotaLeft uint64, err error) {
+}
+
+func (r *revertTracer) CaptureEnd(vmBlock *interfaces.VmAccountBlock, err error) {
+}
+
+func (r *revertTracer) CaptureRevert(data []byte) {
+	r.data = append([]byte{}, data...)
+}
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	"github.com/vitelabs/go-vite/v2/vm"
+	"github.com/vitelabs/go-vite/v2/vm/quota"
+)
+
+// simulateChain is a chain of snapshot blocks whose account blocks are confirmed one by one, the balances
+// are the ones after each snapshot block. The methods not used by the simulation panic.
+type simulateChain struct {
+	chain.Chain
+
+	snapshotBlocks []*ledger.SnapshotBlock
+	accountBlocks  map[types.Address][]*ledger.AccountBlock
+	balances       map[types.Address][]*big.Int
+}
+
+func (c *simulateChain) snapshotBlockByHash(hash types.Hash) *ledger.SnapshotBlock {
+	for _, sb := range c.snapshotBlocks {
+		if sb.Hash == hash {
+			return sb
+		}
+	}
+	return nil
+}
+
+func (c *simulateChain) GetLatestSnapshotBlock() *ledger.SnapshotBlock {
+	return c.snapshotBlocks[len(c.snapshotBlocks)-1]
+}
+
+func (c *simulateChain) GetGenesisSnapshotBlock() *ledger.SnapshotBlock {
+	return c.snapshotBlocks[0]
+}
+
+func (c *simulateChain) GetSnapshotHeaderByHash(hash types.Hash) (*ledger.SnapshotBlock, error) {
+	return c.snapshotBlockByHash(hash), nil
+}
+
+func (c *simulateChain) GetSnapshotBlockByHeight(height uint64) (*ledger.SnapshotBlock, error) {
+	if height == 0 || height > uint64(len(c.snapshotBlocks)) {
+		return nil, nil
+	}
+	return c.snapshotBlocks[height-1], nil
+}
+
+func (c *simulateChain) GetLatestAccountBlock(addr types.Address) (*ledger.AccountBlock, error) {
+	blocks := c.accountBlocks[addr]
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+	return blocks[len(blocks)-1], nil
+}
+
+func (c *simulateChain) GetAccountBlockByHeight(addr types.Address, height uint64) (*ledger.AccountBlock, error) {
+	blocks := c.accountBlocks[addr]
+	if height == 0 || height > uint64(len(blocks)) {
+		return nil, nil
+	}
+	return blocks[height-1], nil
+}
+
+func (c *simulateChain) GetAccountBlockByHash(hash types.Hash) (*ledger.AccountBlock, error) {
+	for _, blocks := range c.accountBlocks {
+		for _, block := range blocks {
+			if block.Hash == hash {
+				return block, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// GetConfirmSnapshotHeaderByAbHash returns the snapshot block above the height of the account block
+func (c *simulateChain) GetConfirmSnapshotHeaderByAbHash(hash types.Hash) (*ledger.SnapshotBlock, error) {
+	block, _ := c.GetAccountBlockByHash(hash)
+	if block == nil || block.Height+1 > uint64(len(c.snapshotBlocks)) {
+		return nil, nil
+	}
+	return c.snapshotBlocks[block.Height], nil
+}
+
+func (c *simulateChain) GetConfirmedTimes(hash types.Hash) (uint64, error) {
+	sb, _ := c.GetConfirmSnapshotHeaderByAbHash(hash)
+	if sb == nil {
+		return 0, nil
+	}
+	return c.GetLatestSnapshotBlock().Height - sb.Height + 1, nil
+}
+
+func (c *simulateChain) GetBalance(addr types.Address, tokenId types.TokenTypeId) (*big.Int, error) {
+	return c.balanceAt(addr, c.GetLatestSnapshotBlock().Height), nil
+}
+
+func (c *simulateChain) balanceAt(addr types.Address, height uint64) *big.Int {
+	balances := c.balances[addr]
+	if height > uint64(len(balances)) {
+		height = uint64(len(balances))
+	}
+	if height == 0 {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Set(balances[height-1])
+}
+
+func (c *simulateChain) GetSnapshotState(addr types.Address, height uint64) (interfaces.StateSnapshot, error) {
+	return &simulateState{balance: c.balanceAt(addr, height)}, nil
+}
+
+func (c *simulateChain) GetTokenInfoById(tokenId types.TokenTypeId) (*types.TokenInfo, error) {
+	return &types.TokenInfo{TokenSymbol: "VITE", Decimals: 18}, nil
+}
+
+func (c *simulateChain) GetQuotaUsedList(addr types.Address) []types.QuotaInfo {
+	return nil
+}
+
+func (c *simulateChain) GetGlobalQuota() types.QuotaInfo {
+	return types.QuotaInfo{}
+}
+
+func (c *simulateChain) GetStakeBeneficialAmount(addr types.Address) (*big.Int, error) {
+	return new(big.Int).Mul(big.NewInt(10000), big.NewInt(1e18)), nil
+}
+
+func (c *simulateChain) GetContractMeta(addr types.Address) (*ledger.ContractMeta, error) {
+	return nil, nil
+}
+
+func (c *simulateChain) GetReceiveAbBySendAb(sendBlockHash types.Hash) (*ledger.AccountBlock, error) {
+	return nil, nil
+}
+
+func (c *simulateChain) GetUnconfirmedBlocks(addr types.Address) []*ledger.AccountBlock {
+	return nil
+}
+
+type simulateState struct {
+	interfaces.StateSnapshot
+	balance *big.Int
+}
+
+func (s *simulateState) GetBalance(tokenId *types.TokenTypeId) (*big.Int, error) {
+	return new(big.Int).Set(s.balance), nil
+}
+
+func (s *simulateState) Release() {
+}
+
+func TestSimulateRawTx(t *testing.T) {
+	upgrade.CleanupUpgradeBox()
+	upgrade.InitUpgradeBox(upgrade.NewLatestUpgradeBox())
+	// the balances are checked by the vm, the quota is not
+	vm.InitVMConfig(false, true, true, false, "")
+	quota.InitQuotaConfig(true, true)
+
+	from, to := types.PubkeyToAddress([]byte("from")), types.PubkeyToAddress([]byte("to"))
+	now := time.Unix(1600000000, 0)
+	c := &simulateChain{
+		accountBlocks: make(map[types.Address][]*ledger.AccountBlock),
+		balances:      make(map[types.Address][]*big.Int),
+	}
+	for i := uint64(1); i <= 3; i++ {
+		timestamp := now.Add(time.Duration(i) * time.Second)
+		c.snapshotBlocks = append(c.snapshotBlocks, &ledger.SnapshotBlock{
+			Hash:      types.DataHash(new(big.Int).SetUint64(i).Bytes()),
+			Height:    i,
+			Timestamp: &timestamp,
+		})
+	}
+	// from receives 100 at snapshot block 2 and sends all of them away at snapshot block 3
+	received := &ledger.AccountBlock{BlockType: ledger.BlockTypeReceive, AccountAddress: from, Height: 1,
+		Hash: types.DataHash([]byte("received"))}
+	sent := &ledger.AccountBlock{BlockType: ledger.BlockTypeSendCall, AccountAddress: from, Height: 2,
+		Hash: types.DataHash([]byte("sent")), PrevHash: received.Hash, TokenId: ledger.ViteTokenId, Amount: big.NewInt(100)}
+	c.accountBlocks[from] = []*ledger.AccountBlock{received, sent}
+	c.balances[from] = []*big.Int{big.NewInt(0), big.NewInt(100), big.NewInt(0)}
+
+	amount := "50"
+	newBlock := func() *AccountBlock {
+		return &AccountBlock{
+			BlockType:      ledger.BlockTypeSendCall,
+			AccountAddress: from,
+			ToAddress:      to,
+			TokenId:        ledger.ViteTokenId,
+			Amount:         &amount,
+		}
+	}
+
+	// the balance is spent in the latest state
+	result, err := simulateRawTx(c, nil, newBlock(), nil)
+	if assert.NoError(t, err) {
+		assert.NotEmpty(t, result.Block.Error)
+	}
+
+	// the balance is kept at snapshot block 2, the block follows the account block confirmed by it
+	result, err = simulateRawTx(c, nil, newBlock(), &c.snapshotBlocks[1].Hash)
+	if assert.NoError(t, err) {
+		assert.Empty(t, result.Block.Error)
+		if assert.NotNil(t, result.Block.Block) {
+			assert.Equal(t, "2", result.Block.Block.Height)
+			assert.Equal(t, received.Hash, result.Block.Block.PrevHash)
+		}
+	}
+
+	unknown := types.DataHash([]byte("unknown"))
+	_, err = simulateRawTx(c, nil, newBlock(), &unknown)
+	assert.Error(t, err)
+}
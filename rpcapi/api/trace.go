@@ -0,0 +1,200 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/vitelabs/go-vite/v2"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	"github.com/vitelabs/go-vite/v2/ledger/consensus"
+	"github.com/vitelabs/go-vite/v2/ledger/generator"
+	"github.com/vitelabs/go-vite/v2/log15"
+	"github.com/vitelabs/go-vite/v2/vm"
+	"github.com/vitelabs/go-vite/v2/vm_db"
+)
+
+const (
+	StructLoggerTracer = "structLogger"
+	CallTracer         = "callTracer"
+)
+
+// TraceApi re-executes account blocks with a vm tracer, it is registered in the private debug namespace.
+type TraceApi struct {
+	chain chain.Chain
+	cs    consensus.Consensus
+	log   log15.Logger
+}
+
+func NewTraceApi(vite *vite.Vite) *TraceApi {
+	return &TraceApi{
+		chain: vite.Chain(),
+		cs:    vite.Consensus(),
+		log:   log15.New("module", "rpc_api/trace_api"),
+	}
+}
+
+func (t TraceApi) String() string {
+	return "TraceApi"
+}
+
+type TraceConfig struct {
+	Tracer string `json:"tracer"` // structLogger(default) or callTracer
+	*vm.StructLoggerConfig
+}
+
+type BlockTrace struct {
+	Hash types.Hash `json:"hash"`
+	// Consistent is false if the re-executed block differs from the block in the chain,
+	// eg. the block read the timestamp of another snapshot block
+	Consistent bool        `json:"consistent"`
+	Error      string      `json:"error,omitempty"`
+	Result     interface{} `json:"result"`
+}
+
+type TraceCallParam struct {
+	From    types.Address      `json:"from"`
+	To      types.Address      `json:"to"`
+	TokenId *types.TokenTypeId `json:"tokenId"`
+	Amount  *string            `json:"amount"`
+	Data    []byte             `json:"data"`
+}
+
+// TraceBlock re-executes the account block on the state before it was inserted,
+// it is available while the redo log of the block is retained.
+func (t *TraceApi) TraceBlock(hash types.Hash, config *TraceConfig) (*BlockTrace, error) {
+	block, err := t.chain.GetAccountBlockByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("account block %s is not exist", hash)
+	}
+	if block.IsSendBlock() && types.IsContractAddr(block.AccountAddress) {
+		return nil, fmt.Errorf("account block %s is sent by contract, trace the receive block %s instead", hash, block.FromBlockHash)
+	}
+
+	var fromBlock *ledger.AccountBlock
+	if block.IsReceiveBlock() {
+		if fromBlock, err = t.chain.GetAccountBlockByHash(block.FromBlockHash); err != nil {
+			return nil, err
+		}
+		if fromBlock == nil {
+			return nil, fmt.Errorf("send block %s is not exist", block.FromBlockHash)
+		}
+	}
+
+	state, snapshotBlock, err := t.chain.GetStateBeforeAccountBlock(block)
+	if err != nil {
+		return nil, err
+	}
+	defer state.Release()
+
+	db, err := vm_db.NewVmDbWithState(t.chain, state, &block.AccountAddress, &snapshotBlock.Hash, &block.PrevHash)
+	if err != nil {
+		return nil, err
+	}
+
+	tracer, result, err := newTracer(config)
+	if err != nil {
+		return nil, err
+	}
+	genResult, err := generator.NewGeneratorWithVmDb(t.chain, t.cs.SBPReader(), db, tracer).GenerateWithBlock(block, fromBlock)
+	if err != nil {
+		return nil, err
+	}
+	return newBlockTrace(hash, genResult, result), nil
+}
+
+// TraceCall executes the receive block of a hypothetical send call on the latest state of the contract,
+// contracts which read the confirmed snapshot block of the send block can't be traced.
+func (t *TraceApi) TraceCall(param TraceCallParam, config *TraceConfig) (*BlockTrace, error) {
+	if !types.IsContractAddr(param.To) {
+		return nil, errors.New("to address must be a contract")
+	}
+	amount := big.NewInt(0)
+	if param.Amount != nil {
+		var err error
+		if amount, err = stringToBigInt(param.Amount); err != nil {
+			return nil, err
+		}
+	}
+	tokenId := ledger.ViteTokenId
+	if param.TokenId != nil {
+		tokenId = *param.TokenId
+	}
+
+	prevBlock, err := t.chain.GetLatestAccountBlock(param.From)
+	if err != nil {
+		return nil, err
+	}
+	sendBlock := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		AccountAddress: param.From,
+		ToAddress:      param.To,
+		Height:         1,
+		TokenId:        tokenId,
+		Amount:         amount,
+		Fee:            big.NewInt(0),
+		Data:           param.Data,
+	}
+	if prevBlock != nil {
+		sendBlock.Height = prevBlock.Height + 1
+		sendBlock.PrevHash = prevBlock.Hash
+	}
+	sendBlock.Hash = sendBlock.ComputeHash()
+
+	prevHash, err := getPrevBlockHash(t.chain, param.To)
+	if err != nil {
+		return nil, err
+	}
+	db, err := vm_db.NewVmDb(t.chain, &param.To, &t.chain.GetLatestSnapshotBlock().Hash, prevHash)
+	if err != nil {
+		return nil, err
+	}
+
+	tracer, result, err := newTracer(config)
+	if err != nil {
+		return nil, err
+	}
+	genResult, err := generator.NewGeneratorWithVmDb(t.chain, t.cs.SBPReader(), db, tracer).GenerateWithOnRoad(sendBlock, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	trace := newBlockTrace(types.Hash{}, genResult, result)
+	if genResult.VMBlock != nil {
+		trace.Hash = genResult.VMBlock.AccountBlock.Hash
+	}
+	trace.Consistent = true
+	return trace, nil
+}
+
+func newTracer(config *TraceConfig) (vm.Tracer, func() interface{}, error) {
+	if config == nil {
+		config = &TraceConfig{}
+	}
+	switch config.Tracer {
+	case "", StructLoggerTracer:
+		tracer := vm.NewStructLogger(config.StructLoggerConfig)
+		return tracer, func() interface{} { return tracer.Result() }, nil
+	case CallTracer:
+		tracer := vm.NewCallTracer()
+		return tracer, func() interface{} { return tracer.Result() }, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown tracer %s", config.Tracer)
+	}
+}
+
+func newBlockTrace(hash types.Hash, genResult *interfaces.GenResult, result func() interface{}) *BlockTrace {
+	trace := &BlockTrace{Hash: hash, Result: result()}
+	if genResult.Err != nil {
+		trace.Error = genResult.Err.Error()
+	}
+	if genResult.VMBlock != nil {
+		trace.Consistent = genResult.VMBlock.AccountBlock.Hash == hash
+	}
+	return trace
+}
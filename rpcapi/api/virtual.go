@@ -45,6 +45,11 @@ func (api *VirtualApi) MineBatch(number uint64) error {
 	return nil
 }
 
+// AddUpgrade sets the height of version and saves the upgrade schedule to the ledger, the node must be
+// restarted with the same points in its Upgrade config.
 func (api *VirtualApi) AddUpgrade(version uint32, height uint64) error {
-	return upgrade.AddUpgradePoint(version, height)
+	if err := upgrade.AddUpgradePoint(version, height, api.chain.GetLatestSnapshotBlock().Height); err != nil {
+		return err
+	}
+	return api.chain.WriteUpgradePoints(upgrade.GetAllPoints())
 }
@@ -123,6 +123,9 @@ func (m WalletApi) DeriveByFullPath(entropyStore string, fullpath string) (*Deri
 	if err != nil {
 		return nil, err
 	}
+	if err := m.wallet.AuthorizeKeyExport(*address); err != nil {
+		return nil, err
+	}
 
 	privateKey, err := key.PrivateKey()
 	if err != nil {
@@ -150,6 +153,9 @@ func (m WalletApi) DeriveByIndex(entropyStore string, index uint32) (*DeriveResu
 	if err != nil {
 		return nil, err
 	}
+	if err := m.wallet.AuthorizeKeyExport(*address); err != nil {
+		return nil, err
+	}
 
 	privateKey, err := key.PrivateKey()
 	if err != nil {
@@ -256,6 +262,9 @@ func (m WalletApi) SignData(addr types.Address, hexMsg string) (*HexSignedTuple,
 	if e != nil {
 		return nil, e
 	}
+	if e := m.wallet.AuthorizeSignData(addr); e != nil {
+		return nil, e
+	}
 
 	signedData, pubkey, err := account.Sign(hash.Bytes())
 	if err != nil {
@@ -317,6 +326,14 @@ func (m WalletApi) CreateTxWithPassphrase(params CreateTransferTxParms) (*types.
 	if err != nil {
 		return nil, err
 	}
+	if err := m.wallet.AuthorizeTransfer(&wallet.TransferRequest{
+		Address:   msg.AccountAddress,
+		ToAddress: *msg.ToAddress,
+		TokenId:   *msg.TokenId,
+		Amount:    amount,
+	}); err != nil {
+		return nil, err
+	}
 	result, e := g.GenerateWithMessage(msg, &msg.AccountAddress, account.Sign)
 
 	if e != nil {
@@ -342,6 +359,9 @@ func (m WalletApi) SignDataWithPassphrase(addr types.Address, hexMsg string, pas
 	if err != nil {
 		return nil, err
 	}
+	if err := m.wallet.AuthorizeSignData(addr); err != nil {
+		return nil, err
+	}
 	signedData, pubkey, err := account.Sign(hash.Bytes())
 	if err != nil {
 		return nil, err
@@ -377,7 +397,10 @@ func (m WalletApi) GetPrivateKey(entropyStore string, passphrase string) (*strin
 	if e != nil {
 		return nil, e
 	}
-	err := manager.Unlock(passphrase)
+	if err := m.wallet.AuthorizeKeyExport(manager.GetPrimaryAddr()); err != nil {
+		return nil, err
+	}
+	err := m.wallet.Unlock(entropyStore, passphrase)
 	if err != nil {
 		return nil, err
 	}
@@ -1,9 +1,13 @@
 package api
 
 import (
+	"encoding/hex"
 	"errors"
+	"time"
 
 	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+	"github.com/vitelabs/go-vite/v2/wallet"
 )
 
 func (m WalletApi) GetEntropyFilesInStandardDir() ([]string, error) {
@@ -19,24 +23,16 @@ func (m WalletApi) ExportMnemonic(entropyFile string, passphrase string) (string
 }
 
 func (m WalletApi) Unlock(entropyFile string, passphrase string) error {
-	manager, e := m.wallet.GetEntropyStoreManager(entropyFile)
-	if e != nil {
-		return e
-	}
-	err := manager.Unlock(passphrase)
-	if err != nil {
-		return err
-	}
-	return nil
+	return m.wallet.Unlock(entropyFile, passphrase)
+}
+
+// UnlockFor unlocks the entropy file and locks it again after timeout seconds
+func (m WalletApi) UnlockFor(entropyFile string, passphrase string, timeout uint64) error {
+	return m.wallet.UnlockFor(entropyFile, passphrase, time.Duration(timeout)*time.Second)
 }
 
 func (m WalletApi) Lock(entropyFile string) error {
-	manager, e := m.wallet.GetEntropyStoreManager(entropyFile)
-	if e != nil {
-		return e
-	}
-	manager.Lock()
-	return nil
+	return m.wallet.Lock(entropyFile)
 }
 
 func (m WalletApi) DeriveAddressesByIndexRange(entropyFile string, startIndex, endIndex uint32) ([]types.Address, error) {
@@ -87,6 +83,9 @@ func (m WalletApi) DeriveAddressByIndex(entropyFile string, index uint32) (*Deri
 	if err != nil {
 		return nil, err
 	}
+	if err := m.wallet.AuthorizeKeyExport(*address); err != nil {
+		return nil, err
+	}
 
 	privateKey, err := key.PrivateKey()
 	if err != nil {
@@ -114,6 +113,9 @@ func (m WalletApi) DeriveAddressByPath(entropyFile string, bip44Path string) (*D
 	if err != nil {
 		return nil, err
 	}
+	if err := m.wallet.AuthorizeKeyExport(*address); err != nil {
+		return nil, err
+	}
 
 	privateKey, err := key.PrivateKey()
 	if err != nil {
@@ -194,3 +196,21 @@ func (m WalletApi) CreateTransaction(params CreateTransactionParms) (*types.Hash
 		Difficulty:       params.Difficulty,
 	})
 }
+
+// ApproveTransfer approves a transfer waiting for the second approval, the signature is the signature of
+// the approval id signed by the approver of the transfer policy. Both are hex strings.
+func (m WalletApi) ApproveTransfer(id types.Hash, pubkey string, signature string) error {
+	pub, err := hex.DecodeString(pubkey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return errors.New("invalid pubkey")
+	}
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return errors.New("invalid signature")
+	}
+	return m.wallet.ApproveTransfer(id, ed25519.PublicKey(pub), sig)
+}
+
+func (m WalletApi) GetPendingTransferApprovals() []*wallet.PendingApproval {
+	return m.wallet.PendingApprovals()
+}
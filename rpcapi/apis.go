@@ -2,6 +2,7 @@ package rpcapi
 
 import (
 	"github.com/vitelabs/go-vite/v2"
+	"github.com/vitelabs/go-vite/v2/net"
 	"github.com/vitelabs/go-vite/v2/rpc"
 	"github.com/vitelabs/go-vite/v2/rpcapi/api"
 	"github.com/vitelabs/go-vite/v2/rpcapi/api/filters"
@@ -36,6 +37,9 @@ const (
 	DATA
 	LEDGERDEBUG
 	VIRTUAL
+	PRIVATE_DEBUG
+	LOG
+	PRIVATE_POW
 	apiTypeLimit // this will be the last ApiType + 1
 )
 
@@ -66,6 +70,9 @@ var apiTypeStrings = []string{
 	"data",
 	"ledgerdebug",
 	"virtual",
+	"private_debug",
+	"log",
+	"private_pow",
 }
 
 func (at ApiType) name() string {
@@ -272,6 +279,27 @@ func GetApi(vite *vite.Vite, apiModule string) rpc.API {
 			Service:   api.NewVirtualApi(vite),
 			Public:    false,
 		}
+	case ApiType(PRIVATE_DEBUG).name():
+		return rpc.API{
+			Namespace: "debug",
+			Version:   "1.0",
+			Service:   api.NewTraceApi(vite),
+			Public:    false,
+		}
+	case ApiType(LOG).name():
+		return rpc.API{
+			Namespace: "log",
+			Version:   "1.0",
+			Service:   api.NewLogApi(),
+			Public:    false,
+		}
+	case ApiType(PRIVATE_POW).name():
+		return rpc.API{
+			Namespace: "pow",
+			Version:   "1.0",
+			Service:   api.NewPrivatePow(),
+			Public:    false,
+		}
 	default:
 		return rpc.API{Namespace: apiModule}
 	}
@@ -309,3 +337,15 @@ func MergeApis(first map[string]rpc.API, second map[string]rpc.API) []rpc.API {
 func GetPublicApis(vite *vite.Vite) map[string]rpc.API {
 	return GetApis(vite, ApiType(LEDGER).name(), ApiType(NET).name(), ApiType(CONTRACT).name(), ApiType(UTIL).name(), ApiType(HEALTH).name())
 }
+
+// GetEdgeApis returns the apis of an edge node, which has no ledger to serve the other modules
+func GetEdgeApis(edge *net.Edge) []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "edge",
+			Version:   "1.0",
+			Service:   api.NewEdgeApi(edge),
+			Public:    true,
+		},
+	}
+}
@@ -3,14 +3,18 @@ package vite
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/vitelabs/go-vite/v2/common/config"
 	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/common/upgrade"
+	"github.com/vitelabs/go-vite/v2/interfaces"
 	"github.com/vitelabs/go-vite/v2/ledger/chain"
 	"github.com/vitelabs/go-vite/v2/ledger/consensus"
+	"github.com/vitelabs/go-vite/v2/ledger/eventlog"
+	"github.com/vitelabs/go-vite/v2/ledger/eventsink"
 	"github.com/vitelabs/go-vite/v2/ledger/onroad"
 	"github.com/vitelabs/go-vite/v2/ledger/pool"
 	"github.com/vitelabs/go-vite/v2/ledger/verifier"
@@ -19,6 +23,7 @@ import (
 	"github.com/vitelabs/go-vite/v2/producer"
 	"github.com/vitelabs/go-vite/v2/vm"
 	"github.com/vitelabs/go-vite/v2/wallet"
+	"github.com/vitelabs/go-vite/v2/wallet/remotesigner"
 )
 
 var (
@@ -36,23 +41,37 @@ type Vite struct {
 	pool          pool.BlockPool
 	consensus     consensus.Consensus
 	onRoad        *onroad.Manager
+	eventLog      *eventlog.EventLog
+	eventSinks    *eventsink.Manager
 }
 
 func New(cfg *config.Config, walletManager *wallet.Manager) (vite *Vite, err error) {
 	// set upgrade
 	upgrade.InitUpgradeBox(cfg.UpgradeCfg.MakeUpgradeBox())
 
-	var account *wallet.Account
+	var account interfaces.Account
 	if cfg.Producer.IsMine() {
-		account, err = walletManager.AccountAtIndex(cfg.EntropyStorePath, cfg.Producer.GetCoinbase(), cfg.Producer.GetIndex())
-		if err != nil {
-			log.Error(fmt.Sprintf("coinBase is not child of entropyStore, coinBase is : %v", cfg.Producer.Coinbase), "err", err)
-			return nil, err
-		}
-
-		cfg.Net.MineKey, err = account.PrivateKey()
-		if err != nil {
-			return
+		if cfg.Producer.SignerEndpoint != "" {
+			// the key of the coinbase is kept by the remote signer
+			signerAccount, err := newRemoteSignerAccount(cfg.Producer.SignerEndpoint, cfg.Producer.GetCoinbase())
+			if err != nil {
+				log.Error(fmt.Sprintf("remote signer can not sign for coinBase %v", cfg.Producer.Coinbase), "err", err)
+				return nil, err
+			}
+			cfg.Net.MineSigner = signerAccount.CryptoSigner()
+			account = signerAccount
+		} else {
+			walletAccount, err := walletManager.AccountAtIndex(cfg.EntropyStorePath, cfg.Producer.GetCoinbase(), cfg.Producer.GetIndex())
+			if err != nil {
+				log.Error(fmt.Sprintf("coinBase is not child of entropyStore, coinBase is : %v", cfg.Producer.Coinbase), "err", err)
+				return nil, err
+			}
+
+			cfg.Net.MineKey, err = walletAccount.PrivateKey()
+			if err != nil {
+				return nil, err
+			}
+			account = walletAccount
 		}
 	}
 
@@ -78,6 +97,8 @@ func New(cfg *config.Config, walletManager *wallet.Manager) (vite *Vite, err err
 	if err != nil {
 		return
 	}
+	// damaged snapshot chunks in block files are fetched from peers
+	chain.SetChunkFetcher(net)
 
 	// vite
 	vite = &Vite{
@@ -95,6 +116,19 @@ func New(cfg *config.Config, walletManager *wallet.Manager) (vite *Vite, err err
 	}
 	// set onroad
 	vite.onRoad = onroad.NewManager(net, pl, vite.producer, vite.consensus.SBPReader(), account)
+
+	if cfg.Subscribe != nil && (cfg.Subscribe.EventLog || len(cfg.Subscribe.EventSinks) > 0) {
+		vite.eventLog, err = eventlog.New(filepath.Join(cfg.DataDir, "events"), chain, cfg.Subscribe.EventLogRetention)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Subscribe != nil && len(cfg.Subscribe.EventSinks) > 0 {
+		vite.eventSinks, err = eventsink.New(vite.eventLog, chain, filepath.Join(cfg.DataDir, "eventsinks"), cfg.Subscribe.EventSinks)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return
 }
 
@@ -124,6 +158,15 @@ func (v *Vite) Start() (err error) {
 
 	v.chain.Start()
 
+	if v.eventLog != nil {
+		if err = v.eventLog.Start(); err != nil {
+			return err
+		}
+	}
+	if v.eventSinks != nil {
+		v.eventSinks.Start()
+	}
+
 	err = v.consensus.Init(consensus.Cfg())
 	if err != nil {
 		return err
@@ -163,6 +206,14 @@ func (v *Vite) Stop() (err error) {
 		}
 	}
 	v.consensus.Stop()
+	if v.eventSinks != nil {
+		v.eventSinks.Stop()
+	}
+	if v.eventLog != nil {
+		if err := v.eventLog.Stop(); err != nil {
+			log.Error("eventLog.Stop failed, error is "+err.Error(), "method", "vite.Stop")
+		}
+	}
 	v.chain.Stop()
 	v.onRoad.Stop()
 	return nil
@@ -204,6 +255,11 @@ func (v *Vite) Verifier() verifier.Verifier {
 	return v.verifier
 }
 
+// EventLog returns nil if the event log is not enabled
+func (v *Vite) EventLog() *eventlog.EventLog {
+	return v.eventLog
+}
+
 func parseCoinbase(coinbaseCfg string) (*types.Address, uint32, error) {
 	splits := strings.Split(coinbaseCfg, ":")
 	if len(splits) != 2 {
@@ -220,3 +276,16 @@ func parseCoinbase(coinbaseCfg string) (*types.Address, uint32, error) {
 
 	return &addr, uint32(i), nil
 }
+
+func newRemoteSignerAccount(endpoint string, coinbase types.Address) (*wallet.SignerAccount, error) {
+	signer, err := remotesigner.Dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	account, err := wallet.NewSignerAccount(signer, coinbase)
+	if err != nil {
+		signer.Close()
+		return nil, err
+	}
+	return account, nil
+}
@@ -119,6 +119,14 @@ func (abi ABIContract) DirectUnpackMethodInput(name string, input []byte) ([]int
 	return nil, errCouldNotLocateNamedMethod
 }
 
+// UnpackOffchainOutput output in v according to the abi specification
+func (abi ABIContract) UnpackOffchainOutput(v interface{}, name string, output []byte) error {
+	if offchain, ok := abi.OffChains[name]; ok {
+		return offchain.Outputs.Unpack(v, output)
+	}
+	return errOffchainNotFound(name)
+}
+
 // DirectUnpackOffchainOutput output in param list according to the abi specification
 func (abi ABIContract) DirectUnpackOffchainOutput(name string, output []byte) ([]interface{}, error) {
 	if offchain, ok := abi.OffChains[name]; ok {
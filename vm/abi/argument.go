@@ -18,19 +18,23 @@ type Argument struct {
 
 type Arguments []Argument
 
+// ArgumentMarshaling is the json form of an argument, components are the fields of a tuple.
+type ArgumentMarshaling struct {
+	Name       string
+	Type       string
+	Components []ArgumentMarshaling
+	Indexed    bool
+}
+
 // UnmarshalJSON implements json.Unmarshaler interface
 func (argument *Argument) UnmarshalJSON(data []byte) error {
-	var extarg struct {
-		Name    string
-		Type    string
-		Indexed bool
-	}
+	var extarg ArgumentMarshaling
 	err := json.Unmarshal(data, &extarg)
 	if err != nil {
 		return errArgumentJsonErr(err)
 	}
 
-	argument.Type, err = NewType(extarg.Type)
+	argument.Type, err = NewTupleType(extarg.Type, extarg.Components)
 	if err != nil {
 		return err
 	}
@@ -55,10 +59,33 @@ func (arguments Arguments) Unpack(v interface{}, data []byte) error {
 	if err != nil {
 		return err
 	}
+	return arguments.Copy(v, marshalledValues)
+}
+
+// Copy performs the operation go format -> provided struct, values are the
+// result of UnpackValues
+func (arguments Arguments) Copy(v interface{}, values []interface{}) error {
+	// make sure the passed value is arguments pointer
+	if reflect.Ptr != reflect.ValueOf(v).Kind() {
+		return errInvalidStruct(v)
+	}
 	if arguments.isTuple() {
-		return arguments.unpackTuple(v, marshalledValues)
+		return arguments.unpackTuple(v, values)
 	}
-	return arguments.unpackAtomic(v, marshalledValues)
+	return arguments.unpackAtomic(v, values)
+}
+
+// UnpackIntoMap performs the operation hexdata -> map, tuples are unpacked into
+// map[string]interface{} too
+func (arguments Arguments) UnpackIntoMap(v map[string]interface{}, data []byte) error {
+	marshalledValues, err := arguments.UnpackValues(data)
+	if err != nil {
+		return err
+	}
+	for i, arg := range arguments {
+		v[arg.Name] = toMapValue(reflect.ValueOf(marshalledValues[i]), arg.Type)
+	}
+	return nil
 }
 
 // Unpack performs the operation hexdata -> Go format
@@ -95,7 +122,7 @@ func (arguments Arguments) unpackTuple(v interface{}, marshalledValues []interfa
 		switch kind {
 		case reflect.Struct:
 			if structField, ok := abi2struct[arg.Name]; ok {
-				if err := set(value.FieldByName(structField), reflectValue, arg); err != nil {
+				if err := set(value.FieldByName(structField), reflectValue, arg.Type); err != nil {
 					return err
 				}
 			}
@@ -108,7 +135,7 @@ func (arguments Arguments) unpackTuple(v interface{}, marshalledValues []interfa
 				return err
 			}
 
-			if err := set(v.Elem(), reflectValue, arg); err != nil {
+			if err := set(v.Elem(), reflectValue, arg.Type); err != nil {
 				return err
 			}
 		default:
@@ -128,36 +155,21 @@ func (arguments Arguments) unpackAtomic(v interface{}, marshalledValues []interf
 	kind := elem.Kind()
 	reflectValue := reflect.ValueOf(marshalledValues[0])
 
-	var abi2struct map[string]string
+	arg := arguments[0]
 	if kind == reflect.Struct {
-		var err error
-		if abi2struct, err = mapAbiToStructFields(arguments, elem); err != nil {
-			return err
+		abi2struct, err := mapAbiToStructFields(arguments, elem)
+		if structField, ok := abi2struct[arg.Name]; err == nil && ok {
+			return set(elem.FieldByName(structField), reflectValue, arg.Type)
 		}
-		arg := arguments[0]
-		if structField, ok := abi2struct[arg.Name]; ok {
-			return set(elem.FieldByName(structField), reflectValue, arg)
+		// a tuple can be unpacked into the struct directly
+		if arg.Type.T == TupleTy {
+			return set(elem, reflectValue, arg.Type)
 		}
-		return nil
+		return err
 	}
 
-	return set(elem, reflectValue, arguments[0])
-
-}
+	return set(elem, reflectValue, arg.Type)
 
-// Computes the full size of an array;
-// i.e. counting nested arrays, which count towards size for unpacking.
-func getArraySize(arr *Type) int {
-	size := arr.Size
-	// Arrays can be nested, with each element being the same size
-	arr = arr.Elem
-	for arr.T == ArrayTy {
-		// Keep multiplying by elem.Size while the elem is an array.
-		size *= arr.Size
-		arr = arr.Elem
-	}
-	// Now we have the full array size, including its children.
-	return size
 }
 
 // UnpackValues can be used to unpack ABI-encoded hexdata according to the ABI-specification,
@@ -168,18 +180,17 @@ func (arguments Arguments) UnpackValues(data []byte) ([]interface{}, error) {
 	virtualArgs := 0
 	for index, arg := range arguments {
 		marshalledValue, err := toGoType((index+virtualArgs)*helper.WordSize, arg.Type, data)
-		if arg.Type.T == ArrayTy {
+		if (arg.Type.T == ArrayTy || arg.Type.T == TupleTy) && !isDynamicType(arg.Type) {
 			// If we have a static array, like [3]uint256, these are coded as
 			// just like uint256,uint256,uint256.
 			// This means that we need to add two 'virtual' arguments when
 			// we count the index from now on.
 			//
-			// Array values nested multiple levels deep are also encoded inline:
-			// [2][3]uint256: uint256,uint256,uint256,uint256,uint256,uint256
+			// Static tuples are encoded inline as well, like (uint256,address).
 			//
-			// Calculate the full array size to get the correct offset for the next argument.
+			// Calculate the full size to get the correct offset for the next argument.
 			// Decrement it by 1, as the normal index increment is still applied.
-			virtualArgs += getArraySize(&arg.Type) - 1
+			virtualArgs += getTypeSize(arg.Type)/helper.WordSize - 1
 		}
 		if err != nil {
 			return nil, err
@@ -209,11 +220,7 @@ func (arguments Arguments) Pack(args ...interface{}) ([]byte, error) {
 	// input offset is the bytes offset for packed output
 	inputOffset := 0
 	for _, abiArg := range abiArgs {
-		if abiArg.Type.T == ArrayTy {
-			inputOffset += helper.WordSize * abiArg.Type.Size
-		} else {
-			inputOffset += helper.WordSize
-		}
+		inputOffset += getTypeSize(abiArg.Type)
 	}
 	var ret []byte
 	for i, a := range args {
@@ -223,8 +230,8 @@ func (arguments Arguments) Pack(args ...interface{}) ([]byte, error) {
 		if err != nil {
 			return nil, err
 		}
-		// check for a slice type (string, bytes, slice)
-		if input.Type.requiresLengthPrefix() {
+		// check for a dynamic type (string, bytes, slice, dynamic array and dynamic tuple)
+		if isDynamicType(input.Type) {
 			// calculate the offset
 			offset := inputOffset + len(variableInput)
 			// set the offset
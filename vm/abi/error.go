@@ -63,6 +63,15 @@ func errUnsupportedArgType(t string) error {
 func errUnknownType(t Type) error {
 	return fmt.Errorf("abi: unknown type %v", t.T)
 }
+func errInvalidTupleFieldName(name string) error {
+	return fmt.Errorf("abi: invalid tuple field name '%s'", name)
+}
+func errDuplicateTupleFieldName(name string) error {
+	return fmt.Errorf("abi: duplicate tuple field name '%s'", name)
+}
+func errTupleFieldNotFound(name string) error {
+	return fmt.Errorf("abi: tuple field '%s' not found", name)
+}
 
 // pack errors
 func errWrongPackedLength(marshalledValues []interface{}) error {
@@ -146,6 +155,11 @@ func sliceTypeCheck(t Type, val reflect.Value) error {
 		return errType(formatSliceString(t.Elem.Kind, t.Size), formatSliceString(val.Type().Elem().Kind(), val.Len()))
 	}
 
+	// tuple elements are checked one by one when packing, they could be structs or maps
+	if t.Elem.T == TupleTy {
+		return nil
+	}
+
 	if t.Elem.T == SliceTy {
 		if val.Len() > 0 {
 			return sliceTypeCheck(*t.Elem, val.Index(0))
@@ -169,6 +183,13 @@ func typeCheck(t Type, value reflect.Value) error {
 		return sliceTypeCheck(t, value)
 	}
 
+	if t.T == TupleTy {
+		if value.Kind() != reflect.Struct && value.Kind() != reflect.Map {
+			return errType(t.Type, value.Type())
+		}
+		return nil
+	}
+
 	// Check base type validity. Element types will be checked later on.
 	if t.Kind != reflect.Array && t.Kind != value.Kind() {
 		return errType(t.Kind, value.Kind())
@@ -89,7 +89,7 @@ func (e Event) DirectUnPack(topics []types.Hash, data []byte) ([]interface{}, er
 	args := make([]interface{}, 0)
 	for _, arg := range e.Inputs {
 		if arg.Indexed {
-			if arg.Type.T == ArrayTy || arg.Type.T == StringTy || arg.Type.T == SliceTy || arg.Type.T == BytesTy {
+			if arg.Type.T == ArrayTy || arg.Type.T == StringTy || arg.Type.T == SliceTy || arg.Type.T == BytesTy || arg.Type.T == TupleTy {
 				args = append(args, topics[index])
 			} else {
 				arg, err := toGoType(0, arg.Type, topics[index].Bytes())
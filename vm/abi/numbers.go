@@ -22,6 +22,7 @@ var (
 	addressT  = reflect.TypeOf(types.Address{})
 	gidT      = reflect.TypeOf(types.Gid{})
 	tokenIdT  = reflect.TypeOf(types.TokenTypeId{})
+	mapT      = reflect.TypeOf(map[string]interface{}{})
 )
 
 // U256 converts a big Int into a 256bit VM number.
@@ -2,6 +2,8 @@ package abi
 
 import (
 	"bytes"
+	"encoding/hex"
+	"encoding/json"
 	"math"
 	"math/big"
 	"reflect"
@@ -434,3 +436,76 @@ func TestPackNumber(t *testing.T) {
 		}
 	}
 }
+
+func TestPackTuple(t *testing.T) {
+	type static struct {
+		A *big.Int
+		B bool
+	}
+	type dynamic struct {
+		A    uint8
+		Name string `abi:"b"`
+	}
+
+	for i, test := range []struct {
+		def    string
+		args   []interface{}
+		output string
+	}{
+		{
+			`[{"name":"s","type":"tuple","components":[{"name":"a","type":"uint256"},{"name":"b","type":"bool"}]},{"name":"x","type":"uint8"}]`,
+			[]interface{}{static{big.NewInt(1), true}, uint8(2)},
+			"0000000000000000000000000000000000000000000000000000000000000001" +
+				"0000000000000000000000000000000000000000000000000000000000000001" +
+				"0000000000000000000000000000000000000000000000000000000000000002",
+		},
+		{
+			`[{"name":"s","type":"tuple","components":[{"name":"a","type":"uint8"},{"name":"b","type":"string"}]}]`,
+			[]interface{}{&dynamic{1, "abc"}},
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+				"0000000000000000000000000000000000000000000000000000000000000001" +
+				"0000000000000000000000000000000000000000000000000000000000000040" +
+				"0000000000000000000000000000000000000000000000000000000000000003" +
+				"6162630000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			`[{"name":"s","type":"tuple","components":[{"name":"a","type":"uint8"},{"name":"b","type":"string"}]}]`,
+			[]interface{}{map[string]interface{}{"a": uint8(1), "b": "abc"}},
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+				"0000000000000000000000000000000000000000000000000000000000000001" +
+				"0000000000000000000000000000000000000000000000000000000000000040" +
+				"0000000000000000000000000000000000000000000000000000000000000003" +
+				"6162630000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			`[{"name":"s","type":"tuple[]","components":[{"name":"a","type":"uint256"},{"name":"b","type":"bool"}]}]`,
+			[]interface{}{[]static{{big.NewInt(1), true}, {big.NewInt(2), false}}},
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+				"0000000000000000000000000000000000000000000000000000000000000002" +
+				"0000000000000000000000000000000000000000000000000000000000000001" +
+				"0000000000000000000000000000000000000000000000000000000000000001" +
+				"0000000000000000000000000000000000000000000000000000000000000002" +
+				"0000000000000000000000000000000000000000000000000000000000000000",
+		},
+	} {
+		var args Arguments
+		if err := json.Unmarshal([]byte(test.def), &args); err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		packed, err := args.Pack(test.args...)
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if hex.EncodeToString(packed) != test.output {
+			t.Errorf("%d: expected %s, got %x", i, test.output, packed)
+		}
+	}
+
+	var args Arguments
+	if err := json.Unmarshal([]byte(`[{"name":"s","type":"tuple","components":[{"name":"a","type":"uint8"}]}]`), &args); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := args.Pack(map[string]interface{}{"b": uint8(1)}); err == nil || err.Error() != "abi: tuple field 'a' not found" {
+		t.Errorf("expected missing field error, got %v", err)
+	}
+}
@@ -6,11 +6,15 @@ import (
 )
 
 // indirect recursively dereferences the value until it either gets the value
-// or finds a big.Int
+// or finds a big.Int, interfaces (like the values of map[string]interface{}) are
+// dereferenced too
 func indirect(v reflect.Value) reflect.Value {
 	if v.Kind() == reflect.Ptr && v.Elem().Type() != derefbigT {
 		return indirect(v.Elem())
 	}
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		return indirect(v.Elem())
+	}
 	return v
 }
 
@@ -54,7 +58,7 @@ func mustArrayToByteSlice(value reflect.Value) reflect.Value {
 //
 // set is a bit more lenient when it comes to assignment and doesn't force an as
 // strict ruleset as bare `reflect` does.
-func set(dst, src reflect.Value, output Argument) error {
+func set(dst, src reflect.Value, t Type) error {
 	dstType := dst.Type()
 	srcType := src.Type()
 	switch {
@@ -63,13 +67,112 @@ func set(dst, src reflect.Value, output Argument) error {
 	case dstType.Kind() == reflect.Interface:
 		dst.Set(src)
 	case dstType.Kind() == reflect.Ptr:
-		return set(dst.Elem(), src, output)
+		return set(dst.Elem(), src, t)
+	case t.T == TupleTy && (dstType.Kind() == reflect.Struct || dstType.Kind() == reflect.Map):
+		return setTuple(dst, src, t)
+	case (t.T == SliceTy || t.T == ArrayTy) && t.Elem.T == TupleTy &&
+		(dstType.Kind() == reflect.Slice || dstType.Kind() == reflect.Array):
+		return setTupleSlice(dst, src, t)
 	default:
 		return errUnmarshalTypeFailed(src, dst)
 	}
 	return nil
 }
 
+// setTuple assigns the unpacked tuple src to a struct or a map[string]interface{}.
+// The fields of a struct are matched by the `abi:""` tag first, then by the capitalised name,
+// the fields not found are skipped.
+func setTuple(dst, src reflect.Value, t Type) error {
+	if dst.Kind() == reflect.Map {
+		if dst.Type() != mapT {
+			return errUnmarshalTypeFailed(src, dst)
+		}
+		dst.Set(reflect.ValueOf(toMapValue(src, t)))
+		return nil
+	}
+
+	for i, name := range t.TupleRawNames {
+		field := tupleStructField(dst, name)
+		if !field.IsValid() {
+			continue
+		}
+		if err := set(field, src.Field(i), *t.TupleElems[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setTupleSlice assigns the unpacked tuple slice or array src element by element.
+func setTupleSlice(dst, src reflect.Value, t Type) error {
+	if dst.Kind() == reflect.Slice {
+		dst.Set(reflect.MakeSlice(dst.Type(), src.Len(), src.Len()))
+	} else if dst.Len() != src.Len() {
+		return errUnmarshalTypeFailed(src, dst)
+	}
+
+	for i := 0; i < src.Len(); i++ {
+		if err := set(dst.Index(i), src.Index(i), *t.Elem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toMapValue converts the unpacked tuples in src to map[string]interface{},
+// and the tuple slices and arrays to []map[string]interface{}, the other values are kept.
+func toMapValue(src reflect.Value, t Type) interface{} {
+	switch {
+	case t.T == TupleTy:
+		m := make(map[string]interface{}, len(t.TupleRawNames))
+		for i, name := range t.TupleRawNames {
+			m[name] = toMapValue(src.Field(i), *t.TupleElems[i])
+		}
+		return m
+	case (t.T == SliceTy || t.T == ArrayTy) && t.Elem.T == TupleTy:
+		list := make([]map[string]interface{}, src.Len())
+		for i := range list {
+			list[i] = toMapValue(src.Index(i), *t.Elem).(map[string]interface{})
+		}
+		return list
+	default:
+		return src.Interface()
+	}
+}
+
+// tupleStructField returns the field of struct v for the tuple field name,
+// an invalid value is returned if not found.
+func tupleStructField(v reflect.Value, name string) reflect.Value {
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		if tagName, ok := typ.Field(i).Tag.Lookup("abi"); ok && tagName == name {
+			return v.Field(i)
+		}
+	}
+	return v.FieldByName(capitalise(name))
+}
+
+// tupleFieldValue returns the value of the ith field of tuple t for packing,
+// v is a struct or a map[string]interface{}.
+func tupleFieldValue(t Type, v reflect.Value, i int) (reflect.Value, error) {
+	name := t.TupleRawNames[i]
+
+	var field reflect.Value
+	if v.Kind() == reflect.Map {
+		if v.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, errType(t.Type, v.Type())
+		}
+		field = v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+	} else {
+		field = tupleStructField(v, name)
+	}
+
+	if !field.IsValid() || (field.Kind() == reflect.Interface && field.IsNil()) {
+		return reflect.Value{}, errTupleFieldNotFound(name)
+	}
+	return field, nil
+}
+
 // requireAssignable assures that `dest` is a pointer and it's not an interface.
 func requireAssignable(dst, src reflect.Value) error {
 	if dst.Kind() != reflect.Ptr && dst.Kind() != reflect.Interface {
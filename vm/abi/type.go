@@ -5,7 +5,9 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
 
+	"github.com/vitelabs/go-vite/v2/common/helper"
 	"github.com/vitelabs/go-vite/v2/common/types"
 )
 
@@ -22,6 +24,7 @@ const (
 	TokenIdTy
 	FixedBytesTy
 	BytesTy
+	TupleTy
 )
 
 // Type is the reflection of the supported argument type
@@ -33,6 +36,9 @@ type Type struct {
 	Size int
 	T    byte // Our own type checking
 
+	TupleElems    []*Type  // Type information of all tuple fields
+	TupleRawNames []string // Raw field name of all tuple fields
+
 	stringKind string // holds the unparsed string for deriving signatures
 }
 
@@ -43,6 +49,12 @@ var (
 
 // NewType creates a new reflection type of abi type given in t.
 func NewType(t string) (typ Type, err error) {
+	return NewTupleType(t, nil)
+}
+
+// NewTupleType creates a new reflection type of abi type given in t, components
+// are the fields of a tuple type, like `tuple`, `tuple[]` or `tuple[2]`.
+func NewTupleType(t string, components []ArgumentMarshaling) (typ Type, err error) {
 	if t == "uint" || t == "int" {
 		// this should fail because it means that there's something wrong with
 		// the abi type (the compiler should always format it to the size...always)
@@ -61,7 +73,7 @@ func NewType(t string) (typ Type, err error) {
 	if strings.Count(t, "[") != 0 {
 		i := strings.LastIndex(t, "[")
 		// recursively embed the type
-		embeddedType, err := NewType(t[:i])
+		embeddedType, err := NewTupleType(t[:i], components)
 		if err != nil {
 			return Type{}, err
 		}
@@ -94,6 +106,8 @@ func NewType(t string) (typ Type, err error) {
 		} else {
 			return Type{}, errInvalidArrayTypeFormatting
 		}
+		// the signature of a tuple array is like `(uint8,address)[]`
+		typ.stringKind = embeddedType.stringKind + sliced
 		return typ, err
 	}
 	// parse the type and size of the abi-type.
@@ -154,6 +168,46 @@ func NewType(t string) (typ Type, err error) {
 			typ.Size = varSize
 			typ.Type = reflect.ArrayOf(varSize, reflect.TypeOf(byte(0)))
 		}
+	case "tuple":
+		if varSize != 0 || len(components) == 0 {
+			return Type{}, errUnsupportedArgType(t)
+		}
+		var (
+			fields     []reflect.StructField
+			elems      []*Type
+			names      []string
+			signatures []string
+			used       = make(map[string]bool)
+		)
+		for _, c := range components {
+			cType, err := NewTupleType(c.Type, c.Components)
+			if err != nil {
+				return Type{}, err
+			}
+			fieldName := capitalise(c.Name)
+			if !isValidFieldName(fieldName) {
+				return Type{}, errInvalidTupleFieldName(c.Name)
+			}
+			if used[fieldName] {
+				return Type{}, errDuplicateTupleFieldName(c.Name)
+			}
+			used[fieldName] = true
+
+			fields = append(fields, reflect.StructField{
+				Name: fieldName,
+				Type: cType.Type,
+				Tag:  reflect.StructTag("json:\"" + c.Name + "\""),
+			})
+			elems = append(elems, &cType)
+			names = append(names, c.Name)
+			signatures = append(signatures, cType.stringKind)
+		}
+		typ.Kind = reflect.Struct
+		typ.Type = reflect.StructOf(fields)
+		typ.TupleElems = elems
+		typ.TupleRawNames = names
+		typ.T = TupleTy
+		typ.stringKind = "(" + strings.Join(signatures, ",") + ")"
 	default:
 		return Type{}, errUnsupportedArgType(t)
 	}
@@ -174,21 +228,73 @@ func (t Type) pack(v reflect.Value) ([]byte, error) {
 		return nil, err
 	}
 
-	if t.T == SliceTy || t.T == ArrayTy {
-		var packed []byte
+	switch t.T {
+	case SliceTy, ArrayTy:
+		var ret []byte
 
+		if t.requiresLengthPrefix() {
+			// pack the length of a slice first
+			packedLen, err := packNum(reflect.ValueOf(v.Len()))
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, packedLen...)
+		}
+
+		// dynamic elements are packed as offsets, the contents are appended at the end
+		offsetReq := isDynamicType(*t.Elem)
+		offset := 0
+		if offsetReq {
+			offset = getTypeSize(*t.Elem) * v.Len()
+		}
+		var tail []byte
 		for i := 0; i < v.Len(); i++ {
 			val, err := t.Elem.pack(v.Index(i))
 			if err != nil {
 				return nil, err
 			}
-			packed = append(packed, val...)
+			if !offsetReq {
+				ret = append(ret, val...)
+				continue
+			}
+			packedOffset, err := packNum(reflect.ValueOf(offset))
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, packedOffset...)
+			offset += len(val)
+			tail = append(tail, val...)
+		}
+		return append(ret, tail...), nil
+	case TupleTy:
+		offset := 0
+		for _, elem := range t.TupleElems {
+			offset += getTypeSize(*elem)
 		}
-		if t.T == SliceTy {
-			return packBytesSlice(packed, v.Len())
-		} else if t.T == ArrayTy {
-			return packed, nil
+
+		var ret, tail []byte
+		for i, elem := range t.TupleElems {
+			field, err := tupleFieldValue(t, v, i)
+			if err != nil {
+				return nil, err
+			}
+			val, err := elem.pack(field)
+			if err != nil {
+				return nil, err
+			}
+			if !isDynamicType(*elem) {
+				ret = append(ret, val...)
+				continue
+			}
+			packedOffset, err := packNum(reflect.ValueOf(offset))
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, packedOffset...)
+			offset += len(val)
+			tail = append(tail, val...)
 		}
+		return append(ret, tail...), nil
 	}
 	return packElement(t, v)
 }
@@ -198,3 +304,49 @@ func (t Type) pack(v reflect.Value) ([]byte, error) {
 func (t Type) requiresLengthPrefix() bool {
 	return t.T == StringTy || t.T == BytesTy || t.T == SliceTy
 }
+
+// isDynamicType returns true if the type is dynamic, the contents of a dynamic type
+// are packed at the end and referenced by an offset.
+func isDynamicType(t Type) bool {
+	switch t.T {
+	case StringTy, BytesTy, SliceTy:
+		return true
+	case ArrayTy:
+		return isDynamicType(*t.Elem)
+	case TupleTy:
+		for _, elem := range t.TupleElems {
+			if isDynamicType(*elem) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getTypeSize returns the size that this type needs to occupy in the head part,
+// static arrays and static tuples are packed inline, the others take a word.
+func getTypeSize(t Type) int {
+	if t.T == ArrayTy && !isDynamicType(*t.Elem) {
+		return t.Size * getTypeSize(*t.Elem)
+	} else if t.T == TupleTy && !isDynamicType(t) {
+		total := 0
+		for _, elem := range t.TupleElems {
+			total += getTypeSize(*elem)
+		}
+		return total
+	}
+	return helper.WordSize
+}
+
+// isValidFieldName checks if name is an exported go identifier.
+func isValidFieldName(name string) bool {
+	for i, c := range name {
+		if i == 0 && !unicode.IsUpper(c) {
+			return false
+		}
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' {
+			return false
+		}
+	}
+	return len(name) > 0
+}
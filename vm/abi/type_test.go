@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/vitelabs/go-vite/v2/common/helper"
 	"github.com/vitelabs/go-vite/v2/common/types"
 )
 
@@ -261,3 +262,59 @@ func TestTypeCheck(t *testing.T) {
 		}
 	}
 }
+
+func TestNewTupleType(t *testing.T) {
+	components := []ArgumentMarshaling{
+		{Name: "amount", Type: "uint256"},
+		{Name: "owners", Type: "address[]"},
+		{Name: "meta", Type: "tuple", Components: []ArgumentMarshaling{{Name: "id", Type: "uint64"}}},
+	}
+
+	typ, err := NewTupleType("tuple", components)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ.T != TupleTy || typ.Kind != reflect.Struct {
+		t.Fatalf("expected tuple type, got %v %v", typ.T, typ.Kind)
+	}
+	if typ.String() != "(uint256,address[],(uint64))" {
+		t.Errorf("unexpected signature %s", typ.String())
+	}
+	if !reflect.DeepEqual(typ.TupleRawNames, []string{"amount", "owners", "meta"}) {
+		t.Errorf("unexpected raw names %v", typ.TupleRawNames)
+	}
+	for i, name := range []string{"Amount", "Owners", "Meta"} {
+		if field := typ.Type.Field(i); field.Name != name || field.Type != typ.TupleElems[i].Type {
+			t.Errorf("unexpected field %d: %s %v", i, field.Name, field.Type)
+		}
+	}
+	if !isDynamicType(typ) {
+		t.Error("tuple with a slice should be dynamic")
+	}
+
+	typ, err = NewTupleType("tuple[2]", components[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ.T != ArrayTy || typ.Elem.T != TupleTy || typ.String() != "(uint256)[2]" {
+		t.Errorf("unexpected tuple array %v %s", typ.T, typ.String())
+	}
+	if isDynamicType(typ) || getTypeSize(typ) != 2*helper.WordSize {
+		t.Errorf("static tuple array should be packed inline, size %d", getTypeSize(typ))
+	}
+
+	for _, test := range []struct {
+		typ        string
+		components []ArgumentMarshaling
+		err        string
+	}{
+		{"tuple", nil, "abi: unsupported arg type: tuple"},
+		{"tuple", []ArgumentMarshaling{{Name: "a-b", Type: "bool"}}, "abi: invalid tuple field name 'a-b'"},
+		{"tuple", []ArgumentMarshaling{{Name: "a", Type: "bool"}, {Name: "_a", Type: "bool"}}, "abi: duplicate tuple field name '_a'"},
+		{"tuple", []ArgumentMarshaling{{Name: "a", Type: "uint"}}, "abi: unsupported arg type: uint"},
+	} {
+		if _, err := NewTupleType(test.typ, test.components); err == nil || err.Error() != test.err {
+			t.Errorf("expected err '%s', got '%v'", test.err, err)
+		}
+	}
+}
@@ -64,23 +64,16 @@ func readFixedBytes(t Type, word []byte) (interface{}, error) {
 
 }
 
-func getFullElemSize(elem *Type) int {
-	//all other should be counted as 32 (slices have pointers to respective elements)
-	size := helper.WordSize
-	//arrays wrap it, each element being the same size
-	for elem.T == ArrayTy {
-		size *= elem.Size
-		elem = elem.Elem
-	}
-	return size
-}
-
 // iteratively unpack elements
 func forEachUnpack(t Type, output []byte, start, size int) (interface{}, error) {
 	if size < 0 {
 		return nil, errNegativeInputSize(size)
 	}
-	if start+helper.WordSize*size > len(output) {
+
+	// Static arrays and static tuples are packed inline, resulting in longer unpack steps.
+	// The others have just 32 bytes per element (pointing to the contents).
+	elemSize := getTypeSize(*t.Elem)
+	if start+elemSize*size > len(output) {
 		return nil, errArrayOffsetOverflow(output, start, size)
 	}
 
@@ -97,13 +90,6 @@ func forEachUnpack(t Type, output []byte, start, size int) (interface{}, error)
 		return nil, errInvalidlArrayType
 	}
 
-	// Arrays have packed elements, resulting in longer unpack steps.
-	// Slices have just 32 bytes per element (pointing to the contents).
-	elemSize := helper.WordSize
-	if t.T == ArrayTy {
-		elemSize = getFullElemSize(t.Elem)
-	}
-
 	for i, j := start, 0; j < size; i, j = i+elemSize, j+1 {
 
 		inter, err := toGoType(i, *t.Elem, output)
@@ -119,6 +105,24 @@ func forEachUnpack(t Type, output []byte, start, size int) (interface{}, error)
 	return refSlice.Interface(), nil
 }
 
+// unpack the fields of a tuple into the struct type of t
+func forTupleUnpack(t Type, output []byte) (interface{}, error) {
+	retval := reflect.New(t.Type).Elem()
+	virtualArgs := 0
+	for index, elem := range t.TupleElems {
+		marshalledValue, err := toGoType((index+virtualArgs)*helper.WordSize, *elem, output)
+		if err != nil {
+			return nil, err
+		}
+		if (elem.T == ArrayTy || elem.T == TupleTy) && !isDynamicType(*elem) {
+			// static arrays and static tuples are packed inline, see Arguments.UnpackValues
+			virtualArgs += getTypeSize(*elem)/helper.WordSize - 1
+		}
+		retval.Field(index).Set(reflect.ValueOf(marshalledValue))
+	}
+	return retval.Interface(), nil
+}
+
 // toGoType parses the output bytes and recursively assigns the value of these bytes
 // into a go type with accordance with the ABI spec.
 func toGoType(index int, t Type, output []byte) (interface{}, error) {
@@ -143,9 +147,26 @@ func toGoType(index int, t Type, output []byte) (interface{}, error) {
 	}
 
 	switch t.T {
+	case TupleTy:
+		if isDynamicType(t) {
+			begin, err := offsetPointsTo(index, output)
+			if err != nil {
+				return nil, err
+			}
+			return forTupleUnpack(t, output[begin:])
+		}
+		return forTupleUnpack(t, output[index:])
 	case SliceTy:
-		return forEachUnpack(t, output, begin, end)
+		// the offsets of dynamic elements are relative to the start of the slice contents
+		return forEachUnpack(t, output[begin:], 0, end)
 	case ArrayTy:
+		if isDynamicType(*t.Elem) {
+			begin, err := offsetPointsTo(index, output)
+			if err != nil {
+				return nil, err
+			}
+			return forEachUnpack(t, output[begin:], 0, t.Size)
+		}
 		return forEachUnpack(t, output, index, t.Size)
 	case StringTy: // variable arrays are written at the end of the return bytes
 		return string(output[begin : begin+end]), nil
@@ -208,3 +229,15 @@ func lengthPrefixPointsTo(index int, output []byte) (start int, length int, err
 	length = int(lengthBig.Uint64())
 	return
 }
+
+// interprets a 32 byte slice as an offset to the contents of a dynamic tuple or a dynamic array.
+func offsetPointsTo(index int, output []byte) (start int, err error) {
+	offset := new(big.Int).SetBytes(output[index : index+helper.WordSize])
+	if offset.Cmp(big.NewInt(int64(len(output)))) > 0 {
+		return 0, errBigSliceOffsetOverflow(offset, big.NewInt(int64(len(output))))
+	}
+	if offset.BitLen() > 63 {
+		return 0, errBigOffsetOverflow(offset)
+	}
+	return int(offset.Uint64()), nil
+}
@@ -3,6 +3,7 @@ package abi
 import (
 	"bytes"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"reflect"
@@ -731,3 +732,101 @@ func TestOOMMaliciousInput(t *testing.T) {
 		}
 	}
 }
+
+func TestUnpackTuple(t *testing.T) {
+	const definition = `[
+	{"type":"function","name":"submit","inputs":[
+		{"name":"order","type":"tuple","components":[
+			{"name":"id","type":"uint64"},
+			{"name":"memo","type":"string"},
+			{"name":"owner","type":"tuple","components":[{"name":"addr","type":"address"},{"name":"tags","type":"bytes32[]"}]}
+		]},
+		{"name":"fills","type":"tuple[]","components":[{"name":"price","type":"uint256"},{"name":"note","type":"string"}]},
+		{"name":"flag","type":"bool"}
+	]},
+	{"type":"offchain","name":"getOrder","inputs":[],"outputs":[
+		{"name":"order","type":"tuple","components":[{"name":"id","type":"uint64"},{"name":"price","type":"uint256"}]},
+		{"name":"count","type":"uint8"}
+	]}]`
+
+	type owner struct {
+		Addr types.Address
+		Tags [][32]byte
+	}
+	type order struct {
+		Id    uint64
+		Memo  string
+		Owner owner
+	}
+	type fill struct {
+		Price *big.Int
+		Text  string `abi:"note"`
+	}
+
+	abi, err := JSONToABIContract(strings.NewReader(definition))
+	require.NoError(t, err)
+	require.Equal(t, "submit((uint64,string,(address,bytes32[])),(uint256,string)[],bool)", abi.Methods["submit"].Sig())
+
+	addr, _ := types.BytesToAddress(helper.LeftPadBytes([]byte{1}, types.AddressSize))
+	in := order{Id: 7, Memo: "hello", Owner: owner{Addr: addr, Tags: [][32]byte{{1}, {2}}}}
+	fills := []fill{{big.NewInt(100), "first"}, {big.NewInt(200), "second"}}
+
+	data, err := abi.PackMethod("submit", in, fills, true)
+	require.NoError(t, err)
+
+	// unpack into go structs
+	var out struct {
+		Order order
+		Fills []fill
+		Flag  bool
+	}
+	require.NoError(t, abi.UnpackMethod(&out, "submit", data))
+	require.Equal(t, in, out.Order)
+	require.Equal(t, fills, out.Fills)
+	require.True(t, out.Flag)
+
+	// unpack into a map
+	m := make(map[string]interface{})
+	require.NoError(t, abi.Methods["submit"].Inputs.UnpackIntoMap(m, data[4:]))
+	require.Equal(t, map[string]interface{}{
+		"id":   uint64(7),
+		"memo": "hello",
+		"owner": map[string]interface{}{
+			"addr": addr,
+			"tags": [][32]byte{{1}, {2}},
+		},
+	}, m["order"])
+	require.Equal(t, []map[string]interface{}{
+		{"price": big.NewInt(100), "note": "first"},
+		{"price": big.NewInt(200), "note": "second"},
+	}, m["fills"])
+	require.Equal(t, true, m["flag"])
+
+	// pack from the map gives the same bytes
+	repacked, err := abi.PackMethod("submit", m["order"], m["fills"], m["flag"])
+	require.NoError(t, err)
+	require.Equal(t, data, repacked)
+
+	// direct unpack
+	params, err := abi.DirectUnpackMethodInput("submit", data)
+	require.NoError(t, err)
+	require.Len(t, params, 3)
+	require.Equal(t, `{"id":7,"memo":"hello","owner":{"addr":"`+addr.String()+`","tags":[`+
+		`[1,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0],`+
+		`[2,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0]]}}`, toJSON(t, params[0]))
+
+	// static tuple in offchain outputs
+	output := helper.HexToBytes("0000000000000000000000000000000000000000000000000000000000000003" +
+		"0000000000000000000000000000000000000000000000000000000000000064" +
+		"0000000000000000000000000000000000000000000000000000000000000002")
+	params, err = abi.DirectUnpackOffchainOutput("getOrder", output)
+	require.NoError(t, err)
+	require.Equal(t, `{"id":3,"price":100}`, toJSON(t, params[0]))
+	require.Equal(t, uint8(2), params[1])
+}
+
+func toJSON(t *testing.T, v interface{}) string {
+	buf, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(buf)
+}
@@ -0,0 +1,86 @@
+package dex
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// DepthLevel is the sum of the remaining orders at an aggregated price.
+type DepthLevel struct {
+	Price      []byte
+	Quantity   *big.Int
+	Amount     *big.Int
+	OrderCount int
+}
+
+const (
+	minDepthPrecision = -(priceIntMaxLen - 1)
+	maxDepthPrecision = priceDecimalMaxLen
+)
+
+var priceDecimalUnit = new(big.Int).Exp(big.NewInt(10), big.NewInt(priceDecimalMaxLen), nil)
+
+// GetMarketDepth walks the book of side from the best price and aggregates the orders into at most limit levels,
+// the price of a level keeps precision decimals, a negative precision rounds the integer part.
+// Sell prices are rounded up and buy prices are rounded down, so a level never looks better than its orders.
+func (mc *Matcher) GetMarketDepth(side bool, precision int32, limit int) ([]*DepthLevel, error) {
+	if err := ValidDepthPrecision(precision); err != nil {
+		return nil, err
+	}
+	book, err := getMakerBook(mc.db, mc.MarketInfo.MarketId, side)
+	if err != nil {
+		return nil, err
+	}
+	defer book.release()
+	return aggregateDepth(book.nextOrder, side, precision, limit), nil
+}
+
+func ValidDepthPrecision(precision int32) error {
+	if precision < minDepthPrecision || precision > maxDepthPrecision {
+		return fmt.Errorf("precision must be in [%d, %d]", minDepthPrecision, maxDepthPrecision)
+	}
+	return nil
+}
+
+func aggregateDepth(nextOrder func() (*Order, bool), side bool, precision int32, limit int) []*DepthLevel {
+	levels := make([]*DepthLevel, 0)
+	var level *DepthLevel
+	for {
+		order, ok := nextOrder()
+		if !ok {
+			break
+		}
+		price := AggregatePrice(order.Price, precision, side)
+		if level == nil || string(level.Price) != string(price) {
+			if len(levels) >= limit {
+				break
+			}
+			level = &DepthLevel{Price: price, Quantity: new(big.Int), Amount: new(big.Int)}
+			levels = append(levels, level)
+		}
+		level.Quantity.Add(level.Quantity, SubBigInt(order.Quantity, order.ExecutedQuantity))
+		level.Amount.Add(level.Amount, SubBigInt(order.Amount, order.ExecutedAmount))
+		level.OrderCount++
+	}
+	return levels
+}
+
+// AggregatePrice rounds the price bytes to precision decimals, it rounds up if roundUp is true, otherwise down.
+func AggregatePrice(price []byte, precision int32, roundUp bool) []byte {
+	value := new(big.Int).SetBytes(price[:5])
+	value.Mul(value, priceDecimalUnit)
+	value.Add(value, new(big.Int).SetBytes(price[5:PriceBytesLength]))
+
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(priceDecimalMaxLen-precision)), nil)
+	rounded, rem := new(big.Int).QuoRem(value, unit, new(big.Int))
+	if roundUp && rem.Sign() > 0 {
+		rounded.Add(rounded, big.NewInt(1))
+	}
+	rounded.Mul(rounded, unit)
+
+	intPart, decimalPart := new(big.Int).QuoRem(rounded, priceDecimalUnit, new(big.Int))
+	result := make([]byte, PriceBytesLength)
+	intPart.FillBytes(result[:5])
+	decimalPart.FillBytes(result[5:])
+	return result
+}
@@ -75,3 +75,79 @@ func TestSafeSubBigInt(t *testing.T) {
 	assert.True(t, bytes.Equal(actualSub, amt))
 	assert.True(t, exceed)
 }
+
+func TestAggregatePrice(t *testing.T) {
+	for _, c := range []struct {
+		price     string
+		precision int32
+		roundUp   bool
+		expected  string
+	}{
+		{"176.0856", 2, false, "176.08"},
+		{"176.0856", 2, true, "176.09"},
+		{"176.08", 2, true, "176.08"},
+		{"176.0856", 0, true, "177"},
+		{"176.0856", -1, false, "170"},
+		{"176.0856", -2, true, "200"},
+		{"0.000000000001", 12, false, "0.000000000001"},
+		{"0.000000000001", 11, false, "0"},
+		{"999999999999.5", -11, true, "1000000000000"},
+	} {
+		assert.Equal(t, c.expected, BytesToPrice(AggregatePrice(PriceToBytes(c.price), c.precision, c.roundUp)), "%s %d %v", c.price, c.precision, c.roundUp)
+	}
+	assert.NoError(t, ValidDepthPrecision(-11))
+	assert.Error(t, ValidDepthPrecision(-12))
+	assert.Error(t, ValidDepthPrecision(13))
+}
+
+func TestAggregateDepth(t *testing.T) {
+	newOrder := func(price string, quantity, amount, executedQuantity, executedAmount int64) *Order {
+		order := &Order{}
+		order.Price = PriceToBytes(price)
+		order.Quantity = big.NewInt(quantity).Bytes()
+		order.Amount = big.NewInt(amount).Bytes()
+		order.ExecutedQuantity = big.NewInt(executedQuantity).Bytes()
+		order.ExecutedAmount = big.NewInt(executedAmount).Bytes()
+		return order
+	}
+	iterate := func(orders ...*Order) func() (*Order, bool) {
+		return func() (*Order, bool) {
+			if len(orders) == 0 {
+				return nil, false
+			}
+			order := orders[0]
+			orders = orders[1:]
+			return order, true
+		}
+	}
+
+	// buy orders from the highest price
+	levels := aggregateDepth(iterate(
+		newOrder("1.25", 100, 125, 40, 50),
+		newOrder("1.21", 10, 12, 0, 0),
+		newOrder("1.19", 20, 23, 0, 0),
+		newOrder("1.1", 30, 33, 0, 0),
+	), false, 1, 2)
+	assert.Equal(t, 2, len(levels))
+	assert.Equal(t, "1.2", BytesToPrice(levels[0].Price))
+	assert.Equal(t, int64(70), levels[0].Quantity.Int64())
+	assert.Equal(t, int64(87), levels[0].Amount.Int64())
+	assert.Equal(t, 2, levels[0].OrderCount)
+	assert.Equal(t, "1.1", BytesToPrice(levels[1].Price))
+	assert.Equal(t, int64(50), levels[1].Quantity.Int64())
+	assert.Equal(t, 2, levels[1].OrderCount)
+
+	// sell orders from the lowest price
+	levels = aggregateDepth(iterate(
+		newOrder("1.11", 10, 11, 0, 0),
+		newOrder("1.2", 20, 24, 0, 0),
+		newOrder("1.21", 30, 36, 0, 0),
+	), true, 1, 10)
+	assert.Equal(t, 2, len(levels))
+	assert.Equal(t, "1.2", BytesToPrice(levels[0].Price))
+	assert.Equal(t, 2, levels[0].OrderCount)
+	assert.Equal(t, "1.3", BytesToPrice(levels[1].Price))
+	assert.Equal(t, int64(30), levels[1].Quantity.Int64())
+
+	assert.Equal(t, 0, len(aggregateDepth(iterate(), true, 1, 10)))
+}
@@ -622,6 +622,7 @@ func opSLoad(pc *uint64, vm *VM, c *contract, mem *memory, stack *stack) ([]byte
 func opSStore(pc *uint64, vm *VM, c *contract, mem *memory, stack *stack) ([]byte, error) {
 	loc, val := stack.pop(), stack.pop()
 	locHash, _ := types.BigToHash(loc)
+	vm.captureStorage(c, locHash.Bytes(), val.Bytes())
 	util.SetValue(c.db, locHash.Bytes(), val.Bytes())
 
 	c.intPool.Put(loc, val)
@@ -59,10 +59,12 @@ func (i *interpreter) runLoop(vm *VM, c *contract) (ret []byte, err error) {
 
 		if !operation.valid {
 			nodeConfig.log.Error("invalid opcode", "op", int(op))
+			vm.captureFault(currentPc, op, 0, c.quotaLeft, util.ErrInvalidOpCode)
 			return nil, util.ErrInvalidOpCode
 		}
 
 		if err := operation.validateStack(st); err != nil {
+			vm.captureFault(currentPc, op, 0, c.quotaLeft, err)
 			return nil, err
 		}
 
@@ -70,19 +72,24 @@ func (i *interpreter) runLoop(vm *VM, c *contract) (ret []byte, err error) {
 		if operation.memorySize != nil {
 			memSize, overflow := helper.BigUint64(operation.memorySize(st))
 			if overflow {
+				vm.captureFault(currentPc, op, 0, c.quotaLeft, util.ErrMemSizeOverflow)
 				return nil, util.ErrMemSizeOverflow
 			}
 			if memorySize, overflow = helper.SafeMul(helper.ToWordSize(memSize), helper.WordSize); overflow {
+				vm.captureFault(currentPc, op, 0, c.quotaLeft, util.ErrMemSizeOverflow)
 				return nil, util.ErrMemSizeOverflow
 			}
 		}
 
 		cost, flag, err = operation.gasCost(vm, c, st, mem, memorySize)
 		if err != nil {
+			vm.captureFault(currentPc, op, cost, c.quotaLeft, err)
 			return nil, err
 		}
+		quotaLeft := c.quotaLeft
 		c.quotaLeft, err = util.UseQuotaWithFlag(c.quotaLeft, cost, flag)
 		if err != nil {
+			vm.captureFault(currentPc, op, cost, quotaLeft, err)
 			return nil, err
 		}
 
@@ -90,6 +97,10 @@ func (i *interpreter) runLoop(vm *VM, c *contract) (ret []byte, err error) {
 			mem.resize(memorySize)
 		}
 
+		if vm.tracer != nil {
+			vm.tracer.CaptureState(currentPc, opCodeToString[op], cost, c.quotaLeft, st.data, mem.store)
+		}
+
 		res, err := operation.execute(&pc, vm, c, mem, st)
 
 		if nodeConfig.IsDebug {
@@ -121,10 +132,12 @@ func (i *interpreter) runLoop(vm *VM, c *contract) (ret []byte, err error) {
 
 		switch {
 		case err != nil:
+			vm.captureFault(currentPc, op, cost, c.quotaLeft, err)
 			return nil, err
 		case operation.halts:
 			return res, nil
 		case operation.reverts:
+			vm.captureRevert(res)
 			return res, util.ErrExecutionReverted
 		case !operation.jumps:
 			pc++
@@ -132,3 +145,22 @@ func (i *interpreter) runLoop(vm *VM, c *contract) (ret []byte, err error) {
 	}
 	panic(util.ErrExecutionCanceled)
 }
+
+func (vm *VM) captureFault(pc uint64, op opCode, cost, quotaLeft uint64, err error) {
+	if vm.tracer != nil {
+		vm.tracer.CaptureFault(pc, opCodeToString[op], cost, quotaLeft, err)
+	}
+}
+
+// captureStorage passes the value of key before SSTORE writes it
+func (vm *VM) captureStorage(c *contract, key, value []byte) {
+	if tracer, ok := vm.tracer.(StorageTracer); ok {
+		tracer.CaptureStorage(key, util.GetValue(c.db, key), value)
+	}
+}
+
+func (vm *VM) captureRevert(data []byte) {
+	if tracer, ok := vm.tracer.(RevertTracer); ok {
+		tracer.CaptureRevert(data)
+	}
+}
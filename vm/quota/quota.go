@@ -293,6 +293,11 @@ func CalcPoWDifficulty(db quotaDb, quotaRequired uint64, q types.Quota, sbHeight
 	return difficultyByQc, err
 }
 
+// MaxPoWDifficulty returns the difficulty of the max quota of a block without congestion
+func MaxPoWDifficulty() *big.Int {
+	return new(big.Int).Set(quotaConfig.difficultyList[sectionLen])
+}
+
 // CalcStakeAmountByQuota calculate stake amount by expected quota used per second
 func CalcStakeAmountByQuota(q uint64) (*big.Int, error) {
 	if q > getMaxQuota() {
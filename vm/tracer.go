@@ -0,0 +1,255 @@
+package vm
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/vitelabs/go-vite/v2/common/helper"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+// Tracer observes the execution of an account block. The interpreter calls
+// CaptureState before executing each opcode, stack and memory are only valid
+// during the call and must be copied if they are kept.
+type Tracer interface {
+	CaptureStart(block *ledger.AccountBlock, sendBlock *ledger.AccountBlock)
+	CaptureState(pc uint64, op string, cost, quotaLeft uint64, stack []*big.Int, memory []byte)
+	CaptureFault(pc uint64, op string, cost, quotaLeft uint64, err error)
+	CaptureEnd(vmBlock *interfaces.VmAccountBlock, err error)
+}
+
+// RevertTracer is implemented by tracers which need the data passed to REVERT,
+// the vm discards it from the account block.
+type RevertTracer interface {
+	CaptureRevert(data []byte)
+}
+
+// StorageTracer is implemented by tracers which need the storage written by SSTORE,
+// prev is the value of the key before the write.
+type StorageTracer interface {
+	CaptureStorage(key, prev, value []byte)
+}
+
+// SetTracer sets the tracer to observe the next execution, nil disables tracing.
+func (vm *VM) SetTracer(tracer Tracer) {
+	vm.tracer = tracer
+}
+
+// StructLoggerConfig limits the size of the struct logs.
+type StructLoggerConfig struct {
+	DisableStack   bool `json:"disableStack"`
+	DisableMemory  bool `json:"disableMemory"`
+	DisableStorage bool `json:"disableStorage"`
+	// Limit is the max count of steps to record, 0 means unlimited
+	Limit int `json:"limit"`
+}
+
+// StructLog is the state of the vm before executing an opcode.
+type StructLog struct {
+	Pc        uint64                  `json:"pc"`
+	Op        string                  `json:"op"`
+	QuotaCost uint64                  `json:"quotaCost"`
+	QuotaLeft uint64                  `json:"quotaLeft"`
+	Stack     []string                `json:"stack,omitempty"`
+	Memory    []string                `json:"memory,omitempty"`
+	Storage   map[string]*StorageDiff `json:"storage,omitempty"`
+	Error     string                  `json:"error,omitempty"`
+}
+
+// StorageDiff is a storage value changed by SSTORE.
+type StorageDiff struct {
+	Prev  string `json:"prev"`
+	Value string `json:"value"`
+}
+
+// StructLogResult is the result of StructLogger.
+type StructLogResult struct {
+	Failed    bool         `json:"failed"`
+	Error     string       `json:"error,omitempty"`
+	QuotaUsed uint64       `json:"quotaUsed"`
+	Steps     []*StructLog `json:"steps"`
+}
+
+// StructLogger records the state of every step of the execution,
+// the storage of a step is the diff written by SSTORE. The diffs are dropped
+// if the execution fails, because the vm reverts the storage of the block.
+type StructLogger struct {
+	cfg    StructLoggerConfig
+	result StructLogResult
+
+	// the last recorded step, nil if the step is over the limit
+	lastStep     *StructLog
+	storageSteps []*StructLog
+}
+
+func NewStructLogger(cfg *StructLoggerConfig) *StructLogger {
+	logger := &StructLogger{result: StructLogResult{Steps: make([]*StructLog, 0)}}
+	if cfg != nil {
+		logger.cfg = *cfg
+	}
+	return logger
+}
+
+func (l *StructLogger) CaptureStart(block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) {
+}
+
+func (l *StructLogger) CaptureState(pc uint64, op string, cost, quotaLeft uint64, stack []*big.Int, memory []byte) {
+	l.lastStep = nil
+	if l.cfg.Limit > 0 && len(l.result.Steps) >= l.cfg.Limit {
+		return
+	}
+	step := &StructLog{Pc: pc, Op: op, QuotaCost: cost, QuotaLeft: quotaLeft}
+	if !l.cfg.DisableStack {
+		step.Stack = make([]string, len(stack))
+		for i, item := range stack {
+			step.Stack[i] = item.Text(16)
+		}
+	}
+	if !l.cfg.DisableMemory {
+		step.Memory = make([]string, 0, len(memory)/32)
+		for i := 0; i+32 <= len(memory); i += 32 {
+			step.Memory = append(step.Memory, hex.EncodeToString(memory[i:i+32]))
+		}
+	}
+	l.result.Steps = append(l.result.Steps, step)
+	l.lastStep = step
+}
+
+func (l *StructLogger) CaptureStorage(key, prev, value []byte) {
+	if l.cfg.DisableStorage || l.lastStep == nil {
+		return
+	}
+	l.lastStep.Storage = map[string]*StorageDiff{
+		hex.EncodeToString(helper.LeftPadBytes(key, types.HashSize)): {
+			Prev:  hex.EncodeToString(helper.LeftPadBytes(prev, types.HashSize)),
+			Value: hex.EncodeToString(helper.LeftPadBytes(value, types.HashSize)),
+		},
+	}
+	l.storageSteps = append(l.storageSteps, l.lastStep)
+}
+
+// CaptureRevert drops the storage diffs, REVERT undoes the writes of the block.
+func (l *StructLogger) CaptureRevert(data []byte) {
+	l.dropStorage()
+}
+
+func (l *StructLogger) dropStorage() {
+	for _, step := range l.storageSteps {
+		step.Storage = nil
+	}
+	l.storageSteps = nil
+}
+
+func (l *StructLogger) CaptureFault(pc uint64, op string, cost, quotaLeft uint64, err error) {
+	if l.cfg.Limit > 0 && len(l.result.Steps) >= l.cfg.Limit {
+		return
+	}
+	l.result.Steps = append(l.result.Steps, &StructLog{Pc: pc, Op: op, QuotaCost: cost, QuotaLeft: quotaLeft, Error: err.Error()})
+}
+
+func (l *StructLogger) CaptureEnd(vmBlock *interfaces.VmAccountBlock, err error) {
+	if vmBlock != nil {
+		l.result.QuotaUsed = vmBlock.AccountBlock.QuotaUsed
+	}
+	if err != nil {
+		l.result.Failed = true
+		l.result.Error = err.Error()
+		l.dropStorage()
+	}
+}
+
+func (l *StructLogger) Result() *StructLogResult {
+	return &l.result
+}
+
+// CallFrame is an account block and the send blocks it triggers.
+type CallFrame struct {
+	BlockType byte              `json:"blockType"`
+	Hash      types.Hash        `json:"hash"`
+	From      types.Address     `json:"from"`
+	To        types.Address     `json:"to"`
+	TokenId   types.TokenTypeId `json:"tokenId"`
+	Amount    string            `json:"amount"`
+	Data      []byte            `json:"data"`
+	QuotaUsed uint64            `json:"quotaUsed"`
+	Error     string            `json:"error,omitempty"`
+	Calls     []*CallFrame      `json:"calls,omitempty"`
+}
+
+// CallTracer records the executed block and the send blocks created by a contract.
+// Contracts call each other asynchronously, so the tree has at most two levels.
+type CallTracer struct {
+	block     *ledger.AccountBlock
+	sendBlock *ledger.AccountBlock
+	vmBlock   *interfaces.VmAccountBlock
+	err       error
+}
+
+func NewCallTracer() *CallTracer {
+	return &CallTracer{}
+}
+
+func (t *CallTracer) CaptureStart(block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) {
+	t.block = block
+	t.sendBlock = sendBlock
+}
+
+func (t *CallTracer) CaptureState(pc uint64, op string, cost, quotaLeft uint64, stack []*big.Int, memory []byte) {
+}
+
+func (t *CallTracer) CaptureFault(pc uint64, op string, cost, quotaLeft uint64, err error) {
+}
+
+func (t *CallTracer) CaptureEnd(vmBlock *interfaces.VmAccountBlock, err error) {
+	t.vmBlock = vmBlock
+	t.err = err
+}
+
+// Result builds the call tree, it should be called after the hash of the blocks are computed.
+func (t *CallTracer) Result() *CallFrame {
+	block := t.block
+	if t.vmBlock != nil {
+		block = t.vmBlock.AccountBlock
+	}
+	if block == nil {
+		return nil
+	}
+
+	frame := newCallFrame(block)
+	if block.IsReceiveBlock() && t.sendBlock != nil {
+		frame.From = t.sendBlock.AccountAddress
+		frame.To = block.AccountAddress
+		frame.TokenId = t.sendBlock.TokenId
+		frame.Amount = amountString(t.sendBlock.Amount)
+		frame.Data = t.sendBlock.Data
+	}
+	if t.err != nil {
+		frame.Error = t.err.Error()
+	}
+	for _, sendBlock := range block.SendBlockList {
+		frame.Calls = append(frame.Calls, newCallFrame(sendBlock))
+	}
+	return frame
+}
+
+func newCallFrame(block *ledger.AccountBlock) *CallFrame {
+	return &CallFrame{
+		BlockType: block.BlockType,
+		Hash:      block.Hash,
+		From:      block.AccountAddress,
+		To:        block.ToAddress,
+		TokenId:   block.TokenId,
+		Amount:    amountString(block.Amount),
+		Data:      block.Data,
+		QuotaUsed: block.QuotaUsed,
+	}
+}
+
+func amountString(amount *big.Int) string {
+	if amount == nil {
+		return "0"
+	}
+	return amount.String()
+}
@@ -0,0 +1,143 @@
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/v2/vm/util"
+)
+
+func TestTracer(t *testing.T) {
+	initCustomFork(t)
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), util.AttovPerVite)
+	db, addr1, _, _, _, _ := prepareDb(viteTotalSupply)
+
+	// code stores 7 at 1, which is 5 before
+	addr2 := types.Address{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 3, 1}
+	db.codeMap[addr2] = []byte{1, byte(PUSH1), 7, byte(PUSH1), 1, byte(SSTORE), byte(STOP)}
+	loc, _ := types.BigToHash(big.NewInt(1))
+	db.storageMap[addr2] = map[string][]byte{ToKey(loc.Bytes()): {5}}
+	db.contractMetaMap[addr2] = &ledger.ContractMeta{Gid: types.DELEGATE_GID, SendConfirmedTimes: 0, QuotaRatio: 10}
+	db.accountBlockMap[addr2] = make(map[types.Hash]*ledger.AccountBlock)
+	db.storageMap[types.AddressQuota][ToKey(abi.GetStakeBeneficialKey(addr2))], _ = abi.ABIQuota.PackVariable(abi.VariableNameStakeBeneficial, new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18)))
+
+	sendBlock := &ledger.AccountBlock{
+		Height:         3,
+		AccountAddress: addr1,
+		BlockType:      ledger.BlockTypeSendCall,
+		Amount:         big.NewInt(0),
+		Fee:            big.NewInt(0),
+		TokenId:        ledger.ViteTokenId,
+		Hash:           types.DataHash([]byte{1, 3}),
+		ToAddress:      addr2,
+	}
+	receiveBlock := &ledger.AccountBlock{
+		Height:         1,
+		AccountAddress: addr2,
+		FromBlockHash:  sendBlock.Hash,
+		BlockType:      ledger.BlockTypeReceive,
+		Hash:           types.DataHash([]byte{2, 1}),
+	}
+
+	structLogger := NewStructLogger(nil)
+	vm := NewVM(nil)
+	vm.SetTracer(structLogger)
+	db.addr = addr2
+	vmBlock, _, err := vm.RunV2(db, receiveBlock, sendBlock, nil)
+	if err != nil || vmBlock == nil {
+		t.Fatalf("receive call failed, %v", err)
+	}
+
+	result := structLogger.Result()
+	ops := []string{"PUSH1", "PUSH1", "SSTORE", "STOP"}
+	if result.Failed || len(result.Steps) != len(ops) || result.QuotaUsed != vmBlock.AccountBlock.QuotaUsed {
+		t.Fatalf("unexpected struct log result %+v", result)
+	}
+	for i, op := range ops {
+		if result.Steps[i].Op != op {
+			t.Fatalf("step %d expected %s, got %s", i, op, result.Steps[i].Op)
+		}
+	}
+	sstore := result.Steps[2]
+	diff := sstore.Storage["0000000000000000000000000000000000000000000000000000000000000001"]
+	if len(sstore.Stack) != 2 || sstore.Stack[1] != "1" || diff == nil ||
+		diff.Prev != "0000000000000000000000000000000000000000000000000000000000000005" ||
+		diff.Value != "0000000000000000000000000000000000000000000000000000000000000007" {
+		t.Fatalf("unexpected sstore step %+v", sstore)
+	}
+
+	callTracer := NewCallTracer()
+	vm = NewVM(nil)
+	vm.SetTracer(callTracer)
+	if _, _, err := vm.RunV2(db, receiveBlock, sendBlock, nil); err != nil {
+		t.Fatal(err)
+	}
+	frame := callTracer.Result()
+	if frame.From != addr1 || frame.To != addr2 || frame.BlockType != ledger.BlockTypeReceive || len(frame.Calls) != 0 {
+		t.Fatalf("unexpected call frame %+v", frame)
+	}
+}
+
+type revertRecorder struct {
+	*StructLogger
+	data []byte
+}
+
+func (r *revertRecorder) CaptureRevert(data []byte) {
+	r.StructLogger.CaptureRevert(data)
+	r.data = append([]byte{}, data...)
+}
+
+func TestTracerCaptureRevert(t *testing.T) {
+	initCustomFork(t)
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), util.AttovPerVite)
+	db, addr1, _, _, _, _ := prepareDb(viteTotalSupply)
+
+	// code stores 7 at 1 and reverts with 32 bytes of 7
+	addr2 := types.Address{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 4, 1}
+	db.codeMap[addr2] = []byte{1, byte(PUSH1), 7, byte(PUSH1), 1, byte(SSTORE),
+		byte(PUSH1), 7, byte(PUSH1), 0, byte(MSTORE), byte(PUSH1), 32, byte(PUSH1), 0, byte(REVERT)}
+	db.contractMetaMap[addr2] = &ledger.ContractMeta{Gid: types.DELEGATE_GID, SendConfirmedTimes: 0, QuotaRatio: 10}
+	db.accountBlockMap[addr2] = make(map[types.Hash]*ledger.AccountBlock)
+	db.storageMap[types.AddressQuota][ToKey(abi.GetStakeBeneficialKey(addr2))], _ = abi.ABIQuota.PackVariable(abi.VariableNameStakeBeneficial, new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18)))
+
+	sendBlock := &ledger.AccountBlock{
+		Height:         3,
+		AccountAddress: addr1,
+		BlockType:      ledger.BlockTypeSendCall,
+		Amount:         big.NewInt(0),
+		Fee:            big.NewInt(0),
+		TokenId:        ledger.ViteTokenId,
+		Hash:           types.DataHash([]byte{1, 3}),
+		ToAddress:      addr2,
+	}
+	receiveBlock := &ledger.AccountBlock{
+		Height:         1,
+		AccountAddress: addr2,
+		FromBlockHash:  sendBlock.Hash,
+		BlockType:      ledger.BlockTypeReceive,
+		Hash:           types.DataHash([]byte{2, 2}),
+	}
+
+	recorder := &revertRecorder{StructLogger: NewStructLogger(nil)}
+	vm := NewVM(nil)
+	vm.SetTracer(recorder)
+	db.addr = addr2
+	if _, _, err := vm.RunV2(db, receiveBlock, sendBlock, nil); err != util.ErrExecutionReverted {
+		t.Fatalf("expected execution reverted, got %v", err)
+	}
+	if len(recorder.data) != 32 || recorder.data[31] != 7 {
+		t.Fatalf("unexpected revert data %x", recorder.data)
+	}
+	result := recorder.Result()
+	if !result.Failed || result.Error != util.ErrExecutionReverted.Error() || len(result.Steps) < 3 || result.Steps[2].Op != "SSTORE" {
+		t.Fatalf("unexpected struct log result %+v", result)
+	}
+	// the reverted write is not a diff
+	if result.Steps[2].Storage != nil {
+		t.Fatalf("unexpected storage of reverted sstore %+v", result.Steps[2])
+	}
+}
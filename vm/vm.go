@@ -136,6 +136,8 @@ type VM struct {
 	// latest snapshot block height, used for fork check
 	latestSnapshotHeight uint64
 	gasTable             *util.QuotaTable
+	// tracer observes the execution if it is not nil
+	tracer Tracer
 }
 
 // NewVM is a constructor of VM. This method is called before running an
@@ -188,7 +190,13 @@ func (vm *VM) RunV2(db interfaces.VmDb, block *ledger.AccountBlock, sendBlock *l
 		if nodeConfig.IsDebug {
 			printDebugBlockInfo(block, vmAccountBlock, err)
 		}
+		if vm.tracer != nil {
+			vm.tracer.CaptureEnd(vmAccountBlock, err)
+		}
 	}()
+	if vm.tracer != nil {
+		vm.tracer.CaptureStart(block, sendBlock)
+	}
 	if nodeConfig.IsDebug {
 		nodeConfig.log.Info("vm run start",
 			"blockType", block.BlockType,
@@ -13,6 +13,9 @@ func (vdb *vmDb) GetBalance(tokenTypeId *types.TokenTypeId) (*big.Int, error) {
 		}
 	}
 
+	if vdb.state != nil {
+		return vdb.state.GetBalance(tokenTypeId)
+	}
 	return vdb.chain.GetBalance(*vdb.address, *tokenTypeId)
 }
 
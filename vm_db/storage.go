@@ -9,6 +9,9 @@ func (vdb *vmDb) GetValue(key []byte) ([]byte, error) {
 	return vdb.GetOriginalValue(key)
 }
 func (vdb *vmDb) GetOriginalValue(key []byte) ([]byte, error) {
+	if vdb.state != nil {
+		return vdb.state.GetValue(key)
+	}
 	return vdb.chain.GetValue(*vdb.address, key)
 }
 
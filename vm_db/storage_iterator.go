@@ -7,9 +7,14 @@ import (
 
 // Cannot be concurrent with write
 func (vdb *vmDb) NewStorageIterator(prefix []byte) (interfaces.StorageIterator, error) {
-	iter, err := vdb.chain.GetStorageIterator(*vdb.address, prefix)
-	if err != nil {
-		return nil, err
+	var iter interfaces.StorageIterator
+	if vdb.state != nil {
+		iter = vdb.state.NewStorageIterator(prefix)
+	} else {
+		var err error
+		if iter, err = vdb.chain.GetStorageIterator(*vdb.address, prefix); err != nil {
+			return nil, err
+		}
 	}
 
 	unsavedIter := vdb.unsaved().NewStorageIterator(prefix)
@@ -25,6 +25,9 @@ type vmDb struct {
 	prevAccountBlock     *ledger.AccountBlock // for cache
 
 	callDepth *uint16 // for cache
+
+	// history state of the address, the latest state of chain is read if it is nil
+	state interfaces.StateSnapshot
 }
 
 func NewVmDb(chain Chain, address *types.Address, latestSnapshotBlockHash *types.Hash, prevAccountBlockHash *types.Hash) (*vmDb, error) {
@@ -46,6 +49,20 @@ func NewVmDb(chain Chain, address *types.Address, latestSnapshotBlockHash *types
 	}, nil
 }
 
+// NewVmDbWithState is NewVmDb whose balance and storage of the address are read from state instead of the latest
+// state of chain, it is used to re-execute an account block on the state before it.
+func NewVmDbWithState(chain Chain, state interfaces.StateSnapshot, address *types.Address, latestSnapshotBlockHash *types.Hash, prevAccountBlockHash *types.Hash) (*vmDb, error) {
+	if state == nil {
+		return nil, errors.New("state is nil")
+	}
+	vdb, err := NewVmDb(chain, address, latestSnapshotBlockHash, prevAccountBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	vdb.state = state
+	return vdb, nil
+}
+
 func (vdb *vmDb) unsaved() *Unsaved {
 	if vdb.uns == nil {
 		vdb.uns = NewUnsaved()
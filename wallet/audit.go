@@ -0,0 +1,137 @@
+package wallet
+
+import (
+	"bufio"
+	"encoding/json"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+)
+
+const (
+	AuditActionUnlock   = "unlock"
+	AuditActionLock     = "lock"
+	AuditActionTransfer = "transfer"
+	AuditActionSignData = "signData"
+	AuditActionApprove  = "approve"
+	AuditActionExport   = "export"
+
+	AuditDecisionAllow   = "allow"
+	AuditDecisionDeny    = "deny"
+	AuditDecisionPending = "pending"
+)
+
+// AuditEntry is a line of the audit log
+type AuditEntry struct {
+	Time        time.Time          `json:"time"`
+	Action      string             `json:"action"`
+	Decision    string             `json:"decision,omitempty"`
+	EntropyFile string             `json:"entropyFile,omitempty"`
+	Address     *types.Address     `json:"address,omitempty"`
+	ToAddress   *types.Address     `json:"toAddress,omitempty"`
+	TokenId     *types.TokenTypeId `json:"tokenId,omitempty"`
+	Amount      string             `json:"amount,omitempty"`
+	ApprovalId  *types.Hash        `json:"approvalId,omitempty"`
+	Reason      string             `json:"reason,omitempty"`
+}
+
+func transferAuditEntry(req *TransferRequest) *AuditEntry {
+	address, toAddress, tokenId := req.Address, req.ToAddress, req.TokenId
+	return &AuditEntry{
+		Action:    AuditActionTransfer,
+		Address:   &address,
+		ToAddress: &toAddress,
+		TokenId:   &tokenId,
+		Amount:    req.Amount.String(),
+	}
+}
+
+// auditLog appends the entries to a file as json lines, an auditLog without a file only writes the log
+type auditLog struct {
+	file *os.File
+	mu   sync.Mutex
+}
+
+func openAuditLog(filename string) (*auditLog, error) {
+	if filename == "" {
+		return &auditLog{}, nil
+	}
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &auditLog{file: file}, nil
+}
+
+func (al *auditLog) Write(entry *AuditEntry) error {
+	if al.file == nil {
+		return nil
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	if _, err := al.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return al.file.Sync()
+}
+
+func (al *auditLog) Close() error {
+	if al.file == nil {
+		return nil
+	}
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	return al.file.Close()
+}
+
+// ReadAuditLog returns the entries of an audit log file, the broken lines are skipped
+func ReadAuditLog(filename string) ([]*AuditEntry, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []*AuditEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry := &AuditEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// restoreSpent counts the transfers allowed today in the audit log into the daily limits,
+// so a restart of the node does not reset them
+func restoreSpent(pe *policyEngine, filename string) error {
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return nil
+	}
+	entries, err := ReadAuditLog(filename)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Action != AuditActionTransfer || entry.Decision != AuditDecisionAllow ||
+			entry.Address == nil || entry.TokenId == nil {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(entry.Amount, 10)
+		if !ok {
+			continue
+		}
+		pe.addSpent(&TransferRequest{Address: *entry.Address, TokenId: *entry.TokenId, Amount: amount}, entry.Time)
+	}
+	return nil
+}
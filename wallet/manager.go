@@ -1,11 +1,13 @@
 package wallet
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/tyler-smith/go-bip39"
@@ -13,6 +15,7 @@ import (
 	"github.com/vitelabs/go-vite/v2/common/config"
 	walleterrors "github.com/vitelabs/go-vite/v2/common/errors"
 	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
 	"github.com/vitelabs/go-vite/v2/log15"
 	"github.com/vitelabs/go-vite/v2/wallet/entropystore"
 	"github.com/vitelabs/go-vite/v2/wallet/hd-bip/derivation"
@@ -25,6 +28,11 @@ type Manager struct {
 	unlockChangedLis    map[int]func(event entropystore.UnlockEvent)
 	mutex               sync.Mutex
 
+	policy       *policyEngine
+	audit        *auditLog
+	unlockTimers map[string]*time.Timer // key is the entropyStore`s abs path
+	unlockMutex  sync.Mutex
+
 	log log15.Logger
 }
 
@@ -42,6 +50,10 @@ func New(config *config.Wallet) *Manager {
 		unlockChangedLis:    make(map[int]func(event entropystore.UnlockEvent)),
 		entropyStoreManager: make(map[string]*entropystore.Manager),
 
+		policy:       newPolicyEngine(nil),
+		audit:        &auditLog{},
+		unlockTimers: make(map[string]*time.Timer),
+
 		log: log15.New("module", "wallet"),
 	}
 }
@@ -54,13 +66,64 @@ func (m Manager) ListAllEntropyFiles() []string {
 	return files
 }
 
+// Unlock unlocks the entropy store, it is locked again after the UnlockTimeout of the config
 func (m *Manager) Unlock(entropyStore, passphrase string) error {
+	return m.UnlockFor(entropyStore, passphrase, 0)
+}
+
+// UnlockFor unlocks the entropy store and locks it again after the timeout. The timeout can not exceed
+// the UnlockTimeout of the config, the UnlockTimeout is used if it is 0.
+func (m *Manager) UnlockFor(entropyStore, passphrase string, timeout time.Duration) error {
 	manager, e := m.GetEntropyStoreManager(entropyStore)
 	if e != nil {
 		return e
 	}
+	filename := manager.GetEntropyStoreFile()
+
+	maxTimeout := time.Duration(m.config.UnlockTimeout) * time.Second
+	if maxTimeout > 0 && timeout > maxTimeout {
+		return fmt.Errorf("the unlock timeout %s exceeds %s", timeout, maxTimeout)
+	}
+	if timeout <= 0 {
+		timeout = maxTimeout
+	}
+
+	entry := &AuditEntry{Action: AuditActionUnlock, EntropyFile: filename, Decision: AuditDecisionAllow}
+	if timeout > 0 {
+		entry.Reason = "locked after " + timeout.String()
+	}
+
+	m.unlockMutex.Lock()
+	defer m.unlockMutex.Unlock()
+
+	if e := manager.Unlock(passphrase); e != nil {
+		entry.Decision = AuditDecisionDeny
+		entry.Reason = e.Error()
+		m.writeAudit(entry)
+		return e
+	}
+	m.writeAudit(entry)
 
-	return manager.Unlock(passphrase)
+	if timer, ok := m.unlockTimers[filename]; ok {
+		timer.Stop()
+		delete(m.unlockTimers, filename)
+	}
+	if timeout > 0 {
+		var timer *time.Timer
+		timer = time.AfterFunc(timeout, func() {
+			m.unlockMutex.Lock()
+			defer m.unlockMutex.Unlock()
+			// the entropy store is unlocked again or locked
+			if m.unlockTimers[filename] != timer {
+				return
+			}
+			delete(m.unlockTimers, filename)
+			manager.Lock()
+			m.writeAudit(&AuditEntry{Action: AuditActionLock, EntropyFile: filename, Reason: "unlock expired"})
+		})
+		m.unlockTimers[filename] = timer
+	}
+	return nil
 }
 
 func (m *Manager) IsUnlocked(entropyStore string) bool {
@@ -76,7 +139,17 @@ func (m *Manager) Lock(entropyStore string) error {
 	if e != nil {
 		return e
 	}
+	filename := manager.GetEntropyStoreFile()
+
+	m.unlockMutex.Lock()
+	defer m.unlockMutex.Unlock()
+
+	if timer, ok := m.unlockTimers[filename]; ok {
+		timer.Stop()
+		delete(m.unlockTimers, filename)
+	}
 	manager.Lock()
+	m.writeAudit(&AuditEntry{Action: AuditActionLock, EntropyFile: filename})
 	return nil
 }
 
@@ -146,14 +219,11 @@ func (m Manager) AccountSearch(entryPath *string, target types.Address, passphra
 	if err != nil {
 		return nil, err
 	}
-	err = manager.Unlock(passphrase)
+	// the entropy store is not left unlocked by a call with the passphrase
+	key, _, err := manager.FindAddrWithPassphrase(passphrase, target)
 	if err != nil {
 		return nil, err
 	}
-	key, _, err := manager.FindAddr(target)
-	if err == walleterrors.ErrAddressNotFound {
-		return nil, err
-	}
 	return newAccount(target, key)
 }
 
@@ -221,6 +291,12 @@ func (m Manager) ExtractMnemonic(entropyStore string, passphrase string) (string
 	if e != nil {
 		return "", e
 	}
+	// the mnemonic exports the keys of all addresses of the entropy store
+	for addr := range m.policy.policies {
+		if _, _, e := manager.FindAddrWithPassphrase(passphrase, addr); e == nil {
+			return "", m.denyKeyExport(addr)
+		}
+	}
 	return manager.ExtractMnemonic(passphrase)
 
 }
@@ -314,6 +390,11 @@ func (m Manager) GetDataDir() string {
 }
 
 func (m *Manager) Start() error {
+	if err := m.startPolicy(); err != nil {
+		m.log.Error("wallet start policy err", "err", err)
+		return err
+	}
+
 	m.entropyStoreManager = make(map[string]*entropystore.Manager)
 	files, e := m.ListEntropyFilesInStandardDir()
 	if e != nil {
@@ -330,11 +411,23 @@ func (m *Manager) Start() error {
 }
 
 func (m *Manager) Stop() {
+	m.unlockMutex.Lock()
+	for filename, timer := range m.unlockTimers {
+		timer.Stop()
+		delete(m.unlockTimers, filename)
+	}
+	m.unlockMutex.Unlock()
+
 	for _, em := range m.entropyStoreManager {
 		em.Lock()
 		em.RemoveUnlockChangeChannel()
 	}
 	m.entropyStoreManager = nil
+
+	if err := m.audit.Close(); err != nil {
+		m.log.Error("wallet close audit log err", "err", err)
+	}
+	m.audit = &auditLog{}
 }
 
 func (m Manager) AddLockEventListener(lis func(event entropystore.UnlockEvent)) int {
@@ -369,3 +462,117 @@ func (m *Manager) MatchAddress(EntryPath string, coinbase types.Address, index u
 	}
 	return nil
 }
+
+func (m *Manager) startPolicy() error {
+	policy := newPolicyEngine(nil)
+	if m.config.PolicyFile != "" {
+		policies, err := LoadPolicies(m.config.PolicyFile)
+		if err != nil {
+			return err
+		}
+		policy = newPolicyEngine(policies)
+		m.log.Info(fmt.Sprintf("load %d transfer policies from %s", len(policies), m.config.PolicyFile))
+	}
+
+	if m.config.AuditLogFile != "" {
+		if err := restoreSpent(policy, m.config.AuditLogFile); err != nil {
+			return err
+		}
+	}
+	audit, err := openAuditLog(m.config.AuditLogFile)
+	if err != nil {
+		return err
+	}
+
+	m.policy = policy
+	m.audit = audit
+	return nil
+}
+
+func (m *Manager) writeAudit(entry *AuditEntry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	if entry.Decision == AuditDecisionDeny {
+		m.log.Warn(fmt.Sprintf("wallet %s denied: %s", entry.Action, entry.Reason))
+	}
+	if err := m.audit.Write(entry); err != nil {
+		m.log.Error("wallet write audit log err", "err", err)
+	}
+}
+
+// AuthorizeTransfer checks the transfer against the policy of the address before it is signed. The amount is
+// counted into the daily limit once the transfer is allowed, even if the transfer is not sent at last.
+// An *ApprovalRequiredError is returned if the transfer needs a second approval.
+func (m *Manager) AuthorizeTransfer(req *TransferRequest) error {
+	entry := transferAuditEntry(req)
+	entry.Time = time.Now()
+
+	err := m.policy.authorize(req, entry.Time)
+	switch e := err.(type) {
+	case nil:
+		entry.Decision = AuditDecisionAllow
+	case *ApprovalRequiredError:
+		entry.Decision = AuditDecisionPending
+		entry.ApprovalId = &e.Id
+	default:
+		entry.Decision = AuditDecisionDeny
+		entry.Reason = err.Error()
+	}
+	m.writeAudit(entry)
+	return err
+}
+
+// AuthorizeSignData checks whether arbitrary data can be signed by the address. It is denied for the
+// addresses with a transfer policy, since a signed block hash bypasses the policy.
+func (m *Manager) AuthorizeSignData(addr types.Address) error {
+	entry := &AuditEntry{Action: AuditActionSignData, Address: &addr, Decision: AuditDecisionAllow}
+	if m.policy.hasPolicy(addr) {
+		entry.Decision = AuditDecisionDeny
+		entry.Reason = ErrSignDataDenied.Error()
+		m.writeAudit(entry)
+		return ErrSignDataDenied
+	}
+	m.writeAudit(entry)
+	return nil
+}
+
+// AuthorizeKeyExport checks whether the private key of the address can be exported, it is denied for
+// the addresses with a transfer policy.
+func (m *Manager) AuthorizeKeyExport(addr types.Address) error {
+	if m.policy.hasPolicy(addr) {
+		return m.denyKeyExport(addr)
+	}
+	return nil
+}
+
+func (m *Manager) denyKeyExport(addr types.Address) error {
+	m.writeAudit(&AuditEntry{Action: AuditActionExport, Address: &addr, Decision: AuditDecisionDeny, Reason: ErrKeyExportDenied.Error()})
+	return ErrKeyExportDenied
+}
+
+// ApproveTransfer approves a pending transfer, the signature is the ed25519 signature of the approval id
+// signed by the approver of the policy
+func (m *Manager) ApproveTransfer(id types.Hash, pubkey ed25519.PublicKey, signature []byte) error {
+	entry := &AuditEntry{Action: AuditActionApprove, ApprovalId: &id, Decision: AuditDecisionAllow}
+
+	approval, err := m.policy.approve(id, pubkey, signature, time.Now())
+	if err != nil {
+		entry.Decision = AuditDecisionDeny
+		entry.Reason = err.Error()
+		m.writeAudit(entry)
+		return err
+	}
+
+	entry.Address = &approval.Address
+	entry.ToAddress = &approval.ToAddress
+	entry.TokenId = &approval.TokenId
+	entry.Amount = approval.Amount
+	m.writeAudit(entry)
+	return nil
+}
+
+// PendingApprovals returns the transfers waiting for approvals
+func (m *Manager) PendingApprovals() []*PendingApproval {
+	return m.policy.pendingApprovals(time.Now())
+}
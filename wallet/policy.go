@@ -0,0 +1,341 @@
+package wallet
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+)
+
+// a pending approval expires if the transfer is not approved and sent again in time
+const approvalTimeout = time.Hour
+
+var (
+	ErrDestinationNotAllowed = errors.New("the destination is not allowed by the transfer policy")
+	ErrDailyLimitExceeded    = errors.New("the daily limit of the transfer policy is exceeded")
+	ErrSignDataDenied        = errors.New("signing data is not allowed for an address with a transfer policy")
+	ErrKeyExportDenied       = errors.New("exporting the key is not allowed for an address with a transfer policy")
+	ErrApprovalNotFound      = errors.New("the pending approval is not found or expired")
+	ErrInvalidApproval       = errors.New("the approval is not signed by the approver of the transfer policy")
+)
+
+// ApprovalRequiredError is returned when a transfer needs a second approval. The transfer is sent
+// again with the same parameters after it is approved by ApproveTransfer.
+type ApprovalRequiredError struct {
+	Id types.Hash
+}
+
+func (e *ApprovalRequiredError) Error() string {
+	return fmt.Sprintf("the transfer needs a second approval, the approval id is %s", e.Id)
+}
+
+// Policy limits the transfers sent from an address
+type Policy struct {
+	Address types.Address
+
+	// the amount of a token sent in a day(UTC), tokens not in the map are not limited
+	DailyLimits map[types.TokenTypeId]*big.Int
+
+	// the transfers can be only sent to these addresses if it is not empty
+	AllowedDestinations map[types.Address]bool
+
+	// the transfers whose amount is not less than the threshold of the token need to be approved by
+	// the Approver, the thresholds are ignored if Approver is nil
+	ApprovalThresholds map[types.TokenTypeId]*big.Int
+	Approver           *types.Address
+}
+
+type policyJSON struct {
+	Address             types.Address     `json:"address"`
+	DailyLimits         map[string]string `json:"dailyLimits,omitempty"` // key is the token id
+	AllowedDestinations []types.Address   `json:"allowedDestinations,omitempty"`
+	ApprovalThresholds  map[string]string `json:"approvalThresholds,omitempty"` // key is the token id
+	Approver            *types.Address    `json:"approver,omitempty"`
+}
+
+func (p *Policy) UnmarshalJSON(input []byte) error {
+	var pj policyJSON
+	if err := json.Unmarshal(input, &pj); err != nil {
+		return err
+	}
+
+	dailyLimits, err := parseAmounts(pj.DailyLimits)
+	if err != nil {
+		return fmt.Errorf("dailyLimits of %s: %v", pj.Address, err)
+	}
+	thresholds, err := parseAmounts(pj.ApprovalThresholds)
+	if err != nil {
+		return fmt.Errorf("approvalThresholds of %s: %v", pj.Address, err)
+	}
+	if len(thresholds) > 0 && pj.Approver == nil {
+		return fmt.Errorf("approvalThresholds of %s are set without an approver", pj.Address)
+	}
+
+	p.Address = pj.Address
+	p.DailyLimits = dailyLimits
+	p.ApprovalThresholds = thresholds
+	p.Approver = pj.Approver
+	p.AllowedDestinations = make(map[types.Address]bool, len(pj.AllowedDestinations))
+	for _, addr := range pj.AllowedDestinations {
+		p.AllowedDestinations[addr] = true
+	}
+	return nil
+}
+
+func parseAmounts(amounts map[string]string) (map[types.TokenTypeId]*big.Int, error) {
+	result := make(map[types.TokenTypeId]*big.Int, len(amounts))
+	for tokenIdStr, str := range amounts {
+		tokenId, err := types.HexToTokenTypeId(tokenIdStr)
+		if err != nil {
+			return nil, err
+		}
+		amount, ok := new(big.Int).SetString(str, 10)
+		if !ok || amount.Sign() < 0 {
+			return nil, fmt.Errorf("invalid amount %s of %s", str, tokenId)
+		}
+		result[tokenId] = amount
+	}
+	return result, nil
+}
+
+// LoadPolicies reads the transfer policies from a json file like
+// {"policies": [{"address": "vite_...", "dailyLimits": {"tti_...": "1000"}, "allowedDestinations": ["vite_..."],
+// "approvalThresholds": {"tti_...": "100"}, "approver": "vite_..."}]}
+func LoadPolicies(filename string) (map[types.Address]*Policy, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Policies []*Policy `json:"policies"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse transfer policies %s: %v", filename, err)
+	}
+
+	policies := make(map[types.Address]*Policy, len(file.Policies))
+	for _, policy := range file.Policies {
+		if _, ok := policies[policy.Address]; ok {
+			return nil, fmt.Errorf("duplicated transfer policies of %s", policy.Address)
+		}
+		policies[policy.Address] = policy
+	}
+	return policies, nil
+}
+
+// TransferRequest is a transfer to be signed by the wallet
+type TransferRequest struct {
+	Address   types.Address
+	ToAddress types.Address
+	TokenId   types.TokenTypeId
+	Amount    *big.Int
+}
+
+func (req *TransferRequest) sameAs(other *TransferRequest) bool {
+	return req.Address == other.Address && req.ToAddress == other.ToAddress &&
+		req.TokenId == other.TokenId && req.Amount.Cmp(other.Amount) == 0
+}
+
+// PendingApproval is a transfer waiting for the approval of the approver
+type PendingApproval struct {
+	Id        types.Hash        `json:"id"`
+	Address   types.Address     `json:"address"`
+	ToAddress types.Address     `json:"toAddress"`
+	TokenId   types.TokenTypeId `json:"tokenId"`
+	Amount    string            `json:"amount"`
+	Approver  types.Address     `json:"approver"`
+	Approved  bool              `json:"approved"`
+	Expire    time.Time         `json:"expire"`
+
+	request *TransferRequest
+}
+
+// policyEngine checks the transfers against the policies, it keeps the amounts sent today and
+// the pending approvals.
+type policyEngine struct {
+	policies map[types.Address]*Policy
+
+	day   string
+	spent map[types.Address]map[types.TokenTypeId]*big.Int
+
+	approvals map[types.Hash]*PendingApproval
+
+	mu sync.Mutex
+}
+
+func newPolicyEngine(policies map[types.Address]*Policy) *policyEngine {
+	return &policyEngine{
+		policies:  policies,
+		spent:     make(map[types.Address]map[types.TokenTypeId]*big.Int),
+		approvals: make(map[types.Hash]*PendingApproval),
+	}
+}
+
+func dayOf(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+func (pe *policyEngine) hasPolicy(addr types.Address) bool {
+	_, ok := pe.policies[addr]
+	return ok
+}
+
+// resetDay clears the amounts sent if the day is changed, it must be called with the lock held
+func (pe *policyEngine) resetDay(now time.Time) {
+	if day := dayOf(now); day != pe.day {
+		pe.day = day
+		pe.spent = make(map[types.Address]map[types.TokenTypeId]*big.Int)
+	}
+}
+
+func (pe *policyEngine) spentOf(addr types.Address, tokenId types.TokenTypeId) *big.Int {
+	tokens, ok := pe.spent[addr]
+	if !ok {
+		tokens = make(map[types.TokenTypeId]*big.Int)
+		pe.spent[addr] = tokens
+	}
+	amount, ok := tokens[tokenId]
+	if !ok {
+		amount = new(big.Int)
+		tokens[tokenId] = amount
+	}
+	return amount
+}
+
+// addSpent restores the amount of a transfer allowed at t
+func (pe *policyEngine) addSpent(req *TransferRequest, t time.Time) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	now := time.Now()
+	pe.resetDay(now)
+	if dayOf(t) != pe.day {
+		return
+	}
+	spent := pe.spentOf(req.Address, req.TokenId)
+	spent.Add(spent, req.Amount)
+}
+
+// authorize checks a transfer, the amount is counted into the daily limit once the transfer is allowed.
+// It returns an *ApprovalRequiredError if the transfer waits for an approval.
+func (pe *policyEngine) authorize(req *TransferRequest, now time.Time) error {
+	policy, ok := pe.policies[req.Address]
+	if !ok {
+		return nil
+	}
+
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	if len(policy.AllowedDestinations) > 0 && !policy.AllowedDestinations[req.ToAddress] {
+		return ErrDestinationNotAllowed
+	}
+
+	pe.resetDay(now)
+	spent := pe.spentOf(req.Address, req.TokenId)
+	if limit, ok := policy.DailyLimits[req.TokenId]; ok {
+		if new(big.Int).Add(spent, req.Amount).Cmp(limit) > 0 {
+			return ErrDailyLimitExceeded
+		}
+	}
+
+	if threshold, ok := policy.ApprovalThresholds[req.TokenId]; ok && policy.Approver != nil && req.Amount.Cmp(threshold) >= 0 {
+		pe.removeExpiredApprovals(now)
+
+		approval := pe.findApproval(req)
+		if approval == nil {
+			approval = pe.newApproval(req, *policy.Approver, now)
+		}
+		if !approval.Approved {
+			return &ApprovalRequiredError{Id: approval.Id}
+		}
+		delete(pe.approvals, approval.Id)
+	}
+
+	spent.Add(spent, req.Amount)
+	return nil
+}
+
+func (pe *policyEngine) findApproval(req *TransferRequest) *PendingApproval {
+	for _, approval := range pe.approvals {
+		if approval.request.sameAs(req) {
+			return approval
+		}
+	}
+	return nil
+}
+
+func (pe *policyEngine) newApproval(req *TransferRequest, approver types.Address, now time.Time) *PendingApproval {
+	data := make([]byte, 0, types.AddressSize*2+types.TokenTypeIdSize+8+len(req.Amount.Bytes()))
+	data = append(data, req.Address.Bytes()...)
+	data = append(data, req.ToAddress.Bytes()...)
+	data = append(data, req.TokenId.Bytes()...)
+	data = append(data, req.Amount.Bytes()...)
+	var nano [8]byte
+	binary.BigEndian.PutUint64(nano[:], uint64(now.UnixNano()))
+	data = append(data, nano[:]...)
+
+	approval := &PendingApproval{
+		Id:        types.DataHash(data),
+		Address:   req.Address,
+		ToAddress: req.ToAddress,
+		TokenId:   req.TokenId,
+		Amount:    req.Amount.String(),
+		Approver:  approver,
+		Expire:    now.Add(approvalTimeout),
+		request:   req,
+	}
+	pe.approvals[approval.Id] = approval
+	return approval
+}
+
+func (pe *policyEngine) removeExpiredApprovals(now time.Time) {
+	for id, approval := range pe.approvals {
+		if now.After(approval.Expire) {
+			delete(pe.approvals, id)
+		}
+	}
+}
+
+// approve marks a pending approval approved, the signature is the signature of the approval id
+// signed by the approver.
+func (pe *policyEngine) approve(id types.Hash, pubkey ed25519.PublicKey, signature []byte, now time.Time) (*PendingApproval, error) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	pe.removeExpiredApprovals(now)
+	approval, ok := pe.approvals[id]
+	if !ok {
+		return nil, ErrApprovalNotFound
+	}
+	if types.PubkeyToAddress(pubkey) != approval.Approver {
+		return nil, ErrInvalidApproval
+	}
+	if err := ed25519.VerifySig(pubkey, id.Bytes(), signature); err != nil {
+		return nil, ErrInvalidApproval
+	}
+
+	approval.Approved = true
+	return approval, nil
+}
+
+func (pe *policyEngine) pendingApprovals(now time.Time) []*PendingApproval {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	pe.removeExpiredApprovals(now)
+	result := make([]*PendingApproval, 0, len(pe.approvals))
+	for _, approval := range pe.approvals {
+		result = append(result, approval)
+	}
+	return result
+}
@@ -0,0 +1,138 @@
+package wallet_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/common/fileutils"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/wallet"
+)
+
+func newPolicyManager(t *testing.T, dir string, policies string) *wallet.Manager {
+	policyFile := filepath.Join(dir, "policies.json")
+	assert.NoError(t, ioutil.WriteFile(policyFile, []byte(policies), 0600))
+
+	manager := wallet.New(&config.Wallet{
+		DataDir:      dir,
+		PolicyFile:   policyFile,
+		AuditLogFile: filepath.Join(dir, "audit.log"),
+	})
+	assert.NoError(t, manager.Start())
+	return manager
+}
+
+func TestManager_AuthorizeTransfer(t *testing.T) {
+	dir := fileutils.CreateTempDir()
+	defer os.RemoveAll(dir)
+
+	from, _ := wallet.RandomAccount()
+	to, _ := wallet.RandomAccount()
+	other, _ := wallet.RandomAccount()
+	approver, _ := wallet.RandomAccount()
+	tokenId := ledger.ViteTokenId
+
+	manager := newPolicyManager(t, dir, fmt.Sprintf(`{"policies": [{
+		"address": "%s",
+		"dailyLimits": {"%s": "100"},
+		"allowedDestinations": ["%s"],
+		"approvalThresholds": {"%s": "50"},
+		"approver": "%s"
+	}]}`, from.Address(), tokenId, to.Address(), tokenId, approver.Address()))
+
+	transfer := func(toAddr types.Address, amount int64) *wallet.TransferRequest {
+		return &wallet.TransferRequest{Address: from.Address(), ToAddress: toAddr, TokenId: tokenId, Amount: big.NewInt(amount)}
+	}
+
+	assert.Equal(t, wallet.ErrDestinationNotAllowed, manager.AuthorizeTransfer(transfer(other.Address(), 1)))
+	assert.NoError(t, manager.AuthorizeTransfer(transfer(to.Address(), 40)))
+
+	// large transfers wait for the approval
+	err := manager.AuthorizeTransfer(transfer(to.Address(), 50))
+	approvalErr, ok := err.(*wallet.ApprovalRequiredError)
+	if !assert.True(t, ok) {
+		t.FailNow()
+	}
+	assert.Equal(t, 1, len(manager.PendingApprovals()))
+
+	// the approval must be signed by the approver
+	signature, pubkey, _ := other.Sign(approvalErr.Id.Bytes())
+	assert.Equal(t, wallet.ErrInvalidApproval, manager.ApproveTransfer(approvalErr.Id, pubkey, signature))
+	signature, pubkey, _ = approver.Sign(approvalErr.Id.Bytes())
+	assert.NoError(t, manager.ApproveTransfer(approvalErr.Id, pubkey, signature))
+
+	assert.NoError(t, manager.AuthorizeTransfer(transfer(to.Address(), 50)))
+	assert.Empty(t, manager.PendingApprovals())
+
+	assert.Equal(t, wallet.ErrDailyLimitExceeded, manager.AuthorizeTransfer(transfer(to.Address(), 20)))
+	assert.NoError(t, manager.AuthorizeTransfer(transfer(to.Address(), 10)))
+
+	// addresses without a policy are not limited
+	assert.NoError(t, manager.AuthorizeTransfer(&wallet.TransferRequest{Address: to.Address(), ToAddress: other.Address(), TokenId: tokenId, Amount: big.NewInt(1000)}))
+	assert.NoError(t, manager.AuthorizeSignData(to.Address()))
+	assert.Equal(t, wallet.ErrSignDataDenied, manager.AuthorizeSignData(from.Address()))
+	assert.Equal(t, wallet.ErrKeyExportDenied, manager.AuthorizeKeyExport(from.Address()))
+	manager.Stop()
+
+	entries, err := wallet.ReadAuditLog(filepath.Join(dir, "audit.log"))
+	assert.NoError(t, err)
+	decisions := make(map[string]int)
+	for _, entry := range entries {
+		decisions[entry.Action+"/"+entry.Decision]++
+	}
+	assert.Equal(t, map[string]int{
+		"transfer/deny":    2,
+		"transfer/pending": 1,
+		"transfer/allow":   4,
+		"approve/deny":     1,
+		"approve/allow":    1,
+		"signData/allow":   1,
+		"signData/deny":    1,
+		"export/deny":      1,
+	}, decisions)
+
+	// the amounts sent today are restored from the audit log
+	manager = newPolicyManager(t, dir, fmt.Sprintf(`{"policies": [{"address": "%s", "dailyLimits": {"%s": "100"}}]}`, from.Address(), tokenId))
+	defer manager.Stop()
+	assert.Equal(t, wallet.ErrDailyLimitExceeded, manager.AuthorizeTransfer(transfer(to.Address(), 1)))
+}
+
+func TestManager_UnlockFor(t *testing.T) {
+	dir := fileutils.CreateTempDir()
+	defer os.RemoveAll(dir)
+
+	manager := wallet.New(&config.Wallet{
+		DataDir:       dir,
+		UnlockTimeout: 60,
+	})
+	assert.NoError(t, manager.Start())
+	defer manager.Stop()
+
+	_, em, err := manager.NewMnemonicAndEntropyStore("123456")
+	assert.NoError(t, err)
+	filename := em.GetEntropyStoreFile()
+
+	assert.Error(t, manager.UnlockFor(filename, "123456", time.Hour))
+	assert.Error(t, manager.UnlockFor(filename, "654321", time.Second))
+	assert.False(t, manager.IsUnlocked(filename))
+
+	assert.NoError(t, manager.UnlockFor(filename, "123456", 100*time.Millisecond))
+	assert.True(t, manager.IsUnlocked(filename))
+	time.Sleep(300 * time.Millisecond)
+	assert.False(t, manager.IsUnlocked(filename))
+
+	// unlocking again resets the timer
+	assert.NoError(t, manager.UnlockFor(filename, "123456", 100*time.Millisecond))
+	assert.NoError(t, manager.Unlock(filename, "123456"))
+	time.Sleep(300 * time.Millisecond)
+	assert.True(t, manager.IsUnlocked(filename))
+}
@@ -0,0 +1,123 @@
+package remotesigner
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+)
+
+const (
+	dialTimeout = 10 * time.Second
+	callTimeout = 10 * time.Second
+)
+
+type jsonRequest struct {
+	Version string        `json:"jsonrpc"`
+	Id      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type jsonError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type jsonResponse struct {
+	Id     uint64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *jsonError      `json:"error"`
+}
+
+// Client is an interfaces.Signer signing by the daemon at the unix socket endpoint. The calls are
+// sent one by one, and the connection is dialed again after an error.
+type Client struct {
+	endpoint string
+
+	conn   net.Conn
+	dec    *json.Decoder
+	nextId uint64
+	mu     sync.Mutex
+}
+
+func Dial(endpoint string) (*Client, error) {
+	c := &Client{endpoint: endpoint}
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Client) connect() error {
+	conn, err := net.DialTimeout("unix", c.endpoint, dialTimeout)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	c.dec = json.NewDecoder(conn)
+	return nil
+}
+
+func (c *Client) call(result interface{}, method string, params ...interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		if err := c.connect(); err != nil {
+			return err
+		}
+	}
+
+	c.nextId++
+	req := &jsonRequest{Version: "2.0", Id: c.nextId, Method: method, Params: params}
+	resp := &jsonResponse{}
+
+	err := c.conn.SetDeadline(time.Now().Add(callTimeout))
+	if err == nil {
+		err = json.NewEncoder(c.conn).Encode(req)
+	}
+	if err == nil {
+		err = c.dec.Decode(resp)
+	}
+	if err == nil && resp.Id != req.Id {
+		err = fmt.Errorf("response id %d does not match request id %d", resp.Id, req.Id)
+	}
+	if err != nil {
+		c.conn.Close()
+		c.conn = nil
+		return err
+	}
+
+	if resp.Error != nil {
+		return fmt.Errorf("%s: %s", method, resp.Error.Message)
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+func (c *Client) Addresses() ([]types.Address, error) {
+	var addresses []types.Address
+	err := c.call(&addresses, Namespace+"_addresses")
+	return addresses, err
+}
+
+func (c *Client) SignData(addr types.Address, data []byte) (signedData, pubkey []byte, err error) {
+	result := &SignResult{}
+	if err := c.call(result, Namespace+"_signData", addr, data); err != nil {
+		return nil, nil, err
+	}
+	return result.Signature, result.PublicKey, nil
+}
+
+func (c *Client) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+	}
+}
@@ -0,0 +1,86 @@
+package remotesigner_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+	"github.com/vitelabs/go-vite/v2/rpc"
+	"github.com/vitelabs/go-vite/v2/wallet"
+	"github.com/vitelabs/go-vite/v2/wallet/remotesigner"
+)
+
+type keySigner map[types.Address]ed25519.PrivateKey
+
+func (ks keySigner) SignData(addr types.Address, data []byte) (signedData, pubkey []byte, err error) {
+	key, ok := ks[addr]
+	if !ok {
+		return nil, nil, errors.New("key not found")
+	}
+	return ed25519.Sign(key, data), key.PubByte(), nil
+}
+
+func TestRemoteSigner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signer")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	addr := types.PrikeyToAddress(key)
+	otherAddr := types.PrikeyToAddress(otherKey)
+
+	// the key of otherAddr is held but not served
+	service := remotesigner.NewService(keySigner{addr: key, otherAddr: otherKey}, []types.Address{addr})
+	endpoint := filepath.Join(dir, "signer.ipc")
+	listener, _, err := rpc.StartIPCEndpoint(endpoint, []rpc.API{{
+		Namespace: remotesigner.Namespace,
+		Service:   service,
+		Public:    true,
+	}})
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	client, err := remotesigner.Dial(endpoint)
+	assert.NoError(t, err)
+	defer client.Close()
+
+	addresses, err := client.Addresses()
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Address{addr}, addresses)
+
+	msg := []byte("snapshot block hash")
+	signature, pubkey, err := client.SignData(addr, msg)
+	assert.NoError(t, err)
+	assert.Equal(t, key.PubByte(), pubkey)
+	assert.NoError(t, ed25519.VerifySig(pubkey, msg, signature))
+
+	_, _, err = client.SignData(otherAddr, msg)
+	assert.Error(t, err)
+
+	// the account used by the producer
+	account, err := wallet.NewSignerAccount(client, addr)
+	assert.NoError(t, err)
+	signature, pub, err := account.Sign(msg)
+	assert.NoError(t, err)
+	assert.NoError(t, account.Verify(pub, msg, signature))
+
+	signer := account.CryptoSigner()
+	assert.Equal(t, ed25519.PublicKey(key.PubByte()), signer.Public())
+	signature, err = signer.Sign(nil, msg, crypto.Hash(0))
+	assert.NoError(t, err)
+	assert.NoError(t, ed25519.VerifySig(key.PubByte(), msg, signature))
+
+	_, err = wallet.NewSignerAccount(client, otherAddr)
+	assert.Error(t, err)
+}
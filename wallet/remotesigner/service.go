@@ -0,0 +1,64 @@
+// Package remotesigner signs blocks by a signing daemon outside the gvite process. The daemon serves
+// JSON-RPC over a unix socket(a named pipe on windows) in the namespace "signer":
+//
+//	signer_addresses() []Address
+//	signer_signData(address Address, data []byte) {"signature": []byte, "publicKey": []byte}
+//
+// The daemon can front an HSM or an air-gapped box. Service is a stand-in backed by any interfaces.Signer,
+// it is served by the rpc package, see the signer command of gvite.
+package remotesigner
+
+import (
+	"fmt"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+const Namespace = "signer"
+
+type SignResult struct {
+	Signature []byte `json:"signature"`
+	PublicKey []byte `json:"publicKey"`
+}
+
+// Service signs data with the keys of the allowed addresses
+type Service struct {
+	signer    interfaces.Signer
+	addresses []types.Address
+	allowed   map[types.Address]bool
+
+	log log15.Logger
+}
+
+func NewService(signer interfaces.Signer, addresses []types.Address) *Service {
+	allowed := make(map[types.Address]bool, len(addresses))
+	for _, addr := range addresses {
+		allowed[addr] = true
+	}
+	return &Service{
+		signer:    signer,
+		addresses: addresses,
+		allowed:   allowed,
+		log:       log15.New("module", "remotesigner"),
+	}
+}
+
+func (s *Service) Addresses() []types.Address {
+	return s.addresses
+}
+
+func (s *Service) SignData(addr types.Address, data []byte) (*SignResult, error) {
+	if !s.allowed[addr] {
+		s.log.Warn("refuse to sign", "addr", addr)
+		return nil, fmt.Errorf("address %s is not served by the signer", addr)
+	}
+	signature, pubkey, err := s.signer.SignData(addr, data)
+	if err != nil {
+		s.log.Error("sign data failed", "addr", addr, "err", err)
+		return nil, err
+	}
+	s.log.Info("sign data", "addr", addr, "len", len(data))
+	return &SignResult{Signature: signature, PublicKey: pubkey}, nil
+}
@@ -0,0 +1,86 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+)
+
+// SignerAccount is an account whose key is kept by a Signer, the key never enters the process
+type SignerAccount struct {
+	signer  interfaces.Signer
+	address types.Address
+	pub     ed25519.PublicKey
+}
+
+// NewSignerAccount checks that the signer holds the key of the address by signing a random message
+func NewSignerAccount(signer interfaces.Signer, address types.Address) (*SignerAccount, error) {
+	msg := make([]byte, 32)
+	if _, err := rand.Read(msg); err != nil {
+		return nil, err
+	}
+	signedData, pubkey, err := signer.SignData(address, msg)
+	if err != nil {
+		return nil, err
+	}
+	if types.PubkeyToAddress(pubkey) != address {
+		return nil, fmt.Errorf("the signer returns the public key of %s, not %s", types.PubkeyToAddress(pubkey), address)
+	}
+	if err := ed25519.VerifySig(pubkey, msg, signedData); err != nil {
+		return nil, err
+	}
+
+	return &SignerAccount{
+		signer:  signer,
+		address: address,
+		pub:     ed25519.PublicKey(pubkey),
+	}, nil
+}
+
+func (acct *SignerAccount) Address() types.Address {
+	return acct.address
+}
+
+func (acct *SignerAccount) Sign(msg []byte) (signData []byte, pub ed25519.PublicKey, err error) {
+	signData, pubkey, err := acct.signer.SignData(acct.address, msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(pubkey, acct.pub) {
+		return nil, nil, errors.New("the signer returns a different public key")
+	}
+	return signData, acct.pub, nil
+}
+
+func (acct *SignerAccount) Verify(pub ed25519.PublicKey, message, signdata []byte) error {
+	return ed25519.VerifySig(pub, message, signdata)
+}
+
+// CryptoSigner returns the account as a crypto.Signer, like an ed25519.PrivateKey
+func (acct *SignerAccount) CryptoSigner() crypto.Signer {
+	return cryptoSigner{acct}
+}
+
+type cryptoSigner struct {
+	acct *SignerAccount
+}
+
+func (s cryptoSigner) Public() crypto.PublicKey {
+	return s.acct.pub
+}
+
+func (s cryptoSigner) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if opts.HashFunc() != crypto.Hash(0) {
+		return nil, errors.New("ed25519: cannot sign hashed message")
+	}
+	signData, _, err := s.acct.Sign(message)
+	return signData, err
+}